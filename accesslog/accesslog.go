@@ -0,0 +1,175 @@
+// Package accesslog writes HTTP access logs to a Rolog, so that web services
+// get rotating access logs by wrapping their handler.
+package accesslog
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/haleyrc/rolog"
+)
+
+// Format is the layout of the access log lines.
+type Format int
+
+const (
+	// Combined is the Apache combined log format:
+	//
+	//	host - user [time] "request" status bytes "referer" "user agent"
+	Combined Format = iota
+	// JSON writes each request as a JSON object.
+	JSON
+)
+
+// combinedTime is the timestamp layout of the combined format.
+const combinedTime = "02/Jan/2006:15:04:05 -0700"
+
+// Middleware returns middleware that writes a line in the given format to r
+// for every request, once the wrapped handler has returned.
+func Middleware(r *rolog.Rolog, format Format) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			rec := &recorder{ResponseWriter: w}
+			start := time.Now()
+			next.ServeHTTP(rec, req)
+			r.Write(line(format, req, rec, start, time.Since(start)))
+		})
+	}
+}
+
+// entry is a request as written by the JSON format.
+type entry struct {
+	Time      time.Time `json:"time"`
+	Remote    string    `json:"remote"`
+	User      string    `json:"user,omitempty"`
+	Method    string    `json:"method"`
+	URI       string    `json:"uri"`
+	Proto     string    `json:"proto"`
+	Status    int       `json:"status"`
+	Bytes     int64     `json:"bytes"`
+	Duration  float64   `json:"duration"`
+	Referer   string    `json:"referer,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+}
+
+// line formats a single access log line, including the trailing newline.
+func line(format Format, req *http.Request, rec *recorder, start time.Time, took time.Duration) []byte {
+	host := req.RemoteAddr
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	user, _, _ := req.BasicAuth()
+	status := rec.statusCode()
+
+	if format == JSON {
+		b, _ := json.Marshal(entry{
+			Time:      start,
+			Remote:    host,
+			User:      user,
+			Method:    req.Method,
+			URI:       req.RequestURI,
+			Proto:     req.Proto,
+			Status:    status,
+			Bytes:     rec.written,
+			Duration:  took.Seconds(),
+			Referer:   req.Referer(),
+			UserAgent: req.UserAgent(),
+		})
+		return append(b, '\n')
+	}
+
+	b := make([]byte, 0, 256)
+	b = append(b, host...)
+	b = append(b, " - "...)
+	b = append(b, dash(user)...)
+	b = append(b, " ["...)
+	b = start.AppendFormat(b, combinedTime)
+	b = append(b, "] "...)
+	b = strconv.AppendQuote(b, req.Method+" "+req.RequestURI+" "+req.Proto)
+	b = append(b, ' ')
+	b = strconv.AppendInt(b, int64(status), 10)
+	b = append(b, ' ')
+	if rec.written > 0 {
+		b = strconv.AppendInt(b, rec.written, 10)
+	} else {
+		b = append(b, '-')
+	}
+	b = append(b, ' ')
+	b = strconv.AppendQuote(b, dash(req.Referer()))
+	b = append(b, ' ')
+	b = strconv.AppendQuote(b, dash(req.UserAgent()))
+	return append(b, '\n')
+}
+
+// dash returns s, or "-" if it is empty, as the combined format does for
+// missing fields.
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// recorder is an http.ResponseWriter that records the status and size of the
+// response.
+type recorder struct {
+	http.ResponseWriter
+	status  int
+	written int64
+}
+
+// statusCode returns the status sent, which is 200 if the handler never set
+// one.
+func (rec *recorder) statusCode() int {
+	if rec.status == 0 {
+		return http.StatusOK
+	}
+	return rec.status
+}
+
+// WriteHeader satisfies http.ResponseWriter.
+func (rec *recorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Write satisfies http.ResponseWriter.
+func (rec *recorder) Write(p []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(p)
+	rec.written += int64(n)
+	return n, err
+}
+
+// Flush satisfies http.Flusher if the underlying writer does.
+func (rec *recorder) Flush() {
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack satisfies http.Hijacker if the underlying writer does, so that
+// websocket upgrades still work.
+func (rec *recorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rec.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	if rec.status == 0 {
+		rec.status = http.StatusSwitchingProtocols
+	}
+	return h.Hijack()
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (rec *recorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
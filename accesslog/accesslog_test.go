@@ -0,0 +1,69 @@
+package accesslog
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/haleyrc/rolog"
+)
+
+func TestMiddlewareWritesAccessLog(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := rolog.New(dir, "access")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/missing" {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write([]byte("hello"))
+	})
+
+	req := httptest.NewRequest("GET", "/hello?x=1", nil)
+	req.RemoteAddr = "192.0.2.1:4321"
+	req.SetBasicAuth("alice", "secret")
+	req.Header.Set("Referer", "http://example.com/")
+	req.Header.Set("User-Agent", `curl/8.0 "quoted"`)
+	Middleware(r, Combined)(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+	got, _ := ioutil.ReadFile(r.CurrentPath())
+	combined := regexp.MustCompile(`^192\.0\.2\.1 - alice \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [-+]\d{4}\] "GET /hello\?x=1 HTTP/1\.1" 200 5 "http://example\.com/" "curl/8\.0 \\"quoted\\""\n$`)
+	if !combined.Match(got) {
+		t.Errorf("Wanted a combined format line, got %q", got)
+	}
+
+	os.Truncate(r.CurrentPath(), 0)
+	req = httptest.NewRequest("GET", "/missing", nil)
+	Middleware(r, JSON)(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+	got, _ = ioutil.ReadFile(r.CurrentPath())
+	var e entry
+	if err := json.Unmarshal(got, &e); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if e.Method != "GET" || e.URI != "/missing" || e.Status != http.StatusNotFound || e.Bytes == 0 {
+		t.Errorf("Wanted the request and a 404 response, got %+v", e)
+	}
+}
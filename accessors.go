@@ -0,0 +1,46 @@
+package rolog
+
+import "time"
+
+// CurrentPath returns the path of the file currently being written.
+func (r *Rolog) CurrentPath() string {
+	return r.currentPath()
+}
+
+// currentPath returns the path of the current file. It is safe to call with
+// or without mu held.
+func (r *Rolog) currentPath() string {
+	p, _ := r.path.Load().(string)
+	return p
+}
+
+// setPath changes the path of the current file. It must be called with mu
+// held, or before the Rolog is shared.
+func (r *Rolog) setPath(path string) {
+	r.path.Store(path)
+}
+
+// CurrentSize returns the number of bytes in the file currently being written.
+func (r *Rolog) CurrentSize() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.size
+}
+
+// NextRotation returns when the next scheduled rotation is due. It is zero if
+// the run loop isn't running or scheduled rotation is disabled.
+func (r *Rolog) NextRotation() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.next
+}
+
+// setNext records when the next scheduled rotation is due.
+func (r *Rolog) setNext(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.next = t
+}
@@ -0,0 +1,54 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAccessorsReportState(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithInterval(time.Hour), WithClock(clock))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	if r.CurrentPath() != filepath.Join(dir, "test.log") {
+		t.Errorf("Wanted path %s, got %s", filepath.Join(dir, "test.log"), r.CurrentPath())
+	}
+
+	r.Write([]byte("hello\n"))
+	if r.CurrentSize() != 6 {
+		t.Errorf("Wanted size 6, got %d", r.CurrentSize())
+	}
+
+	if !r.NextRotation().IsZero() {
+		t.Errorf("Wanted no next rotation before Run, got %s", r.NextRotation())
+	}
+
+	r.Run(context.Background())
+	for clock.numTickers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if want := clock.Now().Add(time.Hour); !r.NextRotation().Equal(want) {
+		t.Errorf("Wanted next rotation at %s, got %s", want, r.NextRotation())
+	}
+}
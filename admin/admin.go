@@ -0,0 +1,67 @@
+// Package admin serves an HTTP endpoint for operating a Rolog from outside its
+// process, such as with the rolog command.
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/haleyrc/rolog"
+)
+
+// Handler returns a handler for operating r. It serves three paths relative to
+// wherever it is mounted, so it is usually wrapped in http.StripPrefix:
+//
+//	POST /rotate    rotates r, responding 204 No Content once it is done
+//	GET  /archives  lists the archives of r as JSON, oldest first
+//	GET  /verify    verifies the archives of r, reporting on them as JSON
+//
+// The handler has no authentication of its own, so it should only be served
+// on a private address or behind middleware that provides it.
+func Handler(r *rolog.Rolog) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rotate", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.Rotate(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/archives", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		archives, err := r.Archives()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if archives == nil {
+			archives = []rolog.ArchiveInfo{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(archives)
+	})
+	mux.HandleFunc("/verify", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		report, err := r.Verify(req.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	})
+	return mux
+}
@@ -0,0 +1,119 @@
+package admin
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/haleyrc/rolog"
+)
+
+func TestHandlerRotatesAndLists(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := rolog.New(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	srv := httptest.NewServer(http.StripPrefix("/rolog", Handler(r)))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/rolog/rotate")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Wanted GET /rotate to be refused, got %s", resp.Status)
+	}
+
+	r.Write([]byte("hello\n"))
+	resp, err = http.Post(srv.URL+"/rolog/rotate", "", nil)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Wanted %d, got %s", http.StatusNoContent, resp.Status)
+	}
+
+	resp, err = http.Get(srv.URL + "/rolog/archives")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer resp.Body.Close()
+
+	var archives []rolog.ArchiveInfo
+	if err := json.NewDecoder(resp.Body).Decode(&archives); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(archives) != 1 || archives[0].Size != 6 {
+		t.Errorf("Wanted a single archive of 6 bytes, got %+v", archives)
+	}
+}
+
+func TestHandlerVerifies(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := rolog.New(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	srv := httptest.NewServer(Handler(r))
+	defer srv.Close()
+
+	r.Write([]byte("hello\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	resp, err := http.Get(srv.URL + "/verify")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer resp.Body.Close()
+
+	var report rolog.VerifyReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if !report.OK() || len(report.Verified) != 1 {
+		t.Errorf("Wanted a single verified archive, got %+v", report)
+	}
+}
@@ -0,0 +1,98 @@
+package rolog
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Adopt brings the file at path, such as one left by the tool the log took
+// over from, into the log as an archive rotated out at at, so that it is named,
+// compressed, encrypted and expired like the log's own archives instead of
+// being left unmanaged beside them. A zero at means the file's modification
+// time. The file is moved into the log's directory, or copied and removed if
+// it can't be renamed there, and a file already compressed with one of the
+// supported codecs keeps its extension so it isn't compressed again.
+//
+// Adopt returns the path the archive ends up at once processed; retention
+// runs straight afterwards, so an archive older than it allows is deleted
+// again at once.
+func (r *Rolog) Adopt(path string, at time.Time) (string, error) {
+	r.mu.Lock()
+	if r.closed() {
+		r.mu.Unlock()
+		return "", ErrClosed
+	}
+	if r.passthrough != nil {
+		r.mu.Unlock()
+		return "", errors.New("cannot adopt into a pass-through log")
+	}
+	var (
+		s   = r.archiveSettings()
+		dir = filepath.Dir(r.currentPath())
+	)
+	r.mu.Unlock()
+
+	return r.adopt(s, dir, path, at)
+}
+
+// AdoptFile adopts the file at path into the log named name in dir, as Adopt
+// would for a Rolog created with the same arguments, but without opening the
+// log, for tools that tidy up after a migration. Only the naming and archive
+// processing options, such as WithCompression and the retention limits,
+// affect the result.
+func AdoptFile(dir, name, path string, at time.Time, opts ...Option) (string, error) {
+	r := newRolog(name, opts)
+	r.setPath(filepath.Join(dir, fmt.Sprintf(r.currentFormat(), name)))
+	return r.adopt(r.archiveSettings(), dir, path, at)
+}
+
+// adopt adopts the file at path as an archive of the log in dir under s.
+func (r *Rolog) adopt(s archiveSettings, dir, path string, at time.Time) (adopted string, err error) {
+	defer func() { r.audit(AuditAdopt, path, err) }()
+
+	fi, err := r.fs.Stat(path)
+	if err != nil {
+		return "", errors.Wrap(err, "could not adopt log file")
+	}
+	if fi.IsDir() {
+		return "", errors.Errorf("cannot adopt directory %s", path)
+	}
+	if at.IsZero() {
+		at = fi.ModTime()
+	}
+
+	var ext string
+	for _, c := range compressedExts {
+		if strings.HasSuffix(path, c) {
+			ext = c
+		}
+	}
+
+	r.rotMu.Lock()
+	defer r.rotMu.Unlock()
+
+	prefix, layout := r.archiveLayout()
+	dst := r.uniquePath(filepath.Join(dir, prefix+at.Local().Format(layout))) + ext
+	r.debugf("adopting %s as %s", path, dst)
+	if err := moveFile(r.fs, path, dst, r.perm); err != nil {
+		return "", errors.Wrap(err, "could not adopt log file")
+	}
+
+	if s.dryRun != nil {
+		return dst, r.dryRunProcess(s, dst)
+	}
+	if dst, err = r.finalize(dst, s); err != nil {
+		return dst, errors.Wrap(err, "could not finalize adopted archive")
+	}
+	if err := r.prune(s); err != nil {
+		return dst, errors.Wrap(err, "could not prune archives")
+	}
+	if err := r.bundle(s); err != nil {
+		return dst, errors.Wrap(err, "could not bundle archives")
+	}
+	return dst, nil
+}
@@ -0,0 +1,144 @@
+package rolog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAdoptBringsFilesIntoTheArchives(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	old := filepath.Join(dir, "old")
+	if err := os.Mkdir(old, 0755); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("oldest\n"))
+	zw.Close()
+	files := map[string][]byte{
+		"app.log.3.gz": gz.Bytes(),
+		"app.log.2":    []byte("older\n"),
+		"app.log.1":    []byte("old\n"),
+	}
+	for name, b := range files {
+		if err := ioutil.WriteFile(filepath.Join(old, name), b, 0644); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithCompression(Gzip), WithMaxBackups(2))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	// The newest goes by its modification time.
+	mtime := clock.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(old, "app.log.1"), mtime, mtime); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	for name, at := range map[string]time.Time{
+		"app.log.3.gz": clock.Now().Add(-3 * time.Hour),
+		"app.log.2":    clock.Now().Add(-2 * time.Hour),
+		"app.log.1":    {},
+	} {
+		if _, err := r.Adopt(filepath.Join(old, name), at); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		if _, err := os.Stat(filepath.Join(old, name)); !os.IsNotExist(err) {
+			t.Errorf("Wanted %s moved, got %v", name, err)
+		}
+	}
+
+	archives, err := r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	// Retention keeps the two newest.
+	if len(archives) != 2 {
+		t.Errorf("Wanted 2 archives, got %+v", archives)
+		t.FailNow()
+	}
+	for i, want := range []struct {
+		end     time.Time
+		content string
+	}{
+		{clock.Now().Add(-2 * time.Hour), "older\n"},
+		{mtime, "old\n"},
+	} {
+		a := archives[i]
+		if !a.End.Equal(want.end.Truncate(time.Second)) || !a.Compressed {
+			t.Errorf("Wanted a compressed archive rotated at %s, got %+v", want.end, a)
+		}
+		rc, err := OpenArchive(a)
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		got, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		if string(got) != want.content {
+			t.Errorf("Wanted %s to contain %q, got %q", a.Path, want.content, got)
+		}
+	}
+}
+
+func TestAdoptFileKeepsCompression(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("hello\n"))
+	zw.Close()
+	path := filepath.Join(dir, "app.log.1.gz")
+	if err := ioutil.WriteFile(path, gz.Bytes(), 0644); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)
+	adopted, err := AdoptFile(dir, "test", path, at, WithCompression(Gzip))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if want := filepath.Join(dir, "test-2020-01-02-030405.log.gz"); adopted != want {
+		t.Errorf("Wanted %s, got %s", want, adopted)
+	}
+
+	archives, err := ListArchives(dir, "test")
+	if err != nil || len(archives) != 1 || archives[0].Path != adopted {
+		t.Errorf("Wanted %s listed, got %+v, %v", adopted, archives, err)
+	}
+}
@@ -0,0 +1,111 @@
+package rolog
+
+import (
+	"bytes"
+	"fmt"
+	"net/smtp"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Alert describes a run of consecutive failed rotations that has reached the
+// threshold given to WithAlert. A rotation fails if swapping files, processing
+// the archive or pruning old archives fails.
+type Alert struct {
+	// Name and Dir identify the log.
+	Name, Dir string
+	// Failures is how many rotations in a row have failed.
+	Failures int
+	// Since is when the first of them failed.
+	Since time.Time
+	// Err is the error of the most recent failure.
+	Err error
+}
+
+// AlertFunc is called by WithAlert. An error it returns is reported on Err.
+type AlertFunc func(Alert) error
+
+// WithAlert calls fn once after rotations have failed after times in a row,
+// so that a log that has silently stopped rotating pages someone rather than
+// filling the disk. Failures are still reported on Err as they happen. Once a
+// rotation succeeds the count starts over and fn may be called again. fn runs
+// on a goroutine of its own, so a slow alert never holds up writes.
+func WithAlert(after int, fn AlertFunc) Option {
+	return func(r *Rolog) {
+		if after <= 0 || fn == nil {
+			r.alerter = nil
+			return
+		}
+		r.alerter = &alerter{after: after, fn: fn}
+	}
+}
+
+// alerter counts the consecutive rotation failures for WithAlert.
+type alerter struct {
+	after int
+	fn    AlertFunc
+
+	mu       sync.Mutex
+	failures int
+	since    time.Time
+}
+
+// alertOutcome records whether a rotation failed, calling the alert if that
+// makes enough failures in a row. Rotations refused because the Rolog is
+// closed don't count.
+func (r *Rolog) alertOutcome(err error) {
+	a := r.alerter
+	if a == nil || errors.Cause(err) == ErrClosed {
+		return
+	}
+
+	a.mu.Lock()
+	if err == nil {
+		a.failures = 0
+		a.mu.Unlock()
+		return
+	}
+	if a.failures == 0 {
+		a.since = r.now()
+	}
+	a.failures++
+	fire := a.failures == a.after
+	alert := Alert{Name: r.name, Dir: filepath.Dir(r.currentPath()), Failures: a.failures, Since: a.since, Err: err}
+	a.mu.Unlock()
+
+	if !fire {
+		return
+	}
+	go func() {
+		defer r.contain("alert")
+		if err := a.fn(alert); err != nil {
+			r.report(errors.Wrap(err, "could not send alert"))
+		}
+	}()
+}
+
+// SMTPAlert returns an AlertFunc that emails the alert from from to each of to
+// through the SMTP server at addr, which is given as host:port. auth may be
+// nil for servers that don't require it.
+func SMTPAlert(addr string, auth smtp.Auth, from string, to ...string) AlertFunc {
+	return func(a Alert) error {
+		return smtp.SendMail(addr, auth, from, to, alertMessage(a, from, to))
+	}
+}
+
+// alertMessage formats an alert as an email.
+func alertMessage(a Alert, from string, to []string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: rolog: %d consecutive rotation failures for %s\r\n", a.Failures, a.Name)
+	fmt.Fprintf(&b, "\r\n")
+	fmt.Fprintf(&b, "Rotation of the %s log in %s has failed %d times in a row since %s.\r\n",
+		a.Name, a.Dir, a.Failures, a.Since.Format(time.RFC3339))
+	fmt.Fprintf(&b, "\r\nThe last error was: %v\r\n", a.Err)
+	return b.Bytes()
+}
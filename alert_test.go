@@ -0,0 +1,193 @@
+package rolog
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestAlertAfterConsecutiveFailures(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	alerts := make(chan Alert, 4)
+	s := &flakyStrategy{failures: 3}
+	r, err := New(dir, "test", WithRotationStrategy(s), WithAlert(2, func(a Alert) error {
+		alerts <- a
+		return nil
+	}))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	expectAlert := func() Alert {
+		select {
+		case a := <-alerts:
+			return a
+		case <-time.After(5 * time.Second):
+			t.Errorf("Wanted an alert")
+			t.FailNow()
+		}
+		return Alert{}
+	}
+	expectNone := func() {
+		select {
+		case a := <-alerts:
+			t.Errorf("Wanted no alert, got %+v", a)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	r.Rotate()
+	expectNone()
+
+	r.Rotate()
+	a := expectAlert()
+	if a.Name != "test" || a.Failures != 2 || a.Err == nil || a.Since.IsZero() {
+		t.Errorf("Wanted an alert for 2 failures of test, got %+v", a)
+	}
+
+	// Only the first run past the threshold alerts.
+	r.Rotate()
+	expectNone()
+
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	// A success starts the count over.
+	s.calls, s.failures = 0, 2
+	r.Rotate()
+	expectNone()
+	r.Rotate()
+	expectAlert()
+}
+
+func TestAlertErrorsAreReported(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithRotationStrategy(&flakyStrategy{failures: 1}), WithAlert(1, func(Alert) error {
+		return errors.New("pager down")
+	}))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Rotate()
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case err := <-r.Err():
+			if strings.Contains(err.Error(), "pager down") {
+				return
+			}
+		case <-deadline:
+			t.Errorf("Wanted the alert's error on Err")
+			return
+		}
+	}
+}
+
+func TestSMTPAlertSendsMail(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("could not listen: %v", err)
+	}
+	defer l.Close()
+
+	mail := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		mail <- fakeSMTP(conn)
+	}()
+
+	send := SMTPAlert(l.Addr().String(), nil, "rolog@example.com", "ops@example.com")
+	err = send(Alert{Name: "app", Dir: "/var/log", Failures: 3, Since: time.Now(), Err: errors.New("disk on fire")})
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	got := <-mail
+	for _, want := range []string{
+		"MAIL FROM:<rolog@example.com>",
+		"RCPT TO:<ops@example.com>",
+		"Subject: rolog: 3 consecutive rotation failures for app",
+		"disk on fire",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Wanted %q in the conversation, got %q", want, got)
+		}
+	}
+}
+
+// fakeSMTP accepts a single message on conn and returns everything the client
+// sent.
+func fakeSMTP(conn net.Conn) string {
+	var (
+		in   = bufio.NewReader(conn)
+		seen strings.Builder
+		data bool
+	)
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	conn.Write([]byte("220 localhost ESMTP\r\n"))
+	for {
+		line, err := in.ReadString('\n')
+		if err != nil {
+			return seen.String()
+		}
+		seen.WriteString(line)
+
+		switch {
+		case data:
+			if line == ".\r\n" {
+				data = false
+				conn.Write([]byte("250 OK\r\n"))
+			}
+		case strings.HasPrefix(line, "EHLO"), strings.HasPrefix(line, "HELO"):
+			conn.Write([]byte("250 localhost\r\n"))
+		case strings.HasPrefix(line, "DATA"):
+			data = true
+			conn.Write([]byte("354 go ahead\r\n"))
+		case strings.HasPrefix(line, "QUIT"):
+			conn.Write([]byte("221 bye\r\n"))
+			return seen.String()
+		default:
+			conn.Write([]byte("250 OK\r\n"))
+		}
+	}
+}
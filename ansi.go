@@ -0,0 +1,72 @@
+package rolog
+
+import "time"
+
+// WithStripANSI removes ANSI escape sequences, such as terminal colour codes,
+// from everything written through the Rolog, for applications that share a
+// formatter between the terminal and the log files. It adds an ANSIEncoder to
+// the Rolog's encoders.
+func WithStripANSI() Option {
+	return func(r *Rolog) {
+		r.encoders = append(r.encoders, &ANSIEncoder{})
+	}
+}
+
+// ansiState is where an ANSIEncoder is within an escape sequence.
+type ansiState int
+
+const (
+	ansiText ansiState = iota
+	ansiEsc
+	ansiCSI
+	ansiOSC
+	ansiOSCEsc
+)
+
+// ANSIEncoder is an Encoder that strips ANSI escape sequences: CSI sequences
+// such as colour codes, OSC sequences such as window titles and hyperlinks,
+// and two-byte escapes. Sequences split across writes are still removed.
+type ANSIEncoder struct {
+	state ansiState
+}
+
+// Encode satisfies Encoder.
+func (e *ANSIEncoder) Encode(dst, p []byte, now time.Time) []byte {
+	for _, b := range p {
+		switch e.state {
+		case ansiText:
+			if b == 0x1b {
+				e.state = ansiEsc
+			} else {
+				dst = append(dst, b)
+			}
+		case ansiEsc:
+			switch b {
+			case '[':
+				e.state = ansiCSI
+			case ']':
+				e.state = ansiOSC
+			default:
+				e.state = ansiText
+			}
+		case ansiCSI:
+			if b >= 0x40 && b <= 0x7e {
+				e.state = ansiText
+			}
+		case ansiOSC:
+			switch b {
+			case 0x07:
+				e.state = ansiText
+			case 0x1b:
+				e.state = ansiOSCEsc
+			}
+		case ansiOSCEsc:
+			if b == '\\' {
+				e.state = ansiText
+			} else {
+				e.state = ansiOSC
+			}
+		}
+	}
+	return dst
+}
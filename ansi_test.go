@@ -0,0 +1,44 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestWithStripANSIRemovesEscapes(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithStripANSI())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	p := []byte("\x1b[1;31mERROR\x1b[0m failed\n\x1b]8;;http://x\x1b\\link\x1b]8;;\x07 ok\x1b[")
+	if n, err := r.Write(p); err != nil || n != len(p) {
+		t.Errorf("Wanted %d bytes written, got %d (%v)", len(p), n, err)
+	}
+	r.Write([]byte("32mdone\x1b[0m\n"))
+
+	got, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if want := "ERROR failed\nlink okdone\n"; string(got) != want {
+		t.Errorf("Wanted %q, got %q", want, got)
+	}
+}
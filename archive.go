@@ -0,0 +1,333 @@
+package rolog
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// ArchiveInfo describes a single archived log file on disk.
+type ArchiveInfo struct {
+	// Path is the full path to the archive.
+	Path string
+	// Start is when the archive began receiving writes, taken from the end of
+	// the previous archive. It is zero for the oldest archive.
+	Start time.Time
+	// End is when the archive was rotated out, parsed from its filename.
+	End time.Time
+	// Size is the size of the archive on disk in bytes.
+	Size int64
+	// Compressed reports whether the archive is stored compressed.
+	Compressed bool
+
+	// ext holds any extensions added after the archive was rotated, such as
+	// the suffix of an Encrypter.
+	ext string
+}
+
+// compressedExts are the archive extensions recognized as compressed.
+var compressedExts = []string{".gz", ".bz2", ".zst"}
+
+// isCompressed reports whether an archive extension denotes a compressed file.
+func isCompressed(ext string) bool {
+	for _, c := range compressedExts {
+		if strings.Contains(ext, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Archives returns every archive belonging to this Rolog, sorted from oldest
+// to newest. Files in the directory that don't match the naming scheme are
+// ignored.
+func (r *Rolog) Archives() ([]ArchiveInfo, error) {
+	return r.archives()
+}
+
+// ListArchives returns the archives of the log named name in dir, as Archives
+// would for a Rolog created with the same arguments, but without opening the
+// log. It is meant for tools that inspect logs written by another process;
+// only the naming options, such as WithExtension, affect the result.
+func ListArchives(dir, name string, opts ...Option) ([]ArchiveInfo, error) {
+	r := newRolog(name, opts)
+	r.setPath(filepath.Join(dir, fmt.Sprintf(r.currentFormat(), name)))
+	if r.dayLayout != "" {
+		r.dayRoot = dir
+	}
+	return r.archives()
+}
+
+// OpenArchive opens the archive described by info for reading, transparently
+// decompressing it according to its extension. Encrypted archives cannot be
+// opened and return an error.
+func OpenArchive(info ArchiveInfo) (io.ReadCloser, error) {
+	ext := filepath.Ext(info.Path)
+	if ext == ".age" || ext == ".gpg" {
+		return nil, errors.Errorf("cannot open encrypted archive %s", info.Path)
+	}
+
+	f, err := os.Open(info.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open archive")
+	}
+
+	var rc io.ReadCloser
+	switch ext {
+	case ".gz":
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, errors.Wrap(err, "could not read gzip archive")
+		}
+		rc = zr
+	case ".bz2":
+		rc = ioutil.NopCloser(bzip2.NewReader(f))
+	case ".zst":
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, errors.Wrap(err, "could not read zstd archive")
+		}
+		rc = zr.IOReadCloser()
+	default:
+		return f, nil
+	}
+
+	return &archiveReader{ReadCloser: rc, f: f}, nil
+}
+
+// archiveReader closes both a decompressor and its underlying file.
+type archiveReader struct {
+	io.ReadCloser
+	f *os.File
+}
+
+// Close satisfies io.Closer.
+func (a *archiveReader) Close() error {
+	a.ReadCloser.Close()
+	return a.f.Close()
+}
+
+// archives returns every archive belonging to this Rolog, sorted from oldest to
+// newest according to the timestamp embedded in the filename.
+func (r *Rolog) archives() ([]ArchiveInfo, error) {
+	if r.passthrough != nil {
+		return nil, nil
+	}
+	archives, err := r.acrossDays(r.archivesIn)
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(archives); i++ {
+		archives[i].Start = archives[i-1].End
+	}
+	return archives, nil
+}
+
+// archivesIn returns the archives of the log in dir, oldest first.
+func (r *Rolog) archivesIn(dir string) ([]ArchiveInfo, error) {
+	prefix, layout := r.archiveLayout()
+
+	fis, err := r.fs.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list archives")
+	}
+
+	var archives []ArchiveInfo
+	for _, fi := range fis {
+		if fi.IsDir() || !strings.HasPrefix(fi.Name(), prefix) {
+			continue
+		}
+
+		stem, ext := strings.TrimPrefix(fi.Name(), prefix), ""
+		if len(stem) > len(layout) {
+			stem, ext = stem[:len(layout)], stem[len(layout):]
+		}
+
+		t, err := time.ParseInLocation(layout, stem, time.Local)
+		if err != nil {
+			// Not one of ours.
+			continue
+		}
+
+		archives = append(archives, ArchiveInfo{
+			Path:       filepath.Join(dir, fi.Name()),
+			End:        t,
+			Size:       fi.Size(),
+			Compressed: isCompressed(ext),
+			ext:        ext,
+		})
+	}
+
+	sort.Slice(archives, func(i, j int) bool {
+		a, b := archives[i], archives[j]
+		if !a.End.Equal(b.End) {
+			return a.End.Before(b.End)
+		}
+		// Rotations within the same second are distinguished by a numeric
+		// suffix, which sorts by length before value.
+		if len(a.Path) != len(b.Path) {
+			return len(a.Path) < len(b.Path)
+		}
+		return a.Path < b.Path
+	})
+
+	for i := 1; i < len(archives); i++ {
+		archives[i].Start = archives[i-1].End
+	}
+
+	return archives, nil
+}
+
+// archiveLayout splits the archive naming format into the literal filename prefix for
+// this Rolog and the time layout that follows it.
+func (r *Rolog) archiveLayout() (prefix, layout string) {
+	parts := strings.SplitN(r.archiveFormat(), "%s", 2)
+	prefix, layout = parts[0]+r.name, parts[1]
+	// Variables before the timestamp go in the prefix, so that digits in
+	// their values aren't taken for part of the layout.
+	if i := lastVarEnd(layout); i > 0 {
+		prefix, layout = prefix+layout[:i], layout[i:]
+	}
+	return r.expandVars(prefix, false), layout
+}
+
+// Encrypter encrypts archives before they are left on disk. Implementations
+// wrap the destination writer so that everything written to the returned
+// WriteCloser is encrypted, mirroring the age and openpgp APIs.
+type Encrypter interface {
+	// Encrypt returns a writer that encrypts to w. The archive is only
+	// complete once the returned writer has been closed.
+	Encrypt(w io.Writer) (io.WriteCloser, error)
+	// Extension is appended to the archive filename, e.g. ".age" or ".gpg".
+	Extension() string
+}
+
+// SetEncrypter configures an Encrypter that is applied to every archive after
+// rotation. The plaintext archive is removed once the encrypted copy has been
+// written. Passing nil disables encryption.
+func (r *Rolog) SetEncrypter(e Encrypter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.encrypter = e
+}
+
+// SetArchivePerm sets the mode bits applied to every archive at rotation time.
+// A zero mode leaves the permissions the archive was created with.
+func (r *Rolog) SetArchivePerm(mode os.FileMode) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.archivePerm = mode
+	r.publish()
+}
+
+// SetArchiveOwner sets the owner and group applied to every archive at
+// rotation time. An id of -1 leaves that attribute unchanged. Ownership is
+// ignored on platforms that don't support it.
+func (r *Rolog) SetArchiveOwner(uid, gid int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.archiveUID, r.archiveGID = uid, gid
+}
+
+// finalize applies any configured post-processing to a freshly rotated archive
+// and returns the path of the resulting file.
+func (r *Rolog) finalize(path string, s archiveSettings) (string, error) {
+	if s.compression != NoCompression && !isCompressed(filepath.Ext(path)) {
+		var (
+			dst   = path + s.compression.Extension()
+			start = r.now()
+			err   = compressFile(r.fs, s.compression, s.compressWorkers, s.compressLevel, path, dst)
+		)
+		r.trace(OpCompress, SpanInfo{Path: path}, start, err)
+		if err != nil {
+			return path, err
+		}
+
+		if err := r.fs.Remove(path); err != nil {
+			return dst, errors.Wrap(err, "could not remove uncompressed archive")
+		}
+		path = dst
+	}
+
+	if s.encrypter != nil {
+		var (
+			dst   = path + s.encrypter.Extension()
+			start = r.now()
+			err   = encryptFile(r.fs, s.encrypter, path, dst)
+		)
+		r.trace(OpEncrypt, SpanInfo{Path: path}, start, err)
+		r.audit(AuditEncrypt, path, err)
+		if err != nil {
+			r.fs.Remove(dst)
+			return path, err
+		}
+
+		if err := r.fs.Remove(path); err != nil {
+			return dst, errors.Wrap(err, "could not remove plaintext archive")
+		}
+		path = dst
+	}
+
+	if s.perm != 0 {
+		if err := chmod(r.fs, path, s.perm); err != nil {
+			return path, errors.Wrap(err, "could not set archive permissions")
+		}
+	}
+
+	if err := chown(r.fs, path, s.uid, s.gid); err != nil {
+		return path, errors.Wrap(err, "could not set archive owner")
+	}
+
+	if s.manifest {
+		if err := r.recordDigest(path); err != nil {
+			return path, errors.Wrap(err, "could not record archive digest")
+		}
+	}
+
+	return path, nil
+}
+
+// encryptFile writes an encrypted copy of src to dst.
+func encryptFile(fs FS, e Encrypter, src, dst string) error {
+	in, err := fs.OpenFile(src, os.O_RDONLY, 0)
+	if err != nil {
+		return errors.Wrap(err, "could not open archive")
+	}
+	defer in.Close()
+
+	out, err := fs.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return errors.Wrap(err, "could not create encrypted archive")
+	}
+	defer out.Close()
+
+	w, err := e.Encrypt(out)
+	if err != nil {
+		return errors.Wrap(err, "could not start encryption")
+	}
+
+	if _, err := io.Copy(w, in); err != nil {
+		return errors.Wrap(err, "could not encrypt archive")
+	}
+
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, "could not finish encryption")
+	}
+
+	return out.Sync()
+}
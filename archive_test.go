@@ -0,0 +1,280 @@
+package rolog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// upperEncrypter is a stand-in Encrypter that upper-cases its input.
+type upperEncrypter struct{}
+
+type upperWriter struct{ w io.Writer }
+
+func (u upperWriter) Write(p []byte) (int, error) { return u.w.Write(bytes.ToUpper(p)) }
+func (u upperWriter) Close() error                { return nil }
+
+func (upperEncrypter) Encrypt(w io.Writer) (io.WriteCloser, error) { return upperWriter{w}, nil }
+func (upperEncrypter) Extension() string                           { return ".up" }
+
+func TestRotateEncryptsArchive(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithInterval(60*time.Minute))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.SetEncrypter(upperEncrypter{})
+	r.Write([]byte("hello\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "test-*"))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if len(matches) != 1 || filepath.Ext(matches[0]) != ".up" {
+		t.Errorf("Wanted a single encrypted archive, got %v", matches)
+		t.FailNow()
+	}
+
+	got, err := ioutil.ReadFile(matches[0])
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if string(got) != "HELLO\n" {
+		t.Errorf("Wanted %q, got %q", "HELLO\n", got)
+	}
+}
+
+func TestArchivesListsArchivesInOrder(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithInterval(60*time.Minute))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	names := []string{
+		base.Add(time.Minute).Format("test-2006-01-02-150405.log.gz"),
+		base.Format("test-2006-01-02-150405.log"),
+		"test-notanarchive.log",
+	}
+	for _, name := range names {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+	}
+
+	archives, err := r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if len(archives) != 2 {
+		t.Errorf("Wanted 2 archives, got %d", len(archives))
+		t.FailNow()
+	}
+
+	if !archives[0].End.Equal(base) || !archives[0].Start.IsZero() || archives[0].Compressed {
+		t.Errorf("Unexpected first archive: %+v", archives[0])
+	}
+
+	if !archives[1].Start.Equal(base) || !archives[1].Compressed || archives[1].Size != 1 {
+		t.Errorf("Unexpected second archive: %+v", archives[1])
+	}
+}
+
+func TestOpenArchiveDecompresses(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test-2006-01-02-150405.log.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	zw := gzip.NewWriter(f)
+	zw.Write([]byte("hello\n"))
+	zw.Close()
+	f.Close()
+
+	rc, err := OpenArchive(ArchiveInfo{Path: path, Compressed: true})
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer rc.Close()
+
+	got, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if string(got) != "hello\n" {
+		t.Errorf("Wanted %q, got %q", "hello\n", got)
+	}
+}
+
+func TestRotateAppliesArchivePerm(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithInterval(60*time.Minute))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.SetArchivePerm(0600)
+	r.SetArchiveOwner(os.Getuid(), os.Getgid())
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+
+	archives, err := r.Archives()
+	if err != nil || len(archives) != 1 {
+		t.Errorf("Wanted one archive, got %d (%v)", len(archives), err)
+		t.FailNow()
+	}
+
+	fi, err := os.Stat(archives[0].Path)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("Wanted mode 0600, got %o", fi.Mode().Perm())
+	}
+}
+
+// blockingEncrypter is an upperEncrypter that waits for release before
+// encrypting, to simulate slow archive processing.
+type blockingEncrypter struct {
+	upperEncrypter
+	started, release chan struct{}
+}
+
+func (b blockingEncrypter) Encrypt(w io.Writer) (io.WriteCloser, error) {
+	close(b.started)
+	<-b.release
+	return b.upperEncrypter.Encrypt(w)
+}
+
+func TestWritesContinueDuringArchiveProcessing(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	e := blockingEncrypter{started: make(chan struct{}), release: make(chan struct{})}
+	r.SetEncrypter(e)
+
+	r.Write([]byte("old\n"))
+	clock.Advance(time.Hour)
+
+	rotated := make(chan error)
+	go func() { rotated <- r.Rotate() }()
+	<-e.started
+
+	written := make(chan error)
+	go func() {
+		_, err := r.Write([]byte("new\n"))
+		written <- err
+	}()
+
+	select {
+	case err := <-written:
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Wanted write to complete while the archive was processed")
+	}
+
+	close(e.release)
+	if err := <-rotated; err != nil {
+		t.Errorf("could not rotate: %q", err)
+	}
+
+	got, err := ioutil.ReadFile(filepath.Join(dir, "test.log"))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if string(got) != "new\n" {
+		t.Errorf("Wanted %q in the current file, got %q", "new\n", got)
+	}
+}
@@ -0,0 +1,123 @@
+package rolog
+
+import "sync"
+
+// WithAsync makes Write enqueue a copy of each payload on a queue of size
+// entries, drained by a single writer goroutine, so the calling goroutine
+// never waits on the disk unless the queue is full. Since Write returns before
+// the data is written, failures are delivered on Err instead. Close drains the
+// queue before closing the file.
+func WithAsync(size int) Option {
+	return func(r *Rolog) {
+		if size < 1 {
+			size = 1
+		}
+		r.queueSize = size
+	}
+}
+
+// DropPolicy decides what a non-blocking Write does when its queue is full.
+type DropPolicy int
+
+const (
+	// DropNewest discards the entry being written.
+	DropNewest DropPolicy = iota
+	// DropOldest evicts the oldest queued entry to make room.
+	DropOldest
+)
+
+// WithNonBlocking is WithAsync, except that Write never waits for room in the
+// queue. When the queue is full, an entry is discarded according to policy and
+// counted in Dropped, for latency-critical services that would rather lose logs
+// than stall.
+func WithNonBlocking(size int, policy DropPolicy) Option {
+	return func(r *Rolog) {
+		WithAsync(size)(r)
+		r.nonBlocking = true
+		r.dropPolicy = policy
+	}
+}
+
+// asyncQueue is the state of the async write path.
+type asyncQueue struct {
+	// mu guards closed against sends racing with Close
+	mu     sync.RWMutex
+	closed bool
+	ch     chan []byte
+	done   chan struct{}
+}
+
+// startQueue starts the writer goroutine if async mode is enabled and not
+// superseded by ring mode.
+func (r *Rolog) startQueue() {
+	if r.queueSize == 0 || r.ring != nil {
+		return
+	}
+
+	q := &asyncQueue{
+		ch:   make(chan []byte, r.queueSize),
+		done: make(chan struct{}),
+	}
+	r.queue = q
+
+	go func() {
+		defer close(q.done)
+		for p := range q.ch {
+			r.writeBackground(p, "queued entry")
+		}
+	}()
+}
+
+// enqueue hands a copy of p to the writer goroutine.
+func (r *Rolog) enqueue(p []byte) (int, error) {
+	q := r.queue
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return 0, ErrClosed
+	}
+
+	entry := append([]byte(nil), p...)
+	if !r.nonBlocking {
+		q.ch <- entry
+		return len(p), nil
+	}
+
+	for {
+		select {
+		case q.ch <- entry:
+			return len(p), nil
+		default:
+		}
+
+		if r.dropPolicy == DropNewest {
+			r.drop(DropQueueFull)
+			return len(p), nil
+		}
+
+		select {
+		case <-q.ch:
+			r.drop(DropQueueFull)
+		default:
+		}
+	}
+}
+
+// stopQueue refuses further writes and waits until everything already queued
+// has been written.
+func (r *Rolog) stopQueue() {
+	q := r.queue
+	if q == nil {
+		return
+	}
+
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.ch)
+	}
+	q.mu.Unlock()
+
+	<-q.done
+}
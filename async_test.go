@@ -0,0 +1,107 @@
+package rolog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAsyncDrainsOnClose(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r, err := New(dir, "test", WithAsync(4))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				fmt.Fprintf(r, "%d-%d\n", i, j)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := r.Close(); err != nil {
+		t.Errorf("could not close: %q", err)
+		t.FailNow()
+	}
+
+	if _, err := r.Write([]byte("late\n")); err == nil {
+		t.Errorf("Wanted an error writing after close")
+	}
+
+	got, err := ioutil.ReadFile(filepath.Join(dir, "test.log"))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if lines := strings.Count(string(got), "\n"); lines != 100 {
+		t.Errorf("Wanted 100 lines, got %d", lines)
+	}
+}
+
+func TestNonBlockingDropPolicies(t *testing.T) {
+	cases := []struct {
+		policy DropPolicy
+		want   string
+	}{
+		{DropNewest, "0\n1\n2\n"},
+		{DropOldest, "0\n3\n4\n"},
+	}
+
+	for _, c := range cases {
+		dir, err := ioutil.TempDir(".", "tmp")
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+
+		fs := newGateFS()
+		r, err := New(dir, "test", WithFS(fs), WithNonBlocking(2, c.policy), WithSyncPolicy(SyncOnRotate))
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+
+		r.Write([]byte("0\n"))
+		<-fs.entered
+		for i := 1; i < 5; i++ {
+			fmt.Fprintf(r, "%d\n", i)
+		}
+
+		if n := r.Dropped(); n != 2 {
+			t.Errorf("Wanted 2 dropped entries under %v, got %d", c.policy, n)
+		}
+
+		close(fs.release)
+		r.Close()
+
+		got, _ := ioutil.ReadFile(filepath.Join(dir, "test.log"))
+		if string(got) != c.want {
+			t.Errorf("Wanted %q under %v, got %q", c.want, c.policy, got)
+		}
+
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}
+}
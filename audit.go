@@ -0,0 +1,163 @@
+package rolog
+
+import (
+	"encoding/json"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AuditAction names a lifecycle operation recorded in the audit trail.
+type AuditAction string
+
+// The actions recorded in the audit trail.
+const (
+	// AuditRotate is a rotation requested with Rotate.
+	AuditRotate AuditAction = "rotate"
+	// AuditPrune is an archive deleted by retention or Purge.
+	AuditPrune AuditAction = "prune"
+	// AuditEncrypt is an archive encrypted after rotation.
+	AuditEncrypt AuditAction = "encrypt"
+	// AuditAdopt is a foreign file brought in as an archive with Adopt.
+	AuditAdopt AuditAction = "adopt"
+	// AuditBundle is an archive moved into its daily bundle.
+	AuditBundle AuditAction = "bundle"
+	// AuditUpload is an archive, or a notice of one, sent off the host by
+	// an extension such as the ship, kafkasink and webhook packages; see
+	// RecordUpload.
+	AuditUpload AuditAction = "upload"
+)
+
+// AuditEntry is one record of the audit trail, written as a line of JSON.
+type AuditEntry struct {
+	// Time is when the operation finished.
+	Time time.Time `json:"time"`
+	// Log is the name of the Rolog.
+	Log string `json:"log"`
+	// User and PID identify the process that performed the operation.
+	User string `json:"user"`
+	PID  int    `json:"pid"`
+	// Action is the operation performed.
+	Action AuditAction `json:"action"`
+	// Path is the file the operation was applied to, if any.
+	Path string `json:"path,omitempty"`
+	// Target is where the file went, such as the bundle an archive was
+	// moved into or the destination of an upload.
+	Target string `json:"target,omitempty"`
+	// Result is "ok" or "failed", and Error describes the failure.
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// WithAudit appends an AuditEntry to the file at path for every manual
+// rotation, every archive pruned, encrypted, adopted, bundled or uploaded,
+// whether it succeeded or not, to give compliance reviews a record of what
+// happened to the logs. The file is only ever appended to, each entry is
+// synced as it is written, and it is never rotated or pruned, so it should be
+// kept apart from the log's own files. Failures to write the trail are
+// reported on Err.
+func WithAudit(path string) Option {
+	return func(r *Rolog) {
+		r.auditPath = path
+	}
+}
+
+// auditLog is the open audit trail.
+type auditLog struct {
+	mu   sync.Mutex
+	f    File
+	user string
+	pid  int
+}
+
+// openAudit opens the audit trail, if one is configured.
+func (r *Rolog) openAudit() error {
+	if r.auditPath == "" {
+		return nil
+	}
+
+	f, err := r.fs.OpenFile(r.auditPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return errors.Wrap(err, "could not open audit trail")
+	}
+
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	r.auditLog = &auditLog{f: f, user: name, pid: os.Getpid()}
+	return nil
+}
+
+// RecordUpload records in the audit trail, if there is one, that the archive
+// at path was sent to target, or failed to be with err. Extensions that send
+// archives off the host call it for each attempt, so the trail accounts for
+// every copy that leaves.
+func (r *Rolog) RecordUpload(path, target string, err error) {
+	r.auditTo(AuditUpload, path, target, err)
+}
+
+// audit records action on path in the audit trail, if one is configured.
+func (r *Rolog) audit(action AuditAction, path string, err error) {
+	r.auditTo(action, path, "", err)
+}
+
+// auditTo records action on path, taking it to target, in the audit trail, if
+// one is configured.
+func (r *Rolog) auditTo(action AuditAction, path, target string, err error) {
+	a := r.auditLog
+	if a == nil {
+		return
+	}
+
+	e := AuditEntry{
+		Time:   r.now(),
+		Log:    r.name,
+		User:   a.user,
+		PID:    a.pid,
+		Action: action,
+		Path:   path,
+		Target: target,
+		Result: "ok",
+	}
+	if err != nil {
+		e.Result, e.Error = "failed", err.Error()
+	}
+
+	line, _ := json.Marshal(e)
+	line = append(line, '\n')
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.f == nil {
+		return
+	}
+	if _, err := a.f.Write(line); err != nil {
+		r.report(errors.Wrap(err, "could not write audit trail"))
+		return
+	}
+	if err := a.f.Sync(); err != nil {
+		r.report(errors.Wrap(err, "could not sync audit trail"))
+	}
+}
+
+// closeAudit closes the audit trail, if one is open. Operations finishing
+// after it is closed aren't recorded.
+func (r *Rolog) closeAudit() {
+	a := r.auditLog
+	if a == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.f != nil {
+		a.f.Close()
+		a.f = nil
+	}
+}
@@ -0,0 +1,146 @@
+package rolog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAuditRecordsLifecycleOperations(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	trail := filepath.Join(dir, "audit.jsonl")
+	r, err := New(dir, "test", WithAudit(trail), WithMaxBackups(1), WithEncrypter(upperEncrypter{}))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	for i := 0; i < 2; i++ {
+		r.Write([]byte("entry\n"))
+		if err := r.Rotate(); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+	}
+	r.Close()
+
+	f, err := os.Open(trail)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer f.Close()
+
+	var got []AuditAction
+	for s := bufio.NewScanner(f); s.Scan(); {
+		var e AuditEntry
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		if e.Log != "test" || e.PID != os.Getpid() || e.Result != "ok" || e.Path == "" {
+			t.Errorf("Wanted a complete, successful entry, got %+v", e)
+		}
+		got = append(got, e.Action)
+	}
+
+	want := []AuditAction{AuditEncrypt, AuditRotate, AuditEncrypt, AuditPrune, AuditRotate}
+	if len(got) != len(want) {
+		t.Errorf("Wanted %v, got %v", want, got)
+		t.FailNow()
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Wanted %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestAuditRecordsBundlesAndUploads(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	trail := filepath.Join(dir, "audit.jsonl")
+	r, err := New(dir, "test", WithAudit(trail))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	y, m, d := time.Now().Date()
+	day := time.Date(y, m, d-2, 1, 0, 0, 0, time.Local)
+	old := filepath.Join(dir, fmt.Sprintf(day.Format(ArchiveFileFormat), "test"))
+	if err := ioutil.WriteFile(old, []byte("old\n"), 0644); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r.SetBundleAge(24 * time.Hour)
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	r.RecordUpload(old, "loghost:514", nil)
+	r.Close()
+
+	b, err := ioutil.ReadFile(trail)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	var got []AuditEntry
+	for s := bufio.NewScanner(bytes.NewReader(b)); s.Scan(); {
+		var e AuditEntry
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		got = append(got, e)
+	}
+
+	bundle := filepath.Join(dir, fmt.Sprintf(day.Format(BundleFileFormat), "test"))
+	want := []AuditEntry{
+		{Action: AuditBundle, Path: old, Target: bundle},
+		{Action: AuditRotate},
+		{Action: AuditUpload, Path: old, Target: "loghost:514"},
+	}
+	if len(got) != len(want) {
+		t.Errorf("Wanted %+v, got %+v", want, got)
+		t.FailNow()
+	}
+	for i, w := range want {
+		if got[i].Action != w.Action || w.Path != "" && (got[i].Path != w.Path || got[i].Target != w.Target) {
+			t.Errorf("Wanted %+v, got %+v", w, got[i])
+		}
+	}
+}
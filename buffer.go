@@ -0,0 +1,60 @@
+package rolog
+
+import (
+	"bufio"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WithBuffer collects writes in a buffer of size bytes rather than handing
+// each one to the operating system, so small frequent writes don't each hit
+// the disk. The buffer is drained whenever it fills, every flushEvery while
+// the Rolog is running, and before every sync, rotation and Close. Since the
+// default SyncPolicy syncs every write, WithBuffer is normally combined with
+// WithSyncPolicy. A non-positive flushEvery disables the periodic flush.
+func WithBuffer(size int, flushEvery time.Duration) Option {
+	return func(r *Rolog) {
+		r.bufSize = size
+		r.flushEvery = flushEvery
+	}
+}
+
+// output returns the writer the write path should use. It must be called with
+// mu held.
+func (r *Rolog) output() io.Writer {
+	if r.buf != nil {
+		return r.buf
+	}
+	return r.f
+}
+
+// drain writes any buffered data to the current file. It must be called with
+// mu held.
+func (r *Rolog) drain() error {
+	if r.buf == nil || r.buf.Buffered() == 0 {
+		return nil
+	}
+	n := r.buf.Buffered()
+	if err := r.buf.Flush(); err != nil {
+		return errors.Wrap(err, "could not drain buffer")
+	}
+	r.observe(event{kind: eventFlush, n: n})
+	return nil
+}
+
+// setFile makes f the current file, pointing the buffer at it if there is one,
+// reserving space for it and compressing it if configured. It must be called with mu held.
+func (r *Rolog) setFile(f File) {
+	r.preallocate(f)
+	f = r.compressLive(f)
+	r.f = f
+	r.follow()
+	switch {
+	case r.buf != nil:
+		r.buf.Reset(f)
+	case r.bufSize > 0:
+		r.buf = bufio.NewWriterSize(f, r.bufSize)
+	}
+}
@@ -0,0 +1,106 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBufferDrainsOnRotateAndClose(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithBuffer(4096, 0), WithSyncPolicy(SyncOnRotate))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	current := filepath.Join(dir, "test.log")
+	r.Write([]byte("first\n"))
+	if got, _ := ioutil.ReadFile(current); len(got) != 0 {
+		t.Errorf("Wanted the write to be buffered, got %q on disk", got)
+	}
+
+	clock.Advance(time.Hour)
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+
+	archives, err := r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(archives) != 1 || archives[0].Size != int64(len("first\n")) {
+		t.Errorf("Wanted the buffer drained into the archive, got %+v", archives)
+	}
+
+	r.Write([]byte("second\n"))
+	if err := r.Close(); err != nil {
+		t.Errorf("could not close: %q", err)
+		t.FailNow()
+	}
+	if got, _ := ioutil.ReadFile(current); string(got) != "second\n" {
+		t.Errorf("Wanted the buffer drained on close, got %q", got)
+	}
+}
+
+func TestBufferFlushesPeriodically(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithBuffer(4096, time.Second), WithSyncPolicy(SyncOnRotate))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Run(ctx)
+	for clock.numTickers() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	r.Write([]byte("hello\n"))
+	clock.Advance(time.Second)
+
+	current := filepath.Join(dir, "test.log")
+	deadline := time.Now().Add(time.Second)
+	for {
+		got, _ := ioutil.ReadFile(current)
+		if string(got) == "hello\n" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Errorf("Wanted the buffer flushed after a second, got %q", got)
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,145 @@
+package rolog
+
+import (
+	"os"
+	"time"
+)
+
+// ConfigBuilder assembles a Rolog through chained method calls, as an
+// alternative to passing Options to New. Create one with Builder.
+type ConfigBuilder struct {
+	cfg  Config
+	opts []Option
+}
+
+// Builder starts a new chained configuration:
+//
+//	r, err := rolog.Builder().Dir("/var/log").Name("app").Every(6 * time.Hour).Build()
+func Builder() *ConfigBuilder {
+	return &ConfigBuilder{}
+}
+
+// Dir sets the directory the logs are written to.
+func (b *ConfigBuilder) Dir(dir string) *ConfigBuilder {
+	b.cfg.Dir = dir
+	return b
+}
+
+// Name sets the base name of the log files.
+func (b *ConfigBuilder) Name(name string) *ConfigBuilder {
+	b.cfg.Name = name
+	return b
+}
+
+// Every sets the rotation interval.
+func (b *ConfigBuilder) Every(d time.Duration) *ConfigBuilder {
+	b.cfg.Interval = d
+	return b
+}
+
+// MaxSize sets the size at which the current file is rotated early.
+func (b *ConfigBuilder) MaxSize(n int64) *ConfigBuilder {
+	b.cfg.MaxSize = n
+	return b
+}
+
+// MaxBackups sets the number of archives to keep.
+func (b *ConfigBuilder) MaxBackups(n int) *ConfigBuilder {
+	b.cfg.MaxBackups = n
+	return b
+}
+
+// MaxTotalSize sets the byte budget for the current file and its archives.
+func (b *ConfigBuilder) MaxTotalSize(n int64) *ConfigBuilder {
+	b.cfg.MaxTotalSize = n
+	return b
+}
+
+// BundleAge sets how old an archive must be before it is bundled.
+func (b *ConfigBuilder) BundleAge(d time.Duration) *ConfigBuilder {
+	b.cfg.BundleAge = d
+	return b
+}
+
+// LatestLink enables the symlink to the newest archive.
+func (b *ConfigBuilder) LatestLink() *ConfigBuilder {
+	b.cfg.LatestLink = true
+	return b
+}
+
+// FileMode sets the mode of the current file.
+func (b *ConfigBuilder) FileMode(mode os.FileMode) *ConfigBuilder {
+	b.cfg.FileMode = mode
+	return b
+}
+
+// ArchivePerm sets the mode applied to archives.
+func (b *ConfigBuilder) ArchivePerm(mode os.FileMode) *ConfigBuilder {
+	b.cfg.ArchivePerm = mode
+	return b
+}
+
+// StdLog attaches the Rolog to the standard library's default logger.
+func (b *ConfigBuilder) StdLog() *ConfigBuilder {
+	b.cfg.StdLog = true
+	return b
+}
+
+// Append continues an existing current file instead of archiving it.
+func (b *ConfigBuilder) Append() *ConfigBuilder {
+	b.cfg.Append = true
+	return b
+}
+
+// Extension replaces DefaultExtension in every filename.
+func (b *ConfigBuilder) Extension(ext string) *ConfigBuilder {
+	b.cfg.Extension = ext
+	return b
+}
+
+// CurrentFilename replaces CurrentFilename as the current file's format.
+func (b *ConfigBuilder) CurrentFilename(format string) *ConfigBuilder {
+	b.cfg.CurrentFilename = format
+	return b
+}
+
+// Buffer sets the size of the write buffer and how often it is drained.
+func (b *ConfigBuilder) Buffer(size int, flushEvery time.Duration) *ConfigBuilder {
+	b.cfg.BufferSize = size
+	b.cfg.FlushInterval = flushEvery
+	return b
+}
+
+// Queue sets the depth of the async write queue.
+func (b *ConfigBuilder) Queue(size int) *ConfigBuilder {
+	b.cfg.QueueSize = size
+	return b
+}
+
+// Compression sets the codec archives are compressed with.
+func (b *ConfigBuilder) Compression(c Compression) *ConfigBuilder {
+	b.cfg.Compression = c
+	return b
+}
+
+// CompressWorkers sets how many goroutines compress each archive.
+func (b *ConfigBuilder) CompressWorkers(n int) *ConfigBuilder {
+	b.cfg.CompressWorkers = n
+	return b
+}
+
+// With adds Options for settings that have no dedicated builder method.
+func (b *ConfigBuilder) With(opts ...Option) *ConfigBuilder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// Config returns the Config assembled so far.
+func (b *ConfigBuilder) Config() Config {
+	return b.cfg
+}
+
+// Build validates the configuration and creates the Rolog.
+func (b *ConfigBuilder) Build() (*Rolog, error) {
+	return NewFromConfig(b.cfg, b.opts...)
+}
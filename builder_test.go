@@ -0,0 +1,37 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestBuilderBuildsARolog(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := Builder().Dir(dir).Name("test").Every(6 * time.Hour).MaxSize(1024).MaxBackups(2).Build()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	if r.interval != 6*time.Hour || r.maxSize != 1024 || r.maxBackups != 2 {
+		t.Errorf("Builder not applied: interval=%s maxSize=%d maxBackups=%d", r.interval, r.maxSize, r.maxBackups)
+	}
+
+	if _, err := Builder().Name("test").Build(); err == nil {
+		t.Errorf("Wanted an error for a missing dir")
+	}
+}
@@ -0,0 +1,255 @@
+package rolog
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// BundleFileFormat is the format for tarballs holding a day's worth of old
+// archives.
+const BundleFileFormat = "%s-2006-01-02.tar.gz"
+
+// SetBundleAge enables consolidation of archives older than d. After each
+// rotation, such archives are grouped by the day they were rotated and moved
+// into a single gzipped tarball per day, named according to BundleFileFormat.
+// Bundles get the archive permissions and owner, as archives do, and are kept
+// in the directory of the archives they hold. Retention treats each bundle as
+// one archive that ended with its day, so MaxBackups, MaxAge and MaxTotalSize
+// delete whole bundles along with the archives. A value of zero disables
+// bundling.
+func (r *Rolog) SetBundleAge(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.bundleAge = d
+	r.publish()
+}
+
+// bundle moves archives older than the bundle age into their daily tarballs.
+func (r *Rolog) bundle(s archiveSettings) error {
+	if s.bundleAge <= 0 {
+		return nil
+	}
+
+	archives, err := r.archives()
+	if err != nil {
+		return err
+	}
+
+	for path, archives := range r.bundleDays(s, archives, r.now()) {
+		if err := writeBundle(r.fs, path, archives, s); err != nil {
+			return err
+		}
+		for _, a := range archives {
+			// already cleared with the delete filter by bundleDays
+			if err := r.unbundled(a, path); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// unbundled removes the archive a once it is safely in the bundle at path. It
+// has been moved rather than deleted, so it is audited as bundled and isn't
+// reported as pruned.
+func (r *Rolog) unbundled(a ArchiveInfo, path string) error {
+	err := r.fs.Remove(a.Path)
+	r.auditTo(AuditBundle, a.Path, path, err)
+	return errors.Wrap(err, "could not remove bundled archive")
+}
+
+// bundles returns the daily tarballs of the log, oldest first, as archives that
+// end when the day they hold does.
+func (r *Rolog) bundles() ([]ArchiveInfo, error) {
+	if r.passthrough != nil {
+		return nil, nil
+	}
+	return r.acrossDays(r.bundlesIn)
+}
+
+// bundlesIn returns the daily tarballs of the log in dir, oldest first.
+func (r *Rolog) bundlesIn(dir string) ([]ArchiveInfo, error) {
+	parts := strings.SplitN(BundleFileFormat, "%s", 2)
+	prefix, layout := parts[0]+r.name, parts[1]
+
+	fis, err := r.fs.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list bundles")
+	}
+
+	var bundles []ArchiveInfo
+	for _, fi := range fis {
+		if fi.IsDir() || !strings.HasPrefix(fi.Name(), prefix) {
+			continue
+		}
+		t, err := time.ParseInLocation(layout, strings.TrimPrefix(fi.Name(), prefix), time.Local)
+		if err != nil {
+			// Not one of ours.
+			continue
+		}
+		y, m, d := t.Date()
+		bundles = append(bundles, ArchiveInfo{
+			Path:       filepath.Join(dir, fi.Name()),
+			End:        time.Date(y, m, d+1, 0, 0, 0, 0, time.Local),
+			Size:       fi.Size(),
+			Compressed: true,
+		})
+	}
+
+	sort.Slice(bundles, func(i, j int) bool {
+		return bundles[i].End.Before(bundles[j].End)
+	})
+	return bundles, nil
+}
+
+// bundleDays groups the archives, sorted oldest first, that are due to be
+// bundled at now by the tarball they go into. Archives the delete filter
+// vetoes are left out, since bundling deletes them.
+func (r *Rolog) bundleDays(s archiveSettings, archives []ArchiveInfo, now time.Time) map[string][]ArchiveInfo {
+	var (
+		cutoff = now.Add(-s.bundleAge)
+		days   = map[string][]ArchiveInfo{}
+	)
+	for _, a := range archives {
+		if a.End.After(cutoff) {
+			break
+		}
+		if s.deleteFilter != nil && !s.deleteFilter(a) {
+			continue
+		}
+		path := filepath.Join(filepath.Dir(a.Path), fmt.Sprintf(a.End.Format(BundleFileFormat), r.name))
+		days[path] = append(days[path], a)
+	}
+	return days
+}
+
+// writeBundle writes the archives into the tarball at path, preserving any
+// entries already bundled there. The tarball is replaced atomically, and is
+// given the archive permissions and owner of s. Without archive permissions,
+// it is made no more readable than the bundle it replaces and any of the
+// archives, so those created private, such as encrypted ones, stay private.
+func writeBundle(fs FS, path string, archives []ArchiveInfo, s archiveSettings) error {
+	tmp := path + ".tmp"
+	f, err := fs.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.Wrap(err, "could not create bundle")
+	}
+	defer fs.Remove(tmp)
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	perm, err := copyBundle(fs, tw, path)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range archives {
+		mode, err := addToBundle(fs, tw, a.Path)
+		if err != nil {
+			return err
+		}
+		perm &= mode
+	}
+
+	if err := tw.Close(); err != nil {
+		return errors.Wrap(err, "could not finish bundle")
+	}
+	if err := gw.Close(); err != nil {
+		return errors.Wrap(err, "could not finish bundle")
+	}
+	if err := f.Sync(); err != nil {
+		return errors.Wrap(err, "could not sync bundle")
+	}
+
+	if s.perm != 0 {
+		perm = s.perm
+	}
+	if err := chmod(fs, tmp, perm); err != nil {
+		return errors.Wrap(err, "could not set bundle permissions")
+	}
+	if err := chown(fs, tmp, s.uid, s.gid); err != nil {
+		return errors.Wrap(err, "could not set bundle owner")
+	}
+
+	return errors.Wrap(fs.Rename(tmp, path), "could not replace bundle")
+}
+
+// copyBundle copies every entry of an existing bundle at path into tw,
+// returning its permissions. A missing bundle is not an error.
+func copyBundle(fs FS, tw *tar.Writer, path string) (os.FileMode, error) {
+	f, err := fs.OpenFile(path, os.O_RDONLY, 0)
+	if os.IsNotExist(err) {
+		return os.ModePerm, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "could not open existing bundle")
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, errors.Wrap(err, "could not stat existing bundle")
+	}
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not read existing bundle")
+	}
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return fi.Mode().Perm(), nil
+		}
+		if err != nil {
+			return 0, errors.Wrap(err, "could not read existing bundle")
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return 0, errors.Wrap(err, "could not copy bundle entry")
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return 0, errors.Wrap(err, "could not copy bundle entry")
+		}
+	}
+}
+
+// addToBundle appends the file at path to tw under its base name, returning
+// its permissions.
+func addToBundle(fs FS, tw *tar.Writer, path string) (os.FileMode, error) {
+	f, err := fs.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not open archive")
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, errors.Wrap(err, "could not stat archive")
+	}
+
+	hdr, err := tar.FileInfoHeader(fi, "")
+	if err != nil {
+		return 0, errors.Wrap(err, "could not build bundle header")
+	}
+
+	if err := tw.WriteHeader(hdr); err != nil {
+		return 0, errors.Wrap(err, "could not write bundle header")
+	}
+
+	_, err = io.Copy(tw, f)
+	return fi.Mode().Perm(), errors.Wrap(err, "could not write bundle entry")
+}
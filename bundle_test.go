@@ -0,0 +1,271 @@
+package rolog
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotateBundlesOldArchives(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithInterval(60*time.Minute))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	y, m, d := time.Now().Date()
+	day := time.Date(y, m, d-2, 1, 0, 0, 0, time.Local)
+	for i := 0; i < 2; i++ {
+		ts := day.Add(time.Duration(i) * time.Hour)
+		path := filepath.Join(dir, fmt.Sprintf(ts.Format(ArchiveFileFormat), "test"))
+		if err := ioutil.WriteFile(path, []byte("old\n"), 0644); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+	}
+
+	r.SetBundleAge(24 * time.Hour)
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+
+	archives, err := r.archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(archives) != 1 {
+		t.Errorf("Wanted only the fresh archive to remain, got %d", len(archives))
+	}
+
+	f, err := os.Open(filepath.Join(dir, fmt.Sprintf(day.Format(BundleFileFormat), "test")))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	var n int
+	for tr := tar.NewReader(gr); ; n++ {
+		if _, err := tr.Next(); err != nil {
+			break
+		}
+	}
+	if n != 2 {
+		t.Errorf("Wanted 2 bundled archives, got %d", n)
+	}
+}
+
+func TestBundleRespectsDeleteFilter(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithInterval(60*time.Minute))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	y, m, d := time.Now().Date()
+	day := time.Date(y, m, d-2, 1, 0, 0, 0, time.Local)
+	var paths []string
+	for i := 0; i < 2; i++ {
+		ts := day.Add(time.Duration(i) * time.Hour)
+		path := filepath.Join(dir, fmt.Sprintf(ts.Format(ArchiveFileFormat), "test"))
+		if err := ioutil.WriteFile(path, []byte("old\n"), 0644); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		paths = append(paths, path)
+	}
+
+	r.SetDeleteFilter(func(a ArchiveInfo) bool {
+		return a.Path != paths[0]
+	})
+	r.SetBundleAge(24 * time.Hour)
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+
+	if _, err := os.Stat(paths[0]); err != nil {
+		t.Errorf("Wanted the vetoed archive kept, got %q", err)
+	}
+	if _, err := os.Stat(paths[1]); !os.IsNotExist(err) {
+		t.Errorf("Wanted the other archive bundled, got %v", err)
+	}
+}
+
+func TestBundleKeepsPrivateArchivesPrivate(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithInterval(60*time.Minute))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	y, m, d := time.Now().Date()
+	day := time.Date(y, m, d-2, 1, 0, 0, 0, time.Local)
+	for i, perm := range []os.FileMode{0644, 0600} {
+		ts := day.Add(time.Duration(i) * time.Hour)
+		path := filepath.Join(dir, fmt.Sprintf(ts.Format(ArchiveFileFormat), "test"))
+		if err := ioutil.WriteFile(path, []byte("old\n"), perm); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		os.Chmod(path, perm)
+	}
+
+	r.SetBundleAge(24 * time.Hour)
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+
+	bundle := filepath.Join(dir, fmt.Sprintf(day.Format(BundleFileFormat), "test"))
+	fi, err := os.Stat(bundle)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("Wanted mode 0600, got %o", fi.Mode().Perm())
+	}
+
+	// Archive permissions, when set, apply to the bundle as to any archive.
+	r.SetArchivePerm(0640)
+	path := filepath.Join(dir, fmt.Sprintf(day.Add(2*time.Hour).Format(ArchiveFileFormat), "test"))
+	if err := ioutil.WriteFile(path, []byte("old\n"), 0600); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+	fi, err = os.Stat(bundle)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if fi.Mode().Perm() != 0640 {
+		t.Errorf("Wanted mode 0640, got %o", fi.Mode().Perm())
+	}
+}
+
+func TestRetentionCoversBundles(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	pruned := make(chan ArchiveInfo, 8)
+	r, err := New(dir, "test", WithInterval(60*time.Minute), WithObserver(ObserverFuncs{
+		Prune: func(a ArchiveInfo) { pruned <- a },
+	}))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	y, m, d := time.Now().Date()
+	var (
+		day    = time.Date(y, m, d-2, 1, 0, 0, 0, time.Local)
+		expiry = filepath.Join(dir, fmt.Sprintf(day.AddDate(0, 0, -2).Format(BundleFileFormat), "test"))
+	)
+	if err := ioutil.WriteFile(expiry, []byte("old bundle"), 0644); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	for i := 0; i < 2; i++ {
+		ts := day.Add(time.Duration(i) * time.Hour)
+		path := filepath.Join(dir, fmt.Sprintf(ts.Format(ArchiveFileFormat), "test"))
+		if err := ioutil.WriteFile(path, []byte("old\n"), 0644); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+	}
+
+	r.SetBundleAge(24 * time.Hour)
+	r.SetMaxAge(72 * time.Hour)
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+
+	if _, err := os.Stat(expiry); !os.IsNotExist(err) {
+		t.Errorf("Wanted the expired bundle deleted, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, fmt.Sprintf(day.Format(BundleFileFormat), "test"))); err != nil {
+		t.Errorf("Wanted the archives bundled, got %q", err)
+	}
+
+	select {
+	case a := <-pruned:
+		if a.Path != expiry {
+			t.Errorf("Wanted the bundle pruned, got %s", a.Path)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Wanted a prune event")
+		t.FailNow()
+	}
+	time.Sleep(10 * time.Millisecond)
+	if n := len(pruned); n != 0 {
+		t.Errorf("Wanted bundled archives not reported as pruned, got %d", n)
+	}
+}
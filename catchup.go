@@ -0,0 +1,121 @@
+package rolog
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// WithCatchUp processes the archives left behind by earlier runs when the
+// Rolog is created, so that turning on compression, encryption or retention
+// also cleans up the history from before. Archives missing the compression or
+// encryption now configured are processed as a fresh one would be, oldest
+// first, and then retention and bundling are applied to the lot. Archives
+// already encrypted some other way are left alone.
+//
+// The work is done in the background, on the CompressPool if one is given,
+// one archive at a time so that rotations aren't held up behind it. Failures
+// are reported on Err, and Close stops the work early, leaving the rest for
+// the next run.
+func WithCatchUp() Option {
+	return func(r *Rolog) {
+		r.catchUp = true
+	}
+}
+
+// startCatchUp starts processing the backlog if WithCatchUp was given.
+func (r *Rolog) startCatchUp() {
+	if !r.catchUp {
+		return
+	}
+
+	r.compressing.Add(1)
+	job := func() {
+		defer r.compressing.Done()
+		r.processBacklog()
+	}
+	if r.compressPool != nil {
+		r.compressPool.submit(job)
+		return
+	}
+	go job()
+}
+
+// processBacklog finishes the processing of the archives from earlier runs,
+// then applies retention and bundling.
+func (r *Rolog) processBacklog() {
+	r.mu.Lock()
+	s := r.archiveSettings()
+	r.mu.Unlock()
+
+	archives, err := r.archives()
+	if err != nil {
+		r.report(errors.Wrap(err, "could not list backlog"))
+		return
+	}
+
+	for _, a := range archives {
+		if !plain(a) || !unfinished(a, s) {
+			continue
+		}
+		select {
+		case <-r.done:
+			return
+		default:
+		}
+
+		if s.dryRun != nil {
+			_, plan := planFinalize(s, a.Path)
+			for _, p := range plan {
+				s.dryRun(p)
+			}
+			continue
+		}
+
+		r.rotMu.Lock()
+		// pruned by a rotation since it was listed
+		if _, err := r.fs.Stat(a.Path); err == nil {
+			r.debugf("processing backlog archive %s", a.Path)
+			if _, err := r.finalize(a.Path, s); err != nil {
+				r.report(errors.Wrapf(err, "could not process backlog archive %s", a.Path))
+			}
+		}
+		r.rotMu.Unlock()
+	}
+
+	r.rotMu.Lock()
+	defer r.rotMu.Unlock()
+
+	if s.dryRun != nil {
+		plan, err := r.planProcessing(s, ArchiveInfo{}, r.now())
+		if err != nil {
+			r.report(errors.Wrap(err, "could not plan backlog retention"))
+		}
+		for _, p := range plan {
+			s.dryRun(p)
+		}
+		return
+	}
+	if err := r.prune(s); err != nil {
+		r.report(errors.Wrap(err, "could not prune backlog"))
+	}
+	if err := r.bundle(s); err != nil {
+		r.report(errors.Wrap(err, "could not bundle backlog"))
+	}
+}
+
+// plain reports whether a has been no more than compressed since it was
+// rotated, as opposed to processed in a way the Rolog doesn't know, such as by
+// an Encrypter that is no longer configured.
+func plain(a ArchiveInfo) bool {
+	for _, part := range strings.Split(a.ext, ".")[1:] {
+		switch part {
+		case "gz", "bz2", "zst":
+			continue
+		}
+		if strings.Trim(part, "0123456789") != "" {
+			return false
+		}
+	}
+	return true
+}
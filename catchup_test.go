@@ -0,0 +1,67 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCatchUpProcessesEarlierArchives(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	for i := 0; i < 4; i++ {
+		r.Write([]byte("hello\n"))
+		if err := r.Rotate(); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		clock.Advance(time.Hour)
+	}
+	r.Close()
+
+	r, err = New(dir, "test", WithClock(clock), WithAppend(), WithCompression(Gzip), WithMaxBackups(3), WithCatchUp())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	// Retention is applied once the backlog has been compressed.
+	archives := waitForArchives(t, r, 3)
+	for _, a := range archives {
+		if !a.Compressed {
+			t.Errorf("Wanted %s compressed by the catch-up", a.Path)
+		}
+	}
+}
+
+func TestCatchUpLeavesUnknownArchivesAlone(t *testing.T) {
+	for ext, want := range map[string]bool{
+		"":        true,
+		".gz":     true,
+		".1":      true,
+		".1.zst":  true,
+		".age":    false,
+		".gz.gpg": false,
+	} {
+		if got := plain(ArchiveInfo{ext: ext}); got != want {
+			t.Errorf("Wanted plain(%q) to be %t, got %t", ext, want, got)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package rolog
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrCurrentMissing is reported on the Err channel when the current file was
+// found deleted or moved and has been recreated.
+var ErrCurrentMissing = errors.New("current log was deleted or moved")
+
+// WithFileCheck makes a running Rolog check every d that its current path
+// still refers to the file it is writing to. If an operator has deleted or
+// moved the file, writes would otherwise go to an unlinked inode forever, so
+// the file is recreated and ErrCurrentMissing is reported. A non-positive d
+// disables the check, which is the default.
+func WithFileCheck(d time.Duration) Option {
+	return func(r *Rolog) {
+		r.checkEvery = d
+	}
+}
+
+// checkCurrent recreates the current file if its path no longer refers to the
+// open file. On filesystems other than OSFS only a missing file is detected,
+// since there is no way to tell whether two files are the same.
+func (r *Rolog) checkCurrent() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.swapping || r.closed() || r.passthrough != nil {
+		return nil
+	}
+
+	want, err := r.fs.Stat(r.currentPath())
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return errors.Wrap(err, "could not check current log")
+	default:
+		if _, ok := baseFS(r.fs).(OSFS); !ok {
+			return nil
+		}
+		have, err := r.f.Stat()
+		if err != nil || os.SameFile(want, have) {
+			return nil
+		}
+	}
+
+	if err := r.recreate(); err != nil {
+		return err
+	}
+
+	return ErrCurrentMissing
+}
+
+// recreate opens a fresh current file, along with its directory if need be, in
+// place of one that has been deleted or moved. It must be called with mu held.
+func (r *Rolog) recreate() error {
+	if err := r.drain(); err != nil {
+		return err
+	}
+
+	if err := r.mkdir(filepath.Dir(r.currentPath())); err != nil {
+		return errors.Wrap(err, "could not recreate log directory")
+	}
+	f, err := openFile(r.fs, r.currentPath(), currentFlag, r.perm)
+	if err != nil {
+		return errors.Wrap(err, "could not recreate current log")
+	}
+	r.f.Close()
+	r.setFile(f)
+
+	r.size = 0
+	if fi, err := f.Stat(); err == nil {
+		r.size = fi.Size()
+	}
+	return r.writeHeader()
+}
@@ -0,0 +1,55 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestCheckCurrentRecreatesDeletedAndMovedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	current := filepath.Join(dir, "test.log")
+	if err := r.checkCurrent(); err != nil {
+		t.Errorf("Wanted no error for an intact file, got %q", err)
+	}
+
+	os.Remove(current)
+	if err := r.checkCurrent(); errors.Cause(err) != ErrCurrentMissing {
+		t.Errorf("Wanted ErrCurrentMissing after a delete, got %v", err)
+	}
+	r.Write([]byte("after delete\n"))
+	if got, _ := ioutil.ReadFile(current); string(got) != "after delete\n" {
+		t.Errorf("Wanted writes to reach the recreated file, got %q", got)
+	}
+
+	os.Rename(current, filepath.Join(dir, "moved.log"))
+	ioutil.WriteFile(current, []byte("impostor\n"), 0644)
+	if err := r.checkCurrent(); errors.Cause(err) != ErrCurrentMissing {
+		t.Errorf("Wanted ErrCurrentMissing after a move, got %v", err)
+	}
+	r.Write([]byte("after move\n"))
+	if got, _ := ioutil.ReadFile(current); string(got) != "impostor\nafter move\n" {
+		t.Errorf("Wanted writes to reach the file at the current path, got %q", got)
+	}
+}
@@ -0,0 +1,74 @@
+package rolog
+
+import (
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Child returns a Rolog that writes to its own file, named after name, in the
+// same directory and with the same options as r, but which is rotated whenever
+// r is. This gives related streams such as access, error and audit logs
+// consistent cut-over times from a single scheduler. The child should not be
+// started with Run; it must still be closed when no longer needed.
+func (r *Rolog) Child(name string) (*Rolog, error) {
+	return r.child(name)
+}
+
+// child creates a Child with extra options applied after r's own.
+func (r *Rolog) child(name string, extra ...Option) (*Rolog, error) {
+	opts := append(r.opts[:len(r.opts):len(r.opts)], func(c *Rolog) {
+		c.stdLog = false
+		// only the root talks to the service manager
+		c.systemd = nil
+	})
+	opts = append(opts, extra...)
+
+	dir := filepath.Dir(r.currentPath())
+	if r.dayLayout != "" {
+		// the child keeps its own dated directories
+		dir = r.dayRoot
+	}
+	c, err := New(dir, name, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create child log")
+	}
+	c.parent = r
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.children = append(r.children, c)
+
+	return c, nil
+}
+
+// detach removes r from the children of its parent, if it has one.
+func (r *Rolog) detach() {
+	p := r.parent
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, c := range p.children {
+		if c == r {
+			p.children = append(p.children[:i], p.children[i+1:]...)
+			break
+		}
+	}
+	r.parent = nil
+}
+
+// rotateChildren rotates every child, returning the first error.
+func rotateChildren(children []*Rolog) error {
+	var first error
+	for _, c := range children {
+		if err := c.rotateFor(ReasonParent); err != nil && first == nil {
+			first = errors.Wrapf(err, "could not rotate child %s", c.name)
+		}
+	}
+	return first
+}
@@ -0,0 +1,77 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestChildRotatesWithParent(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "app", WithClock(clock))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	access, err := r.Child("access")
+	if err != nil {
+		t.Errorf("could not create child: %q", err)
+		t.FailNow()
+	}
+	audit, err := r.Child("audit")
+	if err != nil {
+		t.Errorf("could not create child: %q", err)
+		t.FailNow()
+	}
+
+	r.Write([]byte("app\n"))
+	access.Write([]byte("access\n"))
+	audit.Write([]byte("audit\n"))
+
+	clock.Advance(time.Hour)
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+
+	for _, l := range []*Rolog{r, access, audit} {
+		archives, err := l.Archives()
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		if len(archives) != 1 {
+			t.Errorf("Wanted 1 archive for %s, got %d", l.name, len(archives))
+			continue
+		}
+		if !archives[0].End.Equal(clock.Now()) {
+			t.Errorf("Wanted %s archived at %s, got %s", l.name, clock.Now(), archives[0].End)
+		}
+	}
+
+	if err := audit.Close(); err != nil {
+		t.Errorf("could not close child: %q", err)
+		t.FailNow()
+	}
+
+	clock.Advance(time.Hour)
+	if err := r.Rotate(); err != nil {
+		t.Errorf("wanted closed child to be skipped, got %q", err)
+	}
+	access.Close()
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+// +build !windows
+
+package rolog
+
+import "os"
+
+// Chown satisfies ChownFS.
+func (OSFS) Chown(name string, uid, gid int) error {
+	return os.Chown(name, uid, gid)
+}
@@ -0,0 +1,7 @@
+package rolog
+
+// Chown satisfies ChownFS. It is a no-op on Windows, which has no uid/gid
+// ownership model.
+func (OSFS) Chown(name string, uid, gid int) error {
+	return nil
+}
@@ -0,0 +1,22 @@
+package rolog
+
+// WithCircular keeps the log to a single file with no archives at all, for
+// embedded and appliance deployments with a strict disk budget. Whenever the
+// log would rotate, on its interval or on reaching WithMaxSize, the current
+// file is truncated in place instead, keeping up to keep bytes of its newest
+// whole lines; see TruncateStrategy. An existing current file is carried on
+// with at startup, as there is nowhere to archive it. keep must be less than
+// any max size, or every write would truncate.
+func WithCircular(keep int64) Option {
+	return func(r *Rolog) {
+		r.strategy = TruncateStrategy{Keep: keep}
+		r.appendOnStart = true
+	}
+}
+
+// validKeep reports whether the bytes kept by a TruncateStrategy, if that is
+// the strategy, leave room under the max size.
+func (r *Rolog) validKeep() bool {
+	t, ok := r.strategy.(TruncateStrategy)
+	return !ok || t.Keep >= 0 && (r.maxSize <= 0 || t.Keep < r.maxSize)
+}
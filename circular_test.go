@@ -0,0 +1,121 @@
+package rolog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestCircularTruncatesInPlace(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	path := filepath.Join(dir, "test.log")
+	if err := ioutil.WriteFile(path, []byte("before\n"), 0644); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithMaxSize(100), WithCircular(20))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if string(got) != "before\n" {
+		t.Errorf("Wanted the existing file carried on with, got %q", got)
+	}
+
+	before, err := os.Stat(path)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(r, "line %02d\n", i)
+	}
+
+	got, err = ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	// Lines are 8 bytes, so 20 bytes keeps two whole lines of the file as
+	// it was before the write that didn't fit.
+	if want := "line 09\nline 10\nline 11\nline 12\nline 13\nline 14\nline 15\nline 16\nline 17\nline 18\nline 19\n"; string(got) != want {
+		t.Errorf("Wanted %q, got %q", want, got)
+	}
+	after, err := os.Stat(path)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if !os.SameFile(before, after) {
+		t.Errorf("Wanted the file truncated in place")
+	}
+
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	got, err = ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if want := "line 18\nline 19\n"; string(got) != want {
+		t.Errorf("Wanted %q after a rotation, got %q", want, got)
+	}
+
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(fis) != 1 {
+		var names []string
+		for _, fi := range fis {
+			names = append(names, fi.Name())
+		}
+		t.Errorf("Wanted only the current file, got %s", strings.Join(names, ", "))
+	}
+}
+
+func TestCircularKeepMustFitUnderMaxSize(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	for _, keep := range []int64{-1, 100} {
+		_, err := New(dir, "test", WithMaxSize(100), WithCircular(keep))
+		if errors.Cause(err) != ErrInvalidKeep {
+			t.Errorf("Wanted ErrInvalidKeep keeping %d bytes, got %v", keep, err)
+		}
+	}
+}
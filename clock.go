@@ -0,0 +1,61 @@
+package rolog
+
+import "time"
+
+// Clock is the source of time for a Rolog. It drives both archive naming and
+// the rotation scheduler, so tests can substitute a fake.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTicker returns a Ticker that fires every d.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker is the subset of *time.Ticker used by the rotation scheduler.
+type Ticker interface {
+	// C returns the channel on which ticks are delivered.
+	C() <-chan time.Time
+	// Stop turns off the ticker.
+	Stop()
+}
+
+// IdleTicker is implemented by Tickers that need to know when the run loop has
+// done whatever their ticks called for, such as those of a test clock that
+// waits for a scheduled rotation to finish before letting time move on. The
+// run loop calls Idle on each of its tickers every time it goes back to
+// waiting for them.
+type IdleTicker interface {
+	Ticker
+	Idle()
+}
+
+// systemClock is the Clock backed by the time package.
+type systemClock struct{}
+
+// Now satisfies Clock.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTicker satisfies Clock.
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+// systemTicker adapts *time.Ticker to Ticker.
+type systemTicker struct {
+	*time.Ticker
+}
+
+// C satisfies Ticker.
+func (t systemTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// now returns the current time according to the configured Clock.
+func (r *Rolog) now() time.Time {
+	if r.clock == nil {
+		return time.Now()
+	}
+	return r.clock.Now()
+}
@@ -0,0 +1,441 @@
+// Command rolog operates on the logs of a program that writes them with Rolog,
+// from outside that program.
+//
+// Usage:
+//
+//	rolog rotate -pid PID [-signal HUP]   signal a program using WithRotateSignal
+//	rolog rotate -trigger PATH            create the file given to WithTriggerFile
+//	rolog rotate -admin URL               post to the admin package's Handler
+//	rolog list -dir DIR -name NAME        list archives, oldest first
+//	rolog prune -dir DIR -name NAME [-keep N] [-max-age D] [-max-size N] [-dry-run]
+//	rolog verify -dir DIR -name NAME      check the archives are intact and complete
+//	rolog verify -admin URL               have a running program check its archives
+//	rolog tail -dir DIR -name NAME [-n N] [-f]
+//	rolog adopt -dir DIR -name NAME [-at TIME] [-compression C] FILE...
+//
+// The log commands accept -ext for logs written with WithExtension.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/haleyrc/rolog"
+	"github.com/pkg/errors"
+)
+
+// followPoll is how often tail -f looks for new lines.
+const followPoll = 250 * time.Millisecond
+
+// commands maps each subcommand to its implementation.
+var commands = map[string]func(args []string, out io.Writer) error{
+	"rotate": rotate,
+	"list":   list,
+	"prune":  prune,
+	"verify": verify,
+	"tail":   tail,
+	"adopt":  adopt,
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "rolog:", err)
+		os.Exit(1)
+	}
+}
+
+// run dispatches args to a subcommand.
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("expected a command: rotate, list, prune, verify, tail or adopt")
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return errors.Errorf("unknown command %q", args[0])
+	}
+	return cmd(args[1:], out)
+}
+
+// target is a log named by the -dir, -name and -ext flags.
+type target struct {
+	dir, name, ext string
+}
+
+// targetFlags adds the flags naming a log to fs.
+func targetFlags(fs *flag.FlagSet) *target {
+	t := &target{}
+	fs.StringVar(&t.dir, "dir", ".", "the directory the log is written to")
+	fs.StringVar(&t.name, "name", "", "the base name of the log files")
+	fs.StringVar(&t.ext, "ext", rolog.DefaultExtension, "the extension of the log files")
+	return t
+}
+
+// parse parses args into fs and checks a log was named.
+func (t *target) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if t.name == "" {
+		return errors.New("-name is required")
+	}
+	return nil
+}
+
+// options returns the naming options of the log.
+func (t *target) options() []rolog.Option {
+	if t.ext == rolog.DefaultExtension {
+		return nil
+	}
+	return []rolog.Option{rolog.WithExtension(t.ext)}
+}
+
+// archives lists the archives of the log.
+func (t *target) archives() ([]rolog.ArchiveInfo, error) {
+	return rolog.ListArchives(t.dir, t.name, t.options()...)
+}
+
+// current returns the path of the log's current file.
+func (t *target) current() string {
+	format := strings.TrimSuffix(rolog.CurrentFilename, rolog.DefaultExtension) + t.ext
+	return filepath.Join(t.dir, fmt.Sprintf(format, t.name))
+}
+
+// rotate asks a running program to rotate its log.
+func rotate(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("rotate", flag.ContinueOnError)
+	var (
+		pid     = fs.Int("pid", 0, "the process to signal")
+		sig     = fs.String("signal", "HUP", "the signal to send with -pid")
+		trigger = fs.String("trigger", "", "the trigger file to create")
+		admin   = fs.String("admin", "", "the URL the admin handler is served at")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch {
+	case *pid != 0:
+		return sendSignal(*pid, *sig)
+	case *trigger != "":
+		return errors.Wrap(ioutil.WriteFile(*trigger, nil, 0644), "could not create trigger file")
+	case *admin != "":
+		resp, err := http.Post(strings.TrimSuffix(*admin, "/")+"/rotate", "", nil)
+		if err != nil {
+			return errors.Wrap(err, "could not request rotation")
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+			return errors.Errorf("rotation failed: %s: %s", resp.Status, bytes.TrimSpace(msg))
+		}
+		return nil
+	}
+	return errors.New("one of -pid, -trigger or -admin is required")
+}
+
+// list prints the archives of a log.
+func list(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	t := targetFlags(fs)
+	if err := t.parse(fs, args); err != nil {
+		return err
+	}
+
+	archives, err := t.archives()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ROTATED\tSIZE\tPATH")
+	for _, a := range archives {
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", a.End.Format(time.RFC3339), a.Size, a.Path)
+	}
+	return tw.Flush()
+}
+
+// prune deletes the oldest archives of a log beyond the given limits.
+func prune(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("prune", flag.ContinueOnError)
+	t := targetFlags(fs)
+	var (
+		keep    = fs.Int("keep", 0, "the number of archives to keep")
+		maxAge  = fs.Duration("max-age", 0, "how long archives are kept")
+		maxSize = fs.String("max-size", "", "the byte budget for the current file and its archives")
+		dryRun  = fs.Bool("dry-run", false, "print what would be removed without removing it")
+	)
+	if err := t.parse(fs, args); err != nil {
+		return err
+	}
+
+	var budget int64
+	if *maxSize != "" {
+		n, err := rolog.ParseSize(*maxSize)
+		if err != nil {
+			return err
+		}
+		budget = n
+	}
+	if *keep <= 0 && *maxAge <= 0 && budget <= 0 {
+		return errors.New("one of -keep, -max-age or -max-size is required")
+	}
+
+	archives, err := t.archives()
+	if err != nil {
+		return err
+	}
+
+	total := int64(0)
+	if fi, err := os.Stat(t.current()); err == nil {
+		total = fi.Size()
+	}
+	for _, a := range archives {
+		total += a.Size
+	}
+
+	cutoff := time.Now().Add(-*maxAge)
+	for i, a := range archives {
+		var (
+			excess = *keep > 0 && len(archives)-i > *keep
+			old    = *maxAge > 0 && a.End.Before(cutoff)
+			over   = budget > 0 && total > budget
+		)
+		if !excess && !old && !over {
+			break
+		}
+
+		if *dryRun {
+			fmt.Fprintf(out, "would remove %s\n", a.Path)
+		} else if err := os.Remove(a.Path); err != nil {
+			return errors.Wrap(err, "could not remove archive")
+		} else {
+			fmt.Fprintf(out, "removed %s\n", a.Path)
+		}
+		total -= a.Size
+	}
+
+	return nil
+}
+
+// verify checks the archives of a log, or of a running program through its
+// admin handler, and reports any problems.
+func verify(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	t := targetFlags(fs)
+	admin := fs.String("admin", "", "the URL the admin handler is served at")
+
+	var (
+		report rolog.VerifyReport
+		err    error
+	)
+	if err = fs.Parse(args); err != nil {
+		return err
+	}
+	switch {
+	case *admin != "":
+		report, err = verifyRemote(*admin)
+	case t.name == "":
+		return errors.New("-name is required")
+	default:
+		report, err = rolog.VerifyArchives(context.Background(), t.dir, t.name, t.options()...)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, path := range report.Verified {
+		fmt.Fprintf(out, "ok %s\n", path)
+	}
+	for _, path := range report.Skipped {
+		fmt.Fprintf(out, "skipped %s: encrypted\n", path)
+	}
+	for _, p := range report.Problems {
+		fmt.Fprintf(out, "FAILED %s: %s: %s\n", p.Path, p.Problem, p.Detail)
+	}
+
+	if !report.OK() {
+		return errors.Errorf("verification found %d problems", len(report.Problems))
+	}
+	return nil
+}
+
+// verifyRemote asks the admin handler at url to verify its log.
+func verifyRemote(url string) (rolog.VerifyReport, error) {
+	var report rolog.VerifyReport
+	resp, err := http.Get(strings.TrimSuffix(url, "/") + "/verify")
+	if err != nil {
+		return report, errors.Wrap(err, "could not request verification")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return report, errors.Errorf("verification failed: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	err = json.NewDecoder(resp.Body).Decode(&report)
+	return report, errors.Wrap(err, "could not read verification report")
+}
+
+// tail prints the last lines of a log's current file, optionally following it
+// across rotations.
+func tail(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("tail", flag.ContinueOnError)
+	t := targetFlags(fs)
+	var (
+		n      = fs.Int("n", 10, "the number of lines to print")
+		follow = fs.Bool("f", false, "keep printing lines as they are written")
+	)
+	if err := t.parse(fs, args); err != nil {
+		return err
+	}
+
+	path := t.current()
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "could not open log")
+	}
+	defer func() { f.Close() }()
+
+	if err := lastLines(f, *n, out); err != nil {
+		return err
+	}
+	if !*follow {
+		return nil
+	}
+
+	for {
+		if _, err := io.Copy(out, f); err != nil {
+			return errors.Wrap(err, "could not read log")
+		}
+		time.Sleep(followPoll)
+
+		// Start over on a new file after a rotation, or on the same one
+		// after it has been truncated.
+		have, err := f.Stat()
+		if err != nil {
+			return errors.Wrap(err, "could not read log")
+		}
+		want, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if os.SameFile(have, want) {
+			if pos, err := f.Seek(0, io.SeekCurrent); err == nil && want.Size() < pos {
+				f.Seek(0, io.SeekStart)
+			}
+			continue
+		}
+
+		// Drain what was written before the rotation first.
+		if _, err := io.Copy(out, f); err != nil {
+			return errors.Wrap(err, "could not read log")
+		}
+		next, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		f.Close()
+		f = next
+	}
+}
+
+// adopt brings files written by another tool into a log's archives.
+func adopt(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("adopt", flag.ContinueOnError)
+	t := targetFlags(fs)
+	var (
+		at          = fs.String("at", "", "the RFC 3339 time the files were rotated out, instead of their modification times")
+		compression = fs.String("compression", "", "compress the files with gzip or zstd")
+	)
+	if err := t.parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("expected the files to adopt")
+	}
+
+	var when time.Time
+	if *at != "" {
+		var err error
+		if when, err = time.Parse(time.RFC3339, *at); err != nil {
+			return errors.Wrap(err, "invalid -at")
+		}
+	}
+	opts := t.options()
+	switch c := rolog.Compression(*compression); c {
+	case rolog.NoCompression:
+	case rolog.Gzip, rolog.Zstd:
+		opts = append(opts, rolog.WithCompression(c))
+	default:
+		return errors.Errorf("unknown compression %q", *compression)
+	}
+
+	for _, path := range fs.Args() {
+		adopted, err := rolog.AdoptFile(t.dir, t.name, path, when, opts...)
+		if err != nil {
+			return errors.Wrapf(err, "could not adopt %s", path)
+		}
+		fmt.Fprintf(out, "adopted %s as %s\n", path, adopted)
+	}
+	return nil
+}
+
+// lastLines copies the last n lines of f to out, leaving f positioned at its
+// end.
+func lastLines(f *os.File, n int, out io.Writer) error {
+	end, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return errors.Wrap(err, "could not read log")
+	}
+
+	// Read backwards a chunk at a time until enough newlines have been seen;
+	// the one ending the last line doesn't count.
+	var (
+		start = end
+		buf   = make([]byte, 4096)
+		seen  = 0
+	)
+	if n <= 0 {
+		return nil
+	}
+scan:
+	for start > 0 {
+		size := int64(len(buf))
+		if start < size {
+			size = start
+		}
+		start -= size
+		if _, err := f.ReadAt(buf[:size], start); err != nil {
+			return errors.Wrap(err, "could not read log")
+		}
+		for i := size - 1; i >= 0; i-- {
+			if buf[i] != '\n' || start+i == end-1 {
+				continue
+			}
+			if seen++; seen == n {
+				start += i + 1
+				break scan
+			}
+		}
+	}
+
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		return errors.Wrap(err, "could not read log")
+	}
+	w := bufio.NewWriter(out)
+	if _, err := io.CopyN(w, f, end-start); err != nil {
+		return errors.Wrap(err, "could not read log")
+	}
+	return w.Flush()
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haleyrc/rolog"
+)
+
+// writeArchives creates n plain archives of test in dir, an hour apart and
+// oldest first, returning their paths.
+func writeArchives(t *testing.T, dir string, n int) []string {
+	var paths []string
+	for i := n; i > 0; i-- {
+		ts := time.Now().Add(-time.Duration(i) * time.Hour)
+		path := filepath.Join(dir, fmt.Sprintf(ts.Format(rolog.ArchiveFileFormat), "test"))
+		if err := ioutil.WriteFile(path, []byte("0123456789"), 0644); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestListAndPrune(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	paths := writeArchives(t, dir, 3)
+
+	var out bytes.Buffer
+	if err := run([]string{"list", "-dir", dir, "-name", "test"}, &out); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 4 || !strings.Contains(lines[1], paths[0]) {
+		t.Errorf("Wanted a header and the archives oldest first, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := run([]string{"prune", "-dir", dir, "-name", "test", "-keep", "1", "-dry-run"}, &out); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if _, err := os.Stat(paths[0]); err != nil {
+		t.Errorf("Wanted a dry run to keep %s, got %q", paths[0], err)
+	}
+
+	out.Reset()
+	if err := run([]string{"prune", "-dir", dir, "-name", "test", "-max-size", "25"}, &out); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if _, err := os.Stat(paths[0]); !os.IsNotExist(err) {
+		t.Errorf("Wanted %s pruned, got %v", paths[0], err)
+	}
+	for _, path := range paths[1:] {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Wanted %s to be kept, got %q", path, err)
+		}
+	}
+}
+
+func TestVerifyReportsCorruptArchives(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	paths := writeArchives(t, dir, 2)
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("0123456789"))
+	zw.Close()
+	b := gz.Bytes()
+	b[len(b)-5] ^= 0xff // corrupt the CRC
+	if err := ioutil.WriteFile(paths[1]+".gz", b, 0644); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	os.Remove(paths[1])
+
+	var out bytes.Buffer
+	if err := run([]string{"verify", "-dir", dir, "-name", "test"}, &out); err == nil {
+		t.Errorf("Wanted an error for the corrupt archive, got nil")
+	}
+	if !strings.Contains(out.String(), "ok "+paths[0]) || !strings.Contains(out.String(), "FAILED "+paths[1]+".gz") {
+		t.Errorf("Wanted the first archive ok and the second failed, got %q", out.String())
+	}
+}
+
+func TestTailPrintsLastLines(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	var content strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&content, "line %d\n", i)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "test.log"), []byte(content.String()), 0644); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"tail", "-dir", dir, "-name", "test", "-n", "3"}, &out); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if want := "line 1997\nline 1998\nline 1999\n"; out.String() != want {
+		t.Errorf("Wanted %q, got %q", want, out.String())
+	}
+}
+
+func TestRotateCreatesTriggerFile(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	trigger := filepath.Join(dir, "rotate")
+	if err := run([]string{"rotate", "-trigger", trigger}, ioutil.Discard); err != nil {
+		t.Errorf("unexpected error: %q", err)
+	}
+	if _, err := os.Stat(trigger); err != nil {
+		t.Errorf("Wanted the trigger file created, got %q", err)
+	}
+}
+
+func TestAdoptRenamesIntoTheArchives(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log.1")
+	if err := ioutil.WriteFile(path, []byte("0123456789"), 0644); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	var out bytes.Buffer
+	args := []string{"adopt", "-dir", dir, "-name", "test", "-at", "2020-01-02T03:04:05Z", "-compression", "gzip", path}
+	if err := run(args, &out); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	archives, err := rolog.ListArchives(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(archives) != 1 || !archives[0].Compressed {
+		t.Errorf("Wanted a single compressed archive, got %+v", archives)
+		t.FailNow()
+	}
+	if want := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC); !archives[0].End.Equal(want) {
+		t.Errorf("Wanted the archive rotated at %s, got %s", want, archives[0].End)
+	}
+	if !strings.Contains(out.String(), "adopted "+path) {
+		t.Errorf("Wanted the adoption reported, got %q", out.String())
+	}
+}
@@ -0,0 +1,34 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package main
+
+import (
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// signals are the signals that can be named with -signal.
+var signals = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"TERM": syscall.SIGTERM,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+}
+
+// sendSignal sends the named or numbered signal to pid.
+func sendSignal(pid int, name string) error {
+	sig, ok := signals[strings.TrimPrefix(strings.ToUpper(name), "SIG")]
+	if !ok {
+		n, err := strconv.Atoi(name)
+		if err != nil {
+			return errors.Errorf("unknown signal %q", name)
+		}
+		sig = syscall.Signal(n)
+	}
+	return errors.Wrapf(syscall.Kill(pid, sig), "could not signal %d", pid)
+}
@@ -0,0 +1,16 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package main
+
+import (
+	"runtime"
+
+	"github.com/pkg/errors"
+)
+
+// sendSignal fails, since signals can't be sent to other processes here; use
+// -trigger or -admin instead.
+func sendSignal(pid int, name string) error {
+	return errors.Errorf("signals are not supported on %s, use -trigger or -admin", runtime.GOOS)
+}
@@ -0,0 +1,224 @@
+package rolog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// Compression selects the codec archives are compressed with after rotation.
+type Compression string
+
+const (
+	// NoCompression leaves archives as they were written.
+	NoCompression Compression = ""
+	// Gzip compresses archives to .gz files.
+	Gzip Compression = "gzip"
+	// Zstd compresses archives to .zst files.
+	Zstd Compression = "zstd"
+)
+
+// Extension returns the suffix added to archives compressed with c.
+func (c Compression) Extension() string {
+	switch c {
+	case Gzip:
+		return ".gz"
+	case Zstd:
+		return ".zst"
+	}
+	return ""
+}
+
+// valid reports whether c is a known codec.
+func (c Compression) valid() bool {
+	return c == NoCompression || c.Extension() != ""
+}
+
+// WithCompression compresses every archive after rotation, before any
+// encryption. The uncompressed archive is removed once the compressed copy
+// has been written.
+func WithCompression(c Compression) Option {
+	return func(r *Rolog) {
+		if !c.valid() {
+			r.invalid("Compression", ErrInvalidCompression)
+			return
+		}
+		r.compression = c
+	}
+}
+
+// SetCompression changes the codec applied to archives from the next rotation
+// on. Unknown codecs are ignored; pass NoCompression to disable compression.
+func (r *Rolog) SetCompression(c Compression) {
+	if !c.valid() {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.compression = c
+	r.publish()
+}
+
+// WithCompressWorkers sets how many goroutines compress each archive in
+// parallel. The default of one suits small programs; ingest daemons with large
+// archives and spare cores can raise it. Gzip archives compressed in parallel
+// are written as a series of gzip members, which every gzip reader accepts.
+func WithCompressWorkers(n int) Option {
+	return func(r *Rolog) {
+		if n < 1 {
+			n = 1
+		}
+		r.compressWorkers = n
+	}
+}
+
+// WithCompressionLevel sets the level archives are compressed at, trading
+// speed for size: 1 to 9 for gzip, and 1 to 22 for zstd, where the levels are
+// mapped onto the encoder's nearest setting. Zero, the default, uses the
+// codec's default level.
+func WithCompressionLevel(level int) Option {
+	return func(r *Rolog) {
+		r.compressLevel = level
+	}
+}
+
+// compressChunk is how much of an archive each gzip worker compresses at once.
+const compressChunk = 1 << 20
+
+// compressFile writes a copy of src compressed with c to dst, by way of a
+// temporary file so that dst never holds a partial archive.
+func compressFile(fs FS, c Compression, workers, level int, src, dst string) error {
+	in, err := fs.OpenFile(src, os.O_RDONLY, 0)
+	if err != nil {
+		return errors.Wrap(err, "could not open archive")
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := fs.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return errors.Wrap(err, "could not create compressed archive")
+	}
+	defer fs.Remove(tmp)
+
+	switch c {
+	case Gzip:
+		err = compressGzip(out, in, workers, level)
+	case Zstd:
+		err = compressZstd(out, in, workers, level)
+	}
+	if err == nil {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not compress archive")
+	}
+
+	return errors.Wrap(fs.Rename(tmp, dst), "could not replace compressed archive")
+}
+
+// compressZstd compresses in to out with the given encoder concurrency, at
+// level, or the default level if it is zero.
+func compressZstd(out io.Writer, in io.Reader, workers, level int) error {
+	opts := []zstd.EOption{zstd.WithEncoderConcurrency(workers)}
+	if level != 0 {
+		opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	}
+	zw, err := zstd.NewWriter(out, opts...)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(zw, in); err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// compressGzip compresses in to out at level, or the default level if it is
+// zero. With more than one worker the input is split into chunks that are
+// compressed concurrently and written, in order, as separate gzip members.
+func compressGzip(out io.Writer, in io.Reader, workers, level int) error {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	if workers <= 1 {
+		gw, err := gzip.NewWriterLevel(out, level)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(gw, in); err != nil {
+			gw.Close()
+			return err
+		}
+		return gw.Close()
+	}
+
+	for {
+		var (
+			chunks = make([][]byte, 0, workers)
+			done   bool
+		)
+		for len(chunks) < workers && !done {
+			chunk := make([]byte, compressChunk)
+			n, err := io.ReadFull(in, chunk)
+			if n > 0 {
+				chunks = append(chunks, chunk[:n])
+			}
+			switch err {
+			case nil:
+			case io.EOF, io.ErrUnexpectedEOF:
+				done = true
+			default:
+				return err
+			}
+		}
+
+		var (
+			members = make([]bytes.Buffer, len(chunks))
+			errs    = make([]error, len(chunks))
+			wg      sync.WaitGroup
+		)
+		for i := range chunks {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer catch("compression", &errs[i])
+				gw, err := gzip.NewWriterLevel(&members[i], level)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				if _, err := gw.Write(chunks[i]); err != nil {
+					errs[i] = err
+					return
+				}
+				errs[i] = gw.Close()
+			}(i)
+		}
+		wg.Wait()
+
+		for i := range members {
+			if errs[i] != nil {
+				return errs[i]
+			}
+			if _, err := members[i].WriteTo(out); err != nil {
+				return err
+			}
+		}
+
+		if done {
+			return nil
+		}
+	}
+}
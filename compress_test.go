@@ -0,0 +1,83 @@
+package rolog
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestCompressionCodecs(t *testing.T) {
+	var want bytes.Buffer
+	for i := 0; want.Len() < 3*compressChunk; i++ {
+		fmt.Fprintf(&want, "line %d of a log with some repetition in it\n", i)
+	}
+
+	cases := []struct {
+		c       Compression
+		workers int
+		level   int
+	}{
+		{Gzip, 1, 0},
+		{Gzip, 4, 0},
+		{Gzip, 4, 1},
+		{Zstd, 1, 0},
+		{Zstd, 4, 0},
+		{Zstd, 1, 19},
+	}
+
+	for _, c := range cases {
+		dir, err := ioutil.TempDir(".", "tmp")
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+
+		clock := newFakeClock()
+		r, err := New(dir, "test", WithClock(clock), WithCompression(c.c), WithCompressWorkers(c.workers), WithCompressionLevel(c.level), WithSyncPolicy(SyncOnRotate))
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+
+		r.Write(want.Bytes())
+		clock.Advance(time.Hour)
+		if err := r.Rotate(); err != nil {
+			t.Errorf("could not rotate with %s: %q", c.c, err)
+		}
+
+		archives, err := r.Archives()
+		if err != nil || len(archives) != 1 {
+			t.Errorf("Wanted 1 archive with %s, got %d (%v)", c.c, len(archives), err)
+		} else if a := archives[0]; !a.Compressed || a.Size >= int64(want.Len()) {
+			t.Errorf("Wanted a compressed archive with %s, got %+v", c.c, a)
+		} else {
+			rc, err := OpenArchive(a)
+			if err != nil {
+				t.Errorf("unexpected error: %q", err)
+			} else {
+				got, _ := ioutil.ReadAll(rc)
+				rc.Close()
+				if !bytes.Equal(got, want.Bytes()) {
+					t.Errorf("Wanted the archive to round-trip with %s and %d workers", c.c, c.workers)
+				}
+			}
+		}
+
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}
+}
+
+func TestWithCompressionRejectsUnknownCodecs(t *testing.T) {
+	_, err := New(".", "test", WithCompression("lz4"))
+	if errors.Cause(err) != ErrInvalidCompression {
+		t.Errorf("Wanted %q, got %v", ErrInvalidCompression, err)
+	}
+}
@@ -0,0 +1,95 @@
+package rolog
+
+import "sync"
+
+// CompressPool compresses archives in the background on a fixed number of
+// goroutines, which can be shared by several logs to hold their compression
+// to a few cores between them. Archives that arrive while every worker is
+// busy wait their turn in order, however many there are, so rotations that
+// outpace compression never block or lose an archive.
+type CompressPool struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	jobs    []func()
+	closed  bool
+	workers sync.WaitGroup
+}
+
+// NewCompressPool starts a pool of n workers. If n is less than one, the pool
+// has one worker.
+func NewCompressPool(n int) *CompressPool {
+	if n < 1 {
+		n = 1
+	}
+	p := &CompressPool{}
+	p.cond = sync.NewCond(&p.mu)
+	p.workers.Add(n)
+	for i := 0; i < n; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// WithCompressPool compresses archives on p rather than on the goroutine that
+// rotated, so that rotating returns as soon as the file has been swapped. The
+// rotation event, retention and everything else that needs the finished
+// archive follow once it has been compressed. Close waits for the log's
+// archives in the pool to be done.
+func WithCompressPool(p *CompressPool) Option {
+	return func(r *Rolog) {
+		r.compressPool = p
+	}
+}
+
+// Pending returns how many archives are waiting for a worker.
+func (p *CompressPool) Pending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.jobs)
+}
+
+// Close stops the workers once every queued archive has been compressed.
+// Archives that logs hand the pool after that are compressed on the spot.
+func (p *CompressPool) Close() {
+	p.mu.Lock()
+	p.closed = true
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	p.workers.Wait()
+}
+
+// submit queues job for a worker. A closed pool runs it straight away.
+func (p *CompressPool) submit(job func()) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		job()
+		return
+	}
+	p.jobs = append(p.jobs, job)
+	p.cond.Signal()
+	p.mu.Unlock()
+}
+
+// work runs queued jobs until the pool is closed and drained.
+func (p *CompressPool) work() {
+	defer p.workers.Done()
+
+	for {
+		p.mu.Lock()
+		for len(p.jobs) == 0 && !p.closed {
+			p.cond.Wait()
+		}
+		if len(p.jobs) == 0 {
+			p.mu.Unlock()
+			return
+		}
+		job := p.jobs[0]
+		p.jobs = p.jobs[1:]
+		p.mu.Unlock()
+
+		job()
+	}
+}
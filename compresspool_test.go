@@ -0,0 +1,77 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCompressPoolCompressesInTheBackground(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	var (
+		clock = newFakeClock()
+		pool  = NewCompressPool(1)
+	)
+	defer pool.Close()
+
+	// Hold the only worker up, so the archives queue behind it.
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	pool.submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	r, err := New(dir, "test", WithClock(clock), WithCompression(Gzip), WithCompressPool(pool), WithMaxBackups(2))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	for i := 0; i < 3; i++ {
+		r.Write([]byte("hello\n"))
+		if err := r.Rotate(); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		clock.Advance(time.Hour)
+	}
+
+	if n := pool.Pending(); n != 3 {
+		t.Errorf("Wanted 3 archives waiting, got %d", n)
+	}
+	archives, err := r.Archives()
+	if err != nil || len(archives) != 3 || archives[0].Compressed {
+		t.Errorf("Wanted 3 uncompressed archives before the pool ran, got %+v (%v)", archives, err)
+	}
+
+	close(release)
+	if err := r.Close(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+	}
+
+	archives, err = ListArchives(dir, "test")
+	if err != nil || len(archives) != 2 {
+		t.Errorf("Wanted 2 archives kept, got %d (%v)", len(archives), err)
+		t.FailNow()
+	}
+	for _, a := range archives {
+		if !a.Compressed {
+			t.Errorf("Wanted %s compressed by the time Close returned", a.Path)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package rolog
+
+import (
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Config is a declarative description of a Rolog, for applications that
+// centralize their configuration. The zero value of each field means the same
+// as omitting the corresponding Option.
+type Config struct {
+	// Dir is the directory the logs are written to.
+	Dir string
+	// Name is the base name of the log files.
+	Name string
+	// Interval is how often the logs are rotated. Zero means DefaultInterval
+	// and a negative value disables scheduled rotation.
+	Interval time.Duration
+	// MaxSize is the size in bytes at which the current file is rotated early.
+	MaxSize int64
+	// MaxBackups is the number of archives to keep.
+	MaxBackups int
+	// MaxTotalSize is the byte budget for the current file and its archives.
+	MaxTotalSize int64
+	// MaxAge is how long archives are kept.
+	MaxAge time.Duration
+	// BundleAge is how old an archive must be before it is bundled.
+	BundleAge time.Duration
+	// LatestLink enables the symlink to the newest archive.
+	LatestLink bool
+	// FileMode is the mode of the current file.
+	FileMode os.FileMode
+	// ArchivePerm is the mode applied to archives.
+	ArchivePerm os.FileMode
+	// StdLog attaches the Rolog to the standard library's default logger.
+	StdLog bool
+	// Append continues an existing current file instead of archiving it.
+	Append bool
+	// ArchiveOnStartAge and ArchiveOnStartSize continue an existing current
+	// file unless it has reached either; see WithArchiveOnStart.
+	ArchiveOnStartAge  time.Duration
+	ArchiveOnStartSize int64
+	// Extension replaces DefaultExtension in every filename.
+	Extension string
+	// CurrentFilename replaces CurrentFilename as the current file's format.
+	CurrentFilename string
+	// ArchiveFilename replaces ArchiveFileFormat as the archives' format.
+	ArchiveFilename string
+	// InstanceID is the value of VarInstance in filename formats.
+	InstanceID string
+	// BufferSize is the size in bytes of the write buffer. Zero disables
+	// buffering.
+	BufferSize int
+	// FlushInterval is how often a running Rolog drains the write buffer.
+	FlushInterval time.Duration
+	// QueueSize is the depth of the async write queue. Zero writes
+	// synchronously.
+	QueueSize int
+	// Compression is the codec archives are compressed with.
+	Compression Compression
+	// CompressWorkers is how many goroutines compress each archive.
+	CompressWorkers int
+	// CompressionLevel is the level archives are compressed at. Zero means
+	// the codec's default.
+	CompressionLevel int
+	// CatchUp processes the archives of earlier runs; see WithCatchUp.
+	CatchUp bool
+	// Passthrough says when writes go to standard output instead of files.
+	Passthrough PassthroughMode
+	// Discard throws away every write; see WithDiscard.
+	Discard bool
+	// Circular truncates the current file instead of archiving it, keeping
+	// up to CircularKeep bytes of it; see WithCircular.
+	Circular     bool
+	CircularKeep int64
+}
+
+// Validate reports whether the Config describes a usable Rolog. It performs
+// the same checks as New, including that Dir is writable, and returns a
+// *ConfigError on failure.
+func (c Config) Validate() error {
+	return newRolog(c.Name, c.Options()).validate(c.Dir)
+}
+
+// Options converts the Config into the equivalent list of Options.
+func (c Config) Options() []Option {
+	var opts []Option
+	if c.Interval != 0 {
+		opts = append(opts, WithInterval(c.Interval))
+	}
+	if c.MaxSize != 0 {
+		opts = append(opts, WithMaxSize(c.MaxSize))
+	}
+	if c.MaxBackups != 0 {
+		opts = append(opts, WithMaxBackups(c.MaxBackups))
+	}
+	if c.MaxTotalSize != 0 {
+		opts = append(opts, WithMaxTotalSize(c.MaxTotalSize))
+	}
+	if c.MaxAge != 0 {
+		opts = append(opts, WithMaxAge(c.MaxAge))
+	}
+	if c.BundleAge != 0 {
+		opts = append(opts, WithBundleAge(c.BundleAge))
+	}
+	if c.LatestLink {
+		opts = append(opts, WithLatestLink())
+	}
+	if c.FileMode != 0 {
+		opts = append(opts, WithFileMode(c.FileMode))
+	}
+	if c.ArchivePerm != 0 {
+		opts = append(opts, WithArchivePerm(c.ArchivePerm))
+	}
+	if c.StdLog {
+		opts = append(opts, WithStdLog())
+	}
+	if c.Append {
+		opts = append(opts, WithAppend())
+	}
+	if c.ArchiveOnStartAge != 0 || c.ArchiveOnStartSize != 0 {
+		opts = append(opts, WithArchiveOnStart(c.ArchiveOnStartAge, c.ArchiveOnStartSize))
+	}
+	if c.Extension != "" {
+		opts = append(opts, WithExtension(c.Extension))
+	}
+	if c.CurrentFilename != "" {
+		opts = append(opts, WithCurrentFilename(c.CurrentFilename))
+	}
+	if c.ArchiveFilename != "" {
+		opts = append(opts, WithArchiveFilename(c.ArchiveFilename))
+	}
+	if c.InstanceID != "" {
+		opts = append(opts, WithInstanceID(c.InstanceID))
+	}
+	if c.BufferSize != 0 {
+		opts = append(opts, WithBuffer(c.BufferSize, c.FlushInterval))
+	}
+	if c.QueueSize != 0 {
+		opts = append(opts, WithAsync(c.QueueSize))
+	}
+	if c.Compression != NoCompression {
+		opts = append(opts, WithCompression(c.Compression))
+	}
+	if c.CompressWorkers != 0 {
+		opts = append(opts, WithCompressWorkers(c.CompressWorkers))
+	}
+	if c.CompressionLevel != 0 {
+		opts = append(opts, WithCompressionLevel(c.CompressionLevel))
+	}
+	if c.CatchUp {
+		opts = append(opts, WithCatchUp())
+	}
+	if c.Passthrough != PassthroughNever {
+		opts = append(opts, WithPassthrough(c.Passthrough, nil))
+	}
+	if c.Discard {
+		opts = append(opts, WithDiscard())
+	}
+	if c.Circular {
+		opts = append(opts, WithCircular(c.CircularKeep))
+	}
+	return opts
+}
+
+// Settings returns the runtime settings a Rolog created from the Config
+// starts with.
+func (c Config) Settings() Settings {
+	s := Settings{
+		Interval:         c.Interval,
+		MaxSize:          c.MaxSize,
+		MaxBackups:       c.MaxBackups,
+		MaxTotalSize:     c.MaxTotalSize,
+		MaxAge:           c.MaxAge,
+		BundleAge:        c.BundleAge,
+		Compression:      c.Compression,
+		CompressWorkers:  c.CompressWorkers,
+		CompressionLevel: c.CompressionLevel,
+		LatestLink:       c.LatestLink,
+		ArchivePerm:      c.ArchivePerm,
+	}
+	if s.Interval == 0 {
+		s.Interval = DefaultInterval
+	}
+	return s
+}
+
+// Inherit returns c with every unset field taken from parent, so that the
+// streams of a service can share one policy for rotation, retention and
+// compression and only spell out what differs. Name and StdLog are never
+// inherited, since no two streams can share them. A field is unset if it has
+// its zero value, so a boolean enabled in parent can't be switched off again
+// by c.
+func (c Config) Inherit(parent Config) Config {
+	if c.Dir == "" {
+		c.Dir = parent.Dir
+	}
+	if c.Interval == 0 {
+		c.Interval = parent.Interval
+	}
+	if c.MaxSize == 0 {
+		c.MaxSize = parent.MaxSize
+	}
+	if c.MaxBackups == 0 {
+		c.MaxBackups = parent.MaxBackups
+	}
+	if c.MaxTotalSize == 0 {
+		c.MaxTotalSize = parent.MaxTotalSize
+	}
+	if c.MaxAge == 0 {
+		c.MaxAge = parent.MaxAge
+	}
+	if c.BundleAge == 0 {
+		c.BundleAge = parent.BundleAge
+	}
+	c.LatestLink = c.LatestLink || parent.LatestLink
+	if c.FileMode == 0 {
+		c.FileMode = parent.FileMode
+	}
+	if c.ArchivePerm == 0 {
+		c.ArchivePerm = parent.ArchivePerm
+	}
+	c.Append = c.Append || parent.Append
+	if c.ArchiveOnStartAge == 0 {
+		c.ArchiveOnStartAge = parent.ArchiveOnStartAge
+	}
+	if c.ArchiveOnStartSize == 0 {
+		c.ArchiveOnStartSize = parent.ArchiveOnStartSize
+	}
+	if c.Extension == "" {
+		c.Extension = parent.Extension
+	}
+	if c.CurrentFilename == "" {
+		c.CurrentFilename = parent.CurrentFilename
+	}
+	if c.ArchiveFilename == "" {
+		c.ArchiveFilename = parent.ArchiveFilename
+	}
+	if c.InstanceID == "" {
+		c.InstanceID = parent.InstanceID
+	}
+	if c.BufferSize == 0 {
+		c.BufferSize = parent.BufferSize
+	}
+	if c.FlushInterval == 0 {
+		c.FlushInterval = parent.FlushInterval
+	}
+	if c.QueueSize == 0 {
+		c.QueueSize = parent.QueueSize
+	}
+	if c.Compression == NoCompression {
+		c.Compression = parent.Compression
+	}
+	if c.CompressWorkers == 0 {
+		c.CompressWorkers = parent.CompressWorkers
+	}
+	if c.CompressionLevel == 0 {
+		c.CompressionLevel = parent.CompressionLevel
+	}
+	c.CatchUp = c.CatchUp || parent.CatchUp
+	if c.Passthrough == PassthroughNever {
+		c.Passthrough = parent.Passthrough
+	}
+	c.Discard = c.Discard || parent.Discard
+	c.Circular = c.Circular || parent.Circular
+	if c.CircularKeep == 0 {
+		c.CircularKeep = parent.CircularKeep
+	}
+	return c
+}
+
+// NewFromConfig validates cfg and creates a Rolog from it. Any extra opts are
+// applied after those derived from cfg.
+func NewFromConfig(cfg Config, opts ...Option) (*Rolog, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid config")
+	}
+
+	return New(cfg.Dir, cfg.Name, append(cfg.Options(), opts...)...)
+}
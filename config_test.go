@@ -0,0 +1,53 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestConfigValidateRejectsMissingFields(t *testing.T) {
+	cases := []Config{
+		{Name: "test"},
+		{Dir: "."},
+		{Dir: ".", Name: "test", MaxBackups: -1},
+	}
+
+	for _, cfg := range cases {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Wanted an error for %+v", cfg)
+		}
+	}
+}
+
+func TestNewFromConfigAppliesSettings(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := NewFromConfig(Config{
+		Dir:        dir,
+		Name:       "test",
+		Interval:   time.Hour,
+		MaxSize:    1024,
+		MaxBackups: 3,
+	})
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	if r.interval != time.Hour || r.maxSize != 1024 || r.maxBackups != 3 {
+		t.Errorf("Config not applied: interval=%s maxSize=%d maxBackups=%d", r.interval, r.maxSize, r.maxBackups)
+	}
+}
@@ -0,0 +1,104 @@
+package rolog
+
+import (
+	"context"
+	"fmt"
+)
+
+// contextField is a value WriteContext takes from a context, and the name it
+// is written under.
+type contextField struct {
+	name  string
+	value func(ctx context.Context) string
+}
+
+// contextValues are the values attached to a context by ContextValue.
+type contextValues struct {
+	parent     *contextValues
+	name, text string
+}
+
+// contextValuesKey is the context key of the contextValues.
+type contextValuesKey struct{}
+
+// ContextValue returns a copy of ctx carrying value under name, such as a
+// request ID set by middleware, for WriteContext to prefix onto every line
+// written with it. Values are written in the order they were attached, and a
+// name attached again is written again.
+func ContextValue(ctx context.Context, name, value string) context.Context {
+	parent, _ := ctx.Value(contextValuesKey{}).(*contextValues)
+	return context.WithValue(ctx, contextValuesKey{}, &contextValues{parent: parent, name: name, text: value})
+}
+
+// WithContextKey makes WriteContext prefix the value stored in the context
+// under key, formatted with fmt.Sprint, as name, for values put there by other
+// packages. Contexts without the key get no prefix for it.
+func WithContextKey(name string, key interface{}) Option {
+	return WithContextFunc(name, func(ctx context.Context) string {
+		v := ctx.Value(key)
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprint(v)
+	})
+}
+
+// WithContextFunc makes WriteContext prefix the result of f as name, for
+// values that take more than a lookup to get at, such as the ID of the trace
+// in a context. An empty result gets no prefix.
+func WithContextFunc(name string, f func(ctx context.Context) string) Option {
+	return func(r *Rolog) {
+		r.contextFields = append(r.contextFields, contextField{name: name, value: f})
+	}
+}
+
+// WriteContext writes p with the values from ctx prefixed onto each of its
+// lines as logfmt pairs, such as "request_id=42 trace_id=abc ", so that the
+// lines written while handling a request can be picked out of the file. The
+// values given to WithContextKey and WithContextFunc come first, in the order
+// they were given, followed by those from ContextValue. With no values p is
+// written as it is.
+func (r *Rolog) WriteContext(ctx context.Context, p []byte) (int, error) {
+	prefix := r.contextPrefix(ctx)
+	if len(prefix) == 0 {
+		return r.Write(p)
+	}
+
+	var midLine bool
+	if _, err := r.Write(prefixLines(nil, p, prefix, &midLine)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// contextPrefix returns the prefix WriteContext puts on lines written with
+// ctx.
+func (r *Rolog) contextPrefix(ctx context.Context) []byte {
+	var prefix []byte
+	add := func(name, value string) {
+		if value == "" {
+			return
+		}
+		prefix = append(prefix, name...)
+		prefix = append(prefix, '=')
+		if v := stringBytes(value); needsLogfmtQuote(v) {
+			prefix = appendJSONString(prefix, v)
+		} else {
+			prefix = append(prefix, v...)
+		}
+		prefix = append(prefix, ' ')
+	}
+
+	for _, f := range r.contextFields {
+		add(f.name, f.value(ctx))
+	}
+
+	var attached []*contextValues
+	for v, _ := ctx.Value(contextValuesKey{}).(*contextValues); v != nil; v = v.parent {
+		attached = append(attached, v)
+	}
+	for i := len(attached) - 1; i >= 0; i-- {
+		add(attached[i].name, attached[i].text)
+	}
+	return prefix
+}
@@ -0,0 +1,49 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+type traceKey struct{}
+
+func TestWriteContextPrefixesValues(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r, err := New(dir, "test", WithContextKey("trace_id", traceKey{}))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	ctx := context.WithValue(context.Background(), traceKey{}, "abc")
+	ctx = ContextValue(ctx, "request_id", "42")
+	ctx = ContextValue(ctx, "user", "jo bloggs")
+
+	if n, err := r.WriteContext(ctx, []byte("one\ntwo\n")); n != 8 || err != nil {
+		t.Errorf("Wanted 8 bytes written, got %d (%v)", n, err)
+	}
+	r.WriteContext(context.Background(), []byte("plain\n"))
+
+	got, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	prefix := `trace_id=abc request_id=42 user="jo bloggs" `
+	if want := prefix + "one\n" + prefix + "two\n" + "plain\n"; string(got) != want {
+		t.Errorf("Wanted %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,80 @@
+package rolog
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// copyDir is a directory given to WithCopyDir, and the options of the copy
+// kept there.
+type copyDir struct {
+	dir  string
+	opts []Option
+}
+
+// WithCopyDir keeps a second copy of the log in dir, such as an on-box copy
+// beside one on a centrally collected share. Every entry is written to the
+// copy as it went to the current file, and the copy rotates whenever the log
+// does, so both directories hold the same files. The copy is a log of its own
+// with the same name, configured by opts rather than the options of the log,
+// so it can have its own compression and retention.
+//
+// The copy is written from a goroutine of its own, like the network mirrors,
+// so a slow or failing copy never blocks or fails writes to the log: its
+// failures are reported on Err, and entries that arrive while it can't keep
+// up are discarded from the copy.
+func WithCopyDir(dir string, opts ...Option) Option {
+	return func(r *Rolog) {
+		r.copyDirs = append(r.copyDirs, copyDir{dir: dir, opts: opts})
+	}
+}
+
+// copySink writes a mirror's entries to the copy of a log.
+type copySink struct {
+	r *Rolog
+}
+
+func (s *copySink) send(line []byte, at time.Time) error {
+	return s.sendEntry(append(line[:len(line):len(line)], '\n'), at)
+}
+
+// sendEntry writes the entry whole, so the copy matches the file byte for
+// byte.
+func (s *copySink) sendEntry(p []byte, at time.Time) error {
+	_, err := s.r.Write(p)
+	return err
+}
+
+// rotate rotates the copy in step with the log.
+func (s *copySink) rotate() error {
+	return s.r.Rotate()
+}
+
+func (s *copySink) reset() {}
+
+// Close closes the copy.
+func (s *copySink) Close() error {
+	return s.r.Close()
+}
+
+// openCopies opens the copy in each WithCopyDir directory and mirrors the log
+// to it.
+func (r *Rolog) openCopies() error {
+	var sinks []*copySink
+	for _, c := range r.copyDirs {
+		opts := append([]Option{WithClock(r.clock), WithFS(baseFS(r.fs))}, c.opts...)
+		cp, err := New(c.dir, r.name, opts...)
+		if err != nil {
+			for _, s := range sinks {
+				s.Close()
+			}
+			return errors.Wrapf(err, "could not open copy in %s", c.dir)
+		}
+		sinks = append(sinks, &copySink{r: cp})
+	}
+	for i, s := range sinks {
+		r.addMirror("copy in "+r.copyDirs[i].dir, s)
+	}
+	return nil
+}
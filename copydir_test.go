@@ -0,0 +1,69 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithCopyDirKeepsAnIdenticalCopy(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	var (
+		primary = filepath.Join(dir, "primary")
+		copied  = filepath.Join(dir, "copy")
+		clock   = newFakeClock()
+	)
+	r, err := New(primary, "test", WithClock(clock), WithPrefix("> "), WithCopyDir(copied))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r.Write([]byte("one\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	r.Write([]byte("two\n"))
+	r.Write([]byte("partial"))
+	if err := r.Close(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	for _, d := range []string{primary, copied} {
+		archives, err := ListArchives(d, "test")
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		if len(archives) != 1 {
+			t.Errorf("Wanted 1 archive in %s, got %d", d, len(archives))
+			continue
+		}
+		for path, want := range map[string]string{
+			archives[0].Path:             "> one\n",
+			filepath.Join(d, "test.log"): "> two\n> partial",
+		} {
+			got, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Errorf("unexpected error: %q", err)
+				continue
+			}
+			if string(got) != want {
+				t.Errorf("Wanted %q in %s, got %q", want, path, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package rolog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// LinkTargetFormat is the naming format of the files written to when the
+// current path is a symlink; see WithCurrentLink. The timestamp is when the
+// file was created.
+const LinkTargetFormat = "%s.current-2006-01-02-150405.log"
+
+// ErrNoSymlinks is the cause of the ConfigError returned when WithCurrentLink
+// is used on a filesystem that can't create or read symlinks.
+var ErrNoSymlinks = errors.New("filesystem does not support symlinks")
+
+// ReadlinkFS is implemented by filesystems that can read symbolic links.
+type ReadlinkFS interface {
+	Readlink(name string) (string, error)
+}
+
+// WithCurrentLink makes the current path a symlink to the file actually being
+// written, which is named according to LinkTargetFormat. A rotation creates the
+// next file and swings the link to it before the old file is renamed to its
+// archive name, so readers following the current path, such as tail -F, never
+// find it missing or half rotated. The rotation strategy is ignored. The FS
+// must implement SymlinkFS and ReadlinkFS.
+func WithCurrentLink() Option {
+	return func(r *Rolog) {
+		r.currentLink = true
+	}
+}
+
+// supportsLinks reports whether fs can maintain the current link.
+func supportsLinks(fs FS) bool {
+	_, canLink := baseFS(fs).(SymlinkFS)
+	_, canRead := baseFS(fs).(ReadlinkFS)
+	return canLink && canRead
+}
+
+// linkTarget returns a fresh path in dir for the file behind the current link.
+func (r *Rolog) linkTarget(dir string) string {
+	format := r.withExtension(LinkTargetFormat)
+	return r.uniquePath(filepath.Join(dir, fmt.Sprintf(r.now().Format(format), r.name)))
+}
+
+// openLinked creates the first file behind the current link, archiving the
+// file it pointed at, or the current file itself if it isn't a link, unless
+// continuing it on start.
+func (r *Rolog) openLinked(dir, link string) (File, error) {
+	target, err := readlink(r.fs, link)
+	if err == nil && r.continueOnStart(dir, target) {
+		return openFile(r.fs, target, currentFlag, r.perm)
+	}
+	if err != nil {
+		target = link
+	}
+
+	if _, err := r.fs.Stat(target); err == nil {
+		if err := renameFile(r.fs, target, r.uniquePath(filepath.Join(dir, r.fname()))); err != nil {
+			return nil, errors.Wrap(err, "could not archive existing log")
+		}
+	}
+
+	next := r.linkTarget(dir)
+	f, err := openFile(r.fs, next, currentFlag|os.O_EXCL, r.perm)
+	if err != nil {
+		return nil, err
+	}
+	if err := replaceLink(r.fs, link, next); err != nil {
+		f.Close()
+		r.fs.Remove(next)
+		return nil, err
+	}
+	return f, nil
+}
+
+// rotationStrategy returns the strategy to use for the next rotation. It must
+// be called with mu held.
+func (r *Rolog) rotationStrategy() RotationStrategy {
+	if r.currentLink {
+		return linkStrategy{next: r.linkTarget(filepath.Dir(r.currentPath()))}
+	}
+	return r.strategy
+}
+
+// linkStrategy is the RotationStrategy behind WithCurrentLink. Rotation.Current
+// is the link, and next is the path of the file to switch to.
+type linkStrategy struct {
+	next string
+}
+
+// Rotate satisfies RotationStrategy.
+func (s linkStrategy) Rotate(rot Rotation) (File, string, error) {
+	old, next, err := s.swing(rot)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rot.File.Sync()
+	rot.File.Close()
+	if err := renameFile(rot.FS, old, rot.Archive); err != nil {
+		// The link already points at next, so carry on writing there and
+		// leave the old file where it is.
+		return next, "", errors.Wrap(err, "could not archive old log file")
+	}
+
+	return next, rot.Archive, nil
+}
+
+// swing creates the next file and points the link at it, returning the path of
+// the file the link used to point at.
+func (s linkStrategy) swing(rot Rotation) (old string, next File, err error) {
+	if old, err = readlink(rot.FS, rot.Current); err != nil {
+		return "", nil, errors.Wrap(err, "could not resolve current link")
+	}
+
+	if next, err = openFile(rot.FS, s.next, currentFlag|os.O_EXCL, rot.Perm); err != nil {
+		return "", nil, errors.Wrap(err, "could not open new log file")
+	}
+	if err = replaceLink(rot.FS, rot.Current, s.next); err != nil {
+		next.Close()
+		rot.FS.Remove(s.next)
+		return "", nil, err
+	}
+
+	return old, next, nil
+}
+
+// readlink returns the path the symlink at link points to, resolved against the
+// directory containing the link.
+func readlink(fs FS, link string) (string, error) {
+	rfs, ok := baseFS(fs).(ReadlinkFS)
+	if !ok {
+		return "", ErrNoSymlinks
+	}
+
+	target, err := rfs.Readlink(link)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(link), target)
+	}
+	return target, nil
+}
+
+// maxLinks bounds how many symlinks resolveLinks follows, as the kernel does,
+// so a loop can't hang New.
+const maxLinks = 40
+
+// resolveLinks follows path through any chain of symlinks to the file at the
+// end of it, which need not exist yet. Paths that aren't links, and every path
+// on a filesystem without ReadlinkFS, are returned unchanged.
+func resolveLinks(fs FS, path string) string {
+	for i := 0; i < maxLinks; i++ {
+		target, err := readlink(fs, path)
+		if err != nil {
+			return path
+		}
+		path = target
+	}
+	return path
+}
+
+// replaceLink atomically points the symlink at link to target, which must be in
+// the same directory, so readers never observe the link missing.
+func replaceLink(fs FS, link, target string) error {
+	sfs, ok := baseFS(fs).(SymlinkFS)
+	if !ok {
+		return ErrNoSymlinks
+	}
+
+	tmp := link + ".tmp"
+	fs.Remove(tmp)
+	if err := sfs.Symlink(filepath.Base(target), tmp); err != nil {
+		return errors.Wrap(err, "could not create link")
+	}
+
+	if err := fs.Rename(tmp, link); err != nil {
+		fs.Remove(tmp)
+		return errors.Wrap(err, "could not replace link")
+	}
+
+	return nil
+}
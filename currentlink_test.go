@@ -0,0 +1,154 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithCurrentLinkSwingsTheLinkOnRotate(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithCurrentLink())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	link := filepath.Join(dir, "test.log")
+	first, err := os.Readlink(link)
+	if err != nil {
+		t.Errorf("Wanted the current path to be a link, got %q", err)
+		t.FailNow()
+	}
+	if want := "test.current-2020-01-02-030405.log"; first != want {
+		t.Errorf("Wanted the link to point at %s, got %s", want, first)
+	}
+
+	r.Write([]byte("one\n"))
+	clock.Advance(time.Hour)
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	r.Write([]byte("two\n"))
+
+	second, err := os.Readlink(link)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if second == first {
+		t.Errorf("Wanted the link to move on from %s", first)
+	}
+
+	got, err := ioutil.ReadFile(link)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if string(got) != "two\n" {
+		t.Errorf("Wanted %q through the link, got %q", "two\n", got)
+	}
+
+	archives, err := r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(archives) != 1 {
+		t.Errorf("Wanted 1 archive, got %d", len(archives))
+		t.FailNow()
+	}
+	got, err = ioutil.ReadFile(archives[0].Path)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if string(got) != "one\n" {
+		t.Errorf("Wanted %q in the archive, got %q", "one\n", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, first)); !os.IsNotExist(err) {
+		t.Errorf("Wanted the old target to be archived, got %v", err)
+	}
+}
+
+func TestSymlinkedCurrentFileIsFollowed(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		logDir  = filepath.Join(dir, "log")
+		dataDir = filepath.Join(dir, "data")
+		target  = filepath.Join(dataDir, "test.log")
+		link    = filepath.Join(logDir, "test.log")
+	)
+	for _, d := range []string{logDir, dataDir} {
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+	}
+	if err := os.Symlink(filepath.Join("..", "data", "test.log"), link); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(logDir, "test", WithClock(clock))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	r.Write([]byte("one\n"))
+	clock.Advance(time.Hour)
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	r.Write([]byte("two\n"))
+
+	if fi, err := os.Lstat(link); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Wanted the link to be left in place, got %v (%v)", fi, err)
+	}
+	got, err := ioutil.ReadFile(link)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if string(got) != "two\n" {
+		t.Errorf("Wanted %q through the link, got %q", "two\n", got)
+	}
+
+	got, err = ioutil.ReadFile(filepath.Join(dataDir, "test-2020-01-02-040405.log"))
+	if err != nil {
+		t.Errorf("Wanted the real file archived beside it, got %q", err)
+		t.FailNow()
+	}
+	if string(got) != "one\n" {
+		t.Errorf("Wanted %q in the archive, got %q", "one\n", got)
+	}
+	if r.CurrentPath() != target {
+		t.Errorf("Wanted the current path %s, got %s", target, r.CurrentPath())
+	}
+}
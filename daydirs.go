@@ -0,0 +1,162 @@
+package rolog
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultDayLayout names the dated directories of WithDayDirs when no layout is
+// given, as in logs/2024-06-01/app.log.
+const DefaultDayLayout = "2006-01-02"
+
+// WithDayDirs keeps the current file in a directory named after the day it was
+// opened, formatted with layout, beneath the directory given to New. The first
+// write or rotation after local midnight moves on to a new directory for the
+// new day, and the previous day's file is left where it is under its usual
+// name rather than archived, which is where consumers expecting this layout
+// look for it. Rotations within a day archive into that day's directory as
+// usual, and Archives and retention see the archives of every day's directory,
+// so that those of earlier days expire as any others would.
+//
+// The layout should produce a different name for every day, and an empty one
+// means DefaultDayLayout. With WithoutMkdir, the directories for the coming
+// days must already exist.
+func WithDayDirs(layout string) Option {
+	return func(r *Rolog) {
+		if layout == "" {
+			layout = DefaultDayLayout
+		}
+		r.dayLayout = layout
+	}
+}
+
+// openDay creates the directory beneath root for the day of t, and returns it
+// along with when the day ends.
+func (r *Rolog) openDay(root string, t time.Time) (string, time.Time, error) {
+	dir := filepath.Join(root, t.Format(r.dayLayout))
+	if err := r.mkdir(dir); err != nil {
+		return "", time.Time{}, errors.Wrap(err, "could not create day directory")
+	}
+
+	y, m, d := t.Date()
+	return dir, time.Date(y, m, d+1, 0, 0, 0, 0, t.Location()), nil
+}
+
+// dayOver reports whether the current file belongs to a day that has ended by
+// t. It must be called with mu held.
+func (r *Rolog) dayOver(t time.Time) bool {
+	return r.dayLayout != "" && !t.Before(r.dayEnd)
+}
+
+// rollDay moves the current file on to the directory of the day of start,
+// leaving the old day's file in place. It stands in for rotate once the day is
+// over, and must be called with mu held.
+func (r *Rolog) rollDay(reason RotationReason, start, paused time.Time) (*rotation, error) {
+	dir, end, err := r.openDay(r.dayRoot, start)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, filepath.Base(r.currentPath()))
+	f, err := openFile(r.fs, path, currentFlag, r.perm)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open log file for the new day")
+	}
+
+	r.writeFooter(start)
+	r.writeEndRecord(reason, start, "")
+	written := r.size
+	if err := r.drain(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := r.f.Sync(); err != nil {
+		f.Close()
+		return nil, errors.Wrap(err, "could not sync log file")
+	}
+	if err := r.f.Close(); err != nil {
+		r.report(errors.Wrap(err, "could not close log file"))
+	}
+
+	r.dayEnd = end
+	r.setPath(path)
+	pending := r.swap(f, "", start, written, reason)
+	r.recordPause(pending, paused)
+	return pending, nil
+}
+
+// acrossDays returns what list finds in each day directory, oldest day first,
+// or just in the directory of the current file if the log doesn't keep
+// per-day directories or has failed over out of them.
+func (r *Rolog) acrossDays(list func(dir string) ([]ArchiveInfo, error)) ([]ArchiveInfo, error) {
+	current := filepath.Dir(r.currentPath())
+	if r.dayLayout == "" {
+		return list(current)
+	}
+	if rel, err := filepath.Rel(r.dayRoot, current); err != nil || strings.HasPrefix(rel, "..") {
+		return list(current)
+	}
+
+	dirs, err := r.dayDirs()
+	if err != nil {
+		return nil, err
+	}
+	var all []ArchiveInfo
+	for _, dir := range dirs {
+		found, err := list(dir)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, found...)
+	}
+	return all, nil
+}
+
+// dayDirs returns the day directories beneath dayRoot, oldest first. A layout
+// with separators in it names nested directories, which are descended into.
+func (r *Rolog) dayDirs() ([]string, error) {
+	layout := filepath.ToSlash(r.dayLayout)
+
+	dirs := []string{r.dayRoot}
+	for i := strings.Count(layout, "/"); i >= 0; i-- {
+		var next []string
+		for _, dir := range dirs {
+			fis, err := r.fs.ReadDir(dir)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not list day directories")
+			}
+			for _, fi := range fis {
+				if fi.IsDir() {
+					next = append(next, filepath.Join(dir, fi.Name()))
+				}
+			}
+		}
+		dirs = next
+	}
+
+	days := make(map[string]time.Time, len(dirs))
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(r.dayRoot, dir)
+		if err != nil {
+			continue
+		}
+		t, err := time.ParseInLocation(layout, filepath.ToSlash(rel), time.Local)
+		if err != nil {
+			// Not a day directory.
+			continue
+		}
+		days[dir] = t
+	}
+
+	dirs = dirs[:0]
+	for dir := range days {
+		dirs = append(dirs, dir)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		return days[dirs[i]].Before(days[dirs[j]])
+	})
+	return dirs, nil
+}
@@ -0,0 +1,165 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDayDirsMoveOnAtMidnight(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	clk := newFakeClock()
+	r, err := New(dir, "test", WithClock(clk), WithDayDirs(""))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	// Paths are read without the lock while the day rolls over.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				r.CurrentPath()
+				r.Archives()
+			}
+		}
+	}()
+
+	c, err := r.Child("child")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer c.Close()
+	if want := filepath.Join(dir, "2020-01-02", "child.log"); c.CurrentPath() != want {
+		t.Errorf("Wanted the child's current file at %s, got %s", want, c.CurrentPath())
+	}
+
+	first := r.CurrentPath()
+	if want := filepath.Join(dir, "2020-01-02", "test.log"); first != want {
+		t.Errorf("Wanted the current file at %s, got %s", want, first)
+	}
+
+	r.Write([]byte("a\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	r.Write([]byte("b\n"))
+
+	archives, err := r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(archives) != 1 || filepath.Dir(archives[0].Path) != filepath.Dir(first) {
+		t.Errorf("Wanted one archive beside the current file, got %+v", archives)
+	}
+
+	// The first write of the next day moves on to its directory.
+	clk.Advance(21 * time.Hour)
+	r.Write([]byte("c\n"))
+
+	second := r.CurrentPath()
+	if want := filepath.Join(dir, "2020-01-03", "test.log"); second != want {
+		t.Errorf("Wanted the current file at %s, got %s", want, second)
+	}
+	for path, want := range map[string]string{first: "b\n", second: "c\n"} {
+		got, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		if string(got) != want {
+			t.Errorf("Wanted %s to contain %q, got %q", path, want, got)
+		}
+	}
+
+	// Later rotations archive into the new day's directory.
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	archives, err = r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(archives) != 2 || filepath.Dir(archives[0].Path) != filepath.Dir(first) || filepath.Dir(archives[1].Path) != filepath.Dir(second) {
+		t.Errorf("Wanted an archive in each day's directory, got %+v", archives)
+	}
+}
+
+func TestDayDirsRetentionCoversEarlierDays(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	clk := newFakeClock()
+	r, err := New(dir, "test", WithClock(clk), WithDayDirs("2006/01/02"), WithMaxBackups(1))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	r.Write([]byte("a\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	archives, err := r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(archives) != 1 {
+		t.Errorf("Wanted one archive, got %+v", archives)
+		t.FailNow()
+	}
+	old := archives[0].Path
+
+	clk.Advance(24 * time.Hour)
+	r.Write([]byte("b\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("Wanted the previous day's archive %s pruned, got %v", old, err)
+	}
+	archives, err = ListArchives(dir, "test", WithDayDirs("2006/01/02"))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if want := filepath.Join(dir, "2020", "01", "03"); len(archives) != 1 || filepath.Dir(archives[0].Path) != want {
+		t.Errorf("Wanted only the archive in %s, got %+v", want, archives)
+	}
+}
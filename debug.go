@@ -0,0 +1,103 @@
+package rolog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// WithDebug makes the Rolog write a timestamped line to w for every state
+// transition, every rotation trigger that fires or is skipped, and every file
+// open, rename, removal, stat and directory listing it performs, for working
+// out after the fact why a log did or didn't rotate. It is verbose, so it is
+// meant for diagnosing incidents rather than for production use. As with
+// WithDiagnostics, w should not be the log itself; writes to it are
+// serialized.
+func WithDebug(w io.Writer) Option {
+	return func(r *Rolog) {
+		r.debug = &debugLog{w: w, name: r.name, now: r.now}
+	}
+}
+
+// debugLog writes the lines enabled by WithDebug.
+type debugLog struct {
+	mu   sync.Mutex
+	w    io.Writer
+	name string
+	now  func() time.Time
+}
+
+// debugf writes a debug line if debugging is enabled.
+func (r *Rolog) debugf(format string, args ...interface{}) {
+	d := r.debug
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	fmt.Fprintf(d.w, "%s rolog %s: %s\n", d.now().Format(time.RFC3339Nano), d.name, fmt.Sprintf(format, args...))
+}
+
+// outcome describes the result of an operation for a debug line.
+func outcome(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return "ok"
+}
+
+// debugFS is an FS that writes a debug line for each operation. Only the
+// methods of FS are traced; the optional interfaces are reached through
+// baseFS.
+type debugFS struct {
+	FS
+	r *Rolog
+}
+
+// baseFS returns the FS that fs wraps, if it is a debugFS, so that optional
+// interfaces can be detected.
+func baseFS(fs FS) FS {
+	if d, ok := fs.(debugFS); ok {
+		return d.FS
+	}
+	return fs
+}
+
+// OpenFile satisfies FS.
+func (d debugFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	f, err := d.FS.OpenFile(name, flag, perm)
+	d.r.debugf("open %s (flags %#x, mode %v): %s", name, flag, perm, outcome(err))
+	return f, err
+}
+
+// Rename satisfies FS.
+func (d debugFS) Rename(oldpath, newpath string) error {
+	err := d.FS.Rename(oldpath, newpath)
+	d.r.debugf("rename %s to %s: %s", oldpath, newpath, outcome(err))
+	return err
+}
+
+// Stat satisfies FS.
+func (d debugFS) Stat(name string) (os.FileInfo, error) {
+	fi, err := d.FS.Stat(name)
+	d.r.debugf("stat %s: %s", name, outcome(err))
+	return fi, err
+}
+
+// Remove satisfies FS.
+func (d debugFS) Remove(name string) error {
+	err := d.FS.Remove(name)
+	d.r.debugf("remove %s: %s", name, outcome(err))
+	return err
+}
+
+// ReadDir satisfies FS.
+func (d debugFS) ReadDir(dirname string) ([]os.FileInfo, error) {
+	fis, err := d.FS.ReadDir(dirname)
+	d.r.debugf("list %s: %d entries, %s", dirname, len(fis), outcome(err))
+	return fis, err
+}
@@ -0,0 +1,52 @@
+package rolog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDebugTracesInternals(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	var buf bytes.Buffer
+	r, err := New(dir, "test", WithDebug(&buf), WithMaxSize(10))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r.Write([]byte("first entry\n"))
+	r.Write([]byte("second entry\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		"rolog test: size trigger: 12 bytes plus a 13 byte write exceeds the max size of 10\n",
+		"rolog test: rotating (manual)\n",
+		"rolog test: rename " + r.CurrentPath() + " to ",
+		"rolog test: open " + r.CurrentPath() + " ",
+		"rolog test: state new -> closing\n",
+		"rolog test: state closing -> closed\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Wanted the trace to contain %q, got:\n%s", want, got)
+		}
+	}
+}
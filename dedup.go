@@ -0,0 +1,81 @@
+package rolog
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// RepeatedFormat is the line written in place of a run of identical lines.
+const RepeatedFormat = "last message repeated %d times\n"
+
+// WithDedup collapses runs of identical lines into a single copy followed by a
+// line formatted according to RepeatedFormat, to keep archives small when an
+// application spams the same error. A run ends when a different line arrives,
+// when a repeat arrives more than window after the run started, or when the
+// file is rotated or closed. Only whole lines are compared.
+func WithDedup(window time.Duration) Option {
+	return func(r *Rolog) {
+		r.dedupWindow = window
+	}
+}
+
+// dedup removes lines from p that repeat the previous one, inserting a summary
+// when each run ends, and appends the result to out. It must be called with mu
+// held.
+func (r *Rolog) dedup(out, p []byte) []byte {
+	if r.dedupWindow <= 0 || len(p) == 0 {
+		return p
+	}
+
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			out = append(out, r.endRun()...)
+			out = append(out, p...)
+			r.lastLine = r.lastLine[:0]
+			break
+		}
+
+		line := p[:i+1]
+		p = p[i+1:]
+
+		now := r.now()
+		if len(r.lastLine) > 0 && bytes.Equal(line, r.lastLine) && now.Sub(r.runStart) < r.dedupWindow {
+			r.repeats++
+			continue
+		}
+
+		out = append(out, r.endRun()...)
+		out = append(out, line...)
+		r.lastLine = append(r.lastLine[:0], line...)
+		r.runStart = now
+	}
+
+	return out
+}
+
+// endRun returns the summary for the current run of repeats, if there is one,
+// and resets the count. It must be called with mu held.
+func (r *Rolog) endRun() []byte {
+	if r.repeats == 0 {
+		return nil
+	}
+	n := r.repeats
+	r.repeats = 0
+	return []byte(fmt.Sprintf(RepeatedFormat, n))
+}
+
+// flushRepeats writes out the summary of any pending run and forgets the last
+// line, so the next file starts with a line of its own. It must be called with
+// mu held.
+func (r *Rolog) flushRepeats() {
+	r.lastLine = r.lastLine[:0]
+	if s := r.endRun(); s != nil {
+		// The summary may be written in the middle of a write, so it gets
+		// scratch space of its own.
+		out := r.encode(&[2][]byte{}, s)
+		n, _ := r.output().Write(out)
+		r.size += int64(n)
+	}
+}
@@ -0,0 +1,47 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDedupCollapsesRepeats(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithDedup(time.Minute))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r.Write([]byte("boom\nboom\nboom\n"))
+	r.Write([]byte("boom\n"))
+	r.Write([]byte("ok\n"))
+	r.Write([]byte("boom\n"))
+	clock.Advance(2 * time.Minute)
+	r.Write([]byte("boom\nboom\n"))
+	r.Close()
+
+	got, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	want := "boom\nlast message repeated 3 times\nok\nboom\nboom\nlast message repeated 1 times\n"
+	if string(got) != want {
+		t.Errorf("Wanted %q, got %q", want, got)
+	}
+}
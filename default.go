@@ -0,0 +1,52 @@
+package rolog
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNoDefault is returned by the package-level helpers when no default Rolog
+// has been set.
+var ErrNoDefault = errors.New("no default rolog set")
+
+var (
+	defaultMu sync.RWMutex
+	std       *Rolog
+)
+
+// SetDefault makes r the Rolog used by the package-level Write and Rotate
+// helpers, so small programs don't need to pass it around. Passing nil clears
+// the default.
+func SetDefault(r *Rolog) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	std = r
+}
+
+// Default returns the Rolog set by SetDefault, or nil if there isn't one.
+func Default() *Rolog {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+
+	return std
+}
+
+// Write writes p to the default Rolog.
+func Write(p []byte) (int, error) {
+	r := Default()
+	if r == nil {
+		return 0, ErrNoDefault
+	}
+	return r.Write(p)
+}
+
+// Rotate rotates the default Rolog.
+func Rotate() error {
+	r := Default()
+	if r == nil {
+		return ErrNoDefault
+	}
+	return r.Rotate()
+}
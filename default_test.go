@@ -0,0 +1,60 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDefault(t *testing.T) {
+	if _, err := Write([]byte("lost\n")); err != ErrNoDefault {
+		t.Errorf("Wanted %q without a default, got %v", ErrNoDefault, err)
+	}
+
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	SetDefault(r)
+	defer func() {
+		SetDefault(nil)
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	if Default() != r {
+		t.Errorf("Wanted Default to return the Rolog passed to SetDefault")
+	}
+
+	if _, err := Write([]byte("hello\n")); err != nil {
+		t.Errorf("could not write: %q", err)
+		t.FailNow()
+	}
+
+	clock.Advance(time.Hour)
+	if err := Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+
+	archives, err := r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(archives) != 1 || archives[0].Size != 6 {
+		t.Errorf("Wanted 1 archive of 6 bytes, got %+v", archives)
+	}
+}
@@ -0,0 +1,61 @@
+package rolog
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// diagnostics receives the Rolog's reports about its own operation.
+type diagnostics interface {
+	// failed is called with every error delivered on Err.
+	failed(err error)
+	// retrying is called when attempt of an operation failed with err and is
+	// about to be tried again.
+	retrying(err error, attempt int)
+}
+
+// WithDiagnostics writes a line to w for every operational problem the Rolog
+// runs into, such as a failed prune or a rotation that is being retried, in
+// addition to delivering errors on Err. Since the log files themselves may be
+// what is failing, w should be somewhere else, such as os.Stderr; writes to it
+// are serialized. See also WithDiagnosticsLogger.
+func WithDiagnostics(w io.Writer) Option {
+	return func(r *Rolog) {
+		r.diagnostics = &writerDiagnostics{w: w, name: r.name, now: r.now}
+	}
+}
+
+// writerDiagnostics writes diagnostics to an io.Writer as lines of text.
+type writerDiagnostics struct {
+	mu   sync.Mutex
+	w    io.Writer
+	name string
+	now  func() time.Time
+}
+
+// failed satisfies diagnostics.
+func (d *writerDiagnostics) failed(err error) {
+	d.printf("%v", err)
+}
+
+// retrying satisfies diagnostics.
+func (d *writerDiagnostics) retrying(err error, attempt int) {
+	d.printf("attempt %d failed, retrying: %v", attempt, err)
+}
+
+// printf writes a single diagnostic line.
+func (d *writerDiagnostics) printf(format string, args ...interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	fmt.Fprintf(d.w, "%s rolog %s: %s\n", d.now().Format(time.RFC3339), d.name, fmt.Sprintf(format, args...))
+}
+
+// diagnoseRetry passes a failed attempt to the diagnostics, if any.
+func (r *Rolog) diagnoseRetry(err error, attempt int) {
+	if r.diagnostics != nil {
+		r.diagnostics.retrying(err, attempt)
+	}
+}
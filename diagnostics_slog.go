@@ -0,0 +1,30 @@
+//go:build go1.21
+// +build go1.21
+
+package rolog
+
+import "log/slog"
+
+// WithDiagnosticsLogger is like WithDiagnostics, but reports to l: errors at
+// slog.LevelError and retries at slog.LevelWarn, with the Rolog's name as the
+// "log" attribute.
+func WithDiagnosticsLogger(l *slog.Logger) Option {
+	return func(r *Rolog) {
+		r.diagnostics = slogDiagnostics{l.With("log", r.name)}
+	}
+}
+
+// slogDiagnostics reports diagnostics to a *slog.Logger.
+type slogDiagnostics struct {
+	l *slog.Logger
+}
+
+// failed satisfies diagnostics.
+func (d slogDiagnostics) failed(err error) {
+	d.l.Error("rolog failure", "err", err)
+}
+
+// retrying satisfies diagnostics.
+func (d slogDiagnostics) retrying(err error, attempt int) {
+	d.l.Warn("rolog retry", "attempt", attempt, "err", err)
+}
@@ -0,0 +1,55 @@
+package rolog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithDiagnosticsReportsRetriesAndFailures(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	var (
+		diag bytes.Buffer
+		s    = &flakyStrategy{failures: 2}
+	)
+	r, err := New(dir, "test",
+		WithMaxSize(8),
+		WithRotationStrategy(s),
+		WithRotateAttempts(2, time.Millisecond),
+		WithDiagnostics(&diag),
+	)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("fill up\n"))
+	r.Write([]byte("rotate\n"))
+
+	lines := strings.Split(strings.TrimSpace(diag.String()), "\n")
+	if len(lines) != 2 {
+		t.Errorf("Wanted a retry and a failure, got %q", diag.String())
+		t.FailNow()
+	}
+	if !strings.Contains(lines[0], "rolog test: attempt 1 failed, retrying: transient failure") {
+		t.Errorf("Wanted the retry first, got %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "rolog test: transient failure") {
+		t.Errorf("Wanted the failure last, got %q", lines[1])
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+// +build !windows
+
+package rolog
+
+import "os"
+
+// SyncDir satisfies DirSyncFS.
+func (OSFS) SyncDir(name string) error {
+	d, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
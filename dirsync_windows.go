@@ -0,0 +1,7 @@
+package rolog
+
+// SyncDir satisfies DirSyncFS. It is a no-op on Windows, where directories
+// can't be synced and NTFS journals renames itself.
+func (OSFS) SyncDir(name string) error {
+	return nil
+}
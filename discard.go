@@ -0,0 +1,13 @@
+package rolog
+
+import "io/ioutil"
+
+// WithDiscard makes the Rolog throw away everything written to it, for
+// benchmarks and for deployments where file logging is switched off but the
+// code writing the log should stay the same. It is pass-through mode with
+// nowhere to pass to, so writes still go through encoders, tees, buffering
+// and the async queue, but nothing touches the filesystem and the Rolog
+// never rotates. It replaces a WithPassthrough given before it.
+func WithDiscard() Option {
+	return WithPassthrough(PassthroughAlways, ioutil.Discard)
+}
@@ -0,0 +1,35 @@
+package rolog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscardTouchesNoFiles(t *testing.T) {
+	dir := filepath.Join(".", "tmp-discard")
+
+	r, err := New(dir, "test", WithDiscard(), WithMaxSize(4))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	for i := 0; i < 3; i++ {
+		if n, err := r.Write([]byte("hello\n")); n != 6 || err != nil {
+			t.Errorf("Wanted 6 bytes written, got %d (%v)", n, err)
+		}
+	}
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+	}
+
+	archives, err := r.Archives()
+	if err != nil || len(archives) != 0 {
+		t.Errorf("Wanted no archives, got %+v (%v)", archives, err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Wanted no log directory, got %v", err)
+	}
+}
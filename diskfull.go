@@ -0,0 +1,91 @@
+package rolog
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// DiskFullPolicy decides what a write does when the disk is full.
+type DiskFullPolicy int
+
+const (
+	// DiskFullFail returns the error to the caller, or hands the write to the
+	// fallback writer if there is one. It is the default.
+	DiskFullFail DiskFullPolicy = iota
+	// DiskFullPrune removes archives, oldest first and subject to any delete
+	// filter, until the write fits, then behaves like DiskFullFail if it
+	// still doesn't.
+	DiskFullPrune
+	// DiskFullDrop discards the write and reports success, counting it in
+	// Dropped.
+	DiskFullDrop
+)
+
+// WithDiskFullPolicy chooses how writes behave when the disk is full.
+func WithDiskFullPolicy(p DiskFullPolicy) Option {
+	return func(r *Rolog) {
+		r.diskFull = p
+	}
+}
+
+// isDiskFull reports whether err means the disk is out of space.
+func isDiskFull(err error) bool {
+	err = errors.Cause(err)
+	if perr, ok := err.(*os.PathError); ok {
+		err = perr.Err
+	}
+	return diskFullErrno(err)
+}
+
+// handleDiskFull applies the disk-full policy to out, which the file refused,
+// returning whether the write is settled. It must be called with mu held.
+func (r *Rolog) handleDiskFull(out []byte) bool {
+	switch r.diskFull {
+	case DiskFullDrop:
+		r.drop(DropDiskFull)
+		return true
+	case DiskFullPrune:
+		return r.pruneToFit(out)
+	}
+	return false
+}
+
+// pruneToFit removes archives and bundles, oldest first, until out can be
+// written. It must be called with mu held.
+func (r *Rolog) pruneToFit(out []byte) bool {
+	r.rotMu.Lock()
+	defer r.rotMu.Unlock()
+
+	archives, err := r.retained()
+	if err != nil {
+		r.report(err)
+		return false
+	}
+
+	for _, a := range archives {
+		removed, err := r.remove(a, r.deleteFilter)
+		if err != nil {
+			r.report(err)
+			return false
+		}
+		if !removed {
+			continue
+		}
+
+		n, err := r.output().Write(out)
+		r.size += int64(n)
+		out = out[n:]
+		if err == nil {
+			r.recovered()
+			return true
+		}
+		if !isDiskFull(err) {
+			return false
+		}
+		if r.buf != nil {
+			r.buf.Reset(r.f)
+		}
+	}
+	return false
+}
@@ -0,0 +1,11 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package rolog
+
+import "syscall"
+
+// diskFullErrno reports whether err is the operating system's disk-full error.
+func diskFullErrno(err error) bool {
+	return err == syscall.ENOSPC
+}
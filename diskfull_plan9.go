@@ -0,0 +1,7 @@
+package rolog
+
+// diskFullErrno reports whether err is the operating system's disk-full error.
+// Plan 9 has no such error value, so disk-full failures aren't recognized.
+func diskFullErrno(err error) bool {
+	return false
+}
@@ -0,0 +1,105 @@
+//go:build !plan9
+// +build !plan9
+
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// quotaFS wraps OSFS and fails writes with ENOSPC while the directory holds
+// more than limit bytes.
+type quotaFS struct {
+	OSFS
+	dir   string
+	limit int64
+}
+
+type quotaFile struct {
+	File
+	fs *quotaFS
+}
+
+func (f *quotaFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	file, err := f.OSFS.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &quotaFile{File: file, fs: f}, nil
+}
+
+func (f *quotaFile) Write(p []byte) (int, error) {
+	fis, _ := ioutil.ReadDir(f.fs.dir)
+	var used int64
+	for _, fi := range fis {
+		used += fi.Size()
+	}
+	if f.fs.limit > 0 && used+int64(len(p)) > f.fs.limit {
+		return 0, &os.PathError{Op: "write", Path: "test.log", Err: syscall.ENOSPC}
+	}
+	return f.File.Write(p)
+}
+
+func TestDiskFullPolicies(t *testing.T) {
+	cases := map[string]struct {
+		policy  DiskFullPolicy
+		wantErr bool
+		dropped uint64
+	}{
+		"fail":  {DiskFullFail, true, 0},
+		"prune": {DiskFullPrune, false, 0},
+		"drop":  {DiskFullDrop, false, 1},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir(".", "tmp")
+			if err != nil {
+				t.Errorf("unexpected error: %q", err)
+				t.FailNow()
+			}
+
+			var (
+				clock = newFakeClock()
+				fs    = &quotaFS{dir: dir}
+			)
+			r, err := New(dir, "test", WithClock(clock), WithFS(fs), WithDiskFullPolicy(c.policy))
+			if err != nil {
+				t.Errorf("unexpected error: %q", err)
+				t.FailNow()
+			}
+
+			defer func() {
+				r.Close()
+				if err := os.RemoveAll(dir); err != nil {
+					t.Errorf("could not cleanup temp files: %q", err)
+				}
+			}()
+
+			r.Write([]byte("0123456789\n"))
+			clock.Advance(time.Hour)
+			if err := r.Rotate(); err != nil {
+				t.Errorf("could not rotate: %q", err)
+				t.FailNow()
+			}
+
+			fs.limit = 16
+			_, err = r.Write([]byte("0123456789\n"))
+			if gotErr := err != nil; gotErr != c.wantErr {
+				t.Errorf("Wanted error %v, got %v", c.wantErr, err)
+			}
+			if got := r.Dropped(); got != c.dropped {
+				t.Errorf("Wanted %d dropped, got %d", c.dropped, got)
+			}
+
+			archives, _ := r.Archives()
+			if pruned := len(archives) == 0; pruned != (c.policy == DiskFullPrune) {
+				t.Errorf("Wanted the archive pruned only under DiskFullPrune, got %d left", len(archives))
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package rolog
+
+import "syscall"
+
+// The Windows disk-full errors.
+const (
+	errorHandleDiskFull = syscall.Errno(39)
+	errorDiskFull       = syscall.Errno(112)
+)
+
+// diskFullErrno reports whether err is the operating system's disk-full error.
+func diskFullErrno(err error) bool {
+	return err == errorDiskFull || err == errorHandleDiskFull || err == syscall.ENOSPC
+}
@@ -0,0 +1,144 @@
+package rolog
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DropReason says why an entry was discarded instead of written.
+type DropReason string
+
+const (
+	// DropQueueFull is a write discarded because a non-blocking queue was
+	// full.
+	DropQueueFull DropReason = "queue_full"
+	// DropRingOverwrite is an entry overwritten in a full ring under
+	// RingOverwrite.
+	DropRingOverwrite DropReason = "ring_overwrite"
+	// DropDiskFull is a write discarded under DiskFullDrop.
+	DropDiskFull DropReason = "disk_full"
+	// DropRateLimit is a write discarded by the rate limit.
+	DropRateLimit DropReason = "rate_limit"
+	// DropQuota is a write discarded for being over the quota.
+	DropQuota DropReason = "quota"
+)
+
+// Drops counts the entries a Rolog has discarded, by reason.
+type Drops struct {
+	QueueFull     uint64
+	RingOverwrite uint64
+	DiskFull      uint64
+	RateLimit     uint64
+	Quota         uint64
+}
+
+// Total returns the number of entries discarded for any reason.
+func (d Drops) Total() uint64 {
+	return d.QueueFull + d.RingOverwrite + d.DiskFull + d.RateLimit + d.Quota
+}
+
+// String satisfies fmt.Stringer, listing the non-zero counts as
+// reason=count pairs.
+func (d Drops) String() string {
+	var parts []string
+	for _, c := range []struct {
+		reason DropReason
+		n      uint64
+	}{
+		{DropQueueFull, d.QueueFull},
+		{DropRingOverwrite, d.RingOverwrite},
+		{DropDiskFull, d.DiskFull},
+		{DropRateLimit, d.RateLimit},
+		{DropQuota, d.Quota},
+	} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%s=%d", c.reason, c.n))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// sub returns the counts in d that aren't in prev.
+func (d Drops) sub(prev Drops) Drops {
+	return Drops{
+		QueueFull:     d.QueueFull - prev.QueueFull,
+		RingOverwrite: d.RingOverwrite - prev.RingOverwrite,
+		DiskFull:      d.DiskFull - prev.DiskFull,
+		RateLimit:     d.RateLimit - prev.RateLimit,
+		Quota:         d.Quota - prev.Quota,
+	}
+}
+
+// counter returns the field of d that counts reason.
+func (d *Drops) counter(reason DropReason) *uint64 {
+	switch reason {
+	case DropQueueFull:
+		return &d.QueueFull
+	case DropRingOverwrite:
+		return &d.RingOverwrite
+	case DropDiskFull:
+		return &d.DiskFull
+	case DropQuota:
+		return &d.Quota
+	}
+	return &d.RateLimit
+}
+
+// load returns a copy of d, reading each count atomically.
+func (d *Drops) load() Drops {
+	return Drops{
+		QueueFull:     atomic.LoadUint64(&d.QueueFull),
+		RingOverwrite: atomic.LoadUint64(&d.RingOverwrite),
+		DiskFull:      atomic.LoadUint64(&d.DiskFull),
+		RateLimit:     atomic.LoadUint64(&d.RateLimit),
+		Quota:         atomic.LoadUint64(&d.Quota),
+	}
+}
+
+// WithDropSummary makes a running Rolog write a line into the log every d in
+// which entries were discarded, saying how many and why, so that data loss is
+// visible to whoever reads the log. The line goes through the encoder like any
+// other entry. A non-positive d disables the summary, which is the default.
+func WithDropSummary(d time.Duration) Option {
+	return func(r *Rolog) {
+		r.dropSummary = d
+	}
+}
+
+// Dropped returns how many entries have been discarded for any reason; see
+// DroppedBy for the breakdown.
+func (r *Rolog) Dropped() uint64 {
+	return r.drops.load().Total()
+}
+
+// DroppedBy returns how many entries have been discarded, by reason.
+func (r *Rolog) DroppedBy() Drops {
+	return r.drops.load()
+}
+
+// drop counts an entry discarded for reason.
+func (r *Rolog) drop(reason DropReason) {
+	atomic.AddUint64(r.drops.counter(reason), 1)
+	if m := r.meter(); m != nil {
+		m.Dropped(reason)
+	}
+	r.observe(event{kind: eventDrop, reason: reason})
+}
+
+// summarizeDrops writes a summary of the entries discarded since prev into the
+// log, returning the counts it summarized up to.
+func (r *Rolog) summarizeDrops(prev Drops, every time.Duration) Drops {
+	now := r.drops.load()
+	d := now.sub(prev)
+	if d.Total() == 0 {
+		return now
+	}
+
+	line := fmt.Sprintf("rolog: dropped %d entries in the last %s (%s)\n", d.Total(), every, d)
+	if _, err := r.write([]byte(line)); err != nil && err != ErrClosed {
+		r.report(err)
+	}
+	return now
+}
@@ -0,0 +1,62 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDropsAreCountedAndSummarized(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	limit := RateLimit{WritesPerSecond: 1, Policy: LimitDrop}
+	r, err := New(dir, "test", WithClock(clock), WithRateLimit(limit), WithDropSummary(time.Minute))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Run(context.Background())
+	for clock.numTickers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	for i := 0; i < 3; i++ {
+		r.Write([]byte("entry\n"))
+	}
+	if got, want := r.DroppedBy(), (Drops{RateLimit: 2}); got != want {
+		t.Errorf("Wanted %+v, got %+v", want, got)
+	}
+	if n := r.Dropped(); n != 2 {
+		t.Errorf("Wanted 2 dropped entries, got %d", n)
+	}
+
+	clock.Advance(time.Minute)
+	want := "entry\nrolog: dropped 2 entries in the last 1m0s (rate_limit=2)\n"
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, _ := ioutil.ReadFile(r.CurrentPath())
+		if string(got) == want {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Errorf("Wanted %q, got %q", want, got)
+			t.FailNow()
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,100 @@
+package rolog
+
+import (
+	"bytes"
+	"time"
+)
+
+// Encoder transforms the payload of every write before it reaches the file,
+// for example to add timestamps, wrap lines in JSON or redact secrets.
+//
+// Encode appends the encoding of p, a write that happened at now, to dst and
+// returns the extended slice. It must not retain p or return it in place of
+// dst. Encode is called with the Rolog's lock held, one write at a time, so an
+// Encoder may keep state between calls, such as whether the last write ended
+// mid-line, but it must not be shared between Rologs.
+type Encoder interface {
+	Encode(dst, p []byte, now time.Time) []byte
+}
+
+// EncoderFunc adapts a function to an Encoder.
+type EncoderFunc func(dst, p []byte, now time.Time) []byte
+
+// Encode satisfies Encoder.
+func (f EncoderFunc) Encode(dst, p []byte, now time.Time) []byte {
+	return f(dst, p, now)
+}
+
+// WithEncoder adds encs to the Rolog's encoders. Encoders run in the order
+// their options are given, each on the output of the one before, so the last
+// one has the final say. The built-in options WithPrefix, WithTimestamps,
+// WithJSONLines and WithLogfmt are encoders too, and compose with these in the
+// same way. Since Child reapplies its parent's options, encs are shared with
+// any children, so they should be stateless.
+func WithEncoder(encs ...Encoder) Option {
+	return func(r *Rolog) {
+		r.encoders = append(r.encoders, encs...)
+	}
+}
+
+// encode runs p through the encoders, using bufs as scratch space so that
+// steady-state writes don't allocate. It must be called with mu held.
+func (r *Rolog) encode(bufs *[2][]byte, p []byte) []byte {
+	if len(r.encoders) == 0 || len(p) == 0 {
+		return p
+	}
+
+	now := r.now()
+	for i, enc := range r.encoders {
+		bufs[i%2] = enc.Encode(bufs[i%2][:0], p, now)
+		p = bufs[i%2]
+	}
+	return p
+}
+
+// prefixLines appends p to dst with prefix inserted at the start of every
+// line. midLine tracks whether the previous call ended without a newline, so
+// lines split across writes are only prefixed once.
+func prefixLines(dst, p, prefix []byte, midLine *bool) []byte {
+	for len(p) > 0 {
+		if !*midLine {
+			dst = append(dst, prefix...)
+		}
+
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			dst = append(dst, p...)
+			*midLine = true
+			break
+		}
+
+		dst = append(dst, p[:i+1]...)
+		p = p[i+1:]
+		*midLine = false
+	}
+	return dst
+}
+
+// WithTrailingNewline adds a newline to every write that doesn't end with one,
+// so writers that forget the terminator can't run two records together on one
+// line. It adds a NewlineEncoder to the Rolog's encoders; give it first so the
+// encoders after it see whole lines.
+func WithTrailingNewline() Option {
+	return WithEncoder(NewlineEncoder{})
+}
+
+// NewlineEncoder is an Encoder that terminates every write with a newline.
+type NewlineEncoder struct{}
+
+// Encode satisfies Encoder.
+func (NewlineEncoder) Encode(dst, p []byte, now time.Time) []byte {
+	if len(p) == 0 {
+		// dropped by an earlier encoder
+		return dst
+	}
+	dst = append(dst, p...)
+	if p[len(p)-1] != '\n' {
+		dst = append(dst, '\n')
+	}
+	return dst
+}
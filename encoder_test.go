@@ -0,0 +1,126 @@
+package rolog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWithEncoderComposesInOrder(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	redact := EncoderFunc(func(dst, p []byte, now time.Time) []byte {
+		return append(dst, bytes.Replace(p, []byte("hunter2"), []byte("[redacted]"), -1)...)
+	})
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithEncoder(redact), WithLogfmt(), WithPrefix("app "))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("password=hunter2\n"))
+
+	got, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	want := "app ts=" + clock.Now().Format(time.RFC3339Nano) + " msg=\"password=[redacted]\"\n"
+	if string(got) != want {
+		t.Errorf("Wanted %q, got %q", want, got)
+	}
+}
+
+func TestWithTrailingNewlineTerminatesWrites(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithTrailingNewline(), WithPrefix("> "))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	n, err := r.Write([]byte("one"))
+	if err != nil || n != 3 {
+		t.Errorf("Wanted 3 bytes written, got %d (%v)", n, err)
+	}
+	r.Write([]byte("two\n"))
+
+	got, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if want := "> one\n> two\n"; string(got) != want {
+		t.Errorf("Wanted %q, got %q", want, got)
+	}
+}
+
+func TestTrailingNewlineAfterADroppedWrite(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	drop := EncoderFunc(func(dst, p []byte, now time.Time) []byte {
+		if bytes.HasPrefix(p, []byte("debug")) {
+			return dst
+		}
+		return append(dst, p...)
+	})
+
+	r, err := New(dir, "test", WithEncoder(drop, NewlineEncoder{}))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("debug one"))
+	r.Write([]byte("two"))
+
+	got, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if want := "two\n"; string(got) != want {
+		t.Errorf("Wanted %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,75 @@
+// Package encrypt provides rolog.Encrypter implementations for age and OpenPGP
+// recipients.
+package encrypt
+
+import (
+	"bytes"
+	"io"
+
+	"filippo.io/age"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/haleyrc/rolog"
+	"github.com/pkg/errors"
+)
+
+// ageEncrypter encrypts archives to one or more age recipients.
+type ageEncrypter struct {
+	recipients []age.Recipient
+}
+
+// Age returns an Encrypter for the given age public keys (age1...).
+func Age(keys ...string) (rolog.Encrypter, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no age recipients provided")
+	}
+
+	e := &ageEncrypter{}
+	for _, k := range keys {
+		r, err := age.ParseX25519Recipient(k)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse age recipient")
+		}
+		e.recipients = append(e.recipients, r)
+	}
+
+	return e, nil
+}
+
+// Encrypt satisfies rolog.Encrypter.
+func (e *ageEncrypter) Encrypt(w io.Writer) (io.WriteCloser, error) {
+	return age.Encrypt(w, e.recipients...)
+}
+
+// Extension satisfies rolog.Encrypter.
+func (e *ageEncrypter) Extension() string {
+	return ".age"
+}
+
+// pgpEncrypter encrypts archives to one or more OpenPGP public keys.
+type pgpEncrypter struct {
+	to openpgp.EntityList
+}
+
+// PGP returns an Encrypter for the given ASCII-armored public key ring.
+func PGP(armoredKeyRing []byte) (rolog.Encrypter, error) {
+	to, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armoredKeyRing))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read public key ring")
+	}
+
+	if len(to) == 0 {
+		return nil, errors.New("no pgp recipients provided")
+	}
+
+	return &pgpEncrypter{to: to}, nil
+}
+
+// Encrypt satisfies rolog.Encrypter.
+func (e *pgpEncrypter) Encrypt(w io.Writer) (io.WriteCloser, error) {
+	return openpgp.Encrypt(w, e.to, nil, nil, nil)
+}
+
+// Extension satisfies rolog.Encrypter.
+func (e *pgpEncrypter) Extension() string {
+	return ".gpg"
+}
@@ -0,0 +1,43 @@
+package encrypt
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func TestAgeRoundTrip(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	e, err := Age(id.Recipient().String())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	var buf bytes.Buffer
+	w, err := e.Encrypt(&buf)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	w.Write([]byte("secret"))
+	w.Close()
+
+	r, err := age.Decrypt(&buf, id)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	got, _ := ioutil.ReadAll(r)
+	if string(got) != "secret" {
+		t.Errorf("Wanted %q, got %q", "secret", got)
+	}
+}
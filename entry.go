@@ -0,0 +1,30 @@
+package rolog
+
+import "sync"
+
+// maxPooledEntry is the largest buffer WriteEntry keeps for reuse.
+const maxPooledEntry = 64 * 1024
+
+var entryPool = sync.Pool{
+	New: func() interface{} { return new([]byte) },
+}
+
+// WriteEntry writes the segments as one contiguous entry, for callers that
+// assemble lines from parts. The entry is never interleaved with other writes
+// nor split across files by a rotation. It returns the total number of bytes
+// written from the segments.
+func (r *Rolog) WriteEntry(segments ...[]byte) (int, error) {
+	buf := entryPool.Get().(*[]byte)
+	b := (*buf)[:0]
+	for _, s := range segments {
+		b = append(b, s...)
+	}
+
+	n, err := r.Write(b)
+
+	if cap(b) <= maxPooledEntry {
+		*buf = b
+		entryPool.Put(buf)
+	}
+	return n, err
+}
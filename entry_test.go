@@ -0,0 +1,74 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWriteEntryIsContiguous(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithMaxSize(64), WithSyncPolicy(SyncOnRotate))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(c string) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				n, err := r.WriteEntry([]byte(c), []byte(strings.Repeat(c, 8)), []byte("\n"))
+				if err != nil || n != 10 {
+					t.Errorf("Wanted 10 bytes written, got %d (%v)", n, err)
+				}
+			}
+		}(string(rune('a' + i)))
+	}
+	wg.Wait()
+
+	archives, err := r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	paths := []string{r.CurrentPath()}
+	for _, a := range archives {
+		paths = append(paths, a.Path)
+	}
+
+	lines := 0
+	for _, path := range paths {
+		got, _ := ioutil.ReadFile(path)
+		for _, line := range strings.SplitAfter(string(got), "\n") {
+			if line == "" {
+				continue
+			}
+			if len(line) != 10 || strings.Count(line, line[:1]) != 9 {
+				t.Errorf("Wanted an intact entry in %s, got %q", path, line)
+			}
+			lines++
+		}
+	}
+	if lines != 160 {
+		t.Errorf("Wanted 160 entries, got %d", lines)
+	}
+}
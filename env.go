@@ -0,0 +1,82 @@
+package rolog
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// EnvPrefix is prepended to the names of the environment variables read by
+// ConfigFromEnv.
+const EnvPrefix = "ROLOG_"
+
+// ConfigFromEnv builds a Config from environment variables, for deployments
+// where the environment is the only configuration channel. It reads
+// ROLOG_DIR, ROLOG_NAME, ROLOG_INTERVAL, ROLOG_MAX_SIZE, ROLOG_MAX_BACKUPS,
+// ROLOG_MAX_TOTAL_SIZE, ROLOG_MAX_AGE, ROLOG_BUNDLE_AGE, ROLOG_LATEST_LINK,
+// ROLOG_FILE_MODE, ROLOG_ARCHIVE_PERM, ROLOG_STD_LOG, ROLOG_APPEND,
+// ROLOG_EXTENSION, ROLOG_CURRENT_FILENAME, ROLOG_BUFFER_SIZE,
+// ROLOG_FLUSH_INTERVAL, ROLOG_QUEUE_SIZE, ROLOG_COMPRESSION,
+// ROLOG_COMPRESS_WORKERS and ROLOG_PASSTHROUGH, using the same value syntax as
+// LoadConfig. Unset variables leave the corresponding field at its zero value.
+func ConfigFromEnv() (Config, error) {
+	fc := fileConfig{
+		Dir:          env("DIR"),
+		Name:         env("NAME"),
+		Interval:     scalar(env("INTERVAL")),
+		MaxSize:      scalar(env("MAX_SIZE")),
+		MaxTotalSize: scalar(env("MAX_TOTAL_SIZE")),
+		MaxAge:       scalar(env("MAX_AGE")),
+		BundleAge:    scalar(env("BUNDLE_AGE")),
+		FileMode:     scalar(env("FILE_MODE")),
+		ArchivePerm:  scalar(env("ARCHIVE_PERM")),
+		Extension:    env("EXTENSION"),
+		CurrentName:  env("CURRENT_FILENAME"),
+		BufferSize:   scalar(env("BUFFER_SIZE")),
+		FlushEvery:   scalar(env("FLUSH_INTERVAL")),
+		Compression:  env("COMPRESSION"),
+		Passthrough:  env("PASSTHROUGH"),
+	}
+
+	var err error
+	if v := env("MAX_BACKUPS"); v != "" {
+		if fc.MaxBackups, err = strconv.Atoi(v); err != nil {
+			return Config{}, errors.Wrap(err, "invalid "+EnvPrefix+"MAX_BACKUPS")
+		}
+	}
+	if v := env("QUEUE_SIZE"); v != "" {
+		if fc.QueueSize, err = strconv.Atoi(v); err != nil {
+			return Config{}, errors.Wrap(err, "invalid "+EnvPrefix+"QUEUE_SIZE")
+		}
+	}
+	if v := env("COMPRESS_WORKERS"); v != "" {
+		if fc.Workers, err = strconv.Atoi(v); err != nil {
+			return Config{}, errors.Wrap(err, "invalid "+EnvPrefix+"COMPRESS_WORKERS")
+		}
+	}
+	if v := env("LATEST_LINK"); v != "" {
+		if fc.LatestLink, err = strconv.ParseBool(v); err != nil {
+			return Config{}, errors.Wrap(err, "invalid "+EnvPrefix+"LATEST_LINK")
+		}
+	}
+
+	if v := env("STD_LOG"); v != "" {
+		if fc.StdLog, err = strconv.ParseBool(v); err != nil {
+			return Config{}, errors.Wrap(err, "invalid "+EnvPrefix+"STD_LOG")
+		}
+	}
+
+	if v := env("APPEND"); v != "" {
+		if fc.Append, err = strconv.ParseBool(v); err != nil {
+			return Config{}, errors.Wrap(err, "invalid "+EnvPrefix+"APPEND")
+		}
+	}
+
+	return fc.config()
+}
+
+// env returns the value of the prefixed environment variable key.
+func env(key string) string {
+	return os.Getenv(EnvPrefix + key)
+}
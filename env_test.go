@@ -0,0 +1,33 @@
+package rolog
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	vars := map[string]string{
+		"ROLOG_DIR":         "/var/log",
+		"ROLOG_NAME":        "app",
+		"ROLOG_INTERVAL":    "6h",
+		"ROLOG_MAX_SIZE":    "100MB",
+		"ROLOG_MAX_BACKUPS": "5",
+		"ROLOG_MAX_AGE":     "72h",
+	}
+	for k, v := range vars {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	got, err := ConfigFromEnv()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	want := Config{Dir: "/var/log", Name: "app", Interval: 6 * time.Hour, MaxSize: 100 << 20, MaxBackups: 5, MaxAge: 72 * time.Hour}
+	if got != want {
+		t.Errorf("Wanted %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,30 @@
+package rolog
+
+// The event IDs used by WithEventLog, for filtering in the Event Viewer and in
+// monitoring tools.
+const (
+	// EventIDEntry is the ID of a mirrored log line.
+	EventIDEntry = 1
+	// EventIDRotate is the ID of a completed rotation.
+	EventIDRotate = 2
+	// EventIDError is the ID of an error reported on Err.
+	EventIDError = 3
+)
+
+// WithEventLog reports the Rolog's errors and completed rotations to the
+// Windows Event Log under source, and with entries also mirrors every line
+// written to the current file, so that tooling which only watches the Event
+// Log sees what the Rolog does. Lines are logged as errors, warnings or
+// information according to JournalPriority. The source should be registered
+// beforehand, for instance with the eventlog package of golang.org/x/sys, or
+// the Event Viewer won't be able to format the messages.
+//
+// Mirrored lines are delivered like those of WithSyslog: it never blocks or
+// fails a write, and lines are discarded while it is behind. On platforms
+// other than Windows WithEventLog does nothing, so the same configuration can
+// be used everywhere.
+func WithEventLog(source string, entries bool) Option {
+	return func(r *Rolog) {
+		r.addEventLog(source, entries)
+	}
+}
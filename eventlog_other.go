@@ -0,0 +1,7 @@
+//go:build !windows
+// +build !windows
+
+package rolog
+
+// addEventLog does nothing, since there is no Event Log outside of Windows.
+func (r *Rolog) addEventLog(source string, entries bool) {}
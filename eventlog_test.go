@@ -0,0 +1,13 @@
+//go:build !windows
+// +build !windows
+
+package rolog
+
+import "testing"
+
+func TestEventLogDisabledOutsideWindows(t *testing.T) {
+	r := newRolog("test", []Option{WithEventLog("app", true)})
+	if len(r.mirrors) != 0 || len(r.withObservers) != 0 {
+		t.Errorf("Wanted nothing attached, got %d mirrors and %d observers", len(r.mirrors), len(r.withObservers))
+	}
+}
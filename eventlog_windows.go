@@ -0,0 +1,90 @@
+package rolog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// addEventLog arranges for the Rolog's events, and optionally its lines, to be
+// reported to the Event Log.
+func (r *Rolog) addEventLog(source string, entries bool) {
+	s := &eventLogSink{source: source, mirrored: entries}
+	if entries {
+		r.addMirror("eventlog", s)
+	}
+	r.withObservers = append(r.withObservers, ObserverFuncs{
+		Rotate: func(e RotationEvent) {
+			s.event(6, EventIDRotate, fmt.Sprintf("Rotated %s to %s (%s, %d bytes)", e.OldPath, e.NewPath, e.Reason, e.Bytes))
+		},
+		// Failing to log an error isn't reported, since reporting it would
+		// only come back here.
+		Error: func(err error) {
+			s.event(3, EventIDError, err.Error())
+		},
+	})
+}
+
+// eventLogSink writes to the Event Log, as the mirrorSink for lines and from
+// an observer for events.
+type eventLogSink struct {
+	source string
+	// mirrored is set when lines are mirrored, so the handle is kept open
+	// between them; otherwise it is only opened for each event
+	mirrored bool
+
+	mu  sync.Mutex
+	log *eventlog.Log
+}
+
+// event writes msg with the type matching priority, a syslog priority.
+func (s *eventLogSink) event(priority int, id uint32, msg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.log == nil {
+		l, err := eventlog.Open(s.source)
+		if err != nil {
+			return err
+		}
+		s.log = l
+	}
+	if !s.mirrored {
+		defer s.close()
+	}
+
+	switch {
+	case priority <= 3:
+		return s.log.Error(id, msg)
+	case priority == 4:
+		return s.log.Warning(id, msg)
+	default:
+		return s.log.Info(id, msg)
+	}
+}
+
+// send satisfies mirrorSink.
+func (s *eventLogSink) send(line []byte, _ time.Time) error {
+	if len(line) == 0 {
+		return nil
+	}
+	return s.event(JournalPriority(line), EventIDEntry, string(line))
+}
+
+// reset satisfies mirrorSink.
+func (s *eventLogSink) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.close()
+}
+
+// close closes the handle, if it is open. It must be called with mu held.
+func (s *eventLogSink) close() {
+	if s.log != nil {
+		s.log.Close()
+		s.log = nil
+	}
+}
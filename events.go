@@ -0,0 +1,76 @@
+package rolog
+
+import "time"
+
+// eventBuffer is how many undelivered rotation events are kept for Notify.
+const eventBuffer = 16
+
+// RotationReason records what triggered a rotation.
+type RotationReason string
+
+// The reasons a rotation can happen.
+const (
+	// ReasonManual is a call to Rotate.
+	ReasonManual RotationReason = "manual"
+	// ReasonScheduled is the run loop's interval elapsing, including a missed
+	// rotation caught up by Resume.
+	ReasonScheduled RotationReason = "scheduled"
+	// ReasonSize is the current file reaching its max size.
+	ReasonSize RotationReason = "size"
+	// ReasonDay is the first write after the day of the current file ended,
+	// with WithDayDirs.
+	ReasonDay RotationReason = "day"
+	// ReasonFailback is the log returning to its primary directory, with
+	// WithFailover, archiving what it wrote elsewhere in the meantime.
+	ReasonFailback RotationReason = "failback"
+	// ReasonParent is a child rotating along with its parent.
+	ReasonParent RotationReason = "parent"
+)
+
+// RotationEvent describes a completed rotation.
+type RotationEvent struct {
+	// Reason is what triggered the rotation.
+	Reason RotationReason
+	// OldPath is the path of the current file that was rotated out.
+	OldPath string
+	// NewPath is where the rotated file now lives, after any encryption. It is
+	// empty if the RotationStrategy produced no archive.
+	NewPath string
+	// Bytes is the number of bytes written to the file before it was rotated.
+	Bytes int64
+	// Duration is how long the rotation took, excluding retention.
+	Duration time.Duration
+	// Paused is how long writes were blocked while the files were swapped.
+	Paused time.Duration
+}
+
+// Notify returns a channel on which a RotationEvent is delivered after every
+// rotation, so in-process consumers such as log shippers can react to
+// cut-overs immediately. If the caller doesn't keep up, the oldest undelivered
+// events are discarded.
+func (r *Rolog) Notify() <-chan RotationEvent {
+	return r.events
+}
+
+// notify delivers e on the events channel without blocking, discarding the
+// oldest pending event if the channel is full.
+func (r *Rolog) notify(e RotationEvent) {
+	if m := r.meter(); m != nil {
+		m.Rotated(e)
+	}
+	r.observe(event{kind: eventRotate, rotation: e})
+	r.sdStatus(e)
+
+	for {
+		select {
+		case r.events <- e:
+			return
+		default:
+		}
+
+		select {
+		case <-r.events:
+		default:
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotateSendsEvent(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("hello\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+
+	select {
+	case e := <-r.Notify():
+		if e.OldPath != filepath.Join(dir, "test.log") {
+			t.Errorf("Wanted old path %s, got %s", filepath.Join(dir, "test.log"), e.OldPath)
+		}
+		if _, err := os.Stat(e.NewPath); err != nil {
+			t.Errorf("Wanted new path to exist, got %q", err)
+		}
+		if e.Bytes != 6 {
+			t.Errorf("Wanted 6 bytes, got %d", e.Bytes)
+		}
+	default:
+		t.Errorf("Wanted a rotation event")
+	}
+}
@@ -0,0 +1,71 @@
+package rolog
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ExportOptions tunes an Export.
+type ExportOptions struct {
+	// Since and Until limit the export to what was logged in that window, as
+	// ReadRange does. Zero times leave that end of the window open.
+	Since, Until time.Time
+	// Compression compresses the export with the given codec.
+	Compression Compression
+}
+
+// Export writes a snapshot of the logs to w, from the oldest archive through
+// the current file as they were when Export was called, for support bundles
+// and the like. Archives are decompressed, and the whole export compressed
+// again if opts say so. Writes and rotations carry on undisturbed while it
+// runs. Export stops with ctx's error if ctx is cancelled first.
+func (r *Rolog) Export(ctx context.Context, w io.Writer, opts ExportOptions) error {
+	if !opts.Compression.valid() {
+		return ErrInvalidCompression
+	}
+
+	var (
+		rc  io.ReadCloser
+		err error
+	)
+	if opts.Since.IsZero() && opts.Until.IsZero() {
+		rc, err = r.History()
+	} else {
+		rc, err = r.ReadRange(opts.Since, opts.Until)
+	}
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	in := ctxReader{ctx: ctx, r: rc}
+	switch opts.Compression {
+	case Gzip:
+		err = compressGzip(w, in, 1, 0)
+	case Zstd:
+		err = compressZstd(w, in, 1, 0)
+	default:
+		_, err = io.Copy(w, in)
+	}
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return err
+	}
+	return errors.Wrap(err, "could not export logs")
+}
+
+// ctxReader reads from r until ctx is done.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read satisfies io.Reader.
+func (c ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
@@ -0,0 +1,63 @@
+package rolog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestExportWritesCompressedSnapshot(t *testing.T) {
+	r, clk, cleanup := newHistory(t)
+	defer cleanup()
+
+	var buf bytes.Buffer
+	if err := r.Export(context.Background(), &buf, ExportOptions{Compression: Gzip}); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	b, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	var want string
+	for _, file := range []string{"first", "second", "third"} {
+		want += file + " one\n" + file + " two ERROR\n" + file + " three\n"
+	}
+	if string(b) != want {
+		t.Errorf("Wanted %q, got %q", want, b)
+	}
+
+	buf.Reset()
+	if err := r.Export(context.Background(), &buf, ExportOptions{Since: clk.Now().Add(-30 * time.Minute)}); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if want := "third one\nthird two ERROR\nthird three\n"; buf.String() != want {
+		t.Errorf("Wanted %q, got %q", want, buf.String())
+	}
+}
+
+func TestExportStopsWhenCancelled(t *testing.T) {
+	r, _, cleanup := newHistory(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var buf bytes.Buffer
+	if err := r.Export(ctx, &buf, ExportOptions{}); err != context.Canceled {
+		t.Errorf("Wanted %v, got %v", context.Canceled, err)
+	}
+	if err := r.Export(context.Background(), &buf, ExportOptions{Compression: "lz4"}); err != ErrInvalidCompression {
+		t.Errorf("Wanted %v, got %v", ErrInvalidCompression, err)
+	}
+}
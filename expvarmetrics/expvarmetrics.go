@@ -0,0 +1,72 @@
+// Package expvarmetrics publishes the key counters of a Rolog through expvar,
+// for services that already serve /debug/vars.
+package expvarmetrics
+
+import (
+	"expvar"
+	"time"
+
+	"github.com/haleyrc/rolog"
+)
+
+// DefaultPrefix is the expvar name used when Publish is given an empty prefix.
+const DefaultPrefix = "rolog"
+
+// Metrics is a rolog.Metrics that keeps its counters in an expvar.Map:
+//
+//	bytes_written   bytes accepted by Write
+//	rotations       rotations performed
+//	last_rotation   when the last rotation finished, in RFC 3339 format
+//	errors          errors reported by background work
+//	dropped         entries discarded, by reason
+type Metrics struct {
+	written, rotations, errors expvar.Int
+	lastRotation               expvar.String
+	dropped                    expvar.Map
+}
+
+var _ rolog.Metrics = (*Metrics)(nil)
+
+// Publish adds a Metrics to r and publishes its counters as an expvar.Map
+// named prefix. Like expvar.Publish, it panics if the name is already in use,
+// so Rologs in the same process need distinct prefixes.
+func Publish(r *rolog.Rolog, prefix string) *Metrics {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+
+	m := &Metrics{}
+	vars := expvar.NewMap(prefix)
+	vars.Set("bytes_written", &m.written)
+	vars.Set("rotations", &m.rotations)
+	vars.Set("last_rotation", &m.lastRotation)
+	vars.Set("errors", &m.errors)
+	vars.Set("dropped", &m.dropped)
+
+	r.AddMetrics(m)
+	return m
+}
+
+// Wrote satisfies rolog.Metrics.
+func (m *Metrics) Wrote(n int, _ time.Duration) {
+	m.written.Add(int64(n))
+}
+
+// Rotated satisfies rolog.Metrics.
+func (m *Metrics) Rotated(rolog.RotationEvent) {
+	m.rotations.Add(1)
+	m.lastRotation.Set(time.Now().Format(time.RFC3339))
+}
+
+// Removed satisfies rolog.Metrics.
+func (m *Metrics) Removed(rolog.ArchiveInfo) {}
+
+// Failed satisfies rolog.Metrics.
+func (m *Metrics) Failed(error) {
+	m.errors.Add(1)
+}
+
+// Dropped satisfies rolog.Metrics.
+func (m *Metrics) Dropped(reason rolog.DropReason) {
+	m.dropped.Add(string(reason), 1)
+}
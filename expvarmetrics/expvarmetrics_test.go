@@ -0,0 +1,64 @@
+package expvarmetrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/haleyrc/rolog"
+)
+
+// runs numbers the test runs, since expvar names can only be published once
+// per process.
+var runs int
+
+func TestPublishCountsWritesAndRotations(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := rolog.New(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	runs++
+	name := fmt.Sprintf("testlog%d", runs)
+	Publish(r, name)
+	r.Write([]byte("hello\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	var got struct {
+		Written      int64  `json:"bytes_written"`
+		Rotations    int64  `json:"rotations"`
+		LastRotation string `json:"last_rotation"`
+		Errors       int64  `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &got); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if got.Written != 6 || got.Rotations != 1 || got.Errors != 0 {
+		t.Errorf("Wanted 6 bytes, 1 rotation and no errors, got %+v", got)
+	}
+	if got.LastRotation == "" {
+		t.Errorf("Wanted the last rotation time to be set")
+	}
+}
@@ -0,0 +1,208 @@
+package rolog
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultFailoverAfter is how many consecutive failures WithFailover waits
+// for before switching directories, unless told otherwise.
+const DefaultFailoverAfter = 3
+
+// failoverProbe is how often a running Rolog that has failed over checks
+// whether its primary directory is usable again.
+const failoverProbe = 10 * time.Second
+
+// failover is the state of WithFailover.
+type failover struct {
+	dir   string
+	after int
+	// failures counts consecutive failed writes and rotations
+	failures int
+	// active is set while writing to dir, primary is the current path to
+	// return to, and since is when the log left it
+	active  bool
+	primary string
+	since   time.Time
+}
+
+// WithFailover switches the log to dir, such as a local disk standing in for
+// an unavailable network share, once after consecutive writes or rotations
+// have failed. A non-positive after means DefaultFailoverAfter. The log goes
+// on writing and rotating in dir, while the run loop checks the primary
+// directory, and once it is usable again the log returns to it. The file
+// written during the outage is then archived into the primary directory, as
+// are any archives made in dir, so that the history ends up in one place;
+// rotation events with ReasonFailback flag this. What the primary file held
+// from before the outage is archived first, as of the failover, and the log
+// resumes on a fresh primary file. Anything buffered when the switch happens
+// is lost along with the failing file.
+//
+// The primary directory must still be available to New. FailedOver reports
+// whether the log is currently in dir.
+func WithFailover(dir string, after int) Option {
+	return func(r *Rolog) {
+		if after <= 0 {
+			after = DefaultFailoverAfter
+		}
+		r.failover = &failover{dir: dir, after: after}
+	}
+}
+
+// FailedOver reports whether the log has switched to its failover directory
+// and not yet returned.
+func (r *Rolog) FailedOver() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.failover != nil && r.failover.active
+}
+
+// succeeded notes that a write reached the file. It must be called with mu
+// held.
+func (r *Rolog) succeeded() {
+	if r.failover != nil {
+		r.failover.failures = 0
+	}
+}
+
+// failOver counts a failure because of cause, switching to the failover
+// directory once there have been enough of them, and reports whether it did.
+// It must be called with mu held.
+func (r *Rolog) failOver(cause error) bool {
+	fo := r.failover
+	if fo == nil || fo.active || cause == ErrClosed {
+		return false
+	}
+	if fo.failures++; fo.failures < fo.after {
+		return false
+	}
+
+	if err := r.mkdir(fo.dir); err != nil {
+		r.report(errors.Wrap(err, "could not create failover directory"))
+		return false
+	}
+	path := filepath.Join(fo.dir, filepath.Base(r.currentPath()))
+	f, err := openFile(r.fs, path, currentFlag, r.perm)
+	if err != nil {
+		r.report(errors.Wrap(err, "could not open failover log"))
+		return false
+	}
+
+	r.debugf("failing over to %s after %d failures", fo.dir, fo.failures)
+	r.f.Close()
+	if r.buf != nil {
+		r.buf.Reset(f)
+	}
+	fo.primary, fo.since, fo.active, fo.failures = r.currentPath(), r.now(), true, 0
+	r.setPath(path)
+	r.setFile(f)
+	r.size = 0
+	if fi, err := f.Stat(); err == nil {
+		r.size = fi.Size()
+	}
+	r.report(errors.Wrapf(cause, "failed over to %s", fo.dir))
+	return true
+}
+
+// failBack returns to the primary directory if the log has failed over and the
+// directory is usable again, migrating what was written in the meantime.
+func (r *Rolog) failBack() error {
+	rot, from, stale, err := r.returnToPrimary()
+	if rot == nil || err != nil {
+		return err
+	}
+
+	r.rotMu.Lock()
+	if stale != "" && rot.settings.dryRun == nil {
+		if _, err := r.finalize(stale, rot.settings); err != nil {
+			r.report(errors.Wrap(err, "could not finalize primary log"))
+		}
+	}
+	r.migrateArchives(from, filepath.Dir(r.CurrentPath()))
+	r.rotMu.Unlock()
+
+	return r.process(rot)
+}
+
+// returnToPrimary swaps the failover file for a fresh primary one, archiving
+// both what the primary file held from before the failover and the failover
+// file into the primary directory. It returns the rotation to process along
+// with the failover directory and the archive of the old primary file, if it
+// had anything in it. It returns a nil rotation if the log hasn't failed over
+// or the primary directory is still unusable.
+func (r *Rolog) returnToPrimary() (*rotation, string, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fo := r.failover
+	if r.closed() || fo == nil || !fo.active {
+		return nil, "", "", nil
+	}
+	if err := r.checkDir(filepath.Dir(fo.primary)); err != nil {
+		r.debugf("primary directory still unusable: %v", err)
+		return nil, "", "", nil
+	}
+	var stale string
+	if fi, err := r.fs.Stat(fo.primary); err == nil && fi.Size() > 0 {
+		// New writes must not land after the lines from before the
+		// failover, so those are archived as of when it happened.
+		stale = r.uniquePath(filepath.Join(filepath.Dir(fo.primary), r.fnameAt(fo.since)))
+		if err := renameFile(r.fs, fo.primary, stale); err != nil {
+			r.debugf("primary log still unusable: %v", err)
+			return nil, "", "", nil
+		}
+	}
+	f, err := openFile(r.fs, fo.primary, currentFlag, r.perm)
+	if err != nil {
+		r.debugf("primary log still unusable: %v", err)
+		return nil, "", "", nil
+	}
+
+	paused := time.Now()
+	r.flushRepeats()
+	var (
+		start   = r.now()
+		archive = r.uniquePath(filepath.Join(filepath.Dir(fo.primary), r.fname()))
+	)
+	r.writeFooter(start)
+	r.writeEndRecord(ReasonFailback, start, archive)
+	written := r.size
+	if err := r.drain(); err != nil {
+		f.Close()
+		return nil, "", "", err
+	}
+	r.f.Sync()
+	r.f.Close()
+
+	from := r.currentPath()
+	if err := moveFile(r.fs, from, archive, r.perm); err != nil {
+		r.report(errors.Wrap(err, "could not migrate failover log"))
+		archive = ""
+	}
+
+	r.debugf("returning to %s", filepath.Dir(fo.primary))
+	fo.active = false
+	r.setPath(fo.primary)
+	pending := r.swap(f, archive, start, written, ReasonFailback)
+	r.recordPause(pending, paused)
+	return pending, filepath.Dir(from), stale, nil
+}
+
+// migrateArchives moves the archives in from to the directory to. Failures are
+// reported, leaving the archive in from. It must be called with rotMu held.
+func (r *Rolog) migrateArchives(from, to string) {
+	archives, err := r.archivesIn(from)
+	if err != nil {
+		r.report(errors.Wrap(err, "could not list failover archives"))
+		return
+	}
+	for _, a := range archives {
+		dst := r.uniquePath(filepath.Join(to, filepath.Base(a.Path)))
+		if err := moveFile(r.fs, a.Path, dst, r.perm); err != nil {
+			r.report(errors.Wrapf(err, "could not migrate archive %s", a.Path))
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// downFS wraps OSFS and fails everything done under dir while down is set, as
+// an unreachable network share would.
+type downFS struct {
+	OSFS
+	dir string
+
+	mu   sync.Mutex
+	down bool
+}
+
+type downFile struct {
+	File
+	fs   *downFS
+	name string
+}
+
+func (f *downFS) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+func (f *downFS) failing(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.down && strings.HasPrefix(name, f.dir)
+}
+
+func (f *downFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	if f.failing(name) {
+		return nil, &os.PathError{Op: "open", Path: name, Err: syscall.EIO}
+	}
+	file, err := f.OSFS.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &downFile{File: file, fs: f, name: name}, nil
+}
+
+func (f *downFile) Write(p []byte) (int, error) {
+	if f.fs.failing(f.name) {
+		return 0, syscall.EIO
+	}
+	return f.File.Write(p)
+}
+
+func TestFailoverSwitchesAndReturns(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	var (
+		primary = filepath.Join(dir, "primary")
+		backup  = filepath.Join(dir, "backup")
+		fs      = &downFS{dir: primary}
+		clock   = newFakeClock()
+	)
+	r, err := New(primary, "test", WithFS(fs), WithClock(clock), WithInterval(time.Hour), WithFailover(backup, 2))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Run(ctx)
+	for clock.numTickers() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Paths are read without the lock while the log switches directories.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				r.CurrentPath()
+				r.Archives()
+			}
+		}
+	}()
+
+	r.Write([]byte("before\n"))
+	fs.setDown(true)
+	if _, err := r.Write([]byte("lost\n")); err == nil {
+		t.Errorf("Wanted the first failure to be returned")
+	}
+	if _, err := r.Write([]byte("one\n")); err != nil {
+		t.Errorf("Wanted the second failure to fail over, got %q", err)
+	}
+	if !r.FailedOver() || filepath.Dir(r.CurrentPath()) != backup {
+		t.Errorf("Wanted the log in %s, got %s", backup, r.CurrentPath())
+		t.FailNow()
+	}
+
+	// The log rotates in the failover directory meanwhile.
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	clock.Advance(time.Minute)
+	r.Write([]byte("two\n"))
+
+	// Nothing changes while the primary is still down.
+	clock.Advance(failoverProbe)
+	fs.setDown(false)
+	clock.Advance(failoverProbe)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for r.FailedOver() {
+		if time.Now().After(deadline) {
+			t.Errorf("Wanted the log to return to %s", primary)
+			t.FailNow()
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	r.Write([]byte("after\n"))
+
+	archives := waitForArchives(t, r, 3)
+	for i, want := range []string{"before\n", "one\n", "two\n"} {
+		got, err := ioutil.ReadFile(archives[i].Path)
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("Wanted %q migrated to %s, got %q", want, archives[i].Path, got)
+		}
+	}
+	if left, _ := r.archivesIn(backup); len(left) != 0 {
+		t.Errorf("Wanted nothing left in %s, got %+v", backup, left)
+	}
+
+	got, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if string(got) != "after\n" {
+		t.Errorf("Wanted a fresh primary file, got %q", got)
+	}
+}
@@ -0,0 +1,58 @@
+package rolog
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// WithFallback sends writes to w whenever they can't reach the current file,
+// whether because the write or a size-triggered rotation failed, so lines
+// aren't lost while a condition such as a full or read-only disk is repaired.
+// A nil w means os.Stderr. Such writes report success to the caller.
+//
+// The first failure is delivered on Err, and every write keeps trying the file
+// first. Once one succeeds, onRecover is called, if it isn't nil. It is called
+// with the Rolog's lock held, so it must not write to the Rolog.
+func WithFallback(w io.Writer, onRecover func()) Option {
+	return func(r *Rolog) {
+		if w == nil {
+			w = os.Stderr
+		}
+		r.fallback = w
+		r.onRecover = onRecover
+	}
+}
+
+// fallBack writes out to the fallback writer after the file refused it because
+// of cause, reporting whether it was written. It must be called with mu held.
+func (r *Rolog) fallBack(out []byte, cause error) bool {
+	if r.fallback == nil {
+		return false
+	}
+
+	if !r.failing {
+		r.failing = true
+		r.report(errors.Wrap(cause, "falling back"))
+	}
+
+	if _, err := r.fallback.Write(out); err != nil {
+		r.report(errors.Wrap(err, "could not write to fallback"))
+		return false
+	}
+	return true
+}
+
+// recovered notes that a write reached the file, ending any fallback. It must
+// be called with mu held.
+func (r *Rolog) recovered() {
+	if !r.failing {
+		return
+	}
+
+	r.failing = false
+	if r.onRecover != nil {
+		r.onRecover()
+	}
+}
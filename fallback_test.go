@@ -0,0 +1,85 @@
+package rolog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// failWriteFS wraps OSFS and fails writes to the files it opens while fail is
+// set.
+type failWriteFS struct {
+	OSFS
+	fail bool
+}
+
+type failWriteFile struct {
+	File
+	fs *failWriteFS
+}
+
+func (f *failWriteFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	file, err := f.OSFS.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &failWriteFile{File: file, fs: f}, nil
+}
+
+func (f *failWriteFile) Write(p []byte) (int, error) {
+	if f.fs.fail {
+		return 0, errors.New("device refused the write")
+	}
+	return f.File.Write(p)
+}
+
+func TestWithFallbackCatchesRefusedWrites(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	var (
+		fs        = &failWriteFS{}
+		fallback  bytes.Buffer
+		recovered int
+	)
+	r, err := New(dir, "test", WithFS(fs), WithSyncPolicy(SyncOnRotate), WithFallback(&fallback, func() { recovered++ }))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	fs.fail = true
+	if _, err := r.Write([]byte("lost?\n")); err != nil {
+		t.Errorf("Wanted the fallback to absorb the failure, got %q", err)
+	}
+	if fallback.String() != "lost?\n" {
+		t.Errorf("Wanted the write in the fallback, got %q", fallback.String())
+	}
+	select {
+	case <-r.Err():
+	default:
+		t.Errorf("Wanted the failure to be reported")
+	}
+
+	fs.fail = false
+	r.Write([]byte("back\n"))
+	if recovered != 1 {
+		t.Errorf("Wanted onRecover to be called once, got %d", recovered)
+	}
+	if got, _ := ioutil.ReadFile(r.CurrentPath()); string(got) != "back\n" {
+		t.Errorf("Wanted writes to reach the file again, got %q", got)
+	}
+}
@@ -0,0 +1,149 @@
+package rolog
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/haleyrc/rolog/internal/errq"
+	"github.com/pkg/errors"
+)
+
+// DefaultFluentdAddr is the address of a local Fluentd or Fluent Bit forward
+// input, used when no address is given.
+const DefaultFluentdAddr = "127.0.0.1:24224"
+
+// WithFluentd mirrors every line written to the current file to a Fluentd or
+// Fluent Bit forward input at addr, as an event with the given tag whose
+// record holds the line under "message". The mirror behaves like WithSyslog:
+// it never blocks or fails a write, buffers lines while the collector is slow
+// or down, reconnecting with backoff, and reports failures on Err. To send to
+// Fluentd instead of a file, see NewFluentd.
+func WithFluentd(addr, tag string) Option {
+	return func(r *Rolog) {
+		r.addMirror("fluentd", newFluentdSink(addr, tag))
+	}
+}
+
+// Fluentd is an io.WriteCloser that sends every line written to it to a
+// Fluentd or Fluent Bit forward input, as WithFluentd does, for programs that
+// log to a collector rather than to files.
+type Fluentd struct {
+	m    *mirror
+	err  chan error
+	once sync.Once
+}
+
+// NewFluentd returns a Fluentd sending events with the given tag to the
+// forward input at addr.
+func NewFluentd(addr, tag string) *Fluentd {
+	f := &Fluentd{err: make(chan error, errBuffer)}
+	f.m = newMirror("fluentd", newFluentdSink(addr, tag), time.Now, f.report)
+	go f.m.run()
+	return f
+}
+
+// Write satisfies io.Writer. It never blocks, and never fails; lines are
+// discarded while too many are waiting to be sent.
+func (f *Fluentd) Write(p []byte) (int, error) {
+	return f.m.Write(p)
+}
+
+// Close satisfies io.Closer, sending whatever is still buffered unless the
+// collector is failing.
+func (f *Fluentd) Close() error {
+	err := ErrClosed
+	f.once.Do(func() {
+		close(f.m.stop)
+		f.m.wait()
+		err = nil
+	})
+	return err
+}
+
+// Err returns a channel on which failures to send are delivered. If the
+// caller doesn't keep up, the oldest undelivered errors are discarded.
+func (f *Fluentd) Err() <-chan error {
+	return f.err
+}
+
+// report delivers err on the error channel without blocking, discarding the
+// oldest pending error if the channel is full.
+func (f *Fluentd) report(err error) {
+	errq.Deliver(f.err, err)
+}
+
+// fluentdSink is the mirrorSink that speaks the forward protocol, sending
+// each line as a message-mode event encoded with MessagePack.
+type fluentdSink struct {
+	addr string
+	tag  string
+
+	conn net.Conn
+	buf  []byte
+}
+
+// newFluentdSink returns a fluentdSink, applying the default address.
+func newFluentdSink(addr, tag string) *fluentdSink {
+	if addr == "" {
+		addr = DefaultFluentdAddr
+	}
+	return &fluentdSink{addr: addr, tag: tag}
+}
+
+// send satisfies mirrorSink.
+func (s *fluentdSink) send(line []byte, at time.Time) error {
+	if len(line) == 0 {
+		return nil
+	}
+	if s.conn == nil {
+		conn, err := net.DialTimeout("tcp", s.addr, mirrorTimeout)
+		if err != nil {
+			return errors.Wrap(err, "could not connect")
+		}
+		s.conn = conn
+	}
+
+	// [tag, EventTime, {"message": line}]
+	b := append(s.buf[:0], 0x93)
+	b = appendMsgpackString(b, s.tag)
+	b = append(b, 0xd7, 0x00)
+	b = appendUint32(b, uint32(at.Unix()))
+	b = appendUint32(b, uint32(at.Nanosecond()))
+	b = append(b, 0x81)
+	b = appendMsgpackString(b, "message")
+	b = appendMsgpackString(b, string(line))
+	s.buf = b
+
+	s.conn.SetWriteDeadline(time.Now().Add(mirrorTimeout))
+	_, err := s.conn.Write(b)
+	return err
+}
+
+// reset satisfies mirrorSink.
+func (s *fluentdSink) reset() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// appendMsgpackString appends v as a MessagePack str.
+func appendMsgpackString(b []byte, v string) []byte {
+	switch n := len(v); {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= 0xff:
+		b = append(b, 0xd9, byte(n))
+	case n <= 0xffff:
+		b = append(b, 0xda, byte(n>>8), byte(n))
+	default:
+		b = appendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, v...)
+}
+
+// appendUint32 appends v in big-endian order.
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
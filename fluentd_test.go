@@ -0,0 +1,113 @@
+package rolog
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFluentdSendsForwardEvents(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer l.Close()
+
+	r, err := New(dir, "test", WithFluentd(l.Addr().String(), "app.logs"))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("hello\n"))
+	f := NewFluentd(l.Addr().String(), "app.logs")
+	f.Write([]byte("hello\n"))
+	if err := f.Close(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+	}
+
+	// Both connections carry the same event, apart from its time.
+	head := append([]byte{0x93, 0xa8}, "app.logs"...)
+	head = append(head, 0xd7, 0x00)
+	tail := append([]byte{0x81, 0xa7}, "message"...)
+	tail = append(tail, 0xa5)
+	tail = append(tail, "hello"...)
+
+	for i := 0; i < 2; i++ {
+		conn, err := l.Accept()
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+		got := make([]byte, len(head)+8+len(tail))
+		_, err = io.ReadFull(conn, got)
+		conn.Close()
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		if !bytes.HasPrefix(got, head) || !bytes.HasSuffix(got, tail) {
+			t.Errorf("Wanted a forward message-mode event, got %x", got)
+		}
+	}
+}
+
+func TestFluentdClosesOnce(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer l.Close()
+
+	f := NewFluentd(l.Addr().String(), "app")
+
+	var (
+		wg   sync.WaitGroup
+		errs = make(chan error, 2)
+	)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- f.Close()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var closed int
+	for err := range errs {
+		switch err {
+		case nil:
+		case ErrClosed:
+			closed++
+		default:
+			t.Errorf("unexpected error: %q", err)
+		}
+	}
+	if closed != 1 {
+		t.Errorf("Wanted one of the two closes to find it closed, got %d", closed)
+	}
+}
@@ -0,0 +1,204 @@
+package rolog
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// followPoll is how often a Follower at the end of the current file looks for
+// new data.
+const followPoll = 100 * time.Millisecond
+
+// Follower reads what a Rolog writes as it is written, like tail -F. It is
+// returned by Follow.
+type Follower struct {
+	r    *Rolog
+	done chan struct{}
+	once sync.Once
+
+	// mu guards f and draining, which are only used by Read and Close.
+	mu sync.Mutex
+	f  File
+	// draining is set once the Rolog has moved on from f but f hasn't been
+	// read to its end since.
+	draining bool
+
+	// next holds the files the Rolog has made current since f, opened as
+	// soon as they were, so that none are missed however far behind the
+	// Follower is. It is guarded by the Rolog's mu.
+	next []File
+}
+
+// Follow returns a Follower positioned at the end of the current file. Reads
+// block until more is written, and carry on into the new current file after
+// each rotation or Reopen, but only once everything written to the old one has
+// been read, so nothing is missed or read twice. Only data that has reached
+// the file is seen, so a write buffer delays it until it is flushed. After a
+// copy-truncate rotation, whatever was written between the last read and the
+// truncation is only in the archive.
+//
+// Reads return io.EOF once the Follower is closed, or once the Rolog is closed
+// and everything written to it has been read. A Follower that is no longer
+// read should be closed, as it holds on to every file written since.
+func (r *Rolog) Follow() (*Follower, error) {
+	if r.passthrough != nil {
+		return nil, errors.New("cannot follow a pass-through log")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed() {
+		return nil, ErrClosed
+	}
+	f, err := r.fs.OpenFile(r.currentPath(), os.O_RDONLY, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open log")
+	}
+	if err := skipToEnd(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	fl := &Follower{r: r, done: make(chan struct{}), f: f}
+	if r.followers == nil {
+		r.followers = make(map[*Follower]struct{})
+	}
+	r.followers[fl] = struct{}{}
+	return fl, nil
+}
+
+// skipToEnd positions f at its end.
+func skipToEnd(f File) error {
+	fi, err := f.Stat()
+	if err != nil {
+		return errors.Wrap(err, "could not stat log")
+	}
+	if s, ok := f.(io.Seeker); ok {
+		_, err = s.Seek(fi.Size(), io.SeekStart)
+	} else {
+		_, err = io.CopyN(ioutil.Discard, f, fi.Size())
+	}
+	return errors.Wrap(err, "could not skip to the end of the log")
+}
+
+// follow opens the new current file for every Follower. It must be called
+// with mu held, as soon as the file is made current.
+func (r *Rolog) follow() {
+	for fl := range r.followers {
+		f, err := r.fs.OpenFile(r.currentPath(), os.O_RDONLY, 0)
+		if err != nil {
+			r.report(errors.Wrap(err, "could not open log for a follower"))
+			continue
+		}
+		fl.next = append(fl.next, f)
+	}
+}
+
+// Read satisfies io.Reader.
+func (f *Follower) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for {
+		n, err := f.read(p)
+		if n > 0 || err != nil {
+			return n, err
+		}
+
+		select {
+		case <-f.done:
+		case <-f.r.done:
+		case <-time.After(followPoll):
+		}
+	}
+}
+
+// read reads what is available, moving on to the next file once the current
+// one has been read to its end and replaced. It returns 0 and no error if
+// there is nothing to read yet.
+func (f *Follower) read(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for f.f != nil {
+		n, err := f.f.Read(p)
+		if n > 0 {
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, errors.Wrap(err, "could not read log")
+		}
+
+		if f.draining {
+			// f has been read to its end since the Rolog stopped writing to
+			// it.
+			f.f.Close()
+			f.f, f.draining = f.pop(), false
+			continue
+		}
+
+		f.r.mu.Lock()
+		swapped, closed := len(f.next) > 0, f.r.closed()
+		f.r.mu.Unlock()
+
+		switch {
+		case swapped:
+			// Writes may have reached f between the read and the swap, so
+			// read it once more before moving on.
+			f.draining = true
+		case closed:
+			// The last writes may have landed since the read.
+			if n, _ := f.f.Read(p); n > 0 {
+				return n, nil
+			}
+			return 0, io.EOF
+		default:
+			return 0, nil
+		}
+	}
+	return 0, io.EOF
+}
+
+// pop removes the oldest of the files opened for the Follower, returning nil
+// if it has been closed.
+func (f *Follower) pop() File {
+	f.r.mu.Lock()
+	defer f.r.mu.Unlock()
+
+	if len(f.next) == 0 {
+		return nil
+	}
+	next := f.next[0]
+	f.next = f.next[1:]
+	return next
+}
+
+// Close stops the Follower, unblocking any Read in progress.
+func (f *Follower) Close() error {
+	f.once.Do(func() { close(f.done) })
+
+	f.r.mu.Lock()
+	delete(f.r.followers, f)
+	for _, next := range f.next {
+		next.Close()
+	}
+	f.next = nil
+	f.r.mu.Unlock()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.f == nil {
+		return nil
+	}
+	err := f.f.Close()
+	f.f = nil
+	return err
+}
@@ -0,0 +1,140 @@
+package rolog
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestFollowContinuesAcrossRotations(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r, err := New(dir, "test", WithCompression(Gzip))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	r.Write([]byte("before\n"))
+	f, err := r.Follow()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer f.Close()
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		br := bufio.NewReader(f)
+		for {
+			line, err := br.ReadString('\n')
+			if err != nil {
+				return
+			}
+			lines <- line
+		}
+	}()
+	next := func() string {
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(5 * time.Second):
+			t.Errorf("Wanted a line")
+			t.FailNow()
+		}
+		return ""
+	}
+
+	want := []string{"one\n", "two\n", "three\n", "four\n", "five\n"}
+	r.Write([]byte(want[0]))
+	if got := next(); got != want[0] {
+		t.Errorf("Wanted %q, got %q", want[0], got)
+	}
+
+	// Lines written on either side of rotations, including ones the
+	// Follower hasn't caught up with, arrive once each and in order.
+	r.Write([]byte(want[1]))
+	r.Rotate()
+	r.Write([]byte(want[2]))
+	r.Rotate()
+	r.Write([]byte(want[3]))
+	r.Write([]byte(want[4]))
+	for _, w := range want[1:] {
+		if got := next(); got != w {
+			t.Errorf("Wanted %q, got %q", w, got)
+		}
+	}
+
+	r.Write([]byte("last\n"))
+	r.Close()
+	if got := next(); got != "last\n" {
+		t.Errorf("Wanted %q, got %q", "last\n", got)
+	}
+	select {
+	case line, ok := <-lines:
+		if ok {
+			t.Errorf("Wanted EOF after the Rolog was closed, got %q", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Wanted EOF after the Rolog was closed")
+	}
+}
+
+func TestFollowerCloseUnblocksRead(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r, err := New(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	f, err := r.Follow()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := f.Read(make([]byte, 16))
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	f.Close()
+
+	select {
+	case err := <-done:
+		if errors.Cause(err) != io.EOF {
+			t.Errorf("Wanted EOF, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Wanted Close to unblock Read")
+	}
+}
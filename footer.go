@@ -0,0 +1,58 @@
+package rolog
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ContinuationFooter is the format of the trailer written by WithFooter. It is
+// given the name of the current file and the time of the rotation.
+const ContinuationFooter = "=== continued in %s at %s ==="
+
+// WithFooter appends a line such as
+//
+//	=== continued in app.log at 2024-06-01T03:00:00Z ===
+//
+// to every file before it is archived, so someone reading a single archive
+// knows the stream carries on elsewhere.
+func WithFooter() Option {
+	return WithFooterFunc(func(current string, at time.Time) []byte {
+		return []byte(fmt.Sprintf(ContinuationFooter, current, at.Format(time.RFC3339)))
+	})
+}
+
+// WithFooterFunc is like WithFooter, but calls f for the trailer with the name
+// of the current file and the time of the rotation. A newline is added if the
+// result doesn't end with one. The trailer is written as-is, so a file whose
+// last write didn't end in a newline gets the trailer on the same line.
+func WithFooterFunc(f func(current string, at time.Time) []byte) Option {
+	return func(r *Rolog) {
+		r.footer = f
+	}
+}
+
+// writeFooter writes the trailer to the file about to be archived by a
+// rotation at at. Failures are reported rather than returned, so a trailer
+// can't hold up a rotation. It must be called with mu held.
+func (r *Rolog) writeFooter(at time.Time) {
+	if r.footer == nil {
+		return
+	}
+
+	b := r.footer(filepath.Base(r.currentPath()), at)
+	if len(b) == 0 {
+		return
+	}
+	if b[len(b)-1] != '\n' {
+		b = append(b[:len(b):len(b)], '\n')
+	}
+
+	n, err := r.output().Write(b)
+	r.size += int64(n)
+	if err != nil {
+		r.report(errors.Wrap(err, "could not write footer"))
+	}
+}
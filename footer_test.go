@@ -0,0 +1,52 @@
+package rolog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWithFooterEndsEveryArchive(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithFooter())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("first\n"))
+	clock.Advance(time.Hour)
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+
+	archives, err := r.Archives()
+	if err != nil || len(archives) != 1 {
+		t.Errorf("Wanted 1 archive, got %d (%v)", len(archives), err)
+		t.FailNow()
+	}
+
+	want := "first\n" + fmt.Sprintf(ContinuationFooter, "test.log", clock.Now().Format(time.RFC3339)) + "\n"
+	if got, _ := ioutil.ReadFile(archives[0].Path); string(got) != want {
+		t.Errorf("Wanted %q, got %q", want, got)
+	}
+	if archives[0].Size != int64(len(want)) {
+		t.Errorf("Wanted the footer counted in the archive size, got %d", archives[0].Size)
+	}
+}
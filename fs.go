@@ -0,0 +1,158 @@
+package rolog
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// FS is the filesystem a Rolog operates on. Every file operation performed by
+// rotation, retention and post-processing goes through it, so tests can run
+// against an in-memory implementation or inject failures such as ENOSPC.
+type FS interface {
+	OpenFile(name string, flag int, perm os.FileMode) (File, error)
+	Rename(oldpath, newpath string) error
+	Stat(name string) (os.FileInfo, error)
+	Remove(name string) error
+	ReadDir(dirname string) ([]os.FileInfo, error)
+}
+
+// File is an open file on an FS.
+type File interface {
+	io.ReadWriteCloser
+	Stat() (os.FileInfo, error)
+	Sync() error
+}
+
+// ChmodFS is implemented by filesystems that support permission bits. Options
+// that set modes are ignored on filesystems that don't implement it.
+type ChmodFS interface {
+	Chmod(name string, mode os.FileMode) error
+}
+
+// ChownFS is implemented by filesystems that support file ownership.
+type ChownFS interface {
+	Chown(name string, uid, gid int) error
+}
+
+// SymlinkFS is implemented by filesystems that support symbolic links.
+type SymlinkFS interface {
+	Symlink(oldname, newname string) error
+}
+
+// DirSyncFS is implemented by filesystems that can sync a directory, making
+// renames and file creations within it durable.
+type DirSyncFS interface {
+	SyncDir(name string) error
+}
+
+// MkdirFS is implemented by filesystems that can create directories. The log
+// directory is only created automatically on filesystems that implement it.
+type MkdirFS interface {
+	MkdirAll(path string, perm os.FileMode) error
+}
+
+// OSFS is the FS backed by the os package. It is the default.
+type OSFS struct{}
+
+// OpenFile satisfies FS.
+func (OSFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	return os.OpenFile(name, flag, perm)
+}
+
+// Rename satisfies FS.
+func (OSFS) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+// Stat satisfies FS.
+func (OSFS) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// Remove satisfies FS.
+func (OSFS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// ReadDir satisfies FS.
+func (OSFS) ReadDir(dirname string) ([]os.FileInfo, error) {
+	return ioutil.ReadDir(dirname)
+}
+
+// Chmod satisfies ChmodFS.
+func (OSFS) Chmod(name string, mode os.FileMode) error {
+	return os.Chmod(name, mode)
+}
+
+// MkdirAll satisfies MkdirFS.
+func (OSFS) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+// Symlink satisfies SymlinkFS.
+func (OSFS) Symlink(oldname, newname string) error {
+	return os.Symlink(oldname, newname)
+}
+
+// Readlink satisfies ReadlinkFS.
+func (OSFS) Readlink(name string) (string, error) {
+	return os.Readlink(name)
+}
+
+// currentFlag is how the current file is always opened. With O_APPEND every
+// write lands at the end of the file whatever its position, so external
+// appenders, or a second handle after a Reopen, can't overwrite each other's
+// lines.
+const currentFlag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+
+// openFile opens path on fs with the given flags. If perm is non-zero it is
+// applied to the file regardless of umask; otherwise new files get 0666 before
+// umask, as with os.Create.
+func openFile(fs FS, path string, flag int, perm os.FileMode) (File, error) {
+	mode := perm
+	if mode == 0 {
+		mode = 0666
+	}
+
+	f, err := fs.OpenFile(path, flag, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	if perm != 0 {
+		if err := chmod(fs, path, perm); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+// chmod sets the mode of path if fs supports it.
+func chmod(fs FS, path string, mode os.FileMode) error {
+	if c, ok := baseFS(fs).(ChmodFS); ok {
+		return c.Chmod(path, mode)
+	}
+	return nil
+}
+
+// syncDir syncs the directory dir if fs supports it.
+func syncDir(fs FS, dir string) error {
+	if d, ok := baseFS(fs).(DirSyncFS); ok {
+		return d.SyncDir(dir)
+	}
+	return nil
+}
+
+// chown sets the owner of path if fs supports it, leaving ids of -1 unchanged.
+func chown(fs FS, path string, uid, gid int) error {
+	if uid == -1 && gid == -1 {
+		return nil
+	}
+	if c, ok := baseFS(fs).(ChownFS); ok {
+		return c.Chown(path, uid, gid)
+	}
+	return nil
+}
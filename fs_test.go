@@ -0,0 +1,163 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// faultFS wraps OSFS and fails selected operations with a fixed error.
+type faultFS struct {
+	OSFS
+	renameErr error
+}
+
+func (f *faultFS) Rename(oldpath, newpath string) error {
+	if f.renameErr != nil {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: f.renameErr}
+	}
+	return f.OSFS.Rename(oldpath, newpath)
+}
+
+func TestRotateSurfacesFSErrors(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	fs := &faultFS{}
+	r, err := New(dir, "test", WithFS(fs))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	fs.renameErr = syscall.EPERM
+	err = r.Rotate()
+	if err == nil {
+		t.Errorf("Wanted an error")
+		t.FailNow()
+	}
+
+	lerr, ok := errors.Cause(err).(*os.LinkError)
+	if !ok || lerr.Err != syscall.EPERM {
+		t.Errorf("Wanted EPERM, got %q", err)
+	}
+}
+
+func TestFailedRenameLeavesRologWritable(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	fs := &faultFS{}
+	r, err := New(dir, "test", WithFS(fs), WithMaxSize(10))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("12345678\n"))
+
+	fs.renameErr = syscall.EPERM
+	if _, err := r.Write([]byte("12345678\n")); err == nil {
+		t.Errorf("Wanted the size-triggered rotation to fail")
+	}
+
+	fs.renameErr = nil
+	if _, err := r.Write([]byte("12345678\n")); err != nil {
+		t.Errorf("Wanted writes to recover after the failed rotation, got %q", err)
+	}
+}
+
+// syncFS wraps OSFS and counts Sync calls on the files it opens.
+type syncFS struct {
+	OSFS
+	syncs int
+	delay time.Duration
+}
+
+type syncFile struct {
+	File
+	fs *syncFS
+}
+
+func (f *syncFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	file, err := f.OSFS.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &syncFile{File: file, fs: f}, nil
+}
+
+func (f *syncFile) Sync() error {
+	f.fs.syncs++
+	time.Sleep(f.fs.delay)
+	return f.File.Sync()
+}
+
+// gateFS wraps OSFS and holds every write to the files it opens until release
+// is closed, signalling entered as each write starts.
+type gateFS struct {
+	OSFS
+	entered chan struct{}
+	release chan struct{}
+}
+
+type gateFile struct {
+	File
+	fs *gateFS
+}
+
+func newGateFS() *gateFS {
+	return &gateFS{entered: make(chan struct{}, 64), release: make(chan struct{})}
+}
+
+func (f *gateFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	file, err := f.OSFS.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &gateFile{File: file, fs: f}, nil
+}
+
+func (f *gateFile) Write(p []byte) (int, error) {
+	f.fs.entered <- struct{}{}
+	<-f.fs.release
+	return f.File.Write(p)
+}
+
+// slowRenameFS wraps OSFS and holds every rename until release is closed,
+// signalling entered as each rename starts.
+type slowRenameFS struct {
+	OSFS
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (f *slowRenameFS) Rename(oldpath, newpath string) error {
+	f.entered <- struct{}{}
+	<-f.release
+	return f.OSFS.Rename(oldpath, newpath)
+}
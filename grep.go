@@ -0,0 +1,62 @@
+package rolog
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// GrepOptions tunes a Grep. The files searched are chosen as for Lines.
+type GrepOptions LineOptions
+
+// Match is a line found by Grep.
+type Match struct {
+	// Path is the file the line was found in.
+	Path string
+	// Line is the number of the line within the file, counting from 1.
+	Line int
+	// Text is the line without its trailing newline.
+	Text string
+	// Err is set, instead of Line and Text, when the file at Path couldn't
+	// be searched.
+	Err error
+}
+
+// Grep searches the current file and the archives for lines matching the
+// regular expression pattern, decompressing archives as needed, and streams
+// the matches on the returned channel, which is closed once the search is
+// done or ctx is cancelled. A file that can't be read, such as an encrypted
+// archive, is reported by a Match with Err set, and the search carries on
+// with the next one. The current file is searched as it was when Grep reached
+// it, so writes carrying on meanwhile don't hold the search up.
+func (r *Rolog) Grep(ctx context.Context, pattern string, opts GrepOptions) (<-chan Match, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid pattern")
+	}
+
+	lines, err := r.Lines(ctx, LineOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make(chan Match)
+	go func() {
+		defer close(matches)
+		for line := range lines {
+			if line.Err == nil && !re.MatchString(line.Text) {
+				continue
+			}
+			select {
+			case matches <- Match{Path: line.Path, Line: line.Number, Text: line.Text, Err: line.Err}:
+			case <-ctx.Done():
+				// Lines stops sending once it sees ctx is done too.
+				for range lines {
+				}
+				return
+			}
+		}
+	}()
+	return matches, nil
+}
@@ -0,0 +1,85 @@
+package rolog
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGrepSearchesArchivesAndCurrentFile(t *testing.T) {
+	r, clk, cleanup := newHistory(t)
+	defer cleanup()
+
+	collect := func(opts GrepOptions) []Match {
+		matches, err := r.Grep(context.Background(), "ERROR$", opts)
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		var got []Match
+		for m := range matches {
+			if m.Err != nil {
+				t.Errorf("unexpected error: %q", m.Err)
+			}
+			got = append(got, m)
+		}
+		return got
+	}
+
+	got := collect(GrepOptions{})
+	want := []string{"first two ERROR", "second two ERROR", "third two ERROR"}
+	if len(got) != len(want) {
+		t.Errorf("Wanted %d matches, got %+v", len(want), got)
+		t.FailNow()
+	}
+	for i, m := range got {
+		if m.Text != want[i] || m.Line != 2 {
+			t.Errorf("Wanted %q on line 2, got %q on line %d", want[i], m.Text, m.Line)
+		}
+	}
+	if filepath.Ext(got[0].Path) != ".gz" || got[2].Path != r.CurrentPath() {
+		t.Errorf("Wanted matches from an archive and the current file, got %s and %s", got[0].Path, got[2].Path)
+	}
+
+	got = collect(GrepOptions{NewestFirst: true})
+	if len(got) != 3 || got[0].Text != want[2] || got[2].Text != want[0] {
+		t.Errorf("Wanted newest first, got %+v", got)
+	}
+
+	got = collect(GrepOptions{Since: clk.Now().Add(-30 * time.Minute)})
+	if len(got) != 1 || got[0].Text != want[2] {
+		t.Errorf("Wanted only the current file searched, got %+v", got)
+	}
+}
+
+func TestGrepStopsWhenCancelled(t *testing.T) {
+	r, _, cleanup := newHistory(t)
+	defer cleanup()
+
+	if _, err := r.Grep(context.Background(), "(", GrepOptions{}); err == nil {
+		t.Errorf("Wanted an invalid pattern to be rejected")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	matches, err := r.Grep(ctx, "", GrepOptions{})
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	<-matches
+	cancel()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-matches:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Errorf("Wanted the matches closed after cancelling")
+			return
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package rolog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrHandedOff is returned by Rotate once the Rolog has handed its log off to
+// another process.
+var ErrHandedOff = errors.New("log has been handed off to another process")
+
+// HandoffFileFormat is the name of the file through which a process asks for
+// the log under WithHandoff, in the log directory. It is given the name of the
+// Rolog.
+const HandoffFileFormat = ".%s.handoff"
+
+// DefaultHandoffWait is how long New waits for a handoff under WithHandoff,
+// unless told otherwise.
+const DefaultHandoffWait = 10 * time.Second
+
+const (
+	// handoffPoll is how often a running Rolog looks for a handoff request.
+	handoffPoll = 250 * time.Millisecond
+	// handoffRetry is how often New tries the lock while waiting for a
+	// handoff.
+	handoffRetry = 50 * time.Millisecond
+)
+
+// WithHandoff takes the lock of WithLock, but hands it over to the next
+// process instead of failing it, for zero-downtime restarts where the new
+// process starts before the old one exits. Finding the log locked, New asks
+// the holder to give it up by creating a file named according to
+// HandoffFileFormat, and waits up to wait for it, or DefaultHandoffWait if
+// wait isn't positive, before failing with ErrLocked.
+//
+// The running loop of the old process notices the request, drains and syncs
+// the current file, and stops rotating, so Rotate returns ErrHandedOff from
+// then on. It leaves the time of its next scheduled rotation for the new
+// process and releases the lock. The new process carries on with the current
+// file rather than archiving it, and makes its first scheduled rotation when
+// the old one would have, so the schedule goes on as if nothing had happened.
+// Anything the old process writes while it winds down is appended to the file
+// it has open, and it should be closed once done with. Only a running Rolog
+// hands off, and the FS must implement LockFS.
+func WithHandoff(wait time.Duration) Option {
+	return func(r *Rolog) {
+		if wait <= 0 {
+			wait = DefaultHandoffWait
+		}
+		r.lock = true
+		r.handoffWait = wait
+	}
+}
+
+// takeOver takes the lock for a Rolog writing to dir, asking the holder to
+// hand off if need be. It must be called before the current file is touched.
+func (r *Rolog) takeOver(dir string) error {
+	r.handoffPath = filepath.Join(dir, fmt.Sprintf(HandoffFileFormat, r.name))
+
+	err := r.acquireLock(dir)
+	if errors.Cause(err) != ErrLocked {
+		if err == nil {
+			// left by a process that asked and never got the lock
+			r.fs.Remove(r.handoffPath)
+		}
+		return err
+	}
+
+	r.debugf("log locked, asking for a handoff")
+	f, err := openFile(r.fs, r.handoffPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0)
+	if err != nil {
+		return errors.Wrap(err, "could not ask for a handoff")
+	}
+	f.Close()
+	defer r.fs.Remove(r.handoffPath)
+
+	deadline := time.Now().Add(r.handoffWait)
+	for {
+		time.Sleep(handoffRetry)
+		err = r.acquireLock(dir)
+		if errors.Cause(err) != ErrLocked {
+			break
+		}
+		if time.Now().After(deadline) {
+			return errors.Wrapf(err, "no handoff within %s", r.handoffWait)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	r.handedIn = true
+	if f, err := r.fs.OpenFile(r.handoffPath, os.O_RDONLY, 0); err == nil {
+		b, _ := ioutil.ReadAll(f)
+		f.Close()
+		if next, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(b))); err == nil {
+			r.resumeAt = next
+		}
+	}
+	r.debugf("log handed off, next rotation at %s", r.resumeAt.Format(time.RFC3339))
+	return nil
+}
+
+// handOff gives up the log if another process has asked for it, and reports
+// whether it did.
+func (r *Rolog) handOff() bool {
+	if _, err := r.fs.Stat(r.handoffPath); err != nil {
+		return false
+	}
+
+	r.swapMu.Lock()
+	defer r.swapMu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// wait for the processing of the last rotation, so the next process
+	// doesn't take it for an interrupted one
+	r.rotMu.Lock()
+	defer r.rotMu.Unlock()
+
+	if r.closed() || r.handedOff {
+		return false
+	}
+
+	r.flushRepeats()
+	if err := r.drain(); err != nil {
+		r.report(err)
+	}
+	if err := r.sync(); err != nil {
+		r.report(errors.Wrap(err, "could not sync log"))
+	}
+
+	f, err := r.fs.OpenFile(r.handoffPath, os.O_WRONLY|os.O_TRUNC, 0)
+	if err == nil {
+		if !r.next.IsZero() {
+			_, err = f.Write([]byte(r.next.Format(time.RFC3339Nano)))
+		}
+		f.Close()
+	}
+	if err != nil {
+		// the next process starts its schedule afresh
+		r.report(errors.Wrap(err, "could not pass on the rotation schedule"))
+	}
+
+	r.debugf("handing the log off to another process")
+	r.handedOff = true
+	r.unlock.Close()
+	r.unlock = nil
+	return true
+}
+
+// resume returns when the first scheduled rotation is due if it was handed
+// over by another process, and forgets it.
+func (r *Rolog) resume() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	at := r.resumeAt
+	r.resumeAt = time.Time{}
+	return at
+}
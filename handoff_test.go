@@ -0,0 +1,125 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestHandoffContinuesTheLog(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	clock := newFakeClock()
+	old, err := New(dir, "test", WithClock(clock), WithHandoff(5*time.Second))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer old.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	old.Run(ctx)
+	for clock.numTickers() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	next := old.NextRotation()
+	old.Write([]byte("a\n"))
+	clock.Advance(20 * time.Minute)
+
+	type result struct {
+		r   *Rolog
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		r, err := New(dir, "test", WithClock(clock), WithHandoff(5*time.Second))
+		done <- result{r, err}
+	}()
+
+	var res result
+	deadline := time.Now().Add(5 * time.Second)
+wait:
+	for {
+		select {
+		case res = <-done:
+			break wait
+		case <-time.After(10 * time.Millisecond):
+			if time.Now().After(deadline) {
+				t.Errorf("Wanted the log handed off")
+				t.FailNow()
+			}
+			clock.Advance(handoffPoll)
+		}
+	}
+	if res.err != nil {
+		t.Errorf("unexpected error: %q", res.err)
+		t.FailNow()
+	}
+	r := res.r
+	defer r.Close()
+
+	if err := old.Rotate(); errors.Cause(err) != ErrHandedOff {
+		t.Errorf("Wanted ErrHandedOff rotating the old log, got %v", err)
+	}
+
+	r.Write([]byte("b\n"))
+	got, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if string(got) != "a\nb\n" {
+		t.Errorf("Wanted the current file continued, got %q", got)
+	}
+
+	rctx, rcancel := context.WithCancel(context.Background())
+	defer rcancel()
+	r.Run(rctx)
+	deadline = time.Now().Add(5 * time.Second)
+	for !r.NextRotation().Equal(next) {
+		if time.Now().After(deadline) {
+			t.Errorf("Wanted the next rotation at %s, got %s", next, r.NextRotation())
+			t.FailNow()
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHandoffTimesOut(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	// Not running, so never hands off.
+	r, err := New(dir, "test", WithHandoff(time.Second))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	if _, err := New(dir, "test", WithHandoff(100*time.Millisecond)); errors.Cause(err) != ErrLocked {
+		t.Errorf("Wanted ErrLocked without a handoff, got %v", err)
+	}
+}
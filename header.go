@@ -0,0 +1,41 @@
+package rolog
+
+import "github.com/pkg/errors"
+
+// WithHeader writes header at the top of every new current file, both when
+// the Rolog is created and after each rotation, for provenance information
+// such as the hostname or version. A newline is added if header doesn't end
+// with one. Files that already have content, such as with WithAppend, are left
+// alone.
+func WithHeader(header string) Option {
+	b := []byte(header)
+	return WithHeaderFunc(func() []byte { return b })
+}
+
+// WithHeaderFunc is like WithHeader, but calls f for the header each time a new
+// file is started, for dynamic values such as the start time.
+func WithHeaderFunc(f func() []byte) Option {
+	return func(r *Rolog) {
+		r.header = f
+	}
+}
+
+// writeHeader writes the header to the current file if it is empty. It must be
+// called with mu held.
+func (r *Rolog) writeHeader() error {
+	if r.header == nil || r.size > 0 {
+		return nil
+	}
+
+	b := r.header()
+	if len(b) == 0 {
+		return nil
+	}
+	if b[len(b)-1] != '\n' {
+		b = append(b[:len(b):len(b)], '\n')
+	}
+
+	n, err := r.output().Write(b)
+	r.size += int64(n)
+	return errors.Wrap(err, "could not write header")
+}
@@ -0,0 +1,58 @@
+package rolog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithHeaderFuncStartsEveryFile(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	var (
+		clock = newFakeClock()
+		files int
+	)
+	r, err := New(dir, "test", WithClock(clock), WithHeaderFunc(func() []byte {
+		files++
+		return []byte(fmt.Sprintf("# file %d", files))
+	}))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("first\n"))
+	clock.Advance(time.Hour)
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+	r.Write([]byte("second\n"))
+
+	archives, err := r.Archives()
+	if err != nil || len(archives) != 1 {
+		t.Errorf("Wanted 1 archive, got %d (%v)", len(archives), err)
+		t.FailNow()
+	}
+	if got, _ := ioutil.ReadFile(archives[0].Path); string(got) != "# file 1\nfirst\n" {
+		t.Errorf("Wanted the header at the top of the archive, got %q", got)
+	}
+	if got, _ := ioutil.ReadFile(filepath.Join(dir, "test.log")); string(got) != "# file 2\nsecond\n" {
+		t.Errorf("Wanted the header at the top of the new file, got %q", got)
+	}
+}
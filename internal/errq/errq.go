@@ -0,0 +1,20 @@
+// Package errq delivers errors on the channels that rolog and its subpackages
+// hand out from their Err methods.
+package errq
+
+// Deliver sends err on ch without blocking, discarding the oldest pending
+// error if ch is full.
+func Deliver(ch chan error, err error) {
+	for {
+		select {
+		case ch <- err:
+			return
+		default:
+		}
+
+		select {
+		case <-ch:
+		default:
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package rolog
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// journalSocket is where journald listens for the native protocol.
+var journalSocket = "/run/systemd/journal/socket"
+
+// WithJournald mirrors every line written to the current file to the systemd
+// journal, using its native protocol, tagged with identifier as
+// SYSLOG_IDENTIFIER, so that both the journal and the rotating files are
+// populated without the program logging twice. Each line's PRIORITY is chosen
+// by priority, or by JournalPriority if it is nil.
+//
+// On hosts where journald isn't running, WithJournald does nothing, so the
+// same configuration can be used everywhere. Otherwise the mirror behaves like
+// WithSyslog: it never blocks or fails a write, discards lines while it is
+// behind, and reports delivery failures on Err.
+func WithJournald(identifier string, priority func(line []byte) int) Option {
+	return func(r *Rolog) {
+		if _, err := os.Stat(journalSocket); err != nil {
+			return
+		}
+		if priority == nil {
+			priority = JournalPriority
+		}
+		r.addMirror("journald", &journalSink{
+			path:       journalSocket,
+			identifier: identifier,
+			priority:   priority,
+		})
+	}
+}
+
+// journalLevels maps level names found in log lines to syslog priorities.
+var journalLevels = map[string]int{
+	"emerg":    0,
+	"panic":    0,
+	"alert":    1,
+	"crit":     2,
+	"critical": 2,
+	"fatal":    2,
+	"err":      3,
+	"error":    3,
+	"warn":     4,
+	"warning":  4,
+	"notice":   5,
+	"info":     6,
+	"debug":    7,
+	"trace":    7,
+}
+
+// JournalPriority guesses the syslog priority of line, from 0 for emergencies
+// to 7 for debugging, from the first level name among its leading words, such
+// as "ERROR", "level=warn" or "[debug]". A "<N>" prefix, as understood by
+// sd-daemon, takes precedence. Lines without a level are informational.
+func JournalPriority(line []byte) int {
+	if p, ok := linePriority(line); ok {
+		return p
+	}
+	return 6
+}
+
+// linePriority is JournalPriority, reporting whether line names a level.
+func linePriority(line []byte) (int, bool) {
+	if len(line) >= 3 && line[0] == '<' && line[2] == '>' && line[1] >= '0' && line[1] <= '7' {
+		return int(line[1] - '0'), true
+	}
+
+	if len(line) > 128 {
+		line = line[:128]
+	}
+	words := bytes.FieldsFunc(bytes.ToLower(line), func(c rune) bool {
+		return c < 'a' || c > 'z'
+	})
+	for _, w := range words {
+		if p, ok := journalLevels[string(w)]; ok {
+			return p, true
+		}
+	}
+	return 0, false
+}
+
+// journalSink is the mirrorSink for WithJournald.
+type journalSink struct {
+	path       string
+	identifier string
+	priority   func([]byte) int
+
+	conn net.Conn
+	buf  []byte
+}
+
+// send satisfies mirrorSink.
+func (s *journalSink) send(line []byte, _ time.Time) error {
+	if len(line) == 0 {
+		return nil
+	}
+	if s.conn == nil {
+		conn, err := net.DialTimeout("unixgram", s.path, mirrorTimeout)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	msg := append(s.buf[:0], "PRIORITY="...)
+	msg = strconv.AppendInt(msg, int64(s.priority(line)), 10)
+	if s.identifier != "" {
+		msg = append(msg, "\nSYSLOG_IDENTIFIER="...)
+		msg = append(msg, s.identifier...)
+	}
+	msg = append(msg, "\nMESSAGE="...)
+	msg = append(msg, line...)
+	msg = append(msg, '\n')
+	s.buf = msg
+
+	s.conn.SetWriteDeadline(time.Now().Add(mirrorTimeout))
+	_, err := s.conn.Write(msg)
+	return err
+}
+
+// reset satisfies mirrorSink.
+func (s *journalSink) reset() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
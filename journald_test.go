@@ -0,0 +1,76 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournalPriority(t *testing.T) {
+	cases := []struct {
+		line string
+		want int
+	}{
+		{"plain message", 6},
+		{"ERROR could not connect", 3},
+		{"time=now level=warn msg=slow", 4},
+		{`{"level":"debug","msg":"x"}`, 7},
+		{"[fatal] giving up", 2},
+		{"<5>notice me", 5},
+		{"an error happened", 3},
+	}
+
+	for _, c := range cases {
+		if got := JournalPriority([]byte(c.line)); got != c.want {
+			t.Errorf("Wanted priority %d for %q, got %d", c.want, c.line, got)
+		}
+	}
+}
+
+func TestJournaldMirrorsWrites(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	sock := filepath.Join(dir, "journal.sock")
+	journal, err := net.ListenPacket("unixgram", sock)
+	if err != nil {
+		t.Skipf("unix datagram sockets unavailable: %v", err)
+	}
+	defer journal.Close()
+
+	defer func(prev string) { journalSocket = prev }(journalSocket)
+	journalSocket = sock
+
+	r, err := New(dir, "test", WithJournald("app", nil))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	r.Write([]byte("WARN disk nearly full\n"))
+
+	journal.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1024)
+	n, _, err := journal.ReadFrom(buf)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	want := "PRIORITY=4\nSYSLOG_IDENTIFIER=app\nMESSAGE=WARN disk nearly full\n"
+	if got := string(buf[:n]); got != want {
+		t.Errorf("Wanted %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,89 @@
+package rolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+	"unicode/utf8"
+)
+
+// WithJSONLines wraps every line written through the Rolog in a JSON object
+// of the form {"ts":"<RFC 3339 timestamp>","msg":"<line>"}, so the files can be
+// ingested by tools such as Loki or Elasticsearch without a parser. It adds a
+// JSONLinesEncoder to the Rolog's encoders.
+func WithJSONLines() Option {
+	return WithEncoder(JSONLinesEncoder{})
+}
+
+// JSONLinesEncoder is an Encoder that wraps every line in a JSON object with
+// the time of the write and the line as its message. Lines that are already
+// JSON objects are written unchanged. A trailing partial line is encoded as a
+// line of its own.
+type JSONLinesEncoder struct{}
+
+// Encode satisfies Encoder.
+func (JSONLinesEncoder) Encode(dst, p []byte, now time.Time) []byte {
+	for len(p) > 0 {
+		line := p
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			line, p = p[:i], p[i+1:]
+		} else {
+			p = nil
+		}
+		dst = appendJSONLine(dst, now, line)
+	}
+	return dst
+}
+
+// appendJSONLine appends the JSON-lines encoding of line to buf.
+func appendJSONLine(buf []byte, now time.Time, line []byte) []byte {
+	if len(line) > 0 && line[0] == '{' && json.Valid(line) {
+		buf = append(buf, line...)
+		return append(buf, '\n')
+	}
+
+	buf = append(buf, `{"ts":"`...)
+	buf = now.AppendFormat(buf, time.RFC3339Nano)
+	buf = append(buf, `","msg":`...)
+	buf = appendJSONString(buf, line)
+	return append(buf, "}\n"...)
+}
+
+// hex is used to escape control characters.
+const hex = "0123456789abcdef"
+
+// appendJSONString appends s to buf as a quoted JSON string. Invalid UTF-8 is
+// replaced with U+FFFD, as encoding/json does.
+func appendJSONString(buf, s []byte) []byte {
+	buf = append(buf, '"')
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			switch {
+			case b == '"' || b == '\\':
+				buf = append(buf, '\\', b)
+			case b == '\n':
+				buf = append(buf, '\\', 'n')
+			case b == '\r':
+				buf = append(buf, '\\', 'r')
+			case b == '\t':
+				buf = append(buf, '\\', 't')
+			case b < 0x20:
+				buf = append(buf, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xf])
+			default:
+				buf = append(buf, b)
+			}
+			i++
+			continue
+		}
+
+		c, size := utf8.DecodeRune(s[i:])
+		if c == utf8.RuneError && size == 1 {
+			buf = append(buf, `�`...)
+		} else {
+			buf = append(buf, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(buf, '"')
+}
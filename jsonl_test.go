@@ -0,0 +1,65 @@
+package rolog
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONLines(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithJSONLines())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("plain \"quoted\"\ttext\n{\"level\":\"info\"}\n"))
+	r.Write([]byte("bad \xff byte"))
+
+	got, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(got), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Errorf("Wanted 3 lines, got %q", got)
+		t.FailNow()
+	}
+
+	want := []map[string]string{
+		{"ts": clock.Now().Format(time.RFC3339Nano), "msg": "plain \"quoted\"\ttext"},
+		{"level": "info"},
+		{"ts": clock.Now().Format(time.RFC3339Nano), "msg": "bad � byte"},
+	}
+	for i, line := range lines {
+		var rec map[string]string
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Errorf("Wanted valid JSON, got %q: %v", line, err)
+			continue
+		}
+		for k, v := range want[i] {
+			if rec[k] != v {
+				t.Errorf("Wanted %s=%q in line %d, got %q", k, v, i, rec[k])
+			}
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Package kafkasink publishes the output of a Rolog to a Kafka topic, so that
+// it can feed a streaming pipeline as well as the files.
+package kafkasink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/haleyrc/rolog"
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+)
+
+// maxLine is the longest line of an archive that can be published.
+const maxLine = 1 << 20
+
+// Config says where and how a Sink publishes.
+type Config struct {
+	// Brokers are the addresses of the Kafka brokers to bootstrap from.
+	Brokers []string
+	// Topic is the topic messages are published to.
+	Topic string
+	// Archives makes the Sink publish each archive once its rotation has
+	// completed, as a batch with a message per line, instead of publishing
+	// every write as it happens.
+	Archives bool
+	// BatchSize and BatchTimeout bound how many messages are collected, and
+	// for how long, before a batch is sent. They default to kafka-go's
+	// defaults of 100 messages and a second.
+	BatchSize    int
+	BatchTimeout time.Duration
+}
+
+// Sink publishes a Rolog's writes or archives to Kafka. Publishing is
+// asynchronous and never blocks a write; messages that can't be delivered are
+// reported through the Rolog's Err channel, Metrics and Observers. Each
+// archive published is recorded in the Rolog's audit trail once all of its
+// messages have been delivered or failed.
+type Sink struct {
+	r    *rolog.Rolog
+	w    *kafka.Writer
+	stop func()
+
+	// uploads are the archives being published, by path
+	mu      sync.Mutex
+	uploads map[string]*upload
+
+	// publish sends messages, and is replaced in tests
+	publish func(msgs ...kafka.Message) error
+}
+
+// upload is an archive being published.
+type upload struct {
+	// left is how many of its messages are yet to be delivered, and err
+	// the first failure delivering them
+	left int
+	err  error
+}
+
+// New returns a Sink publishing the output of r as configured by c, and
+// attaches it to r.
+func New(r *rolog.Rolog, c Config) (*Sink, error) {
+	if len(c.Brokers) == 0 {
+		return nil, errors.New("no brokers configured")
+	}
+	if c.Topic == "" {
+		return nil, errors.New("no topic configured")
+	}
+
+	s := &Sink{r: r}
+	s.w = &kafka.Writer{
+		Addr:         kafka.TCP(c.Brokers...),
+		Topic:        c.Topic,
+		BatchSize:    c.BatchSize,
+		BatchTimeout: c.BatchTimeout,
+		Async:        true,
+		Completion:   s.completed,
+	}
+	s.publish = func(msgs ...kafka.Message) error {
+		return s.w.WriteMessages(context.Background(), msgs...)
+	}
+	s.attach(c.Archives)
+	return s, nil
+}
+
+// attach starts feeding the Sink from its Rolog.
+func (s *Sink) attach(archives bool) {
+	if archives {
+		s.stop = s.r.Observe(rolog.ObserverFuncs{Rotate: s.rotated})
+		return
+	}
+	s.stop = func() {}
+	s.r.AddTee(s)
+}
+
+// Write satisfies io.Writer, publishing p as a single message without its
+// trailing newline. It is called by the Rolog for every write.
+func (s *Sink) Write(p []byte) (int, error) {
+	value := append([]byte(nil), bytes.TrimSuffix(p, []byte("\n"))...)
+	if err := s.publish(kafka.Message{Value: value}); err != nil {
+		s.r.Report(errors.Wrap(err, "could not publish to kafka"))
+	}
+	return len(p), nil
+}
+
+// rotated publishes the archive of a completed rotation.
+func (s *Sink) rotated(e rolog.RotationEvent) {
+	if e.NewPath == "" {
+		return
+	}
+	if err := s.publishArchive(e.NewPath); err != nil {
+		s.r.Report(errors.Wrapf(err, "could not publish %s to kafka", e.NewPath))
+	}
+}
+
+// publishArchive publishes the archive at path, a message per line, with the
+// archive's path as the key.
+func (s *Sink) publishArchive(path string) error {
+	rc, err := rolog.OpenArchive(rolog.ArchiveInfo{Path: path})
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	var (
+		key  = []byte(path)
+		msgs []kafka.Message
+	)
+	sc := bufio.NewScanner(rc)
+	sc.Buffer(nil, maxLine)
+	for sc.Scan() {
+		msgs = append(msgs, kafka.Message{Key: key, Value: append([]byte(nil), sc.Bytes()...)})
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	if s.uploads == nil {
+		s.uploads = make(map[string]*upload)
+	}
+	s.uploads[path] = &upload{left: len(msgs)}
+	s.mu.Unlock()
+
+	if err := s.publish(msgs...); err != nil {
+		s.mu.Lock()
+		delete(s.uploads, path)
+		s.mu.Unlock()
+		s.r.RecordUpload(path, s.w.Topic, err)
+		return err
+	}
+	return nil
+}
+
+// completed is called by the writer with the outcome of each batch.
+func (s *Sink) completed(msgs []kafka.Message, err error) {
+	if err != nil {
+		s.r.Report(errors.Wrapf(err, "could not deliver %d messages to kafka", len(msgs)))
+	}
+
+	s.mu.Lock()
+	var done map[string]error
+	for _, m := range msgs {
+		path := string(m.Key)
+		u := s.uploads[path]
+		if u == nil {
+			continue
+		}
+		if u.err == nil {
+			u.err = err
+		}
+		if u.left--; u.left == 0 {
+			if done == nil {
+				done = make(map[string]error)
+			}
+			done[path] = u.err
+			delete(s.uploads, path)
+		}
+	}
+	s.mu.Unlock()
+
+	for path, err := range done {
+		s.r.RecordUpload(path, s.w.Topic, err)
+	}
+}
+
+// Close stops publishing archives and waits for pending messages to be
+// delivered. In write mode the Sink stays attached to the Rolog, so it should
+// be closed after the Rolog.
+func (s *Sink) Close() error {
+	s.stop()
+	return s.w.Close()
+}
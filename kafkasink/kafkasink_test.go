@@ -0,0 +1,89 @@
+package kafkasink
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/haleyrc/rolog"
+	"github.com/segmentio/kafka-go"
+)
+
+// recorder collects published messages in place of a broker.
+type recorder struct {
+	mu   sync.Mutex
+	msgs []kafka.Message
+}
+
+func (rec *recorder) publish(msgs ...kafka.Message) error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	rec.msgs = append(rec.msgs, msgs...)
+	return nil
+}
+
+func (rec *recorder) values() []string {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	var vs []string
+	for _, m := range rec.msgs {
+		vs = append(vs, string(m.Value))
+	}
+	return vs
+}
+
+func TestSinkPublishes(t *testing.T) {
+	for _, archives := range []bool{false, true} {
+		dir, err := ioutil.TempDir(".", "tmp")
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+
+		r, err := rolog.New(dir, "test")
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+
+		rec := &recorder{}
+		s := &Sink{r: r, w: &kafka.Writer{}, publish: rec.publish}
+		s.attach(archives)
+
+		r.Write([]byte("first\n"))
+		r.Write([]byte("second\n"))
+		if len(rec.values()) != 0 && archives {
+			t.Errorf("Wanted nothing published before rotation, got %q", rec.values())
+		}
+		if err := r.Rotate(); err != nil {
+			t.Errorf("unexpected error: %q", err)
+		}
+
+		deadline := time.Now().Add(5 * time.Second)
+		for len(rec.values()) < 2 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if got := rec.values(); len(got) != 2 || got[0] != "first" || got[1] != "second" {
+			t.Errorf("Wanted both lines published with archives=%t, got %q", archives, got)
+		}
+
+		s.Close()
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}
+}
+
+func TestNewRequiresBrokersAndTopic(t *testing.T) {
+	if _, err := New(nil, Config{Topic: "logs"}); err == nil {
+		t.Errorf("Wanted an error without brokers")
+	}
+	if _, err := New(nil, Config{Brokers: []string{"localhost:9092"}}); err == nil {
+		t.Errorf("Wanted an error without a topic")
+	}
+}
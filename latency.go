@@ -0,0 +1,72 @@
+package rolog
+
+import (
+	"sort"
+	"time"
+)
+
+// pauseSamples is how many of the most recent rotation pauses are kept for
+// percentiles.
+const pauseSamples = 256
+
+// PauseStats summarizes how long rotations have blocked writers. Count, Min,
+// Max and Avg cover every rotation since New; the percentiles cover the most
+// recent ones only.
+type PauseStats struct {
+	Count         int
+	Min, Max, Avg time.Duration
+	P50, P99      time.Duration
+}
+
+// pauseTracker accumulates rotation pauses. It is guarded by mu.
+type pauseTracker struct {
+	samples  [pauseSamples]time.Duration
+	count    int
+	min, max time.Duration
+	sum      time.Duration
+}
+
+// record adds a pause of d.
+func (t *pauseTracker) record(d time.Duration) {
+	if t.count == 0 || d < t.min {
+		t.min = d
+	}
+	if d > t.max {
+		t.max = d
+	}
+	t.sum += d
+	t.samples[t.count%pauseSamples] = d
+	t.count++
+}
+
+// stats summarizes the pauses recorded so far.
+func (t *pauseTracker) stats() PauseStats {
+	if t.count == 0 {
+		return PauseStats{}
+	}
+
+	n := t.count
+	if n > pauseSamples {
+		n = pauseSamples
+	}
+	recent := append([]time.Duration(nil), t.samples[:n]...)
+	sort.Slice(recent, func(i, j int) bool { return recent[i] < recent[j] })
+
+	return PauseStats{
+		Count: t.count,
+		Min:   t.min,
+		Max:   t.max,
+		Avg:   t.sum / time.Duration(t.count),
+		P50:   percentile(recent, 50),
+		P99:   percentile(recent, 99),
+	}
+}
+
+// percentile returns the p-th percentile of sorted, using the nearest rank.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	i := (len(sorted)*p + 99) / 100
+	if i < 1 {
+		i = 1
+	}
+	return sorted[i-1]
+}
@@ -0,0 +1,27 @@
+package rolog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPauseTrackerSummarizesRecentPauses(t *testing.T) {
+	var tr pauseTracker
+	for i := 1; i <= pauseSamples+100; i++ {
+		tr.record(time.Duration(i) * time.Millisecond)
+	}
+
+	got := tr.stats()
+	if got.Count != pauseSamples+100 || got.Min != time.Millisecond || got.Max != 356*time.Millisecond {
+		t.Errorf("Wanted every pause counted, got %+v", got)
+	}
+	if want := 178500 * time.Microsecond; got.Avg != want {
+		t.Errorf("Wanted an average of %v, got %v", want, got.Avg)
+	}
+
+	// Only the most recent 256 pauses, 101ms to 356ms, count towards the
+	// percentiles.
+	if got.P50 != 228*time.Millisecond || got.P99 != 354*time.Millisecond {
+		t.Errorf("Wanted p50 228ms and p99 354ms, got %v and %v", got.P50, got.P99)
+	}
+}
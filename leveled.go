@@ -0,0 +1,203 @@
+package rolog
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Level identifies one of the streams of a Leveled logger.
+type Level int
+
+// The levels of a Leveled logger, from least to most severe.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+	numLevels
+)
+
+// String returns the lower-case name of the level, as used in filenames.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// LineLevel guesses the level of line from the level name among its leading
+// words, as JournalPriority does, reporting false if it names none. Syslog
+// priorities more severe than warnings count as errors, and notices as
+// informational.
+func LineLevel(line []byte) (Level, bool) {
+	p, ok := linePriority(line)
+	switch {
+	case !ok:
+		return LevelInfo, false
+	case p <= 3:
+		return LevelError, true
+	case p == 4:
+		return LevelWarn, true
+	case p == 7:
+		return LevelDebug, true
+	}
+	return LevelInfo, true
+}
+
+// Leveled writes each level to a separate rotating file, such as app-info.log
+// and app-error.log, all rotated together by a single scheduler. Entries can
+// be written to the level's Rolog, with WriteLevel, or with Write, which
+// routes each line by the level it names.
+type Leveled struct {
+	streams [numLevels]*Rolog
+
+	// mu guards levelOf and last, and keeps the lines of concurrent Writes
+	// from interleaving
+	mu      sync.Mutex
+	levelOf func([]byte) (Level, bool)
+	// last is the level of the last line routed by Write
+	last Level
+}
+
+// NewLeveled creates a Leveled logger whose files are named after name and the
+// level, in dir. The opts apply to every level's file.
+//
+// As with New, the returned logger is not already running, and its Run method
+// must be invoked manually.
+func NewLeveled(dir, name string, opts ...Option) (*Leveled, error) {
+	l := &Leveled{levelOf: LineLevel, last: LevelInfo}
+
+	root, err := New(dir, name+"-"+LevelDebug.String(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	l.streams[LevelDebug] = root
+
+	for level := LevelInfo; level < numLevels; level++ {
+		c, err := root.Child(name + "-" + level.String())
+		if err != nil {
+			l.Close()
+			return nil, errors.Wrapf(err, "could not create %s log", level)
+		}
+		l.streams[level] = c
+	}
+
+	return l, nil
+}
+
+// Writer returns the Rolog for level, or nil if level is unknown.
+func (l *Leveled) Writer(level Level) *Rolog {
+	if level < 0 || level >= numLevels {
+		return nil
+	}
+	return l.streams[level]
+}
+
+// WriteLevel writes p to the file for level.
+func (l *Leveled) WriteLevel(level Level, p []byte) (int, error) {
+	w := l.Writer(level)
+	if w == nil {
+		return 0, errors.Errorf("unknown level %d", level)
+	}
+	return w.Write(p)
+}
+
+// SetLevelFunc changes how Write finds the level of a line. f reports false
+// for lines that don't name a level, such as the continuation lines of a stack
+// trace, which then go to the same file as the line before them. Passing nil
+// restores the default of LineLevel.
+func (l *Leveled) SetLevelFunc(f func(line []byte) (Level, bool)) {
+	if f == nil {
+		f = LineLevel
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.levelOf = f
+}
+
+// Write satisfies io.Writer, sending each line of p to the file for the level
+// it names, so the firehose can be written with a single writer and errors
+// still land in a file of their own. Consecutive lines for the same file are
+// written together.
+func (l *Leveled) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var (
+		n     int
+		start int
+		level = l.last
+	)
+	flush := func(end int) error {
+		if end == start {
+			return nil
+		}
+		m, err := l.streams[level].Write(p[start:end])
+		n += m
+		start = end
+		return err
+	}
+
+	for i := 0; i < len(p); {
+		end := len(p)
+		if j := bytes.IndexByte(p[i:], '\n'); j >= 0 {
+			end = i + j + 1
+		}
+		if next, ok := l.levelOf(p[i:end]); ok && next != level && next >= 0 && next < numLevels {
+			if err := flush(i); err != nil {
+				return n, err
+			}
+			level = next
+		}
+		i = end
+	}
+	l.last = level
+	return n, flush(len(p))
+}
+
+// Debug returns the Rolog for debug messages.
+func (l *Leveled) Debug() *Rolog { return l.streams[LevelDebug] }
+
+// Info returns the Rolog for informational messages.
+func (l *Leveled) Info() *Rolog { return l.streams[LevelInfo] }
+
+// Warn returns the Rolog for warnings.
+func (l *Leveled) Warn() *Rolog { return l.streams[LevelWarn] }
+
+// Error returns the Rolog for errors.
+func (l *Leveled) Error() *Rolog { return l.streams[LevelError] }
+
+// Run starts the shared scheduler; see Rolog.Run.
+func (l *Leveled) Run(ctx context.Context) {
+	l.streams[LevelDebug].Run(ctx)
+}
+
+// Rotate rotates every level's file at once.
+func (l *Leveled) Rotate() error {
+	return l.streams[LevelDebug].Rotate()
+}
+
+// Close closes every level's file, returning the first error.
+func (l *Leveled) Close() error {
+	var first error
+	for level := numLevels - 1; level >= 0; level-- {
+		if s := l.streams[level]; s != nil {
+			if err := s.Close(); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
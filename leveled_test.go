@@ -0,0 +1,115 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLeveledRoutesAndRotatesTogether(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	l, err := NewLeveled(dir, "app", WithClock(clock))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		l.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	l.Info().Write([]byte("started\n"))
+	l.Error().Write([]byte("failed\n"))
+
+	for name, want := range map[string]string{
+		"app-info.log":  "started\n",
+		"app-error.log": "failed\n",
+		"app-debug.log": "",
+		"app-warn.log":  "",
+	} {
+		got, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("Wanted %q in %s, got %q", want, name, got)
+		}
+	}
+
+	clock.Advance(time.Hour)
+	if err := l.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+
+	for level := LevelDebug; level <= LevelError; level++ {
+		archives, err := l.Writer(level).Archives()
+		if err != nil || len(archives) != 1 {
+			t.Errorf("Wanted 1 %s archive, got %d (%v)", level, len(archives), err)
+		}
+	}
+}
+
+func TestLeveledWriteRoutesByLevel(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	l, err := NewLeveled(dir, "app")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		l.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	l.Write([]byte("continued from nothing\nINFO started\nlevel=error failed\n  at main.go:12\n"))
+	l.Write([]byte("  at main.go:40\n[warn] slow\n"))
+	l.WriteLevel(LevelDebug, []byte("tick\n"))
+	if _, err := l.WriteLevel(numLevels, []byte("lost\n")); err == nil {
+		t.Errorf("Wanted an unknown level to be rejected")
+	}
+
+	for name, want := range map[string]string{
+		"app-info.log":  "continued from nothing\nINFO started\n",
+		"app-error.log": "level=error failed\n  at main.go:12\n  at main.go:40\n",
+		"app-warn.log":  "[warn] slow\n",
+		"app-debug.log": "tick\n",
+	} {
+		got, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("Wanted %q in %s, got %q", want, name, got)
+		}
+	}
+
+	l.SetLevelFunc(func(line []byte) (Level, bool) {
+		return LevelDebug, true
+	})
+	l.Write([]byte("ERROR but debug\n"))
+	if got, _ := ioutil.ReadFile(filepath.Join(dir, "app-debug.log")); string(got) != "tick\nERROR but debug\n" {
+		t.Errorf("Wanted the level func used, got %q", got)
+	}
+}
@@ -0,0 +1,72 @@
+package rolog
+
+import (
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// ErrClosed is returned by operations on a Rolog after Close, including a
+// second Close.
+var ErrClosed = errors.New("rolog is closed")
+
+// State is a stage in the life of a Rolog.
+type State int32
+
+const (
+	// StateNew is an open Rolog whose run loop isn't running, either because
+	// Run hasn't been called or because its context was cancelled.
+	StateNew State = iota
+	// StateRunning is an open Rolog whose run loop is running.
+	StateRunning
+	// StateClosing is a Rolog that is persisting what was written to it
+	// during Close. Writes already queued are still written, new ones are
+	// refused.
+	StateClosing
+	// StateClosed is a Rolog that has been closed. Every operation returns
+	// ErrClosed.
+	StateClosed
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateNew:
+		return "new"
+	case StateRunning:
+		return "running"
+	case StateClosing:
+		return "closing"
+	case StateClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// State returns the current stage in the life of the Rolog.
+func (r *Rolog) State() State {
+	return State(atomic.LoadInt32(&r.state))
+}
+
+// transition moves the Rolog from one state to another, reporting whether it
+// was in from.
+func (r *Rolog) transition(from, to State) bool {
+	if !atomic.CompareAndSwapInt32(&r.state, int32(from), int32(to)) {
+		return false
+	}
+	r.debugf("state %s -> %s", from, to)
+	return true
+}
+
+// setState moves the Rolog to s unconditionally.
+func (r *Rolog) setState(s State) {
+	from := State(atomic.SwapInt32(&r.state, int32(s)))
+	r.debugf("state %s -> %s", from, s)
+}
+
+// closed reports whether Close has finished, after which the current file must
+// not be touched. It must be called with mu held, since Close moves to
+// StateClosed under mu.
+func (r *Rolog) closed() bool {
+	return r.State() == StateClosed
+}
@@ -0,0 +1,110 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestLifecycle(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r, err := New(dir, "test", WithAsync(8))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if got := r.State(); got != StateNew {
+		t.Errorf("Wanted %s, got %s", StateNew, got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.Run(ctx)
+	r.Run(ctx)
+	if got := r.State(); got != StateRunning {
+		t.Errorf("Wanted %s, got %s", StateRunning, got)
+	}
+
+	cancel()
+	deadline := time.Now().Add(2 * time.Second)
+	for r.State() != StateNew && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := r.State(); got != StateNew {
+		t.Errorf("Wanted %s once the context is cancelled, got %s", StateNew, got)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Errorf("could not close: %q", err)
+	}
+	if got := r.State(); got != StateClosed {
+		t.Errorf("Wanted %s, got %s", StateClosed, got)
+	}
+
+	r.Run(context.Background())
+	if got := r.State(); got != StateClosed {
+		t.Errorf("Wanted Run after Close to do nothing, got %s", got)
+	}
+
+	for name, op := range map[string]func() error{
+		"Close":  r.Close,
+		"Rotate": r.Rotate,
+		"Flush":  r.Flush,
+		"Reopen": r.Reopen,
+		"Write": func() error {
+			_, err := r.Write([]byte("late\n"))
+			return err
+		},
+	} {
+		if err := op(); errors.Cause(err) != ErrClosed {
+			t.Errorf("Wanted ErrClosed from %s after Close, got %v", name, err)
+		}
+	}
+}
+
+func TestCloseBeforeRunDoesNotBlock(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r, err := New(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.Close()
+		r.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Errorf("Wanted Close to return")
+	}
+}
@@ -0,0 +1,113 @@
+package rolog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// LineOptions selects the lines returned by Lines.
+type LineOptions struct {
+	// NewestFirst reads the current file first and then the archives from
+	// newest to oldest. The lines of each file are always in order.
+	NewestFirst bool
+	// Since and Until skip the files written entirely before Since or after
+	// Until. Files that overlap the window are read in full; use each Line's
+	// Time to narrow it further. Zero times leave that end of the window open.
+	Since, Until time.Time
+}
+
+// Line is a line of the log read by Lines.
+type Line struct {
+	// Path is the file the line was read from.
+	Path string
+	// Number is the number of the line within the file, counting from 1.
+	Number int
+	// Offset is where the line starts in the file, in bytes from the start of
+	// its decompressed contents.
+	Offset int64
+	// Time is the timestamp at the start of the line, in any of the forms
+	// ReadRange recognizes, or zero if it has none.
+	Time time.Time
+	// Text is the line without its trailing newline.
+	Text string
+	// Err is set, instead of the fields above other than Path, when the file
+	// at Path couldn't be read.
+	Err error
+}
+
+// Lines reads the lines of the archives and the current file, decompressing
+// archives as needed, and streams them on the returned channel, which is
+// closed once they have all been sent or ctx is cancelled. A file that can't
+// be read, such as an encrypted archive, is reported by a Line with Err set,
+// and reading carries on with the next one. The current file is read as it
+// was when Lines got to it, so writes carrying on meanwhile don't hold it up.
+func (r *Rolog) Lines(ctx context.Context, opts LineOptions) (<-chan Line, error) {
+	sources, err := r.sources(opts.Since, opts.Until)
+	if err != nil {
+		return nil, err
+	}
+	if opts.NewestFirst {
+		for i, j := 0, len(sources)-1; i < j; i, j = i+1, j-1 {
+			sources[i], sources[j] = sources[j], sources[i]
+		}
+	}
+
+	var (
+		lines   = make(chan Line)
+		layouts = r.timestampLayouts()
+	)
+	go func() {
+		defer close(lines)
+		for _, a := range sources {
+			if err := r.readLines(ctx, a, layouts, lines); err == context.Canceled || err == context.DeadlineExceeded {
+				return
+			} else if err != nil {
+				select {
+				case lines <- Line{Path: a.Path, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return lines, nil
+}
+
+// readLines sends the lines of a, returning ctx's error if it is cancelled
+// first.
+func (r *Rolog) readLines(ctx context.Context, a ArchiveInfo, layouts []string, lines chan<- Line) error {
+	rc, err := r.openSource(a)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	var (
+		br     = bufio.NewReader(rc)
+		offset int64
+	)
+	for n := 1; ; n++ {
+		b, err := br.ReadBytes('\n')
+		if len(b) > 0 {
+			line := Line{Path: a.Path, Number: n, Offset: offset, Text: string(bytes.TrimSuffix(b, []byte("\n")))}
+			line.Time, _ = lineTime(b, layouts)
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			offset += int64(len(b))
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "could not read "+a.Path)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLinesCarryTheirPosition(t *testing.T) {
+	r, _, cleanup := newHistory(t)
+	defer cleanup()
+
+	lines, err := r.Lines(context.Background(), LineOptions{NewestFirst: true})
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	var got []Line
+	for line := range lines {
+		if line.Err != nil {
+			t.Errorf("unexpected error: %q", line.Err)
+		}
+		got = append(got, line)
+	}
+	if len(got) != 9 {
+		t.Errorf("Wanted 9 lines, got %+v", got)
+		t.FailNow()
+	}
+
+	want := []Line{
+		{Path: r.CurrentPath(), Number: 1, Offset: 0, Text: "third one"},
+		{Path: r.CurrentPath(), Number: 2, Offset: 10, Text: "third two ERROR"},
+		{Path: r.CurrentPath(), Number: 3, Offset: 26, Text: "third three"},
+	}
+	for i, w := range want {
+		if got[i] != w {
+			t.Errorf("Wanted %+v, got %+v", w, got[i])
+		}
+	}
+	if last := got[8]; last.Text != "first three" || last.Number != 3 || last.Path == r.CurrentPath() {
+		t.Errorf("Wanted the oldest archive last, got %+v", last)
+	}
+}
+
+func TestLinesParseTimestamps(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	clk := newFakeClock()
+	r, err := New(dir, "test", WithClock(clk), WithJSONLines())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	r.Write([]byte("hello\n"))
+	clk.Advance(time.Minute)
+	r.Write([]byte("world\n"))
+
+	lines, err := r.Lines(context.Background(), LineOptions{})
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	want := []time.Time{clk.Now().Add(-time.Minute), clk.Now()}
+	i := 0
+	for line := range lines {
+		if i < len(want) && !line.Time.Equal(want[i]) {
+			t.Errorf("Wanted line %d stamped %v, got %v", i+1, want[i], line.Time)
+		}
+		i++
+	}
+	if i != len(want) {
+		t.Errorf("Wanted %d lines, got %d", len(want), i)
+	}
+}
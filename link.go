@@ -0,0 +1,31 @@
+package rolog
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// LatestFilename is the name of the symlink pointing at the newest archive.
+const LatestFilename = "%s-latest.log"
+
+// SetLatestLink enables maintenance of a symlink, named according to
+// LatestFilename, that always points at the most recently completed archive.
+// It has no effect if the FS doesn't implement SymlinkFS.
+func (r *Rolog) SetLatestLink(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.latestLink = enabled
+	r.publish()
+}
+
+// updateLatest swings the latest symlink to target. The link is replaced
+// atomically so readers never observe it missing.
+func (r *Rolog) updateLatest(target string, s archiveSettings) error {
+	if _, ok := baseFS(r.fs).(SymlinkFS); !s.latestLink || !ok {
+		return nil
+	}
+
+	link := filepath.Join(filepath.Dir(r.currentPath()), fmt.Sprintf(r.latestFormat(), r.name))
+	return replaceLink(r.fs, link, target)
+}
@@ -0,0 +1,152 @@
+package rolog
+
+import (
+	"compress/gzip"
+	"io"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// ErrLiveCompression is the cause of a ConfigError for compressing archives
+// that are already compressed by WithLiveCompression.
+var ErrLiveCompression = errors.New("archives can't be compressed again when the current file is")
+
+// ErrLiveTruncate is the cause of a ConfigError for truncating the current file
+// in place, as TruncateStrategy and CopyTruncateStrategy do, when it is
+// written compressed by WithLiveCompression.
+var ErrLiveTruncate = errors.New("a compressed current file can't be truncated in place")
+
+// WithLiveCompression writes the current file compressed with c as it goes,
+// for services so verbose that even one interval's worth of uncompressed log
+// is too much. The current file and archives get the extension of c, so
+// archives come out compressed without any processing and can be read with
+// OpenArchive, and WithCompression can't be used as well.
+//
+// A compressed stream can only be read up to its last flush point, so one is
+// written every flushEvery by a running Rolog, and whenever the file is
+// synced, as well as when it is closed; a non-positive flushEvery leaves it
+// to syncing. Flush points cost some compression, so they shouldn't be too
+// frequent. Each run that continues an existing current file adds a stream of
+// its own, which gzip and zstd readers take as one. Tools that read the
+// current file directly, such as Follow, see the compressed bytes, and sizes,
+// such as for WithMaxSize, count the data before compression. The rename and
+// reopen strategies of rotation work with it, but New rejects the truncating
+// ones, including WithCircular, with ErrLiveTruncate.
+func WithLiveCompression(c Compression, flushEvery time.Duration) Option {
+	return func(r *Rolog) {
+		if !c.valid() {
+			r.invalid("LiveCompression", ErrInvalidCompression)
+			return
+		}
+		r.liveCompression, r.liveFlush = c, flushEvery
+	}
+}
+
+// liveFile is a current file written through a compressor.
+type liveFile struct {
+	File
+	w liveWriter
+}
+
+// liveWriter is a streaming compressor.
+type liveWriter interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// newLiveFile returns f wrapped in a compressor for c at level, or the
+// default level if it is zero.
+func newLiveFile(f File, c Compression, level int) (*liveFile, error) {
+	var (
+		w   liveWriter
+		err error
+	)
+	switch c {
+	case Gzip:
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		w, err = gzip.NewWriterLevel(f, level)
+	case Zstd:
+		opts := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+		if level != 0 {
+			opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+		}
+		w, err = zstd.NewWriter(f, opts...)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not start live compression")
+	}
+	return &liveFile{File: f, w: w}, nil
+}
+
+// Write satisfies io.Writer, compressing p.
+func (f *liveFile) Write(p []byte) (int, error) {
+	return f.w.Write(p)
+}
+
+// Read satisfies io.Reader.
+func (f *liveFile) Read([]byte) (int, error) {
+	return 0, errors.New("cannot read from a compressed current file")
+}
+
+// Sync writes a flush point and syncs the file.
+func (f *liveFile) Sync() error {
+	if err := f.w.Flush(); err != nil {
+		return err
+	}
+	return f.File.Sync()
+}
+
+// Close ends the compressed stream and closes the file.
+func (f *liveFile) Close() error {
+	err := f.w.Close()
+	if cerr := f.File.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// truncates reports whether s rotates by truncating the current file in place.
+func truncates(s RotationStrategy) bool {
+	switch s.(type) {
+	case TruncateStrategy, CopyTruncateStrategy:
+		return true
+	}
+	return false
+}
+
+// compressLive wraps the file about to become current in a compressor if the
+// current file is compressed. It must be called with mu held.
+func (r *Rolog) compressLive(f File) File {
+	if r.liveCompression == NoCompression || r.passthrough != nil {
+		return f
+	}
+	lf, err := newLiveFile(f, r.liveCompression, r.compressLevel)
+	if err != nil {
+		r.report(err)
+		return f
+	}
+	return lf
+}
+
+// flushLive writes a flush point into the current file.
+func (r *Rolog) flushLive() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed() {
+		return
+	}
+	if err := r.drain(); err != nil {
+		r.report(err)
+		return
+	}
+	if lf, ok := r.f.(*liveFile); ok {
+		if err := lf.w.Flush(); err != nil {
+			r.report(errors.Wrap(err, "could not flush compressed log"))
+		}
+	}
+}
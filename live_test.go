@@ -0,0 +1,140 @@
+package rolog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLiveCompressionArchivesCompressed(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r, err := New(dir, "test", WithClock(newFakeClock()), WithLiveCompression(Zstd, 0))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	if !strings.HasSuffix(r.CurrentPath(), "test.log.zst") {
+		t.Errorf("Wanted the current file to be a .zst file, got %s", r.CurrentPath())
+	}
+
+	r.Write([]byte("hello\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	archives, err := r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(archives) != 1 || !archives[0].Compressed || !strings.HasSuffix(archives[0].Path, ".log.zst") {
+		t.Errorf("Wanted one compressed archive, got %+v", archives)
+		t.FailNow()
+	}
+	rc, err := OpenArchive(archives[0])
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer rc.Close()
+	if got, err := ioutil.ReadAll(rc); err != nil || string(got) != "hello\n" {
+		t.Errorf("Wanted the archive to hold %q, got %q (%v)", "hello\n", got, err)
+	}
+}
+
+func TestLiveCompressionFlushPoints(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r, err := New(dir, "test", WithLiveCompression(Gzip, 0))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	r.Write([]byte("first\n"))
+	r.flushLive()
+
+	// Everything up to the flush point can be read while the stream is open.
+	b, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	got, _ := ioutil.ReadAll(zr)
+	if string(got) != "first\n" {
+		t.Errorf("Wanted %q before the flush point, got %q", "first\n", got)
+	}
+	r.Close()
+
+	// A later run carries on in a stream of its own.
+	r, err = New(dir, "test", WithLiveCompression(Gzip, 0), WithAppend())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	r.Write([]byte("second\n"))
+	r.Close()
+
+	f, err := os.Open(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer f.Close()
+	zr, err = gzip.NewReader(f)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if got, err := ioutil.ReadAll(zr); err != nil || string(got) != "first\nsecond\n" {
+		t.Errorf("Wanted both runs in the file, got %q (%v)", got, err)
+	}
+}
+
+func TestLiveCompressionRefusesArchiveCompression(t *testing.T) {
+	_, err := New(".", "test", WithLiveCompression(Gzip, 0), WithCompression(Gzip))
+	if cerr, ok := err.(*ConfigError); !ok || cerr.Err != ErrLiveCompression {
+		t.Errorf("Wanted ErrLiveCompression, got %v", err)
+	}
+}
+
+func TestLiveCompressionRefusesTruncation(t *testing.T) {
+	for name, opt := range map[string]Option{
+		"circular":      WithCircular(0),
+		"copy-truncate": WithRotationStrategy(CopyTruncateStrategy{}),
+	} {
+		_, err := New(".", "test", WithLiveCompression(Gzip, 0), opt)
+		if cerr, ok := err.(*ConfigError); !ok || cerr.Err != ErrLiveTruncate {
+			t.Errorf("Wanted ErrLiveTruncate for %s, got %v", name, err)
+		}
+	}
+}
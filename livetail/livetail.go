@@ -0,0 +1,165 @@
+// Package livetail streams the lines written to a Rolog to browsers as
+// Server-Sent Events, so that developers can watch a production log without
+// shell access to the host.
+package livetail
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/haleyrc/rolog"
+)
+
+const (
+	// clientBuffer is how many lines are held for a client that is slow to
+	// read before further lines are discarded for it.
+	clientBuffer = 256
+	// keepAlive is how often an idle stream is sent a comment, so that
+	// proxies don't time it out.
+	keepAlive = 15 * time.Second
+)
+
+// message is a single event sent to clients.
+type message struct {
+	event string
+	data  []byte
+}
+
+// Tail is an http.Handler streaming a Rolog's writes as they happen. Each line
+// is sent as a message event, and each completed rotation as a rotate event
+// whose data is the archive's path. Because the lines are taken from the
+// Rolog's writes rather than read from its files, streams carry on across
+// rotations without a gap. A client that falls behind misses lines rather
+// than slowing the log down.
+type Tail struct {
+	stop func()
+
+	mu      sync.Mutex
+	closed  bool
+	clients map[chan message]struct{}
+}
+
+// New returns a Tail streaming the output of r, and attaches it to r.
+func New(r *rolog.Rolog) *Tail {
+	t := &Tail{clients: map[chan message]struct{}{}}
+	r.AddTee(t)
+	t.stop = r.Observe(rolog.ObserverFuncs{Rotate: t.rotated})
+	return t
+}
+
+// Write satisfies io.Writer, sending every line of p to the connected
+// clients. It is called by the Rolog for every write.
+func (t *Tail) Write(p []byte) (int, error) {
+	for _, line := range bytes.SplitAfter(p, []byte("\n")) {
+		if line = bytes.TrimSuffix(line, []byte("\n")); len(line) > 0 {
+			t.broadcast(message{data: append([]byte(nil), line...)})
+		}
+	}
+	return len(p), nil
+}
+
+// rotated sends a rotate event.
+func (t *Tail) rotated(e rolog.RotationEvent) {
+	t.broadcast(message{event: "rotate", data: []byte(e.NewPath)})
+}
+
+// broadcast sends m to every client with room for it.
+func (t *Tail) broadcast(m message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for c := range t.clients {
+		select {
+		case c <- m:
+		default:
+		}
+	}
+}
+
+// ServeHTTP satisfies http.Handler, streaming events until the client goes
+// away or the Tail is closed.
+func (t *Tail) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	c, ok := t.subscribe()
+	if !ok {
+		http.Error(w, "tail closed", http.StatusServiceUnavailable)
+		return
+	}
+	defer t.unsubscribe(c)
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ping := time.NewTicker(keepAlive)
+	defer ping.Stop()
+
+	for {
+		select {
+		case m, ok := <-c:
+			if !ok {
+				return
+			}
+			if m.event != "" {
+				fmt.Fprintf(w, "event: %s\n", m.event)
+			}
+			fmt.Fprintf(w, "data: %s\n\n", bytes.Replace(m.data, []byte("\r"), nil, -1))
+		case <-ping.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-req.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// subscribe adds a client, unless the Tail is closed.
+func (t *Tail) subscribe() (chan message, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return nil, false
+	}
+	c := make(chan message, clientBuffer)
+	t.clients[c] = struct{}{}
+	return c, true
+}
+
+// unsubscribe removes a client.
+func (t *Tail) unsubscribe(c chan message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.clients, c)
+}
+
+// Close ends every stream and refuses new ones. The Tail stays attached to the
+// Rolog's writes, so it should be closed after the Rolog.
+func (t *Tail) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return rolog.ErrClosed
+	}
+	t.closed = true
+	for c := range t.clients {
+		close(c)
+		delete(t.clients, c)
+	}
+	t.mu.Unlock()
+
+	t.stop()
+	return nil
+}
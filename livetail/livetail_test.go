@@ -0,0 +1,86 @@
+package livetail
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/haleyrc/rolog"
+)
+
+func TestTailStreamsAcrossRotations(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := rolog.New(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	tail := New(r)
+	srv := httptest.NewServer(tail)
+	defer func() {
+		srv.Close()
+		tail.Close()
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Wanted an event stream, got %q", ct)
+	}
+
+	r.Write([]byte("before\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	r.Write([]byte("after\nand again\n"))
+
+	// the rotate event is delivered by an observer, so it may arrive
+	// before or after the lines written after the rotation
+	var (
+		sc    = bufio.NewScanner(resp.Body)
+		event string
+		lines []string
+		rots  []string
+	)
+	for len(lines)+len(rots) < 4 && sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: ") && event == "rotate":
+			rots = append(rots, strings.TrimPrefix(line, "data: "))
+		case strings.HasPrefix(line, "data: "):
+			lines = append(lines, strings.TrimPrefix(line, "data: "))
+		case line == "":
+			event = ""
+		}
+	}
+
+	want := []string{"before", "after", "and again"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("Wanted %q, got %q", want, lines)
+	}
+	if len(rots) != 1 || !strings.HasPrefix(rots[0], filepath.Clean(dir)) {
+		t.Errorf("Wanted a rotate event for an archive in %s, got %q", dir, rots)
+	}
+}
@@ -0,0 +1,272 @@
+package rolog
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the on-disk representation of a Config. Durations and sizes
+// are written as human-friendly strings such as "6h" and "100MB".
+type fileConfig struct {
+	Dir          string `json:"dir" yaml:"dir"`
+	Name         string `json:"name" yaml:"name"`
+	Interval     scalar `json:"interval" yaml:"interval"`
+	MaxSize      scalar `json:"max_size" yaml:"max_size"`
+	MaxBackups   int    `json:"max_backups" yaml:"max_backups"`
+	MaxTotalSize scalar `json:"max_total_size" yaml:"max_total_size"`
+	MaxAge       scalar `json:"max_age" yaml:"max_age"`
+	BundleAge    scalar `json:"bundle_age" yaml:"bundle_age"`
+	LatestLink   bool   `json:"latest_link" yaml:"latest_link"`
+	FileMode     scalar `json:"file_mode" yaml:"file_mode"`
+	ArchivePerm  scalar `json:"archive_perm" yaml:"archive_perm"`
+	StdLog       bool   `json:"std_log" yaml:"std_log"`
+	Append       bool   `json:"append" yaml:"append"`
+	StartAge     scalar `json:"archive_on_start_age" yaml:"archive_on_start_age"`
+	StartSize    scalar `json:"archive_on_start_size" yaml:"archive_on_start_size"`
+	Extension    string `json:"extension" yaml:"extension"`
+	CurrentName  string `json:"current_filename" yaml:"current_filename"`
+	ArchiveName  string `json:"archive_filename" yaml:"archive_filename"`
+	InstanceID   string `json:"instance_id" yaml:"instance_id"`
+	BufferSize   scalar `json:"buffer_size" yaml:"buffer_size"`
+	FlushEvery   scalar `json:"flush_interval" yaml:"flush_interval"`
+	QueueSize    int    `json:"queue_size" yaml:"queue_size"`
+	Compression  string `json:"compression" yaml:"compression"`
+	Workers      int    `json:"compress_workers" yaml:"compress_workers"`
+	Level        int    `json:"compression_level" yaml:"compression_level"`
+	CatchUp      bool   `json:"catch_up" yaml:"catch_up"`
+	Passthrough  string `json:"passthrough" yaml:"passthrough"`
+	Discard      bool   `json:"discard" yaml:"discard"`
+	Circular     bool   `json:"circular" yaml:"circular"`
+	CircularKeep scalar `json:"circular_keep" yaml:"circular_keep"`
+	// Logs are the named logs that inherit from this one
+	Logs map[string]fileConfig `json:"logs" yaml:"logs"`
+}
+
+// scalar is a config value that may be written as either a string or a bare
+// number.
+type scalar string
+
+// UnmarshalJSON satisfies json.Unmarshaler.
+func (s *scalar) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err == nil {
+		*s = scalar(str)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return errors.Wrap(err, "expected a string or number")
+	}
+	*s = scalar(n.String())
+	return nil
+}
+
+// LoadConfig reads a Config from a JSON or YAML file, chosen by its extension
+// (.yaml and .yml are YAML, anything else is JSON). Intervals and ages use
+// time.ParseDuration syntax, sizes are parsed with ParseSize, and the archive
+// permissions are octal.
+func LoadConfig(path string) (Config, error) {
+	fc, err := readConfig(path)
+	if err != nil {
+		return Config{}, err
+	}
+	return fc.config()
+}
+
+// LoadConfigs reads the named logs of a service from a file in the format of
+// LoadConfig. The top level of the file configures the parent, and each entry
+// of its logs section configures a log that inherits the parent's settings
+// with Config.Inherit, named after its key unless it gives a name of its own:
+//
+//	dir: /var/log/app
+//	interval: 24h
+//	max_backups: 7
+//	compression: gzip
+//	logs:
+//	  access: {}
+//	  audit:
+//	    max_backups: 90
+func LoadConfigs(path string) (map[string]Config, error) {
+	fc, err := readConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := fc.config()
+	if err != nil {
+		return nil, err
+	}
+
+	cfgs := make(map[string]Config, len(fc.Logs))
+	for name, child := range fc.Logs {
+		if len(child.Logs) > 0 {
+			return nil, errors.Errorf("log %s: logs can't be nested", name)
+		}
+		cfg, err := child.config()
+		if err != nil {
+			return nil, errors.Wrapf(err, "log %s", name)
+		}
+		cfg = cfg.Inherit(parent)
+		if cfg.Name == "" {
+			cfg.Name = name
+		}
+		cfgs[name] = cfg
+	}
+	return cfgs, nil
+}
+
+// readConfig reads the file representation of a config from path.
+func readConfig(path string) (fileConfig, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fileConfig{}, errors.Wrap(err, "could not read config")
+	}
+
+	var fc fileConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &fc)
+	default:
+		err = json.Unmarshal(b, &fc)
+	}
+	if err != nil {
+		return fileConfig{}, errors.Wrap(err, "could not parse config")
+	}
+	return fc, nil
+}
+
+// config converts the file representation into a Config.
+func (fc fileConfig) config() (Config, error) {
+	var (
+		cfg = Config{
+			Dir:              fc.Dir,
+			Name:             fc.Name,
+			MaxBackups:       fc.MaxBackups,
+			LatestLink:       fc.LatestLink,
+			StdLog:           fc.StdLog,
+			Append:           fc.Append,
+			Extension:        fc.Extension,
+			CurrentFilename:  fc.CurrentName,
+			ArchiveFilename:  fc.ArchiveName,
+			InstanceID:       fc.InstanceID,
+			QueueSize:        fc.QueueSize,
+			Compression:      Compression(fc.Compression),
+			CompressWorkers:  fc.Workers,
+			CompressionLevel: fc.Level,
+			CatchUp:          fc.CatchUp,
+			Discard:          fc.Discard,
+			Circular:         fc.Circular,
+		}
+		err error
+	)
+
+	if cfg.Interval, err = parseDuration(fc.Interval); err != nil {
+		return Config{}, errors.Wrap(err, "invalid interval")
+	}
+	if cfg.MaxAge, err = parseDuration(fc.MaxAge); err != nil {
+		return Config{}, errors.Wrap(err, "invalid max_age")
+	}
+	if cfg.BundleAge, err = parseDuration(fc.BundleAge); err != nil {
+		return Config{}, errors.Wrap(err, "invalid bundle_age")
+	}
+	if cfg.ArchiveOnStartAge, err = parseDuration(fc.StartAge); err != nil {
+		return Config{}, errors.Wrap(err, "invalid archive_on_start_age")
+	}
+	if cfg.ArchiveOnStartSize, err = parseSize(fc.StartSize); err != nil {
+		return Config{}, errors.Wrap(err, "invalid archive_on_start_size")
+	}
+	if cfg.MaxSize, err = parseSize(fc.MaxSize); err != nil {
+		return Config{}, errors.Wrap(err, "invalid max_size")
+	}
+	if cfg.MaxTotalSize, err = parseSize(fc.MaxTotalSize); err != nil {
+		return Config{}, errors.Wrap(err, "invalid max_total_size")
+	}
+	if cfg.CircularKeep, err = parseSize(fc.CircularKeep); err != nil {
+		return Config{}, errors.Wrap(err, "invalid circular_keep")
+	}
+	if cfg.FileMode, err = parseMode(fc.FileMode); err != nil {
+		return Config{}, errors.Wrap(err, "invalid file_mode")
+	}
+	if cfg.ArchivePerm, err = parseMode(fc.ArchivePerm); err != nil {
+		return Config{}, errors.Wrap(err, "invalid archive_perm")
+	}
+	if cfg.FlushInterval, err = parseDuration(fc.FlushEvery); err != nil {
+		return Config{}, errors.Wrap(err, "invalid flush_interval")
+	}
+	if cfg.Passthrough, err = parsePassthrough(fc.Passthrough); err != nil {
+		return Config{}, errors.Wrap(err, "invalid passthrough")
+	}
+	size, err := parseSize(fc.BufferSize)
+	if err != nil {
+		return Config{}, errors.Wrap(err, "invalid buffer_size")
+	}
+	cfg.BufferSize = int(size)
+
+	return cfg, nil
+}
+
+// parseDuration parses an optional duration.
+func parseDuration(s scalar) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(string(s))
+}
+
+// parseMode parses optional octal permission bits.
+func parseMode(s scalar) (os.FileMode, error) {
+	if s == "" {
+		return 0, nil
+	}
+	perm, err := strconv.ParseUint(string(s), 8, 32)
+	return os.FileMode(perm), err
+}
+
+// parseSize parses an optional size.
+func parseSize(s scalar) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return ParseSize(string(s))
+}
+
+// sizeUnits maps size suffixes to their multipliers. Units are binary, so
+// "1KB" is 1024 bytes.
+var sizeUnits = []struct {
+	suffix string
+	mult   int64
+}{
+	{"TIB", 1 << 40}, {"GIB", 1 << 30}, {"MIB", 1 << 20}, {"KIB", 1 << 10},
+	{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+	{"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize parses a human-friendly byte size such as "100MB", "1.5G" or
+// "4096". Units are case-insensitive and binary.
+func ParseSize(s string) (int64, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+
+	mult := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(str, u.suffix) {
+			str, mult = strings.TrimSpace(strings.TrimSuffix(str, u.suffix)), u.mult
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(str, 64)
+	if err != nil || n < 0 {
+		return 0, errors.Errorf("invalid size %q", s)
+	}
+
+	return int64(n * float64(mult)), nil
+}
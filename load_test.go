@@ -0,0 +1,136 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseSize(t *testing.T) {
+	cases := map[string]int64{
+		"4096":  4096,
+		"100MB": 100 << 20,
+		"1.5g":  3 << 29,
+		"2 KiB": 2048,
+	}
+
+	for in, want := range cases {
+		got, err := ParseSize(in)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %q", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Wanted %d for %q, got %d", want, in, got)
+		}
+	}
+
+	if _, err := ParseSize("lots"); err == nil {
+		t.Errorf("Wanted an error for an invalid size")
+	}
+}
+
+func TestLoadConfigParsesJSONAndYAML(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"rolog.json": `{"dir": "/var/log", "name": "app", "interval": "6h", "max_size": "100MB", "max_backups": 5, "archive_perm": "0600"}`,
+		"rolog.yaml": "dir: /var/log\nname: app\ninterval: 6h\nmax_size: 100MB\nmax_backups: 5\narchive_perm: \"0600\"\n",
+	}
+
+	want := Config{Dir: "/var/log", Name: "app", Interval: 6 * time.Hour, MaxSize: 100 << 20, MaxBackups: 5, ArchivePerm: 0600}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+
+		got, err := LoadConfig(path)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %q", name, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Wanted %+v from %s, got %+v", want, name, got)
+		}
+	}
+}
+
+func TestLoadConfigParsesTuning(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "rolog.yaml")
+	contents := "dir: /var/log\nname: app\nbuffer_size: 64KB\nflush_interval: 1s\nqueue_size: 4096\ncompression: zstd\ncompress_workers: 4\npassthrough: container\n"
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	got, err := LoadConfig(path)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	want := Config{Dir: "/var/log", Name: "app", BufferSize: 64 << 10, FlushInterval: time.Second, QueueSize: 4096, Compression: Zstd, CompressWorkers: 4, Passthrough: PassthroughInContainer}
+	if got != want {
+		t.Errorf("Wanted %+v, got %+v", want, got)
+	}
+}
+
+func TestLoadConfigsInheritsFromTheParent(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "rolog.yaml")
+	contents := "dir: /var/log\nname: app\nstd_log: true\ninterval: 24h\nmax_backups: 7\ncompression: gzip\nlogs:\n  access: {}\n  audit:\n    name: audit-trail\n    max_backups: 90\n    max_age: 2160h\n"
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	got, err := LoadConfigs(path)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	want := map[string]Config{
+		"access": {Dir: "/var/log", Name: "access", Interval: 24 * time.Hour, MaxBackups: 7, Compression: Gzip},
+		"audit":  {Dir: "/var/log", Name: "audit-trail", Interval: 24 * time.Hour, MaxBackups: 90, MaxAge: 90 * 24 * time.Hour, Compression: Gzip},
+	}
+	if len(got) != len(want) {
+		t.Errorf("Wanted %d logs, got %d", len(want), len(got))
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("Wanted %+v for %s, got %+v", w, name, got[name])
+		}
+	}
+
+	nested := "dir: /var/log\nlogs:\n  a:\n    logs:\n      b: {}\n"
+	if err := ioutil.WriteFile(path, []byte(nested), 0644); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if _, err := LoadConfigs(path); err == nil {
+		t.Errorf("Wanted nested logs to be rejected")
+	}
+}
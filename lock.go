@@ -0,0 +1,51 @@
+package rolog
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// ErrLocked is returned by New when WithLock is given and another process
+// already holds the lock for the same log.
+var ErrLocked = errors.New("log is locked by another process")
+
+// LockFS is implemented by filesystems that support advisory locks. Lock takes
+// an exclusive lock on name, creating it if necessary, without blocking. It
+// returns ErrLocked if the lock is held elsewhere, and the lock is released by
+// closing the returned io.Closer.
+type LockFS interface {
+	Lock(name string) (io.Closer, error)
+}
+
+// LockFileFormat is the name of the lock file taken by WithLock, in the log
+// directory. It is given the name of the Rolog.
+const LockFileFormat = ".%s.lock"
+
+// WithLock takes an advisory lock on a file named according to LockFileFormat
+// for as long as the Rolog is open, so that two instances of a service pointed
+// at the same directory fail fast with ErrLocked instead of clobbering each
+// other's rotations. New fails if the FS doesn't implement LockFS.
+func WithLock() Option {
+	return func(r *Rolog) {
+		r.lock = true
+	}
+}
+
+// acquireLock takes the lock for a Rolog writing to dir. It must be called
+// before the current file is touched.
+func (r *Rolog) acquireLock(dir string) error {
+	lfs, ok := baseFS(r.fs).(LockFS)
+	if !ok {
+		return errors.New("filesystem does not support locking")
+	}
+
+	l, err := lfs.Lock(filepath.Join(dir, fmt.Sprintf(LockFileFormat, r.name)))
+	if err != nil {
+		return errors.Wrap(err, "could not lock log")
+	}
+	r.unlock = l
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package rolog
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Lock satisfies LockFS. Locking isn't supported on this platform, so it
+// always fails rather than silently leaving the log unprotected.
+func (OSFS) Lock(name string) (io.Closer, error) {
+	return nil, errors.New("file locking is not supported on this platform")
+}
@@ -0,0 +1,45 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestWithLockRejectsASecondInstance(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r, err := New(dir, "test", WithLock())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if _, err := New(dir, "test", WithLock()); errors.Cause(err) != ErrLocked {
+		t.Errorf("Wanted ErrLocked for a second instance, got %v", err)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Errorf("could not close: %q", err)
+		t.FailNow()
+	}
+
+	r, err = New(dir, "test", WithLock())
+	if err != nil {
+		t.Errorf("Wanted the lock to be released on Close, got %q", err)
+		t.FailNow()
+	}
+	r.Close()
+}
@@ -0,0 +1,60 @@
+package rolog
+
+import (
+	"bytes"
+	"time"
+)
+
+// WithLogfmt encodes every line written through the Rolog as a logfmt record
+// of the form ts=<RFC 3339 timestamp> msg=<line>. It adds a LogfmtEncoder to
+// the Rolog's encoders.
+func WithLogfmt() Option {
+	return WithEncoder(LogfmtEncoder{})
+}
+
+// LogfmtEncoder is an Encoder that writes every line as a logfmt record with
+// the time of the write and the line as its message, quoting the message when
+// it contains spaces, quotes, '=' or control characters. A trailing partial
+// line is encoded as a line of its own.
+type LogfmtEncoder struct{}
+
+// Encode satisfies Encoder.
+func (LogfmtEncoder) Encode(dst, p []byte, now time.Time) []byte {
+	for len(p) > 0 {
+		line := p
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			line, p = p[:i], p[i+1:]
+		} else {
+			p = nil
+		}
+		dst = appendLogfmt(dst, now, line)
+	}
+	return dst
+}
+
+// appendLogfmt appends the logfmt encoding of line to buf.
+func appendLogfmt(buf []byte, now time.Time, line []byte) []byte {
+	buf = append(buf, "ts="...)
+	buf = now.AppendFormat(buf, time.RFC3339Nano)
+	buf = append(buf, " msg="...)
+	if needsLogfmtQuote(line) {
+		buf = appendJSONString(buf, line)
+	} else {
+		buf = append(buf, line...)
+	}
+	return append(buf, '\n')
+}
+
+// needsLogfmtQuote reports whether v must be quoted to be read back as a
+// single logfmt value.
+func needsLogfmtQuote(v []byte) bool {
+	if len(v) == 0 {
+		return true
+	}
+	for _, b := range v {
+		if b <= ' ' || b == '=' || b == '"' || b == 0x7f {
+			return true
+		}
+	}
+	return !bytes.Equal(bytes.ToValidUTF8(v, nil), v)
+}
@@ -0,0 +1,46 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLogfmt(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithLogfmt())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("started\nuser said \"hi\"\n\n"))
+
+	got, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	ts := clock.Now().Format(time.RFC3339Nano)
+	want := "ts=" + ts + " msg=started\n" +
+		"ts=" + ts + " msg=\"user said \\\"hi\\\"\"\n" +
+		"ts=" + ts + " msg=\"\"\n"
+	if string(got) != want {
+		t.Errorf("Wanted %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,320 @@
+package rolog
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// LogrotateStanza is one block of a logrotate configuration, converted into
+// the equivalent Config.
+type LogrotateStanza struct {
+	// Paths are the log files, or globs, the block applies to.
+	Paths []string
+	// Config holds the equivalent settings. Dir and Name are taken from the
+	// first path, and should be set by the caller if it is a glob.
+	Config Config
+	// Ignored lists the directives that have no equivalent in Rolog, such as
+	// copytruncate or postrotate, in the order they appeared.
+	Ignored []string
+
+	// sizeOnly records that MaxSize came from size, which replaces the time
+	// directives rather than complementing them
+	sizeOnly bool
+}
+
+// logrotateIntervals maps the time directives of logrotate to intervals.
+var logrotateIntervals = map[string]time.Duration{
+	"hourly":  time.Hour,
+	"daily":   24 * time.Hour,
+	"weekly":  7 * 24 * time.Hour,
+	"monthly": 30 * 24 * time.Hour,
+	"yearly":  365 * 24 * time.Hour,
+}
+
+// logrotateScripts are the directives that open a script running until
+// endscript.
+var logrotateScripts = map[string]bool{
+	"prerotate":   true,
+	"postrotate":  true,
+	"firstaction": true,
+	"lastaction":  true,
+	"preremove":   true,
+}
+
+// LoadLogrotate reads a logrotate configuration file, as ParseLogrotate does.
+func LoadLogrotate(path string) ([]LogrotateStanza, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read logrotate config")
+	}
+	defer f.Close()
+
+	return ParseLogrotate(f)
+}
+
+// ParseLogrotate reads a logrotate configuration and returns a
+// LogrotateStanza for each of its blocks, so that existing configuration can
+// be reused when migrating from logrotate. The directives are mapped as
+// follows:
+//
+//	hourly, daily, weekly, monthly, yearly  Interval
+//	size N                                  MaxSize, disabling scheduled rotation
+//	maxsize N                               MaxSize
+//	rotate N                                MaxBackups (-1 keeps every archive)
+//	maxage N                                MaxAge, in days
+//	compress, nocompress                    Compression (gzip)
+//	create MODE                             FileMode
+//	dateext                                 nothing; archive names are always dated
+//
+// As in logrotate, directives outside of a block are defaults for the blocks
+// that follow them, and of size and the time directives the last one given
+// wins. Every other directive, including scripts, is recorded in Ignored.
+func ParseLogrotate(rd io.Reader) ([]LogrotateStanza, error) {
+	var (
+		stanzas []LogrotateStanza
+		global  LogrotateStanza
+		cur     *LogrotateStanza
+		paths   []string
+		script  string
+		line    int
+	)
+
+	sc := bufio.NewScanner(rd)
+	for sc.Scan() {
+		line++
+		fields, err := logrotateFields(sc.Text())
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", line)
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		if script != "" {
+			if fields[0] == "endscript" {
+				script = ""
+			}
+			continue
+		}
+
+		switch fields[0] {
+		case "{":
+			if cur != nil || len(paths) == 0 {
+				return nil, errors.Errorf("line %d: unexpected {", line)
+			}
+			if len(fields) > 1 {
+				return nil, errors.Errorf("line %d: unexpected %q after {", line, fields[1])
+			}
+			cur = newLogrotateStanza(global, paths)
+			paths = nil
+			continue
+		case "}":
+			if cur == nil {
+				return nil, errors.Errorf("line %d: unexpected }", line)
+			}
+			stanzas = append(stanzas, *cur)
+			cur = nil
+			continue
+		}
+
+		opens := fields[len(fields)-1] == "{"
+		if cur != nil && opens {
+			return nil, errors.Errorf("line %d: unexpected {", line)
+		}
+		if cur == nil && (strings.ContainsAny(fields[0], `/*?`) || opens) {
+			paths = append(paths, fields...)
+			if opens {
+				paths = paths[:len(paths)-1]
+				if len(paths) == 0 {
+					return nil, errors.Errorf("line %d: unexpected {", line)
+				}
+				cur = newLogrotateStanza(global, paths)
+				paths = nil
+			}
+			continue
+		}
+		if len(paths) > 0 {
+			return nil, errors.Errorf("line %d: expected { after %s", line, strings.Join(paths, " "))
+		}
+
+		target := &global
+		if cur != nil {
+			target = cur
+		}
+		if logrotateScripts[fields[0]] {
+			script = fields[0]
+		}
+		if err := target.directive(fields); err != nil {
+			return nil, errors.Wrapf(err, "line %d", line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, errors.Wrap(err, "could not read logrotate config")
+	}
+
+	switch {
+	case script != "":
+		return nil, errors.Errorf("%s is missing endscript", script)
+	case cur != nil:
+		return nil, errors.Errorf("block for %s is missing }", strings.Join(cur.Paths, " "))
+	case len(paths) > 0:
+		return nil, errors.Errorf("expected { after %s", strings.Join(paths, " "))
+	}
+
+	return stanzas, nil
+}
+
+// newLogrotateStanza returns a stanza for paths inheriting the defaults in
+// global.
+func newLogrotateStanza(global LogrotateStanza, paths []string) *LogrotateStanza {
+	s := &LogrotateStanza{
+		Paths:    paths,
+		Config:   global.Config,
+		Ignored:  append([]string(nil), global.Ignored...),
+		sizeOnly: global.sizeOnly,
+	}
+
+	base := filepath.Base(paths[0])
+	ext := filepath.Ext(base)
+	s.Config.Dir = filepath.Dir(paths[0])
+	s.Config.Name = strings.TrimSuffix(base, ext)
+	if ext != "" && ext != DefaultExtension {
+		s.Config.Extension = ext
+	}
+
+	return s
+}
+
+// directive applies a single directive to the stanza.
+func (s *LogrotateStanza) directive(fields []string) error {
+	name, args := fields[0], fields[1:]
+	c := &s.Config
+
+	if d, ok := logrotateIntervals[name]; ok {
+		c.Interval = d
+		if s.sizeOnly {
+			c.MaxSize, s.sizeOnly = 0, false
+		}
+		return nil
+	}
+
+	switch name {
+	case "size", "maxsize":
+		if len(args) != 1 {
+			return errors.Errorf("%s takes a size", name)
+		}
+		n, err := ParseSize(args[0])
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s", name)
+		}
+		c.MaxSize, s.sizeOnly = n, name == "size"
+		if s.sizeOnly {
+			c.Interval = -1
+		}
+	case "rotate":
+		n, err := logrotateCount(name, args)
+		if err != nil {
+			return err
+		}
+		switch {
+		case n == -1:
+			c.MaxBackups = 0
+		case n < 1:
+			return errors.Errorf("rotate %d is not supported, archives can only be disabled with Purge", n)
+		default:
+			c.MaxBackups = n
+		}
+	case "maxage":
+		n, err := logrotateCount(name, args)
+		if err != nil {
+			return err
+		}
+		c.MaxAge = time.Duration(n) * 24 * time.Hour
+	case "compress":
+		c.Compression = Gzip
+	case "nocompress":
+		c.Compression = NoCompression
+	case "create":
+		if len(args) == 0 {
+			return nil
+		}
+		mode, err := strconv.ParseUint(args[0], 8, 32)
+		if err != nil {
+			return errors.Wrap(err, "invalid create mode")
+		}
+		c.FileMode = os.FileMode(mode)
+		if len(args) > 1 {
+			s.Ignored = append(s.Ignored, "create owner")
+		}
+	case "dateext":
+	default:
+		s.Ignored = append(s.Ignored, name)
+	}
+
+	return nil
+}
+
+// logrotateCount parses the single integer argument of a directive.
+func logrotateCount(name string, args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, errors.Errorf("%s takes a count", name)
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid %s", name)
+	}
+	return n, nil
+}
+
+// logrotateFields splits a line into its fields, dropping comments, splitting
+// off braces and honoring double quotes.
+func logrotateFields(line string) ([]string, error) {
+	var (
+		fields []string
+		field  strings.Builder
+		quoted bool
+		have   bool
+	)
+	flush := func() {
+		if have {
+			fields = append(fields, field.String())
+			field.Reset()
+			have = false
+		}
+	}
+
+	for _, c := range line {
+		switch {
+		case quoted && c == '"':
+			quoted = false
+		case quoted:
+			field.WriteRune(c)
+		case c == '"':
+			quoted, have = true, true
+		case c == '#':
+			flush()
+			return fields, nil
+		case c == '{' || c == '}':
+			flush()
+			fields = append(fields, string(c))
+		case c == ' ' || c == '\t':
+			flush()
+		default:
+			field.WriteRune(c)
+			have = true
+		}
+	}
+	if quoted {
+		return nil, errors.New("unterminated quote")
+	}
+	flush()
+
+	return fields, nil
+}
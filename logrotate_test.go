@@ -0,0 +1,98 @@
+package rolog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLogrotate(t *testing.T) {
+	const conf = `
+# defaults
+weekly
+rotate 4
+compress
+
+/var/log/app/api.log {
+	daily
+	rotate 7
+	maxsize 100M
+	maxage 30
+	dateext
+	create 0640 app app
+	missingok
+	postrotate
+		systemctl reload api # not a directive
+	endscript
+}
+
+"/var/log/app/worker.txt" /var/log/app/other.txt
+{
+	size 10k
+	nocompress
+}
+`
+
+	stanzas, err := ParseLogrotate(strings.NewReader(conf))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(stanzas) != 2 {
+		t.Errorf("Wanted 2 stanzas, got %d", len(stanzas))
+		t.FailNow()
+	}
+
+	api := stanzas[0]
+	want := Config{
+		Dir:         "/var/log/app",
+		Name:        "api",
+		Interval:    24 * time.Hour,
+		MaxSize:     100 << 20,
+		MaxBackups:  7,
+		MaxAge:      30 * 24 * time.Hour,
+		FileMode:    0640,
+		Compression: Gzip,
+	}
+	if !reflect.DeepEqual(api.Config, want) {
+		t.Errorf("Wanted %+v, got %+v", want, api.Config)
+	}
+	if ignored := []string{"create owner", "missingok", "postrotate"}; !reflect.DeepEqual(api.Ignored, ignored) {
+		t.Errorf("Wanted %q ignored, got %q", ignored, api.Ignored)
+	}
+
+	worker := stanzas[1]
+	if paths := []string{"/var/log/app/worker.txt", "/var/log/app/other.txt"}; !reflect.DeepEqual(worker.Paths, paths) {
+		t.Errorf("Wanted paths %q, got %q", paths, worker.Paths)
+	}
+	want = Config{
+		Dir:        "/var/log/app",
+		Name:       "worker",
+		Extension:  ".txt",
+		Interval:   -1,
+		MaxSize:    10 << 10,
+		MaxBackups: 4,
+	}
+	if !reflect.DeepEqual(worker.Config, want) {
+		t.Errorf("Wanted %+v, got %+v", want, worker.Config)
+	}
+}
+
+func TestParseLogrotateRejectsInvalidConfig(t *testing.T) {
+	tests := map[string]string{
+		"unclosed block":    "/var/log/a.log {\nrotate 3\n",
+		"unopened block":    "rotate 3\n}\n",
+		"missing brace":     "/var/log/a.log\nrotate 3\n",
+		"nested block":      "/var/log/a.log {\n/var/log/b.log {\n}\n}\n",
+		"rotate zero":       "/var/log/a.log {\nrotate 0\n}\n",
+		"invalid size":      "/var/log/a.log {\nsize lots\n}\n",
+		"missing endscript": "/var/log/a.log {\npostrotate\n}\n",
+		"unclosed quote":    "\"/var/log/a.log {\n}\n",
+	}
+	for name, conf := range tests {
+		if _, err := ParseLogrotate(strings.NewReader(conf)); err == nil {
+			t.Errorf("%s: Wanted an error, got nil", name)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Package logrushook adapts a Rolog for use with logrus, so that existing
+// logrus users can adopt rotation by adding a single hook.
+package logrushook
+
+import (
+	"github.com/haleyrc/rolog"
+	"github.com/sirupsen/logrus"
+)
+
+// Hook is a logrus.Hook that writes entries at its levels to a Rolog.
+type Hook struct {
+	r         *rolog.Rolog
+	levels    []logrus.Level
+	formatter logrus.Formatter
+}
+
+// New returns a Hook that writes entries at the given levels to r, or at every
+// level if none are given. Entries are formatted by the logger's own Formatter
+// unless SetFormatter is called.
+func New(r *rolog.Rolog, levels ...logrus.Level) *Hook {
+	if len(levels) == 0 {
+		levels = logrus.AllLevels
+	}
+	return &Hook{r: r, levels: levels}
+}
+
+// SetFormatter makes the Hook format entries with f instead of the logger's
+// Formatter, for example to write JSON to the file while the terminal gets
+// text. Passing nil restores the default.
+func (h *Hook) SetFormatter(f logrus.Formatter) {
+	h.formatter = f
+}
+
+// Levels satisfies logrus.Hook.
+func (h *Hook) Levels() []logrus.Level {
+	return h.levels
+}
+
+// Fire satisfies logrus.Hook.
+func (h *Hook) Fire(e *logrus.Entry) error {
+	f := h.formatter
+	if f == nil {
+		f = e.Logger.Formatter
+	}
+
+	b, err := f.Format(e)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.r.Write(b)
+	return err
+}
@@ -0,0 +1,54 @@
+package logrushook
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/haleyrc/rolog"
+	"github.com/sirupsen/logrus"
+)
+
+func TestHookRoutesLevels(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := rolog.New(dir, "errors")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	logger.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+
+	hook := New(r, logrus.ErrorLevel, logrus.WarnLevel)
+	logger.AddHook(hook)
+	logger.Info("routine")
+	logger.Error("broken")
+
+	hook.SetFormatter(&logrus.JSONFormatter{DisableTimestamp: true})
+	logger.Warn("careful")
+
+	got, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	want := "level=error msg=broken\n{\"level\":\"warning\",\"msg\":\"careful\"}\n"
+	if string(got) != want {
+		t.Errorf("Wanted %q, got %q", want, got)
+	}
+}
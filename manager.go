@@ -0,0 +1,304 @@
+package rolog
+
+import (
+	"context"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// managerPoll is how often a running Manager looks for rotations that are due.
+const managerPoll = time.Second
+
+// Manager owns several named Rologs and drives their scheduled rotations from
+// a single goroutine, instead of each running a loop of its own. It can apply
+// options to all of them, such as shared retention, and hold them all to one
+// disk budget.
+//
+// Logs opened through a Manager shouldn't be started with Run. The Manager
+// only performs their scheduled rotations; logs that need the rest of the run
+// loop's background work, such as flushing a write buffer on a timer, are
+// better run on their own.
+type Manager struct {
+	clock  Clock
+	shared []Option
+	budget int64
+
+	mu      sync.Mutex
+	logs    map[string]*managedLog
+	running bool
+	closed  bool
+	done    chan struct{}
+	// prune is signalled after every rotation of a managed log
+	prune chan struct{}
+}
+
+// managedLog is a Rolog owned by a Manager.
+type managedLog struct {
+	r *Rolog
+	// next is when the next scheduled rotation is due
+	next time.Time
+	stop func()
+}
+
+// ManagerOption configures a Manager.
+type ManagerOption func(*Manager)
+
+// WithManagerClock replaces the system clock of the Manager's scheduler, and
+// is passed on to every log it opens.
+func WithManagerClock(c Clock) ManagerOption {
+	return func(m *Manager) {
+		if c != nil {
+			m.clock = c
+		}
+	}
+}
+
+// WithSharedOptions applies opts to every log the Manager opens, ahead of the
+// options given to Open.
+func WithSharedOptions(opts ...Option) ManagerOption {
+	return func(m *Manager) {
+		m.shared = append(m.shared, opts...)
+	}
+}
+
+// WithSharedMaxTotalSize holds the current files and archives of all the logs
+// together to n bytes. After each rotation, the oldest archives across all of
+// them are removed, whichever log they belong to, until the total fits. It
+// works alongside the retention of each log.
+func WithSharedMaxTotalSize(n int64) ManagerOption {
+	return func(m *Manager) {
+		m.budget = n
+	}
+}
+
+// NewManager returns a Manager with no logs.
+func NewManager(opts ...ManagerOption) *Manager {
+	m := &Manager{
+		clock: systemClock{},
+		logs:  make(map[string]*managedLog),
+		done:  make(chan struct{}),
+		prune: make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Open creates a Rolog as New does and hands it to the Manager, which rotates
+// it on schedule from then on and closes it with the rest. Names must be
+// unique within a Manager.
+func (m *Manager) Open(dir, name string, opts ...Option) (*Rolog, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil, ErrClosed
+	}
+	if _, ok := m.logs[name]; ok {
+		return nil, errors.Errorf("a log named %q is already open", name)
+	}
+
+	all := append([]Option{WithClock(m.clock)}, m.shared...)
+	r, err := New(dir, name, append(all, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &managedLog{r: r}
+	l.schedule(m.clock.Now())
+	l.stop = r.Observe(ObserverFuncs{Rotate: func(RotationEvent) {
+		select {
+		case m.prune <- struct{}{}:
+		default:
+		}
+	}})
+	m.logs[name] = l
+	return r, nil
+}
+
+// Get returns the log opened under name, or nil if there isn't one.
+func (m *Manager) Get(name string) *Rolog {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if l, ok := m.logs[name]; ok {
+		return l.r
+	}
+	return nil
+}
+
+// Names returns the names of the open logs, sorted.
+func (m *Manager) Names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.logs))
+	for name := range m.logs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run starts the Manager's scheduler in a separate goroutine. It stops when
+// ctx is cancelled or the Manager is closed, after which the logs, which stay
+// writable, are no longer rotated on schedule. Run does nothing if the
+// scheduler is already running or the Manager is closed.
+func (m *Manager) Run(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running || m.closed {
+		return
+	}
+	m.running = true
+
+	t := m.clock.NewTicker(managerPoll)
+	go func() {
+		defer func() {
+			t.Stop()
+			m.mu.Lock()
+			m.running = false
+			m.mu.Unlock()
+		}()
+
+		for {
+			select {
+			case <-t.C():
+				m.rotateDue(m.clock.Now())
+			case <-m.prune:
+				m.enforceBudget()
+			case <-m.done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// schedule sets when l is next due to be rotated, from now.
+func (l *managedLog) schedule(now time.Time) {
+	l.next = time.Time{}
+	if d := l.r.Settings().Interval; d > 0 {
+		l.next = now.Add(d)
+	}
+	l.r.setNext(l.next)
+}
+
+// rotateDue rotates every log whose scheduled rotation is due at now. Failures
+// are reported on the log's Err, and retried at its next interval.
+func (m *Manager) rotateDue(now time.Time) {
+	m.mu.Lock()
+	var due []*managedLog
+	for _, l := range m.logs {
+		if !l.next.IsZero() && !now.Before(l.next) {
+			due = append(due, l)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, l := range due {
+		if err := l.r.scheduledRotate(); err != nil && err != ErrClosed {
+			l.r.report(err)
+		}
+		m.mu.Lock()
+		l.schedule(now)
+		m.mu.Unlock()
+	}
+}
+
+// ownedArchive is an archive and the log it belongs to.
+type ownedArchive struct {
+	ArchiveInfo
+	r *Rolog
+}
+
+// enforceBudget removes the oldest archives across every log until their
+// total size fits in the shared budget.
+func (m *Manager) enforceBudget() {
+	if m.budget <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	logs := make([]*Rolog, 0, len(m.logs))
+	for _, l := range m.logs {
+		logs = append(logs, l.r)
+	}
+	m.mu.Unlock()
+
+	var (
+		archives []ownedArchive
+		total    int64
+	)
+	for _, r := range logs {
+		as, err := r.archives()
+		if err != nil {
+			r.report(err)
+			continue
+		}
+		for _, a := range as {
+			archives = append(archives, ownedArchive{a, r})
+			total += a.Size
+		}
+		total += r.CurrentSize()
+	}
+	sort.SliceStable(archives, func(i, j int) bool {
+		return archives[i].End.Before(archives[j].End)
+	})
+
+	for _, a := range archives {
+		if total <= m.budget {
+			return
+		}
+
+		a.r.mu.Lock()
+		filter := a.r.archiveSettings().deleteFilter
+		a.r.mu.Unlock()
+
+		// Hold off the log's own archive processing, as Purge does.
+		a.r.rotMu.Lock()
+		removed, err := a.r.remove(a.ArchiveInfo, filter)
+		a.r.rotMu.Unlock()
+		if os.IsNotExist(errors.Cause(err)) {
+			// compressed or pruned since it was listed
+			continue
+		}
+		if err != nil {
+			a.r.report(errors.Wrap(err, "could not enforce shared budget"))
+			continue
+		}
+		if removed {
+			total -= a.Size
+		}
+	}
+}
+
+// Close stops the scheduler and closes every log, returning the first error.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return ErrClosed
+	}
+	m.closed = true
+	close(m.done)
+	logs := m.logs
+	m.logs = nil
+	m.mu.Unlock()
+
+	var first error
+	for name, l := range logs {
+		l.stop()
+		if err := l.r.Close(); err != nil && first == nil {
+			first = errors.Wrapf(err, "could not close %s", name)
+		}
+	}
+	return first
+}
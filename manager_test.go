@@ -0,0 +1,139 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+// waitForArchives fails t unless r has want archives within a few seconds.
+func waitForArchives(t *testing.T, r *Rolog, want int) []ArchiveInfo {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		archives, err := r.Archives()
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		if len(archives) == want {
+			return archives
+		}
+		if time.Now().After(deadline) {
+			t.Errorf("Wanted %d archives of %s, got %d", want, r.name, len(archives))
+			t.FailNow()
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestManagerRotatesEveryLogOnSchedule(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	clk := newFakeClock()
+	m := NewManager(WithManagerClock(clk))
+	defer m.Close()
+
+	a, err := m.Open(dir, "a", WithInterval(time.Hour))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	b, err := m.Open(dir, "b", WithInterval(2*time.Hour))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if _, err := m.Open(dir, "a"); err == nil {
+		t.Errorf("Wanted a second log named a to be rejected")
+	}
+	if m.Get("b") != b || m.Get("c") != nil {
+		t.Errorf("Wanted Get to find the open logs")
+	}
+	if names := m.Names(); len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("Wanted names [a b], got %v", names)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.Run(ctx)
+
+	a.Write([]byte("hello\n"))
+	b.Write([]byte("hello\n"))
+
+	clk.Advance(time.Hour)
+	waitForArchives(t, a, 1)
+	waitForArchives(t, b, 0)
+
+	clk.Advance(time.Hour)
+	waitForArchives(t, a, 2)
+	waitForArchives(t, b, 1)
+
+	if err := m.Close(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+	}
+	if _, err := a.Write([]byte("late\n")); err == nil {
+		t.Errorf("Wanted the logs closed with the Manager")
+	}
+	if err := m.Close(); err != ErrClosed {
+		t.Errorf("Wanted %v, got %v", ErrClosed, err)
+	}
+}
+
+func TestManagerEnforcesSharedBudget(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	clk := newFakeClock()
+	m := NewManager(WithManagerClock(clk), WithSharedMaxTotalSize(25), WithSharedOptions(WithInterval(24*time.Hour)))
+	defer m.Close()
+
+	a, err := m.Open(dir, "a")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	b, err := m.Open(dir, "b")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.Run(ctx)
+
+	// Three ten byte archives, a's first, rotated a minute apart.
+	for _, r := range []*Rolog{a, b, a} {
+		r.Write([]byte("123456789\n"))
+		if err := r.Rotate(); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		clk.Advance(time.Minute)
+	}
+
+	archives := waitForArchives(t, a, 1)
+	if want := clk.Now().Add(-time.Minute); !archives[0].End.Equal(want) {
+		t.Errorf("Wanted the newest archive of a kept, got %+v", archives[0])
+	}
+	waitForArchives(t, b, 1)
+}
@@ -0,0 +1,139 @@
+package rolog
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// ManifestFileFormat is the format for the manifest of WithManifest, kept in
+// the directory of the archives it describes.
+const ManifestFileFormat = "%s.manifest"
+
+// WithManifest records the SHA-256 digest of each archive once it has been
+// compressed and encrypted as configured, in a manifest beside the archives
+// named according to ManifestFileFormat, so that Verify can tell an archive
+// that was changed or damaged on disk from the one the Rolog wrote, whatever
+// its format. The manifest is rewritten with each archive, dropping the
+// archives that have since been pruned or bundled.
+func WithManifest() Option {
+	return func(r *Rolog) {
+		r.manifest = true
+	}
+}
+
+// manifestEntry is one line of the manifest.
+type manifestEntry struct {
+	Archive string `json:"archive"`
+	SHA256  string `json:"sha256"`
+}
+
+// manifestPath returns the path of the manifest for the archives in dir.
+func (r *Rolog) manifestPath(dir string) string {
+	return filepath.Join(dir, fmt.Sprintf(ManifestFileFormat, r.name))
+}
+
+// recordDigest adds the digest of the archive at path to the manifest of its
+// directory, replacing the manifest atomically.
+func (r *Rolog) recordDigest(path string) error {
+	sum, err := fileDigest(r.fs, path)
+	if err != nil {
+		return err
+	}
+
+	r.manifestMu.Lock()
+	defer r.manifestMu.Unlock()
+
+	var (
+		dir      = filepath.Dir(path)
+		manifest = r.manifestPath(dir)
+	)
+	digests, err := r.readManifest(dir)
+	if err != nil {
+		return err
+	}
+	digests[filepath.Base(path)] = sum
+
+	tmp := manifest + ".tmp"
+	f, err := r.fs.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return errors.Wrap(err, "could not create manifest")
+	}
+	defer r.fs.Remove(tmp)
+	defer f.Close()
+
+	names := make([]string, 0, len(digests))
+	for name := range digests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, name := range names {
+		if _, err := r.fs.Stat(filepath.Join(dir, name)); os.IsNotExist(err) {
+			// pruned or bundled since it was recorded
+			continue
+		}
+		if err := enc.Encode(manifestEntry{Archive: name, SHA256: digests[name]}); err != nil {
+			return errors.Wrap(err, "could not write manifest")
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return errors.Wrap(err, "could not write manifest")
+	}
+	if err := f.Sync(); err != nil {
+		return errors.Wrap(err, "could not sync manifest")
+	}
+
+	return errors.Wrap(r.fs.Rename(tmp, manifest), "could not replace manifest")
+}
+
+// readManifest returns the digests recorded for the archives in dir by their
+// base names. A missing manifest has none.
+func (r *Rolog) readManifest(dir string) (map[string]string, error) {
+	digests := make(map[string]string)
+	f, err := r.fs.OpenFile(r.manifestPath(dir), os.O_RDONLY, 0)
+	if os.IsNotExist(err) {
+		return digests, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open manifest")
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	for {
+		var e manifestEntry
+		err := dec.Decode(&e)
+		if err == io.EOF {
+			return digests, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read manifest")
+		}
+		digests[e.Archive] = e.SHA256
+	}
+}
+
+// fileDigest returns the hex SHA-256 digest of the file at path.
+func fileDigest(fs FS, path string) (string, error) {
+	f, err := fs.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		return "", errors.Wrap(err, "could not open archive")
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", errors.Wrap(err, "could not read archive")
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,107 @@
+package rolog
+
+import "time"
+
+// Metrics receives measurements from a Rolog as they happen, for exporting to
+// a monitoring system; see the promcollector, expvarmetrics and otelrolog
+// packages. Calls are made from whichever goroutine did the work, sometimes
+// with internal locks held, so implementations must be safe for concurrent use
+// and must not call back into the Rolog.
+type Metrics interface {
+	// Wrote is called after each write with the bytes accepted and how long
+	// the write took.
+	Wrote(n int, took time.Duration)
+	// Rotated is called after each rotation with the event that is
+	// delivered on Notify.
+	Rotated(e RotationEvent)
+	// Removed is called for every archive deleted by retention or Purge.
+	Removed(a ArchiveInfo)
+	// Failed is called with every error reported on the Err channel.
+	Failed(err error)
+	// Dropped is called for every entry discarded instead of written.
+	Dropped(reason DropReason)
+}
+
+// WithMetrics is the construction-time equivalent of SetMetrics.
+func WithMetrics(m Metrics) Option {
+	return func(r *Rolog) {
+		r.metrics.Store(metricsHolder{m})
+	}
+}
+
+// SetMetrics installs m to receive the Rolog's measurements from now on,
+// replacing any installed before. Passing nil stops them.
+func (r *Rolog) SetMetrics(m Metrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.metrics.Store(metricsHolder{m})
+}
+
+// AddMetrics installs m to receive the Rolog's measurements alongside any
+// installed before, so that several exporters can watch one Rolog.
+func (r *Rolog) AddMetrics(m Metrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if prev := r.meter(); prev != nil {
+		m = MultiMetrics(prev, m)
+	}
+	r.metrics.Store(metricsHolder{m})
+}
+
+// MultiMetrics returns a Metrics that passes every measurement to each of ms in
+// turn.
+func MultiMetrics(ms ...Metrics) Metrics {
+	return multiMetrics(append([]Metrics(nil), ms...))
+}
+
+// multiMetrics is the Metrics returned by MultiMetrics.
+type multiMetrics []Metrics
+
+// Wrote satisfies Metrics.
+func (mm multiMetrics) Wrote(n int, took time.Duration) {
+	for _, m := range mm {
+		m.Wrote(n, took)
+	}
+}
+
+// Rotated satisfies Metrics.
+func (mm multiMetrics) Rotated(e RotationEvent) {
+	for _, m := range mm {
+		m.Rotated(e)
+	}
+}
+
+// Removed satisfies Metrics.
+func (mm multiMetrics) Removed(a ArchiveInfo) {
+	for _, m := range mm {
+		m.Removed(a)
+	}
+}
+
+// Failed satisfies Metrics.
+func (mm multiMetrics) Failed(err error) {
+	for _, m := range mm {
+		m.Failed(err)
+	}
+}
+
+// metricsHolder lets a nil Metrics be stored in an atomic.Value.
+type metricsHolder struct {
+	m Metrics
+}
+
+// meter returns the installed Metrics, or nil if there are none. It can be
+// called with or without mu held.
+func (r *Rolog) meter() Metrics {
+	h, _ := r.metrics.Load().(metricsHolder)
+	return h.m
+}
+
+// Dropped satisfies Metrics.
+func (mm multiMetrics) Dropped(reason DropReason) {
+	for _, m := range mm {
+		m.Dropped(reason)
+	}
+}
@@ -0,0 +1,213 @@
+package rolog
+
+import (
+	"bytes"
+	"io"
+	"runtime/debug"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// mirrorBuffer is how many writes a mirror queues while its destination
+	// is slow or down before it starts discarding them.
+	mirrorBuffer = 1024
+	// mirrorTimeout bounds each delivery, so that a hung destination is
+	// treated as a failed one.
+	mirrorTimeout = 5 * time.Second
+	// mirrorMaxBackoff caps the wait between reconnection attempts.
+	mirrorMaxBackoff = 30 * time.Second
+)
+
+// mirrorSink is the destination of a mirror.
+type mirrorSink interface {
+	// send delivers a single line, without its newline, written at the
+	// given time, connecting first if necessary.
+	send(line []byte, at time.Time) error
+	// reset drops the connection after a failure, so the next send
+	// reconnects.
+	reset()
+}
+
+// entrySink is a mirrorSink that takes whole writes rather than single lines.
+type entrySink interface {
+	mirrorSink
+	sendEntry(p []byte, at time.Time) error
+}
+
+// rotatingSink is a mirrorSink that rotates along with the log.
+type rotatingSink interface {
+	mirrorSink
+	rotate() error
+}
+
+// mirrorEntry is a write waiting to be mirrored, or a rotation of the log if
+// rotate is set.
+type mirrorEntry struct {
+	p      []byte
+	at     time.Time
+	rotate bool
+}
+
+// mirror is a tee that copies writes to a network destination from its own
+// goroutine, so that a slow or unreachable destination never blocks or fails
+// the file writes. Writes are queued, and discarded once the queue is full.
+// A failed delivery is reported on Err, and the mirror reconnects with backoff
+// before sending anything else.
+type mirror struct {
+	what   string
+	sink   mirrorSink
+	now    func() time.Time
+	report func(error)
+
+	ch   chan mirrorEntry
+	stop chan struct{}
+	done chan struct{}
+}
+
+// addMirror registers a mirror of every write to sink, started by New.
+func (r *Rolog) addMirror(what string, sink mirrorSink) {
+	m := newMirror(what, sink, r.now, r.report)
+	r.mirrors = append(r.mirrors, m)
+	r.tees = append(r.tees, m)
+}
+
+// newMirror returns an unstarted mirror to sink that reports failures to
+// report.
+func newMirror(what string, sink mirrorSink, now func() time.Time, report func(error)) *mirror {
+	return &mirror{
+		what:   what,
+		sink:   sink,
+		now:    now,
+		report: report,
+		ch:     make(chan mirrorEntry, mirrorBuffer),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// rotated queues a rotation of the log behind the writes before it, without
+// blocking.
+func (m *mirror) rotated() {
+	select {
+	case m.ch <- mirrorEntry{at: m.now(), rotate: true}:
+	default:
+	}
+}
+
+// Write satisfies io.Writer, queueing a copy of p without blocking.
+func (m *mirror) Write(p []byte) (int, error) {
+	select {
+	case m.ch <- mirrorEntry{p: append([]byte(nil), p...), at: m.now()}:
+	default:
+	}
+	return len(p), nil
+}
+
+// run delivers queued writes until the mirror is stopped, then delivers
+// whatever is still queued unless the destination is failing.
+func (m *mirror) run() {
+	defer close(m.done)
+	defer func() {
+		if v := recover(); v != nil {
+			m.report(&PanicError{Op: m.what + " mirror", Value: v, Stack: debug.Stack()})
+		}
+	}()
+
+	var backoff time.Duration
+	for {
+		select {
+		case e := <-m.ch:
+			if err := m.deliver(e); err != nil {
+				m.sink.reset()
+				m.report(errors.Wrapf(err, "could not write to %s", m.what))
+				backoff = nextBackoff(backoff)
+				select {
+				case <-time.After(backoff):
+				case <-m.stop:
+					return
+				}
+				continue
+			}
+			backoff = 0
+		case <-m.stop:
+			for {
+				select {
+				case e := <-m.ch:
+					if m.deliver(e) != nil {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver sends each line of e, or passes on the rotation.
+func (m *mirror) deliver(e mirrorEntry) error {
+	if e.rotate {
+		if s, ok := m.sink.(rotatingSink); ok {
+			return s.rotate()
+		}
+		return nil
+	}
+	if s, ok := m.sink.(entrySink); ok {
+		return s.sendEntry(e.p, e.at)
+	}
+
+	p := e.p
+	for len(p) > 0 {
+		line := p
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			line, p = p[:i], p[i+1:]
+		} else {
+			p = nil
+		}
+		if err := m.sink.send(line, e.at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextBackoff doubles d, starting from a tenth of a second, up to
+// mirrorMaxBackoff.
+func nextBackoff(d time.Duration) time.Duration {
+	switch {
+	case d == 0:
+		return 100 * time.Millisecond
+	case d*2 > mirrorMaxBackoff:
+		return mirrorMaxBackoff
+	}
+	return d * 2
+}
+
+// startMirrors starts the goroutine of every mirror.
+func (r *Rolog) startMirrors() {
+	for _, m := range r.mirrors {
+		go m.run()
+	}
+}
+
+// stopMirrors stops every mirror, waiting for it to deliver what is queued.
+func (r *Rolog) stopMirrors() {
+	for _, m := range r.mirrors {
+		close(m.stop)
+	}
+	for _, m := range r.mirrors {
+		m.wait()
+	}
+}
+
+// wait waits for a stopped mirror to finish, then disconnects it, closing the
+// sink if it can be closed.
+func (m *mirror) wait() {
+	<-m.done
+	m.sink.reset()
+	if c, ok := m.sink.(io.Closer); ok {
+		c.Close()
+	}
+}
@@ -0,0 +1,66 @@
+package rolog
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DefaultDirPerm is the mode given to log directories that the Rolog has to
+// create, before umask.
+const DefaultDirPerm os.FileMode = 0755
+
+// WithDirPerm sets the mode of any log directories the Rolog creates. The
+// default is DefaultDirPerm.
+func WithDirPerm(perm os.FileMode) Option {
+	return func(r *Rolog) {
+		r.dirPerm = perm
+	}
+}
+
+// WithoutMkdir stops the Rolog from creating its directory. New then fails if
+// the directory doesn't exist, and rotations fail if it disappears, which suits
+// deployments where the directory is provisioned and a missing one is a sign
+// that something is wrong.
+func WithoutMkdir() Option {
+	return func(r *Rolog) {
+		r.noMkdir = true
+	}
+}
+
+// mkdirFS returns the FS as a MkdirFS, or nil if directories mustn't or can't
+// be created.
+func (r *Rolog) mkdirFS() MkdirFS {
+	m, ok := baseFS(r.fs).(MkdirFS)
+	if r.noMkdir || !ok {
+		return nil
+	}
+	return m
+}
+
+// mkdir creates dir and any missing parents if allowed. It is a no-op if dir
+// already exists.
+func (r *Rolog) mkdir(dir string) error {
+	m := r.mkdirFS()
+	if m == nil {
+		return nil
+	}
+
+	perm := r.dirPerm
+	if perm == 0 {
+		perm = DefaultDirPerm
+	}
+	return m.MkdirAll(dir, perm)
+}
+
+// ensureDir recreates the log directory, and a fresh current file within it, if
+// the directory has disappeared since New, so that a rotation has something to
+// rotate. It must be called with mu held.
+func (r *Rolog) ensureDir() error {
+	if r.mkdirFS() == nil {
+		return nil
+	}
+	if _, err := r.fs.Stat(filepath.Dir(r.currentPath())); !os.IsNotExist(err) {
+		return nil
+	}
+	return r.recreate()
+}
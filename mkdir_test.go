@@ -0,0 +1,58 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewCreatesTheLogDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	logDir := filepath.Join(dir, "var", "log")
+	r, err := New(logDir, "test", WithDirPerm(0700))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	fi, err := os.Stat(logDir)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if fi.Mode().Perm() != 0700 {
+		t.Errorf("Wanted mode 0700, got %v", fi.Mode().Perm())
+	}
+
+	if err := os.RemoveAll(logDir); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if err := r.Rotate(); err != nil {
+		t.Errorf("Wanted the rotation to recreate the directory, got %q", err)
+	}
+	r.Write([]byte("after\n"))
+
+	got, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("Wanted the directory to be recreated, got %q", err)
+		t.FailNow()
+	}
+	if string(got) != "after\n" {
+		t.Errorf("Wanted %q, got %q", "after\n", got)
+	}
+}
@@ -0,0 +1,116 @@
+package rolog
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultExtension is the extension used by ArchiveFileFormat,
+// CurrentFilename and LatestFilename. It can be replaced with WithExtension.
+const DefaultExtension = ".log"
+
+// withExtension swaps DefaultExtension at the end of format for the configured
+// extension.
+func (r *Rolog) withExtension(format string) string {
+	if r.ext == "" {
+		return format
+	}
+	return strings.TrimSuffix(format, DefaultExtension) + r.ext
+}
+
+// The variables that filename formats may contain.
+const (
+	// VarHost is replaced by the hostname.
+	VarHost = "{host}"
+	// VarPID is replaced by the process ID.
+	VarPID = "{pid}"
+	// VarInstance is replaced by the ID given to WithInstanceID.
+	VarInstance = "{instance}"
+)
+
+// WithInstanceID sets the value of VarInstance in filename formats, such as
+// the ID of a replica, so that several processes writing to a shared volume
+// each have files of their own.
+func WithInstanceID(id string) Option {
+	return func(r *Rolog) {
+		r.instance = id
+	}
+}
+
+// WithArchiveFilename replaces ArchiveFileFormat as the naming format of
+// archives. The format must contain a single %s, which is replaced by the name
+// given to New, followed by a timestamp in the layout of the time package,
+// such as "%s-{host}-2006-01-02-150405.log". Filename variables may appear
+// anywhere before the timestamp, but not after it.
+func WithArchiveFilename(format string) Option {
+	return func(r *Rolog) {
+		r.archiveName = format
+	}
+}
+
+// archiveFormat returns the archive naming format in effect, with its
+// variables still in place.
+func (r *Rolog) archiveFormat() string {
+	if r.archiveName != "" {
+		return r.archiveName
+	}
+	return r.withExtension(ArchiveFileFormat)
+}
+
+// currentFormat returns the current-file naming format in effect.
+func (r *Rolog) currentFormat() string {
+	if r.currentName != "" {
+		return r.expandVars(r.currentName, true) + r.liveCompression.Extension()
+	}
+	return r.withExtension(CurrentFilename) + r.liveCompression.Extension()
+}
+
+// latestFormat returns the latest-link naming format in effect.
+func (r *Rolog) latestFormat() string {
+	return r.withExtension(LatestFilename)
+}
+
+// expandVars replaces the filename variables in s. With escape set, any %
+// in their values is doubled, for formats passed on to fmt.Sprintf.
+func (r *Rolog) expandVars(s string, escape bool) string {
+	if !strings.Contains(s, "{") {
+		return s
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+	vals := []string{host, strconv.Itoa(os.Getpid()), r.instance}
+	if escape {
+		for i, v := range vals {
+			vals[i] = strings.Replace(v, "%", "%%", -1)
+		}
+	}
+	return strings.NewReplacer(VarHost, vals[0], VarPID, vals[1], VarInstance, vals[2]).Replace(s)
+}
+
+// lastVarEnd returns the index just past the last filename variable in s, or
+// zero if it has none.
+func lastVarEnd(s string) int {
+	end := 0
+	for _, v := range []string{VarHost, VarPID, VarInstance} {
+		if i := strings.LastIndex(s, v); i >= 0 && i+len(v) > end {
+			end = i + len(v)
+		}
+	}
+	return end
+}
+
+// validArchiveLayout reports whether the archive naming format in effect has
+// a timestamp after its variables.
+func (r *Rolog) validArchiveLayout() bool {
+	if strings.Count(r.archiveFormat(), "%s") != 1 {
+		return false
+	}
+	_, layout := r.archiveLayout()
+	t := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	return t.Format(layout) != layout
+}
@@ -0,0 +1,111 @@
+package rolog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtensionAndCurrentFilename(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithExtension("ndjson"), WithCurrentFilename("%s.current.ndjson"))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	if _, err := os.Stat(filepath.Join(dir, "test.current.ndjson")); err != nil {
+		t.Errorf("Wanted the current file to use the template, got %q", err)
+	}
+
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+
+	archives, err := r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if len(archives) != 1 || !strings.HasSuffix(archives[0].Path, ".ndjson") {
+		t.Errorf("Wanted one .ndjson archive, got %v", archives)
+	}
+}
+
+func TestFilenameVariables(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+	// The instance ID is full of characters that mean something to
+	// fmt.Sprintf and time.Format.
+	opts := []Option{
+		WithClock(newFakeClock()),
+		WithInstanceID("2%d"),
+		WithCurrentFilename("%s-{host}-{instance}.log"),
+		WithArchiveFilename("%s-{instance}-{pid}-2006-01-02-150405.log"),
+	}
+	r, err := New(dir, "test", opts...)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	if want := filepath.Join(dir, "test-"+host+"-2%d.log"); r.CurrentPath() != want {
+		t.Errorf("Wanted the current file at %s, got %s", want, r.CurrentPath())
+	}
+
+	r.Write([]byte("hello\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+
+	archives, err := ListArchives(dir, "test", opts...)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	want := filepath.Join(dir, fmt.Sprintf("test-2%%d-%d-2020-01-02-030405.log", os.Getpid()))
+	if len(archives) != 1 || archives[0].Path != want || archives[0].End.Hour() != 3 {
+		t.Errorf("Wanted one archive at %s, got %+v", want, archives)
+	}
+}
+
+func TestArchiveFilenameNeedsTimestampLast(t *testing.T) {
+	for _, format := range []string{"test.log", "%s-2006-01-02-{pid}.log", "%s-%s-2006.log"} {
+		_, err := New(".", "test", WithArchiveFilename(format))
+		if cerr, ok := err.(*ConfigError); !ok || cerr.Err != ErrInvalidArchiveName {
+			t.Errorf("Wanted %q refused, got %v", format, err)
+		}
+	}
+}
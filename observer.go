@@ -0,0 +1,240 @@
+package rolog
+
+import "runtime/debug"
+
+// observerBuffer is how many undelivered events are kept for each Observer.
+const observerBuffer = 64
+
+// Observer is told about the things that happen to a Rolog. Each Observer
+// registered with Observe or WithObserver has its own goroutine and queue, so
+// events reach it in order, but a slow Observer never holds up the Rolog: if
+// its queue is full, further events are discarded until it catches up.
+// ObserverFuncs implements Observer for callers interested in only some of
+// the events.
+type Observer interface {
+	// OnRotate is called after each rotation with the event that is
+	// delivered on Notify.
+	OnRotate(e RotationEvent)
+	// OnPrune is called for every archive deleted by retention or Purge.
+	OnPrune(a ArchiveInfo)
+	// OnError is called with every error reported on the Err channel.
+	OnError(err error)
+	// OnDrop is called for every entry discarded instead of written.
+	OnDrop(reason DropReason)
+	// OnFlush is called with the number of bytes each time the write buffer
+	// is drained to the current file.
+	OnFlush(n int)
+}
+
+// ObserverFuncs is an Observer that calls whichever of its functions are set.
+type ObserverFuncs struct {
+	Rotate func(RotationEvent)
+	Prune  func(ArchiveInfo)
+	Error  func(error)
+	Drop   func(DropReason)
+	Flush  func(int)
+}
+
+// OnRotate satisfies Observer.
+func (o ObserverFuncs) OnRotate(e RotationEvent) {
+	if o.Rotate != nil {
+		o.Rotate(e)
+	}
+}
+
+// OnPrune satisfies Observer.
+func (o ObserverFuncs) OnPrune(a ArchiveInfo) {
+	if o.Prune != nil {
+		o.Prune(a)
+	}
+}
+
+// OnError satisfies Observer.
+func (o ObserverFuncs) OnError(err error) {
+	if o.Error != nil {
+		o.Error(err)
+	}
+}
+
+// OnDrop satisfies Observer.
+func (o ObserverFuncs) OnDrop(reason DropReason) {
+	if o.Drop != nil {
+		o.Drop(reason)
+	}
+}
+
+// OnFlush satisfies Observer.
+func (o ObserverFuncs) OnFlush(n int) {
+	if o.Flush != nil {
+		o.Flush(n)
+	}
+}
+
+// WithObserver is the construction-time equivalent of Observe.
+func WithObserver(o Observer) Option {
+	return func(r *Rolog) {
+		r.withObservers = append(r.withObservers, o)
+	}
+}
+
+// Observe registers o to be told about the Rolog's events from now on, along
+// with any other Observers, until the returned function is called or the
+// Rolog is closed. Events already queued for o are still delivered after
+// that.
+func (r *Rolog) Observe(o Observer) (stop func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed() {
+		return func() {}
+	}
+	s := r.subscribe(o)
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.unsubscribe(s)
+	}
+}
+
+// eventKind says which Observer method an event is for.
+type eventKind int
+
+const (
+	eventRotate eventKind = iota
+	eventPrune
+	eventError
+	eventDrop
+	eventFlush
+)
+
+// event is an Observer call waiting to be delivered. Only the fields for its
+// kind are set.
+type event struct {
+	kind     eventKind
+	rotation RotationEvent
+	archive  ArchiveInfo
+	err      error
+	reason   DropReason
+	n        int
+}
+
+// deliver calls the method of o that ev is for.
+func (ev event) deliver(o Observer) {
+	switch ev.kind {
+	case eventRotate:
+		o.OnRotate(ev.rotation)
+	case eventPrune:
+		o.OnPrune(ev.archive)
+	case eventError:
+		o.OnError(ev.err)
+	case eventDrop:
+		o.OnDrop(ev.reason)
+	case eventFlush:
+		o.OnFlush(ev.n)
+	}
+}
+
+// subscriber is an Observer registered with a Rolog, and the queue of events
+// waiting for it.
+type subscriber struct {
+	o    Observer
+	ch   chan event
+	done chan struct{}
+}
+
+// run delivers events to the Observer until the subscriber is stopped, then
+// delivers whatever is still queued.
+func (s *subscriber) run(r *Rolog) {
+	deliver := func(ev event) {
+		// A panic in OnError isn't reported, since that would call OnError
+		// again.
+		defer func() {
+			if v := recover(); v != nil && ev.kind != eventError {
+				r.report(&PanicError{Op: "observer callback", Value: v, Stack: debug.Stack()})
+			}
+		}()
+		ev.deliver(s.o)
+	}
+
+	for {
+		select {
+		case ev := <-s.ch:
+			deliver(ev)
+		case <-s.done:
+			for {
+				select {
+				case ev := <-s.ch:
+					deliver(ev)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// subscribers is the set of Observers, copied on every change so dispatch can
+// read it without locking.
+type subscribers struct {
+	list []*subscriber
+}
+
+// subscribe starts a subscriber for o. It must be called with mu held, or
+// during New.
+func (r *Rolog) subscribe(o Observer) *subscriber {
+	s := &subscriber{
+		o:    o,
+		ch:   make(chan event, observerBuffer),
+		done: make(chan struct{}),
+	}
+	go s.run(r)
+
+	prev := r.subscribers()
+	r.observers.Store(subscribers{append(prev[:len(prev):len(prev)], s)})
+	return s
+}
+
+// unsubscribe stops s, if it is still registered. It must be called with mu
+// held.
+func (r *Rolog) unsubscribe(s *subscriber) {
+	var rest []*subscriber
+	found := false
+	for _, other := range r.subscribers() {
+		if other == s {
+			found = true
+			continue
+		}
+		rest = append(rest, other)
+	}
+	if !found {
+		return
+	}
+
+	r.observers.Store(subscribers{rest})
+	close(s.done)
+}
+
+// stopObservers stops every subscriber. It must be called with mu held.
+func (r *Rolog) stopObservers() {
+	for _, s := range r.subscribers() {
+		r.unsubscribe(s)
+	}
+}
+
+// subscribers returns the current subscribers.
+func (r *Rolog) subscribers() []*subscriber {
+	subs, _ := r.observers.Load().(subscribers)
+	return subs.list
+}
+
+// observe queues ev for every subscriber without blocking, discarding it for
+// any whose queue is full.
+func (r *Rolog) observe(ev event) {
+	for _, s := range r.subscribers() {
+		select {
+		case s.ch <- ev:
+		default:
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestObserversReceiveEvents(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	rotated := make(chan RotationEvent, 8)
+	pruned := make(chan ArchiveInfo, 8)
+	first := ObserverFuncs{
+		Rotate: func(e RotationEvent) { rotated <- e },
+		Prune:  func(a ArchiveInfo) { pruned <- a },
+	}
+
+	r, err := New(dir, "test", WithMaxBackups(1), WithObserver(first))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	// A stuck Observer must not hold up rotation or the other Observers.
+	unblock := make(chan struct{})
+	defer close(unblock)
+	r.Observe(ObserverFuncs{Rotate: func(RotationEvent) { <-unblock }})
+
+	second := make(chan RotationEvent, 8)
+	stop := r.Observe(ObserverFuncs{Rotate: func(e RotationEvent) { second <- e }})
+
+	for i := 0; i < observerBuffer+2; i++ {
+		r.Write([]byte("entry\n"))
+		if err := r.Rotate(); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		if i == 0 {
+			stop()
+		}
+	}
+
+	for _, ch := range []chan RotationEvent{rotated, second} {
+		select {
+		case e := <-ch:
+			if e.Reason != ReasonManual {
+				t.Errorf("Wanted a manual rotation, got %q", e.Reason)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("Wanted a rotation event")
+			t.FailNow()
+		}
+	}
+	select {
+	case <-pruned:
+	case <-time.After(5 * time.Second):
+		t.Errorf("Wanted a prune event")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if n := len(second); n != 0 {
+		t.Errorf("Wanted no events after stop, got %d", n)
+	}
+}
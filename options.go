@@ -0,0 +1,170 @@
+package rolog
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// Option configures a Rolog at construction time.
+type Option func(*Rolog)
+
+// WithInterval sets how often the logs are rotated. A non-positive interval
+// disables scheduled rotation, which is useful in combination with
+// WithMaxSize.
+func WithInterval(d time.Duration) Option {
+	return func(r *Rolog) {
+		r.interval = d
+	}
+}
+
+// WithStdLog sets the output of the standard library's default logger to the
+// new Rolog, as AttachToStdLog does.
+func WithStdLog() Option {
+	return func(r *Rolog) {
+		r.stdLog = true
+	}
+}
+
+// WithFileMode sets the mode of the current file, applied both in New and
+// whenever a rotation creates a new file. Unlike os.Create, the mode is applied
+// exactly rather than being filtered by the umask.
+func WithFileMode(mode os.FileMode) Option {
+	return func(r *Rolog) {
+		r.perm = mode
+	}
+}
+
+// WithClock replaces the system clock used for archive naming and scheduling,
+// primarily so tests can drive rotation deterministically.
+func WithClock(c Clock) Option {
+	return func(r *Rolog) {
+		if c != nil {
+			r.clock = c
+		}
+	}
+}
+
+// WithFS replaces the operating system filesystem with fs.
+func WithFS(fs FS) Option {
+	return func(r *Rolog) {
+		if fs != nil {
+			r.fs = fs
+		}
+	}
+}
+
+// WithAppend makes New continue writing to an existing current file instead of
+// archiving it, which suits services that restart frequently.
+func WithAppend() Option {
+	return func(r *Rolog) {
+		r.appendOnStart = true
+	}
+}
+
+// WithExtension replaces DefaultExtension in the names of the current file,
+// the archives and the latest link, e.g. ".ndjson" or ".txt".
+func WithExtension(ext string) Option {
+	return func(r *Rolog) {
+		if ext != "" && !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		r.ext = ext
+	}
+}
+
+// WithCurrentFilename replaces CurrentFilename as the naming format of the
+// current file. The format must contain a single %s, which is replaced by the
+// name given to New, and may contain the filename variables VarHost, VarPID
+// and VarInstance. Archives keep their own naming scheme; see
+// WithArchiveFilename.
+func WithCurrentFilename(format string) Option {
+	return func(r *Rolog) {
+		r.currentName = format
+	}
+}
+
+// WithMaxSize rotates the current file before a write would take it past n
+// bytes. A single write larger than n still lands in one file.
+func WithMaxSize(n int64) Option {
+	return func(r *Rolog) {
+		r.maxSize = n
+	}
+}
+
+// WithMaxBackups keeps at most n archives, deleting the oldest after each
+// rotation. n must be at least one; use Purge to remove every archive.
+func WithMaxBackups(n int) Option {
+	return func(r *Rolog) {
+		if n < 1 {
+			r.invalid("MaxBackups", ErrInvalidRetention)
+		}
+		r.maxBackups = n
+	}
+}
+
+// WithMaxTotalSize is the construction-time equivalent of SetMaxTotalSize.
+func WithMaxTotalSize(n int64) Option {
+	return func(r *Rolog) {
+		r.maxTotalSize = n
+	}
+}
+
+// WithEncrypter is the construction-time equivalent of SetEncrypter.
+func WithEncrypter(e Encrypter) Option {
+	return func(r *Rolog) {
+		r.encrypter = e
+	}
+}
+
+// WithMaxAge is the construction-time equivalent of SetMaxAge.
+func WithMaxAge(d time.Duration) Option {
+	return func(r *Rolog) {
+		r.maxAge = d
+	}
+}
+
+// WithBundleAge is the construction-time equivalent of SetBundleAge.
+func WithBundleAge(d time.Duration) Option {
+	return func(r *Rolog) {
+		r.bundleAge = d
+	}
+}
+
+// WithDeleteFilter is the construction-time equivalent of SetDeleteFilter.
+func WithDeleteFilter(f func(ArchiveInfo) bool) Option {
+	return func(r *Rolog) {
+		r.deleteFilter = f
+	}
+}
+
+// WithLatestLink is the construction-time equivalent of SetLatestLink.
+func WithLatestLink() Option {
+	return func(r *Rolog) {
+		r.latestLink = true
+	}
+}
+
+// WithRotationStrategy is the construction-time equivalent of
+// SetRotationStrategy.
+func WithRotationStrategy(s RotationStrategy) Option {
+	return func(r *Rolog) {
+		if s != nil {
+			r.strategy = s
+		}
+	}
+}
+
+// WithArchivePerm is the construction-time equivalent of SetArchivePerm.
+func WithArchivePerm(mode os.FileMode) Option {
+	return func(r *Rolog) {
+		r.archivePerm = mode
+	}
+}
+
+// WithArchiveOwner is the construction-time equivalent of SetArchiveOwner.
+func WithArchiveOwner(uid, gid int) Option {
+	return func(r *Rolog) {
+		r.archiveUID, r.archiveGID = uid, gid
+	}
+}
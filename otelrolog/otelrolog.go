@@ -0,0 +1,166 @@
+// Package otelrolog reports the work of a Rolog to OpenTelemetry, as spans for
+// rotations and archive processing and as metrics.
+package otelrolog
+
+import (
+	"context"
+	"time"
+
+	"github.com/haleyrc/rolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies this package to the tracer and meter
+// providers.
+const instrumentationName = "github.com/haleyrc/rolog/otelrolog"
+
+// Config customizes an Instrumentation.
+type Config struct {
+	// TracerProvider creates the tracer spans are recorded with. It
+	// defaults to the global provider.
+	TracerProvider trace.TracerProvider
+	// MeterProvider creates the meter metrics are recorded with. It defaults
+	// to the global provider.
+	MeterProvider metric.MeterProvider
+	// Attributes are added to every measurement, for telling several Rologs
+	// in one process apart. Spans always carry the log's name and directory.
+	Attributes []attribute.KeyValue
+}
+
+// Instrumentation is a rolog.Tracer and rolog.Metrics backed by OpenTelemetry.
+type Instrumentation struct {
+	tracer trace.Tracer
+	attrs  []attribute.KeyValue
+
+	written          metric.Int64Counter
+	writeDuration    metric.Float64Histogram
+	rotations        metric.Int64Counter
+	rotationDuration metric.Float64Histogram
+	rotationPause    metric.Float64Histogram
+	pruned           metric.Int64Counter
+	errors           metric.Int64Counter
+	dropped          metric.Int64Counter
+}
+
+var (
+	_ rolog.Tracer  = (*Instrumentation)(nil)
+	_ rolog.Metrics = (*Instrumentation)(nil)
+)
+
+// New creates the instruments described by cfg.
+func New(cfg Config) (*Instrumentation, error) {
+	tp, mp := cfg.TracerProvider, cfg.MeterProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+
+	var (
+		meter = mp.Meter(instrumentationName)
+		i     = &Instrumentation{
+			tracer: tp.Tracer(instrumentationName),
+			attrs:  cfg.Attributes,
+		}
+		err error
+	)
+	if i.written, err = meter.Int64Counter("rolog.written",
+		metric.WithUnit("By"), metric.WithDescription("Bytes accepted by Write.")); err != nil {
+		return nil, err
+	}
+	if i.writeDuration, err = meter.Float64Histogram("rolog.write.duration",
+		metric.WithUnit("s"), metric.WithDescription("How long each Write took.")); err != nil {
+		return nil, err
+	}
+	if i.rotations, err = meter.Int64Counter("rolog.rotations",
+		metric.WithDescription("Rotations performed, by reason.")); err != nil {
+		return nil, err
+	}
+	if i.rotationDuration, err = meter.Float64Histogram("rolog.rotation.duration",
+		metric.WithUnit("s"), metric.WithDescription("How long each rotation took, including finalizing the archive.")); err != nil {
+		return nil, err
+	}
+	if i.rotationPause, err = meter.Float64Histogram("rolog.rotation.pause",
+		metric.WithUnit("s"), metric.WithDescription("How long each rotation blocked writes.")); err != nil {
+		return nil, err
+	}
+	if i.pruned, err = meter.Int64Counter("rolog.pruned",
+		metric.WithDescription("Archives deleted by retention or Purge.")); err != nil {
+		return nil, err
+	}
+	if i.errors, err = meter.Int64Counter("rolog.errors",
+		metric.WithDescription("Errors reported by background work.")); err != nil {
+		return nil, err
+	}
+	if i.dropped, err = meter.Int64Counter("rolog.dropped",
+		metric.WithDescription("Entries discarded instead of written.")); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// Options returns the Options that install i on a Rolog.
+func (i *Instrumentation) Options() []rolog.Option {
+	return []rolog.Option{rolog.WithTracer(i), rolog.WithMetrics(i)}
+}
+
+// Span satisfies rolog.Tracer.
+func (i *Instrumentation) Span(op string, info rolog.SpanInfo, start, end time.Time, err error) {
+	attrs := []attribute.KeyValue{
+		attribute.String("rolog.name", info.Name),
+		attribute.String("rolog.dir", info.Dir),
+	}
+	if info.Reason != "" {
+		attrs = append(attrs, attribute.String("rolog.reason", string(info.Reason)))
+	}
+	if info.Path != "" {
+		attrs = append(attrs, attribute.String("rolog.path", info.Path))
+	}
+
+	_, span := i.tracer.Start(context.Background(), "rolog."+op,
+		trace.WithTimestamp(start), trace.WithAttributes(attrs...))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(trace.WithTimestamp(end))
+}
+
+// Wrote satisfies rolog.Metrics.
+func (i *Instrumentation) Wrote(n int, took time.Duration) {
+	ctx, opt := context.Background(), metric.WithAttributes(i.attrs...)
+	i.written.Add(ctx, int64(n), opt)
+	i.writeDuration.Record(ctx, took.Seconds(), opt)
+}
+
+// Rotated satisfies rolog.Metrics.
+func (i *Instrumentation) Rotated(e rolog.RotationEvent) {
+	var (
+		ctx   = context.Background()
+		attrs = append([]attribute.KeyValue{attribute.String("rolog.reason", string(e.Reason))}, i.attrs...)
+	)
+	i.rotations.Add(ctx, 1, metric.WithAttributes(attrs...))
+	i.rotationDuration.Record(ctx, e.Duration.Seconds(), metric.WithAttributes(attrs...))
+	i.rotationPause.Record(ctx, e.Paused.Seconds(), metric.WithAttributes(attrs...))
+}
+
+// Removed satisfies rolog.Metrics.
+func (i *Instrumentation) Removed(rolog.ArchiveInfo) {
+	i.pruned.Add(context.Background(), 1, metric.WithAttributes(i.attrs...))
+}
+
+// Failed satisfies rolog.Metrics.
+func (i *Instrumentation) Failed(error) {
+	i.errors.Add(context.Background(), 1, metric.WithAttributes(i.attrs...))
+}
+
+// Dropped satisfies rolog.Metrics.
+func (i *Instrumentation) Dropped(reason rolog.DropReason) {
+	attrs := append([]attribute.KeyValue{attribute.String("rolog.reason", string(reason))}, i.attrs...)
+	i.dropped.Add(context.Background(), 1, metric.WithAttributes(attrs...))
+}
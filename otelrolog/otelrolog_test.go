@@ -0,0 +1,94 @@
+package otelrolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/haleyrc/rolog"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInstrumentationRecordsRotations(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	var (
+		spans  = tracetest.NewSpanRecorder()
+		reader = sdkmetric.NewManualReader()
+	)
+	inst, err := New(Config{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	})
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := rolog.New(dir, "test", append(inst.Options(), rolog.WithCompression(rolog.Gzip))...)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("hello\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	names := make(map[string]map[string]string)
+	for _, s := range spans.Ended() {
+		attrs := make(map[string]string)
+		for _, kv := range s.Attributes() {
+			attrs[string(kv.Key)] = kv.Value.Emit()
+		}
+		names[s.Name()] = attrs
+	}
+	rotate, ok := names["rolog.rotate"]
+	if !ok {
+		t.Errorf("Wanted a rotate span, got %v", names)
+		t.FailNow()
+	}
+	if rotate["rolog.name"] != "test" || rotate["rolog.dir"] != filepath.Clean(dir) || rotate["rolog.reason"] != "manual" {
+		t.Errorf("Wanted the name, directory and reason, got %v", rotate)
+	}
+	if _, ok := names["rolog.compress"]; !ok {
+		t.Errorf("Wanted a compress span, got %v", names)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	sums := make(map[string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok {
+				for _, dp := range sum.DataPoints {
+					sums[m.Name] += dp.Value
+				}
+			}
+		}
+	}
+	if sums["rolog.written"] != 6 || sums["rolog.rotations"] != 1 {
+		t.Errorf("Wanted 6 bytes written and 1 rotation, got %v", sums)
+	}
+}
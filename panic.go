@@ -0,0 +1,51 @@
+package rolog
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/pkg/errors"
+)
+
+// PanicError is reported in place of a panic recovered from background work,
+// such as archive processing, user hooks called during it, or the writer
+// goroutines, so that a bug there can't take the host process down with it.
+type PanicError struct {
+	// Op describes the work that panicked.
+	Op string
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+// Error satisfies error.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic during %s: %v", e.Op, e.Value)
+}
+
+// catch recovers a panic in the calling goroutine and stores it in err as a
+// *PanicError. It must be deferred directly.
+func catch(op string, err *error) {
+	if v := recover(); v != nil {
+		*err = &PanicError{Op: op, Value: v, Stack: debug.Stack()}
+	}
+}
+
+// contain recovers a panic in the calling goroutine and reports it as a
+// *PanicError. It must be deferred directly.
+func (r *Rolog) contain(op string) {
+	if v := recover(); v != nil {
+		r.report(&PanicError{Op: op, Value: v, Stack: debug.Stack()})
+	}
+}
+
+// writeBackground writes p on behalf of one of the writer goroutines, reporting
+// failures and panics rather than returning them.
+func (r *Rolog) writeBackground(p []byte, what string) {
+	defer r.contain("write of " + what)
+
+	if _, err := r.write(p); err != nil {
+		r.report(errors.Wrap(err, "could not write "+what))
+	}
+}
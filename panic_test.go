@@ -0,0 +1,104 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPanicInArchiveProcessingIsReturned(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	filter := func(ArchiveInfo) bool { panic("filter bug") }
+	r, err := New(dir, "test", WithMaxBackups(1), WithDeleteFilter(filter))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	for i := 0; i < 2; i++ {
+		r.Write([]byte("entry\n"))
+		err = r.Rotate()
+	}
+
+	perr, ok := err.(*PanicError)
+	if !ok {
+		t.Errorf("Wanted a *PanicError, got %v", err)
+		t.FailNow()
+	}
+	if perr.Value != "filter bug" || len(perr.Stack) == 0 {
+		t.Errorf("Wanted the panic value and stack, got %v and %d bytes", perr.Value, len(perr.Stack))
+	}
+
+	if _, err := r.Write([]byte("still here\n")); err != nil {
+		t.Errorf("Wanted the Rolog to stay writable, got %q", err)
+	}
+	if err := r.Rotate(); err == nil {
+		t.Errorf("Wanted the next rotation to reach the filter again")
+	}
+}
+
+func TestPanicInWriterGoroutineIsReported(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	enc := EncoderFunc(func(dst, p []byte, _ time.Time) []byte {
+		if strings.HasPrefix(string(p), "boom") {
+			panic("encoder bug")
+		}
+		return append(dst, p...)
+	})
+	r, err := New(dir, "test", WithAsync(4), WithEncoder(enc))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("boom\n"))
+	select {
+	case err := <-r.Err():
+		if _, ok := err.(*PanicError); !ok {
+			t.Errorf("Wanted a *PanicError, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Wanted the panic to be reported")
+		t.FailNow()
+	}
+
+	r.Write([]byte("after\n"))
+	if err := r.Close(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+	}
+
+	got, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if string(got) != "after\n" {
+		t.Errorf("Wanted the writer goroutine to carry on, got %q", got)
+	}
+}
@@ -0,0 +1,123 @@
+package rolog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// PassthroughMode says when a Rolog writes to a stream such as standard output
+// instead of to files.
+type PassthroughMode int
+
+const (
+	// PassthroughNever always writes files. It is the default.
+	PassthroughNever PassthroughMode = iota
+	// PassthroughInContainer passes writes through when InContainer reports
+	// that the process is running in a container.
+	PassthroughInContainer
+	// PassthroughAlways always passes writes through.
+	PassthroughAlways
+)
+
+// parsePassthrough parses the config spelling of a PassthroughMode: "never",
+// "container" or "always".
+func parsePassthrough(s string) (PassthroughMode, error) {
+	switch s {
+	case "", "never":
+		return PassthroughNever, nil
+	case "container":
+		return PassthroughInContainer, nil
+	case "always":
+		return PassthroughAlways, nil
+	}
+	return PassthroughNever, errors.Errorf("unknown mode %q", s)
+}
+
+// WithPassthrough makes the Rolog write to w, or to standard output if w is
+// nil, instead of to files when mode says so. Nothing is created in the log
+// directory, which needn't exist, and the Rolog never rotates: Rotate and
+// Reopen do nothing and Archives is always empty. Everything else, including
+// encoders, tees, buffering and the async queue, behaves as usual. This lets
+// the same program and configuration write rotated files on a VM and leave
+// log handling to the runtime in Kubernetes.
+func WithPassthrough(mode PassthroughMode, w io.Writer) Option {
+	return func(r *Rolog) {
+		if mode == PassthroughAlways || mode == PassthroughInContainer && InContainer() {
+			if w == nil {
+				w = os.Stdout
+			}
+			r.passthrough = w
+		} else {
+			r.passthrough = nil
+		}
+	}
+}
+
+// containerMarkers are files that container runtimes create in every
+// container, and cgroupMarkers are found in the cgroup paths of processes
+// they start.
+var (
+	containerMarkers = []string{"/.dockerenv", "/run/.containerenv"}
+	cgroupMarkers    = [][]byte{[]byte("docker"), []byte("kubepods"), []byte("containerd"), []byte("libpod"), []byte("lxc")}
+	cgroupPath       = "/proc/1/cgroup"
+)
+
+// InContainer reports whether the process appears to be running in a
+// container, judging by the environment Kubernetes and systemd-nspawn set,
+// the marker files Docker and Podman create, and the cgroups of the init
+// process.
+func InContainer() bool {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" || os.Getenv("container") != "" {
+		return true
+	}
+	for _, path := range containerMarkers {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+
+	b, err := ioutil.ReadFile(cgroupPath)
+	if err != nil {
+		return false
+	}
+	for _, m := range cgroupMarkers {
+		if bytes.Contains(b, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// openPassthrough stands the passthrough writer in for the current file.
+func (r *Rolog) openPassthrough(dir string) {
+	r.setPath(filepath.Join(dir, fmt.Sprintf(r.currentFormat(), r.name)))
+	r.setFile(passthroughFile{r.passthrough})
+}
+
+// passthroughFile is the File of a Rolog in pass-through mode. The stream
+// belongs to the caller, so it is never synced or closed.
+type passthroughFile struct {
+	io.Writer
+}
+
+// Read satisfies File.
+func (passthroughFile) Read([]byte) (int, error) {
+	return 0, errors.New("cannot read from a pass-through log")
+}
+
+// Stat satisfies File.
+func (passthroughFile) Stat() (os.FileInfo, error) {
+	return nil, errors.New("cannot stat a pass-through log")
+}
+
+// Sync satisfies File.
+func (passthroughFile) Sync() error { return nil }
+
+// Close satisfies File.
+func (passthroughFile) Close() error { return nil }
@@ -0,0 +1,98 @@
+package rolog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPassthroughWritesToStream(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	var buf bytes.Buffer
+	missing := filepath.Join(dir, "missing")
+	r, err := New(missing, "test", WithPassthrough(PassthroughAlways, &buf), WithMaxSize(4))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	r.Write([]byte("hello\n"))
+	r.Write([]byte("world\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+	}
+	if err := r.Reopen(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+	}
+	r.Write([]byte("again\n"))
+
+	if got, want := buf.String(), "hello\nworld\nagain\n"; got != want {
+		t.Errorf("Wanted %q on the stream, got %q", want, got)
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("Wanted the log directory not to be created, got %v", err)
+	}
+	archives, err := r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+	}
+	if len(archives) != 0 {
+		t.Errorf("Wanted no archives, got %d", len(archives))
+	}
+}
+
+func TestPassthroughInContainer(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	oldMarkers, oldCgroup := containerMarkers, cgroupPath
+	defer func() { containerMarkers, cgroupPath = oldMarkers, oldCgroup }()
+	for _, k := range []string{"KUBERNETES_SERVICE_HOST", "container"} {
+		if v, ok := os.LookupEnv(k); ok {
+			os.Unsetenv(k)
+			defer os.Setenv(k, v)
+		}
+	}
+
+	containerMarkers = nil
+	cgroupPath = filepath.Join(dir, "cgroup")
+	ioutil.WriteFile(cgroupPath, []byte("0::/init.scope\n"), 0644)
+
+	var buf bytes.Buffer
+	r := newRolog("test", []Option{WithPassthrough(PassthroughInContainer, &buf)})
+	if r.passthrough != nil {
+		t.Errorf("Wanted files outside a container")
+	}
+
+	ioutil.WriteFile(cgroupPath, []byte("0::/kubepods/besteffort/pod1234\n"), 0644)
+	r = newRolog("test", []Option{WithPassthrough(PassthroughInContainer, &buf)})
+	if r.passthrough != &buf {
+		t.Errorf("Wanted pass-through inside a container")
+	}
+
+	r = newRolog("test", []Option{WithPassthrough(PassthroughNever, &buf)})
+	if r.passthrough != nil {
+		t.Errorf("Wanted files with PassthroughNever")
+	}
+}
@@ -0,0 +1,41 @@
+package rolog
+
+// Pause suspends scheduled rotations, for example during a backup window.
+// Writes and size-triggered rotations carry on as normal.
+func (r *Rolog) Pause() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.paused = true
+}
+
+// Resume re-enables scheduled rotations after Pause. If catchUp is true and a
+// scheduled rotation was skipped while paused, it is performed immediately.
+func (r *Rolog) Resume(catchUp bool) error {
+	r.mu.Lock()
+	missed := r.missed
+	r.paused, r.missed = false, false
+	r.mu.Unlock()
+
+	if !catchUp || !missed {
+		return nil
+	}
+	return r.rotateFor(ReasonScheduled)
+}
+
+// scheduledRotate performs a rotation on behalf of the run loop unless the
+// Rolog is paused, in which case it is recorded as missed.
+func (r *Rolog) scheduledRotate() error {
+	r.mu.Lock()
+	paused := r.paused
+	if paused {
+		r.missed = true
+	}
+	r.mu.Unlock()
+
+	if paused {
+		r.debugf("scheduled rotation skipped while paused")
+		return nil
+	}
+	return r.rotateFor(ReasonScheduled)
+}
@@ -0,0 +1,60 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestPauseSkipsAndResumeCatchesUp(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithInterval(time.Hour), WithClock(clock))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Run(context.Background())
+	for clock.numTickers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	r.Pause()
+	clock.Advance(time.Hour)
+	for {
+		r.mu.Lock()
+		missed := r.missed
+		r.mu.Unlock()
+		if missed {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if archives, _ := r.Archives(); len(archives) != 0 {
+		t.Errorf("Wanted no rotation while paused, got %d archives", len(archives))
+	}
+
+	if err := r.Resume(true); err != nil {
+		t.Errorf("unexpected error: %q", err)
+	}
+
+	if archives, _ := r.Archives(); len(archives) != 1 {
+		t.Errorf("Wanted the missed rotation on resume, got %d archives", len(archives))
+	}
+}
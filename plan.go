@@ -0,0 +1,202 @@
+package rolog
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// PlanAction is a kind of change to the files of a log.
+type PlanAction string
+
+// The changes a rotation and the archive processing after it make.
+const (
+	// ActionRename moves the current file to its archive.
+	ActionRename PlanAction = "rename"
+	// ActionCompress replaces an archive with a compressed one.
+	ActionCompress PlanAction = "compress"
+	// ActionEncrypt replaces an archive with an encrypted one.
+	ActionEncrypt PlanAction = "encrypt"
+	// ActionDelete removes an archive under retention.
+	ActionDelete PlanAction = "delete"
+	// ActionBundle moves an archive into its daily tarball.
+	ActionBundle PlanAction = "bundle"
+	// ActionRemove removes a file left by an interrupted rotation.
+	ActionRemove PlanAction = "remove"
+)
+
+// PlannedAction is a change that a rotation or retention would make.
+type PlannedAction struct {
+	Action PlanAction
+	// Path is the file changed.
+	Path string
+	// Target is where the file ends up, except for deletions.
+	Target string
+	// Reason is the retention limit behind a deletion, such as "max age",
+	// or what a removed file was, such as "temporary file".
+	Reason string
+}
+
+// WithDryRun stops the Rolog from processing its archives. Rotations still
+// move the current file aside, so the log keeps working, but the compression,
+// encryption, retention and bundling that would follow, and the repair of
+// interrupted rotations when the Rolog starts, are passed to report instead of
+// being done, so a new retention policy can be tried against a production
+// directory without losing anything to it. report is called from New and the
+// archive processing, one action at a time.
+func WithDryRun(report func(PlannedAction)) Option {
+	return func(r *Rolog) {
+		r.dryRun = report
+	}
+}
+
+// PlanRotation returns what rotating now would do, in order, without doing it:
+// the rename of the current file and the processing of the archive, for the
+// log and each of its children. Deletions are worked out from the archives as
+// they are now, so they can differ from those of a later rotation.
+func (r *Rolog) PlanRotation() ([]PlannedAction, error) {
+	r.mu.Lock()
+	if r.passthrough != nil {
+		r.mu.Unlock()
+		return nil, nil
+	}
+	var (
+		s       = r.archiveSettings()
+		current = r.currentPath()
+		size    = r.size
+		archive = r.uniquePath(filepath.Join(filepath.Dir(r.currentPath()), r.fname()))
+		now     = r.now()
+		reopen  bool
+	)
+	switch r.rotationStrategy().(type) {
+	case ReopenStrategy, TruncateStrategy:
+		reopen = true
+	}
+	r.mu.Unlock()
+
+	var plan []PlannedAction
+	if !reopen {
+		plan = append(plan, PlannedAction{Action: ActionRename, Path: current, Target: archive})
+		// The new current file has yet to be written.
+		s.current = 0
+		processing, err := r.planProcessing(s, ArchiveInfo{Path: archive, End: now, Size: size}, now)
+		if err != nil {
+			return nil, err
+		}
+		plan = append(plan, processing...)
+	}
+
+	for _, c := range s.children {
+		cplan, err := c.PlanRotation()
+		if err != nil {
+			return nil, err
+		}
+		plan = append(plan, cplan...)
+	}
+	return plan, nil
+}
+
+// PlanPrune returns what retention and bundling would do to the archives as
+// they are now, in order, without doing it.
+func (r *Rolog) PlanPrune() ([]PlannedAction, error) {
+	r.mu.Lock()
+	s := r.archiveSettings()
+	r.mu.Unlock()
+
+	return r.planProcessing(s, ArchiveInfo{}, r.now())
+}
+
+// planProcessing returns what processing the archive a, if it has a path, and
+// then applying retention and bundling under s at now would do.
+func (r *Rolog) planProcessing(s archiveSettings, a ArchiveInfo, now time.Time) ([]PlannedAction, error) {
+	archives, err := r.archives()
+	if err != nil {
+		return nil, err
+	}
+
+	var plan []PlannedAction
+	if a.Path != "" {
+		// The archive is already listed if it was just rotated, but not
+		// under the name it ends up with.
+		rest := archives[:0:0]
+		for _, b := range archives {
+			if b.Path == a.Path {
+				a = b
+				continue
+			}
+			rest = append(rest, b)
+		}
+
+		var finalized []PlannedAction
+		a.Path, finalized = planFinalize(s, a.Path)
+		plan = append(plan, finalized...)
+
+		archives = append(rest, a)
+		sort.SliceStable(archives, func(i, j int) bool {
+			return archives[i].End.Before(archives[j].End)
+		})
+	}
+
+	removed := make(map[string]bool)
+	if s.maxTotalSize > 0 || s.maxBackups > 0 || s.maxAge > 0 {
+		bundles, err := r.bundles()
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range expired(s, withBundles(archives, bundles), now) {
+			plan = append(plan, PlannedAction{Action: ActionDelete, Path: v.Path, Reason: v.limit})
+			removed[v.Path] = true
+		}
+	}
+
+	if s.bundleAge > 0 {
+		var left []ArchiveInfo
+		for _, b := range archives {
+			if !removed[b.Path] {
+				left = append(left, b)
+			}
+		}
+		days := r.bundleDays(s, left, now)
+		tarballs := make([]string, 0, len(days))
+		for path := range days {
+			tarballs = append(tarballs, path)
+		}
+		sort.Strings(tarballs)
+		for _, path := range tarballs {
+			for _, b := range days[path] {
+				plan = append(plan, PlannedAction{Action: ActionBundle, Path: b.Path, Target: path})
+			}
+		}
+	}
+	return plan, nil
+}
+
+// planFinalize returns what finalize would do to the archive at path under s,
+// and the path it would end up at.
+func planFinalize(s archiveSettings, path string) (string, []PlannedAction) {
+	var plan []PlannedAction
+	if s.compression != NoCompression && !isCompressed(filepath.Ext(path)) {
+		dst := path + s.compression.Extension()
+		plan = append(plan, PlannedAction{Action: ActionCompress, Path: path, Target: dst})
+		path = dst
+	}
+	if s.encrypter != nil {
+		dst := path + s.encrypter.Extension()
+		plan = append(plan, PlannedAction{Action: ActionEncrypt, Path: path, Target: dst})
+		path = dst
+	}
+	return path, plan
+}
+
+// dryRunProcess reports what processing the archive at archived under s would
+// do, in place of doing it.
+func (r *Rolog) dryRunProcess(s archiveSettings, archived string) error {
+	plan, err := r.planProcessing(s, ArchiveInfo{Path: archived, End: r.now()}, r.now())
+	if err != nil {
+		return err
+	}
+	for _, p := range plan {
+		s.dryRun(p)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestPlanRotationChangesNothing(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithMaxBackups(2), WithCompression(Gzip))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	for i := 0; i < 3; i++ {
+		r.Write([]byte("hello\n"))
+		if err := r.Rotate(); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		clock.Advance(time.Hour)
+	}
+	r.Write([]byte("hello\n"))
+
+	before, err := r.Archives()
+	if err != nil || len(before) != 2 {
+		t.Errorf("Wanted 2 archives, got %d (%v)", len(before), err)
+		t.FailNow()
+	}
+
+	plan, err := r.PlanPrune()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+	}
+	if len(plan) != 0 {
+		t.Errorf("Wanted nothing to prune, got %+v", plan)
+	}
+
+	plan, err = r.PlanRotation()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	want := []PlanAction{ActionRename, ActionCompress, ActionDelete}
+	if len(plan) != len(want) {
+		t.Errorf("Wanted %v, got %+v", want, plan)
+		t.FailNow()
+	}
+	for i, p := range plan {
+		if p.Action != want[i] {
+			t.Errorf("Wanted action %d to be %s, got %+v", i, want[i], p)
+		}
+	}
+	if plan[0].Path != r.CurrentPath() || plan[1].Path != plan[0].Target || plan[1].Target != plan[0].Target+".gz" {
+		t.Errorf("Wanted the current file archived and compressed, got %+v", plan)
+	}
+	if plan[2].Path != before[0].Path || plan[2].Reason != "max backups" {
+		t.Errorf("Wanted the oldest archive deleted, got %+v", plan[2])
+	}
+
+	after, err := r.Archives()
+	if err != nil || len(after) != 2 || after[0].Path != before[0].Path {
+		t.Errorf("Wanted the archives untouched, got %+v (%v)", after, err)
+	}
+}
+
+func TestWithDryRunReportsProcessing(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	var (
+		clock   = newFakeClock()
+		planned []PlannedAction
+	)
+	r, err := New(dir, "test", WithClock(clock), WithMaxBackups(1), WithCompression(Gzip), WithDryRun(func(p PlannedAction) {
+		planned = append(planned, p)
+	}))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	for i := 0; i < 2; i++ {
+		r.Write([]byte("hello\n"))
+		if err := r.Rotate(); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		clock.Advance(time.Hour)
+	}
+
+	archives, err := r.Archives()
+	if err != nil || len(archives) != 2 || archives[0].Compressed || archives[1].Compressed {
+		t.Errorf("Wanted both archives kept uncompressed, got %+v (%v)", archives, err)
+	}
+
+	// The first rotation compresses, the second compresses and deletes.
+	want := []PlanAction{ActionCompress, ActionCompress, ActionDelete}
+	if len(planned) != len(want) {
+		t.Errorf("Wanted %v, got %+v", want, planned)
+		t.FailNow()
+	}
+	for i, p := range planned {
+		if p.Action != want[i] {
+			t.Errorf("Wanted action %d to be %s, got %+v", i, want[i], p)
+		}
+	}
+	if planned[2].Path != archives[0].Path {
+		t.Errorf("Wanted the older archive deleted, got %+v", planned[2])
+	}
+}
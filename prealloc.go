@@ -0,0 +1,37 @@
+package rolog
+
+// WithPreallocate reserves n bytes of disk space for every new current file,
+// to reduce fragmentation and surface a full disk at rotation time rather than
+// mid-interval. If n is zero, the max size is used. The reservation doesn't
+// change the visible size of the file. It is best-effort, and does nothing on
+// platforms other than Linux or if the FS doesn't hand out *os.File-like
+// handles.
+func WithPreallocate(n int64) Option {
+	return func(r *Rolog) {
+		r.prealloc = n
+		if n == 0 {
+			r.prealloc = -1
+		}
+	}
+}
+
+// fder is implemented by files backed by a file descriptor, like *os.File.
+type fder interface {
+	Fd() uintptr
+}
+
+// preallocate reserves space for f according to the configuration, ignoring
+// any failure. It must be called with mu held.
+func (r *Rolog) preallocate(f File) {
+	n := r.prealloc
+	if n < 0 {
+		n = r.settings().MaxSize
+	}
+	if n <= 0 {
+		return
+	}
+
+	if fd, ok := f.(fder); ok {
+		fallocate(fd.Fd(), n)
+	}
+}
@@ -0,0 +1,12 @@
+package rolog
+
+import "syscall"
+
+// fallocKeepSize is FALLOC_FL_KEEP_SIZE, which reserves blocks without
+// extending the file.
+const fallocKeepSize = 0x1
+
+// fallocate reserves n bytes for the file open at fd.
+func fallocate(fd uintptr, n int64) error {
+	return syscall.Fallocate(int(fd), fallocKeepSize, 0, n)
+}
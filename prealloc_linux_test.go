@@ -0,0 +1,45 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestPreallocateReservesSpace(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	const size = 1 << 20
+	r, err := New(dir, "test", WithMaxSize(size), WithPreallocate(0))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	fi, err := os.Stat(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if fi.Size() != 0 {
+		t.Errorf("Wanted the visible size to stay 0, got %d", fi.Size())
+	}
+	if blocks := fi.Sys().(*syscall.Stat_t).Blocks; blocks*512 < size {
+		if err := fallocate(r.f.(fder).Fd(), size); err != nil {
+			t.Skipf("filesystem doesn't support fallocate: %v", err)
+		}
+		t.Errorf("Wanted at least %d bytes reserved, got %d", size, blocks*512)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package rolog
+
+// fallocate is a no-op where preallocation isn't supported.
+func fallocate(fd uintptr, n int64) error {
+	return nil
+}
@@ -0,0 +1,63 @@
+package rolog
+
+import "time"
+
+// WithPrefix prepends prefix to every line written through the Rolog.
+func WithPrefix(prefix string) Option {
+	return WithPrefixFunc(func() string { return prefix })
+}
+
+// WithPrefixFunc prepends the result of f to every line written through the
+// Rolog, for dynamic values such as request IDs. It adds a PrefixEncoder to the
+// Rolog's encoders.
+func WithPrefixFunc(f func() string) Option {
+	return func(r *Rolog) {
+		r.encoders = append(r.encoders, &PrefixEncoder{Prefix: f})
+	}
+}
+
+// WithTimestamps prepends the time of each write, formatted with layout and
+// followed by a space, to every line written through the Rolog, for callers
+// that write raw bytes without timestamps of their own. An empty layout means
+// time.RFC3339Nano. It adds a TimestampEncoder to the Rolog's encoders, so to
+// put the timestamp ahead of a prefix, give WithTimestamps after WithPrefix.
+func WithTimestamps(layout string) Option {
+	return func(r *Rolog) {
+		r.encoders = append(r.encoders, &TimestampEncoder{Layout: layout})
+	}
+}
+
+// PrefixEncoder is an Encoder that prepends the result of Prefix to every line.
+// Prefix is called once per write and its result is applied to each line in
+// that write. Lines split across several writes are only prefixed once.
+type PrefixEncoder struct {
+	Prefix func() string
+
+	midLine bool
+}
+
+// Encode satisfies Encoder.
+func (e *PrefixEncoder) Encode(dst, p []byte, now time.Time) []byte {
+	return prefixLines(dst, p, stringBytes(e.Prefix()), &e.midLine)
+}
+
+// TimestampEncoder is an Encoder that prepends the time of each write,
+// formatted with Layout and followed by a space, to every line. Every line of a
+// multi-line write is stamped. An empty Layout means time.RFC3339Nano.
+type TimestampEncoder struct {
+	Layout string
+
+	midLine bool
+	stamp   []byte
+}
+
+// Encode satisfies Encoder.
+func (e *TimestampEncoder) Encode(dst, p []byte, now time.Time) []byte {
+	layout := e.Layout
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+
+	e.stamp = append(now.AppendFormat(e.stamp[:0], layout), ' ')
+	return prefixLines(dst, p, e.stamp, &e.midLine)
+}
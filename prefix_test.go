@@ -0,0 +1,83 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithPrefixPrefixesEveryLine(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithPrefix("[app] "))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	n, err := r.Write([]byte("one\ntwo\nthr"))
+	if err != nil || n != 11 {
+		t.Errorf("Wanted 11 bytes written, got %d (%v)", n, err)
+	}
+	r.Write([]byte("ee\nfour\n"))
+
+	got, err := ioutil.ReadFile(filepath.Join(dir, "test.log"))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	want := "[app] one\n[app] two\n[app] three\n[app] four\n"
+	if string(got) != want {
+		t.Errorf("Wanted %q, got %q", want, got)
+	}
+}
+
+func TestWithTimestampsStampsEveryLine(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithPrefix("[app] "), WithTimestamps(""))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("one\ntwo\n"))
+
+	got, err := ioutil.ReadFile(filepath.Join(dir, "test.log"))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	ts := clock.Now().Format(time.RFC3339Nano)
+	want := ts + " [app] one\n" + ts + " [app] two\n"
+	if string(got) != want {
+		t.Errorf("Wanted %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,180 @@
+// Package promcollector exports the measurements of a Rolog to Prometheus.
+package promcollector
+
+import (
+	"time"
+
+	"github.com/haleyrc/rolog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Opts customizes the metrics of a Collector.
+type Opts struct {
+	// Namespace prefixes every metric name. It defaults to "rolog".
+	Namespace string
+	// ConstLabels are added to every metric, for telling several Rologs in
+	// one process apart.
+	ConstLabels prometheus.Labels
+	// Buckets are the histogram buckets for write and rotation durations,
+	// in seconds. They default to prometheus.DefBuckets.
+	Buckets []float64
+}
+
+// Collector is a prometheus.Collector and rolog.Metrics reporting on a single
+// Rolog. Archive counts and sizes are read from disk on every scrape; every
+// other metric is updated as the Rolog works.
+type Collector struct {
+	r *rolog.Rolog
+
+	written        prometheus.Counter
+	writeLatency   prometheus.Histogram
+	rotations      *prometheus.CounterVec
+	rotateDuration prometheus.Histogram
+	rotatePause    prometheus.Histogram
+	lastRotation   prometheus.Gauge
+	pruned         prometheus.Counter
+	errors         prometheus.Counter
+	dropped        *prometheus.CounterVec
+	archives       *prometheus.Desc
+	archiveBytes   *prometheus.Desc
+	scrapeErrors   prometheus.Counter
+}
+
+var (
+	_ prometheus.Collector = (*Collector)(nil)
+	_ rolog.Metrics        = (*Collector)(nil)
+)
+
+// New returns a Collector for r and adds it to r's Metrics. It still has to be
+// registered, for example with prometheus.MustRegister.
+func New(r *rolog.Rolog, opts Opts) *Collector {
+	ns := opts.Namespace
+	if ns == "" {
+		ns = "rolog"
+	}
+	buckets := opts.Buckets
+	if buckets == nil {
+		buckets = prometheus.DefBuckets
+	}
+	labels := opts.ConstLabels
+
+	c := &Collector{
+		r: r,
+		written: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: ns, Name: "written_bytes_total", ConstLabels: labels,
+			Help: "Bytes accepted by Write.",
+		}),
+		writeLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: ns, Name: "write_duration_seconds", ConstLabels: labels,
+			Help: "How long each Write took.", Buckets: buckets,
+		}),
+		rotations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "rotations_total", ConstLabels: labels,
+			Help: "Rotations performed, by reason.",
+		}, []string{"reason"}),
+		rotateDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: ns, Name: "rotation_duration_seconds", ConstLabels: labels,
+			Help: "How long each rotation took, including finalizing the archive.", Buckets: buckets,
+		}),
+		rotatePause: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: ns, Name: "rotation_pause_seconds", ConstLabels: labels,
+			Help: "How long each rotation blocked writes.", Buckets: buckets,
+		}),
+		lastRotation: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns, Name: "last_rotation_timestamp_seconds", ConstLabels: labels,
+			Help: "When the last rotation finished, as a Unix time.",
+		}),
+		pruned: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: ns, Name: "pruned_archives_total", ConstLabels: labels,
+			Help: "Archives deleted by retention or Purge.",
+		}),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: ns, Name: "errors_total", ConstLabels: labels,
+			Help: "Errors reported by background work.",
+		}),
+		dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "dropped_total", ConstLabels: labels,
+			Help: "Entries discarded instead of written, by reason.",
+		}, []string{"reason"}),
+		archives: prometheus.NewDesc(prometheus.BuildFQName(ns, "", "archives"),
+			"Archives currently on disk.", nil, labels),
+		archiveBytes: prometheus.NewDesc(prometheus.BuildFQName(ns, "", "archive_bytes"),
+			"Total size of the archives currently on disk.", nil, labels),
+		scrapeErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: ns, Name: "scrape_errors_total", ConstLabels: labels,
+			Help: "Scrapes that could not list the archives.",
+		}),
+	}
+	r.AddMetrics(c)
+	return c
+}
+
+// Wrote satisfies rolog.Metrics.
+func (c *Collector) Wrote(n int, took time.Duration) {
+	c.written.Add(float64(n))
+	c.writeLatency.Observe(took.Seconds())
+}
+
+// Rotated satisfies rolog.Metrics.
+func (c *Collector) Rotated(e rolog.RotationEvent) {
+	c.rotations.WithLabelValues(string(e.Reason)).Inc()
+	c.rotateDuration.Observe(e.Duration.Seconds())
+	c.rotatePause.Observe(e.Paused.Seconds())
+	c.lastRotation.SetToCurrentTime()
+}
+
+// Removed satisfies rolog.Metrics.
+func (c *Collector) Removed(rolog.ArchiveInfo) {
+	c.pruned.Inc()
+}
+
+// Failed satisfies rolog.Metrics.
+func (c *Collector) Failed(error) {
+	c.errors.Inc()
+}
+
+// Dropped satisfies rolog.Metrics.
+func (c *Collector) Dropped(reason rolog.DropReason) {
+	c.dropped.WithLabelValues(string(reason)).Inc()
+}
+
+// Describe satisfies prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.written.Describe(ch)
+	c.writeLatency.Describe(ch)
+	c.rotations.Describe(ch)
+	c.rotateDuration.Describe(ch)
+	c.rotatePause.Describe(ch)
+	c.lastRotation.Describe(ch)
+	c.pruned.Describe(ch)
+	c.errors.Describe(ch)
+	c.dropped.Describe(ch)
+	c.scrapeErrors.Describe(ch)
+	ch <- c.archives
+	ch <- c.archiveBytes
+}
+
+// Collect satisfies prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	if archives, err := c.r.Archives(); err != nil {
+		c.scrapeErrors.Inc()
+	} else {
+		var size int64
+		for _, a := range archives {
+			size += a.Size
+		}
+		ch <- prometheus.MustNewConstMetric(c.archives, prometheus.GaugeValue, float64(len(archives)))
+		ch <- prometheus.MustNewConstMetric(c.archiveBytes, prometheus.GaugeValue, float64(size))
+	}
+
+	c.written.Collect(ch)
+	c.writeLatency.Collect(ch)
+	c.rotations.Collect(ch)
+	c.rotateDuration.Collect(ch)
+	c.rotatePause.Collect(ch)
+	c.lastRotation.Collect(ch)
+	c.pruned.Collect(ch)
+	c.errors.Collect(ch)
+	c.dropped.Collect(ch)
+	c.scrapeErrors.Collect(ch)
+}
@@ -0,0 +1,79 @@
+package promcollector
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/haleyrc/rolog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestCollectorReportsWritesRotationsAndArchives(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := rolog.New(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(New(r, Opts{ConstLabels: prometheus.Labels{"log": "test"}}))
+
+	r.Write([]byte("hello\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	got := make(map[string]float64)
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			switch {
+			case m.GetCounter() != nil:
+				got[mf.GetName()] += m.GetCounter().GetValue()
+			case m.GetGauge() != nil:
+				got[mf.GetName()] = m.GetGauge().GetValue()
+			case m.GetHistogram() != nil:
+				got[mf.GetName()] = float64(m.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+
+	want := map[string]float64{
+		"rolog_written_bytes_total":       6,
+		"rolog_write_duration_seconds":    1,
+		"rolog_rotations_total":           1,
+		"rolog_rotation_duration_seconds": 1,
+		"rolog_rotation_pause_seconds":    1,
+		"rolog_archives":                  1,
+		"rolog_archive_bytes":             6,
+		"rolog_errors_total":              0,
+	}
+	for name, v := range want {
+		if got[name] != v {
+			t.Errorf("Wanted %s to be %v, got %v", name, v, got[name])
+		}
+	}
+	if got["rolog_last_rotation_timestamp_seconds"] == 0 {
+		t.Errorf("Wanted the last rotation time to be set")
+	}
+}
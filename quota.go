@@ -0,0 +1,87 @@
+package rolog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Quota caps how much a Rolog writes in each window of time, such as 2 GB a
+// day, whatever rotation and retention are doing, so that a storm of logging
+// can't fill the disk.
+type Quota struct {
+	// Bytes is how much may be written in each window.
+	Bytes int64
+	// Window is how long each window lasts. A window starts with the first
+	// write after the previous one ended.
+	Window time.Duration
+	// SampleEvery, if positive, lets one in every SampleEvery writes over
+	// the quota through, so the log still shows what is going on. Otherwise
+	// every write over the quota is discarded.
+	SampleEvery int
+}
+
+// WithQuota discards writes once q.Bytes have been written in the current
+// window, until the next window starts. The first write over the quota is
+// preceded by a line in the log saying so, and the first write of the next
+// window by one saying how many writes were discarded, so the gap is never
+// silent. Discarded writes are reported as successful to the caller and
+// counted in Dropped. A quota without positive Bytes and Window is ignored.
+func WithQuota(q Quota) Option {
+	return func(r *Rolog) {
+		if q.Bytes <= 0 || q.Window <= 0 {
+			r.quota = nil
+			return
+		}
+		r.quota = &quota{cfg: q}
+	}
+}
+
+// quota applies a Quota.
+type quota struct {
+	mu  sync.Mutex
+	cfg Quota
+	// start is when the window began, used is how much has been written in
+	// it, and over counts the writes in it that went over the quota
+	start time.Time
+	used  int64
+	over  int
+}
+
+// admit accounts for a write of n bytes at now. It reports whether the write
+// may go ahead, and returns any line to write into the log first.
+func (q *quota) admit(now time.Time, n int) (bool, string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var note string
+	if q.start.IsZero() || !now.Before(q.start.Add(q.cfg.Window)) {
+		if q.over > 0 {
+			note = fmt.Sprintf("rolog: log quota reset, %d writes over it were dropped\n", q.over)
+		}
+		q.start, q.used, q.over = now, 0, 0
+	}
+
+	if q.used+int64(n) <= q.cfg.Bytes {
+		q.used += int64(n)
+		return true, note
+	}
+
+	if q.over++; q.over == 1 {
+		until := q.start.Add(q.cfg.Window)
+		note += fmt.Sprintf("rolog: log quota of %d bytes per %s reached, dropping writes until %s\n", q.cfg.Bytes, q.cfg.Window, until.Format(time.RFC3339))
+	}
+	return q.cfg.SampleEvery > 0 && q.over%q.cfg.SampleEvery == 0, note
+}
+
+// withinQuota applies the quota to a write of n bytes, writing any line about
+// it into the log. It reports whether the write should go ahead.
+func (r *Rolog) withinQuota(n int) bool {
+	ok, note := r.quota.admit(r.now(), n)
+	if note != "" {
+		if _, err := r.dispatch([]byte(note)); err != nil && err != ErrClosed {
+			r.report(err)
+		}
+	}
+	return ok
+}
@@ -0,0 +1,70 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestQuotaDropsWritesUntilTheNextWindow(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithQuota(Quota{Bytes: 10, Window: time.Hour}))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	for _, line := range []string{"aaaa\n", "bbbb\n", "cccc\n", "dddd\n"} {
+		if n, err := r.Write([]byte(line)); n != len(line) || err != nil {
+			t.Errorf("Wanted %d bytes written, got %d (%v)", len(line), n, err)
+		}
+	}
+	if n := r.DroppedBy().Quota; n != 2 {
+		t.Errorf("Wanted 2 writes dropped for the quota, got %d", n)
+	}
+
+	clock.Advance(time.Hour)
+	r.Write([]byte("eeee\n"))
+
+	got, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	want := "aaaa\nbbbb\n" +
+		"rolog: log quota of 10 bytes per 1h0m0s reached, dropping writes until " + clock.Now().Format(time.RFC3339) + "\n" +
+		"rolog: log quota reset, 2 writes over it were dropped\n" +
+		"eeee\n"
+	if string(got) != want {
+		t.Errorf("Wanted the log to contain %q, got %q", want, got)
+	}
+}
+
+func TestQuotaSamplesWritesOverIt(t *testing.T) {
+	q := &quota{cfg: Quota{Bytes: 1, Window: time.Hour, SampleEvery: 3}}
+	now := time.Now()
+
+	var through int
+	for i := 0; i < 10; i++ {
+		if ok, _ := q.admit(now, 1); ok {
+			through++
+		}
+	}
+	// the first fits, then one in three of the other nine
+	if through != 4 {
+		t.Errorf("Wanted 4 writes through, got %d", through)
+	}
+}
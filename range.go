@@ -0,0 +1,149 @@
+package rolog
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"time"
+)
+
+// ReadRange returns a reader of what was logged from from until to, read from
+// the archives and the current file in order and decompressed as needed. The
+// files are chosen by the times in their names, so the archives rotated out
+// before from and those started after to aren't opened at all. As with
+// History, what is read is what was there when ReadRange was called.
+//
+// Within those files, lines that start with a timestamp are kept only if it
+// falls within [from, to), and lines without one, such as the continuations of
+// a multi-line message, go with the line before them. Timestamps written by
+// WithTimestamps, WithJSONLines and WithLogfmt are recognized, as is an
+// RFC 3339 timestamp at the start of a line. A log without timestamps is
+// returned a whole file at a time. A zero from or to leaves that end of the
+// range open.
+func (r *Rolog) ReadRange(from, to time.Time) (io.ReadCloser, error) {
+	h, err := r.snapshot(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rangeReader{
+		h:       h,
+		br:      bufio.NewReader(h),
+		from:    from,
+		to:      to,
+		layouts: r.timestampLayouts(),
+		keep:    true,
+	}, nil
+}
+
+// timestampLayouts returns the layouts lines may be timestamped with.
+func (r *Rolog) timestampLayouts() []string {
+	layouts := []string{time.RFC3339Nano}
+	for _, enc := range r.encoders {
+		if ts, ok := enc.(*TimestampEncoder); ok && ts.Layout != "" {
+			layouts = append(layouts, ts.Layout)
+		}
+	}
+	return layouts
+}
+
+// rangeReader reads the lines of a snapshot that fall within a time range.
+type rangeReader struct {
+	h        *historyReader
+	br       *bufio.Reader
+	from, to time.Time
+	layouts  []string
+
+	buf []byte
+	// keep says whether lines without a timestamp of their own are kept
+	keep bool
+	err  error
+}
+
+// Read satisfies io.Reader.
+func (rr *rangeReader) Read(p []byte) (int, error) {
+	for len(rr.buf) == 0 {
+		if rr.err != nil {
+			return 0, rr.err
+		}
+		rr.next()
+	}
+
+	n := copy(p, rr.buf)
+	rr.buf = rr.buf[n:]
+	return n, nil
+}
+
+// next buffers the next line if it is in the range, or records why there are
+// no more.
+func (rr *rangeReader) next() {
+	line, err := rr.br.ReadBytes('\n')
+	if err != nil {
+		rr.err = err
+	}
+
+	if t, ok := lineTime(line, rr.layouts); ok {
+		if !rr.to.IsZero() && !t.Before(rr.to) {
+			// Everything after this is later still.
+			rr.err = io.EOF
+			return
+		}
+		rr.keep = rr.from.IsZero() || !t.Before(rr.from)
+	}
+	if rr.keep {
+		rr.buf = line
+	}
+}
+
+// Close satisfies io.Closer.
+func (rr *rangeReader) Close() error {
+	rr.buf, rr.err = nil, io.EOF
+	return rr.h.Close()
+}
+
+// lineTime returns the timestamp at the start of line, written as the ts field
+// of a JSON or logfmt line or in one of layouts.
+func lineTime(line []byte, layouts []string) (time.Time, bool) {
+	var (
+		json   = []byte(`{"ts":"`)
+		logfmt = []byte("ts=")
+	)
+	switch {
+	case bytes.HasPrefix(line, json):
+		line = line[len(json):]
+		if i := bytes.IndexByte(line, '"'); i >= 0 {
+			t, err := time.Parse(time.RFC3339Nano, string(line[:i]))
+			return t, err == nil
+		}
+		return time.Time{}, false
+	case bytes.HasPrefix(line, logfmt):
+		line = line[len(logfmt):]
+		if i := bytes.IndexByte(line, ' '); i >= 0 {
+			line = line[:i]
+		}
+		t, err := time.Parse(time.RFC3339Nano, string(bytes.TrimSpace(line)))
+		return t, err == nil
+	}
+
+	for _, layout := range layouts {
+		// The layout may contain spaces, so try as many words as it has.
+		words := bytes.Count([]byte(layout), []byte(" ")) + 1
+		if t, err := time.ParseInLocation(layout, string(leadingWords(line, words)), time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// leadingWords returns the first n space separated words of line.
+func leadingWords(line []byte, n int) []byte {
+	end := 0
+	for i := 0; i < n; i++ {
+		j := bytes.IndexByte(line[end:], ' ')
+		if j < 0 {
+			return bytes.TrimRight(line, "\r\n")
+		}
+		end += j + 1
+	}
+	return line[:end-1]
+}
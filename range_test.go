@@ -0,0 +1,119 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadRangeSelectsTimestampedLines(t *testing.T) {
+	for _, layout := range []string{"", "2006-01-02 15:04:05"} {
+		dir, err := ioutil.TempDir(".", "tmp")
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		defer func() {
+			if err := os.RemoveAll(dir); err != nil {
+				t.Errorf("could not cleanup temp files: %q", err)
+			}
+		}()
+
+		clk := newFakeClock()
+		start := clk.Now()
+		r, err := New(dir, "test", WithClock(clk), WithCompression(Gzip), WithTimestamps(layout))
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		defer r.Close()
+
+		// Six lines ten minutes apart, rotated every other line.
+		for i := 0; i < 6; i++ {
+			if i > 0 && i%2 == 0 {
+				r.Rotate()
+			}
+			r.Write([]byte("line " + string(rune('a'+i)) + "\n"))
+			clk.Advance(10 * time.Minute)
+		}
+
+		tests := []struct {
+			from, to time.Time
+			want     string
+		}{
+			{want: "abcdef"},
+			{from: start.Add(15 * time.Minute), to: start.Add(35 * time.Minute), want: "cd"},
+			{from: start.Add(10 * time.Minute), to: start.Add(20 * time.Minute), want: "b"},
+			{from: start.Add(45 * time.Minute), want: "f"},
+			{to: start.Add(5 * time.Minute), want: "a"},
+		}
+		for _, tt := range tests {
+			rc, err := r.ReadRange(tt.from, tt.to)
+			if err != nil {
+				t.Errorf("unexpected error: %q", err)
+				continue
+			}
+			b, err := ioutil.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				t.Errorf("unexpected error: %q", err)
+				continue
+			}
+
+			var got string
+			for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+				if line != "" {
+					got += line[len(line)-1:]
+				}
+			}
+			if got != tt.want {
+				t.Errorf("Wanted lines %q between %v and %v with layout %q, got %q", tt.want, tt.from, tt.to, layout, b)
+			}
+		}
+	}
+}
+
+func TestReadRangeReturnsWholeFilesWithoutTimestamps(t *testing.T) {
+	r, clk, cleanup := newHistory(t)
+	defer cleanup()
+
+	rc, err := r.ReadRange(clk.Now().Add(-90*time.Minute), time.Time{})
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer rc.Close()
+
+	b, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if want := "second one\nsecond two ERROR\nsecond three\nthird one\nthird two ERROR\nthird three\n"; string(b) != want {
+		t.Errorf("Wanted %q, got %q", want, b)
+	}
+}
+
+func TestLineTime(t *testing.T) {
+	want := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		line string
+		ok   bool
+	}{
+		{line: "2020-01-02T03:04:05Z hello\n", ok: true},
+		{line: `{"ts":"2020-01-02T03:04:05Z","msg":"hello"}` + "\n", ok: true},
+		{line: "ts=2020-01-02T03:04:05Z msg=hello\n", ok: true},
+		{line: "2020-01-02T03:04:05Z\n", ok: true},
+		{line: "  at continuation\n"},
+		{line: "hello\n"},
+		{line: ""},
+	}
+	for _, tt := range tests {
+		got, ok := lineTime([]byte(tt.line), []string{time.RFC3339Nano})
+		if ok != tt.ok || ok && !got.Equal(want) {
+			t.Errorf("Wanted %v, %t for %q, got %v, %t", want, tt.ok, tt.line, got, ok)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package rolog
+
+import (
+	"sync"
+	"time"
+)
+
+// LimitPolicy decides what happens to writes over the rate limit.
+type LimitPolicy int
+
+const (
+	// LimitBlock makes the writer wait until the write fits within the limit.
+	LimitBlock LimitPolicy = iota
+	// LimitDrop discards writes over the limit.
+	LimitDrop
+	// LimitSample discards writes over the limit, except for every
+	// SampleEvery'th one, so a runaway loop stays visible in the logs.
+	LimitSample
+)
+
+// RateLimit caps how fast the application can write through a Rolog. Each limit
+// allows bursts of up to one second's worth.
+type RateLimit struct {
+	// BytesPerSecond caps throughput. Zero means no limit.
+	BytesPerSecond int64
+	// WritesPerSecond caps the number of writes. Zero means no limit.
+	WritesPerSecond int
+	// Policy is what happens to writes over the limit.
+	Policy LimitPolicy
+	// SampleEvery is how many over-limit writes make up each one let through
+	// under LimitSample.
+	SampleEvery int
+}
+
+// WithRateLimit throttles writes with a token bucket, so a runaway debug loop
+// can't saturate the disk. Dropped writes are reported as successful to the
+// caller and counted in Dropped.
+func WithRateLimit(l RateLimit) Option {
+	return func(r *Rolog) {
+		if l.BytesPerSecond <= 0 && l.WritesPerSecond <= 0 {
+			r.limiter = nil
+			return
+		}
+		r.limiter = &limiter{
+			cfg:    l,
+			bytes:  newBucket(float64(l.BytesPerSecond)),
+			writes: newBucket(float64(l.WritesPerSecond)),
+		}
+	}
+}
+
+// bucket is a token bucket holding up to one second of its rate. A zero rate
+// never limits.
+type bucket struct {
+	rate, tokens float64
+	last         time.Time
+}
+
+// newBucket returns a full bucket refilled at rate tokens per second.
+func newBucket(rate float64) *bucket {
+	return &bucket{rate: rate, tokens: rate}
+}
+
+// refill adds the tokens accrued since the last refill.
+func (b *bucket) refill(now time.Time) {
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.rate {
+			b.tokens = b.rate
+		}
+	}
+	b.last = now
+}
+
+// fits reports whether n tokens can be taken. A request larger than the bucket
+// fits once the bucket is full.
+func (b *bucket) fits(n float64) bool {
+	return b.rate <= 0 || b.tokens >= n || b.tokens >= b.rate
+}
+
+// take removes n tokens, possibly going into debt, and returns how long it will
+// take to repay it.
+func (b *bucket) take(n float64) time.Duration {
+	if b.rate <= 0 {
+		return 0
+	}
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// limiter applies a RateLimit.
+type limiter struct {
+	mu            sync.Mutex
+	cfg           RateLimit
+	bytes, writes *bucket
+	// over counts writes over the limit under LimitSample
+	over int
+}
+
+// reserve accounts for a write of n bytes at now. It reports whether the write
+// may go ahead and, if so, how long it must wait first.
+func (l *limiter) reserve(now time.Time, n int) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.bytes.refill(now)
+	l.writes.refill(now)
+
+	if l.cfg.Policy != LimitBlock && !(l.bytes.fits(float64(n)) && l.writes.fits(1)) {
+		if l.cfg.Policy == LimitDrop || l.cfg.SampleEvery <= 0 {
+			return 0, false
+		}
+		if l.over++; l.over%l.cfg.SampleEvery != 0 {
+			return 0, false
+		}
+	}
+
+	wait := l.bytes.take(float64(n))
+	if w := l.writes.take(1); w > wait {
+		wait = w
+	}
+	if l.cfg.Policy != LimitBlock {
+		wait = 0
+	}
+	return wait, true
+}
+
+// throttle applies the rate limit to a write of n bytes, waiting if necessary.
+// It reports whether the write should go ahead.
+func (r *Rolog) throttle(n int) bool {
+	wait, ok := r.limiter.reserve(r.now(), n)
+	if !ok {
+		return false
+	}
+
+	if wait > 0 {
+		t := r.clock.NewTicker(wait)
+		<-t.C()
+		t.Stop()
+	}
+	return true
+}
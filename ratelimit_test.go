@@ -0,0 +1,100 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimitPolicies(t *testing.T) {
+	cases := []struct {
+		limit RateLimit
+		want  int
+	}{
+		{RateLimit{WritesPerSecond: 2, Policy: LimitDrop}, 4},
+		{RateLimit{BytesPerSecond: 12, Policy: LimitDrop}, 6},
+		{RateLimit{WritesPerSecond: 2, Policy: LimitSample, SampleEvery: 4}, 6},
+	}
+
+	for _, c := range cases {
+		dir, err := ioutil.TempDir(".", "tmp")
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+
+		clock := newFakeClock()
+		r, err := New(dir, "test", WithClock(clock), WithRateLimit(c.limit))
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+
+		for second := 0; second < 2; second++ {
+			for i := 0; i < 10; i++ {
+				if n, err := r.Write([]byte("abc\n")); err != nil || n != 4 {
+					t.Errorf("Wanted dropped writes to look successful, got %d (%v)", n, err)
+				}
+			}
+			clock.Advance(time.Second)
+		}
+
+		got, _ := ioutil.ReadFile(r.CurrentPath())
+		if lines := strings.Count(string(got), "\n"); lines != c.want {
+			t.Errorf("Wanted %d lines written under %+v, got %d", c.want, c.limit, lines)
+		}
+
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}
+}
+
+func TestRateLimitBlocks(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithRateLimit(RateLimit{WritesPerSecond: 1}))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("first\n"))
+
+	done := make(chan struct{})
+	go func() {
+		r.Write([]byte("second\n"))
+		close(done)
+	}()
+
+	for clock.numTickers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-done:
+		t.Errorf("Wanted the second write to wait for the limit")
+	default:
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("Wanted the second write to go ahead once the limit allowed it")
+	}
+}
@@ -0,0 +1,50 @@
+package rolog
+
+import (
+	"bytes"
+	"io"
+)
+
+// readFromBuffer is the chunk size ReadFrom reads src in.
+const readFromBuffer = 32 * 1024
+
+// ReadFrom satisfies io.ReaderFrom, so io.Copy can stream large payloads such
+// as a subprocess's output into the Rolog. The data is written in chunks that
+// end on a line boundary where possible, each going through Write, so size
+// triggers and rotations still apply and lines aren't split across files.
+func (r *Rolog) ReadFrom(src io.Reader) (int64, error) {
+	var (
+		buf   = make([]byte, readFromBuffer)
+		held  int
+		total int64
+	)
+	for {
+		n, rerr := src.Read(buf[held:])
+		held += n
+
+		chunk := buf[:held]
+		if rerr == nil {
+			if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+				chunk = chunk[:i+1]
+			} else if held < len(buf) {
+				chunk = nil
+			}
+		}
+
+		if len(chunk) > 0 {
+			w, err := r.Write(chunk)
+			total += int64(w)
+			if err != nil {
+				return total, err
+			}
+			held = copy(buf, buf[len(chunk):held])
+		}
+
+		if rerr == io.EOF {
+			return total, nil
+		}
+		if rerr != nil {
+			return total, rerr
+		}
+	}
+}
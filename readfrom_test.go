@@ -0,0 +1,69 @@
+package rolog
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadFromKeepsLinesWhole(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithMaxSize(100))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	want := strings.Repeat("0123456789abcdefghi\n", readFromBuffer/10)
+	src := iotest.HalfReader(strings.NewReader(want))
+
+	n, err := io.Copy(r, src)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if n != int64(len(want)) {
+		t.Errorf("Wanted %d bytes copied, got %d", len(want), n)
+	}
+
+	archives, err := r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(archives) == 0 {
+		t.Errorf("Wanted the copy to trigger rotations")
+	}
+
+	var got bytes.Buffer
+	for _, a := range archives {
+		p, _ := ioutil.ReadFile(a.Path)
+		if len(p) > 0 && p[len(p)-1] != '\n' {
+			t.Errorf("Wanted %s to end with a whole line", a.Path)
+		}
+		got.Write(p)
+	}
+	current, _ := ioutil.ReadFile(r.CurrentPath())
+	got.Write(current)
+
+	if got.String() != want {
+		t.Errorf("Wanted the archives and current file to hold the whole payload")
+	}
+}
@@ -0,0 +1,40 @@
+package rolog
+
+import "time"
+
+// SetInterval changes how often the logs are rotated. A running loop picks up
+// the change on its next cycle and schedules the following rotation d from
+// then. A non-positive interval is only honored if a max size is set, since
+// the logs would otherwise never rotate; it then disables scheduled rotation.
+func (r *Rolog) SetInterval(d time.Duration) {
+	r.mu.Lock()
+	if d <= 0 && r.maxSize <= 0 {
+		r.mu.Unlock()
+		return
+	}
+	r.interval = d
+	r.publish()
+	r.mu.Unlock()
+
+	r.reschedule()
+}
+
+// SetMaxSize changes the size at which the current file is rotated early,
+// taking effect on the next write. A value of zero removes the limit.
+func (r *Rolog) SetMaxSize(n int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.maxSize = n
+	r.publish()
+}
+
+// SetMaxBackups changes the number of archives kept, taking effect after the
+// next rotation. A value of zero removes the limit.
+func (r *Rolog) SetMaxBackups(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.maxBackups = n
+	r.publish()
+}
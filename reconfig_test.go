@@ -0,0 +1,48 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSetIntervalReschedulesRunLoop(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithInterval(time.Hour), WithClock(clock))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Run(context.Background())
+	for clock.numTickers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	r.SetInterval(time.Minute)
+	for clock.numTickers() == 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if want := clock.Now().Add(time.Minute); !r.NextRotation().Equal(want) {
+		t.Errorf("Wanted next rotation at %s, got %s", want, r.NextRotation())
+	}
+
+	clock.Advance(time.Minute)
+	waitForRotation(t, r)
+}
@@ -0,0 +1,95 @@
+package rolog
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RotationRecord is the JSON line written at the boundaries of each file by
+// WithRotationRecords. Matching the End record of one file with the Start
+// record of the next lets downstream tools detect missing archives.
+type RotationRecord struct {
+	// Event is "start" for the first record in a file and "end" for the last.
+	Event string `json:"rolog"`
+	// Seq numbers the files written since the Rolog was created, starting at
+	// 0. The end record of file n is followed by the start record of n+1.
+	Seq uint64 `json:"seq"`
+	// Reason is what triggered the rotation. It is empty in the start record
+	// of the file created by New.
+	Reason RotationReason `json:"reason,omitempty"`
+	// Time is when the rotation happened, or when the Rolog was created.
+	Time time.Time `json:"ts"`
+	// Bytes is the size of the file before the end record.
+	Bytes int64 `json:"bytes,omitempty"`
+	// Archive is the name the file is archived under, in an end record, or the
+	// name of the previous file, in a start record, before any compression or
+	// encryption extension is added.
+	Archive string `json:"archive,omitempty"`
+	// Next is the name of the file writes continue in, in an end record.
+	Next string `json:"next,omitempty"`
+}
+
+// WithRotationRecords writes a RotationRecord as a JSON line at the start of
+// every new file and at the end of every file before it is archived.
+func WithRotationRecords() Option {
+	return func(r *Rolog) {
+		r.records = true
+	}
+}
+
+// writeEndRecord ends the file about to be archived as archive by a rotation
+// for reason at at. Failures are reported, so a record can't hold up a
+// rotation. It must be called with mu held.
+func (r *Rolog) writeEndRecord(reason RotationReason, at time.Time, archive string) {
+	if !r.records {
+		return
+	}
+
+	err := r.writeRecord(RotationRecord{
+		Event:   "end",
+		Seq:     r.rotations,
+		Reason:  reason,
+		Time:    at,
+		Bytes:   r.size,
+		Archive: filepath.Base(archive),
+		Next:    filepath.Base(r.currentPath()),
+	})
+	if err != nil {
+		r.report(err)
+	}
+}
+
+// writeStartRecord starts the current file, created by a rotation for reason
+// at at that archived the previous file as archive. It must be called with mu
+// held, after rotations has been advanced.
+func (r *Rolog) writeStartRecord(reason RotationReason, at time.Time, archive string) error {
+	if !r.records {
+		return nil
+	}
+
+	rec := RotationRecord{
+		Event:  "start",
+		Seq:    r.rotations,
+		Reason: reason,
+		Time:   at,
+	}
+	if archive != "" {
+		rec.Archive = filepath.Base(archive)
+	}
+	return r.writeRecord(rec)
+}
+
+// writeRecord writes rec to the current file. It must be called with mu held.
+func (r *Rolog) writeRecord(rec RotationRecord) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return errors.Wrap(err, "could not encode rotation record")
+	}
+
+	n, err := r.output().Write(append(b, '\n'))
+	r.size += int64(n)
+	return errors.Wrap(err, "could not write rotation record")
+}
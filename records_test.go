@@ -0,0 +1,71 @@
+package rolog
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithRotationRecordsMarksFileBoundaries(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithRotationRecords(), WithMaxSize(200))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte(strings.Repeat("x", 100) + "\n"))
+	r.Write([]byte(strings.Repeat("y", 100) + "\n"))
+
+	archives, err := r.Archives()
+	if err != nil || len(archives) != 1 {
+		t.Errorf("Wanted 1 archive, got %d (%v)", len(archives), err)
+		t.FailNow()
+	}
+
+	records := func(path string) []RotationRecord {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		var recs []RotationRecord
+		for _, line := range strings.Split(string(b), "\n") {
+			var rec RotationRecord
+			if json.Unmarshal([]byte(line), &rec) == nil && rec.Event != "" {
+				recs = append(recs, rec)
+			}
+		}
+		return recs
+	}
+
+	old := records(archives[0].Path)
+	if len(old) != 2 || old[0].Event != "start" || old[0].Seq != 0 || old[1].Event != "end" {
+		t.Errorf("Wanted start and end records in the archive, got %+v", old)
+		t.FailNow()
+	}
+	if old[1].Reason != ReasonSize || old[1].Next != "test.log" || old[1].Archive != filepath.Base(archives[0].Path) {
+		t.Errorf("Wanted the end record to describe the rotation, got %+v", old[1])
+	}
+
+	cur := records(filepath.Join(dir, "test.log"))
+	if len(cur) != 1 || cur[0].Event != "start" || cur[0].Seq != old[1].Seq+1 || cur[0].Archive != old[1].Archive {
+		t.Errorf("Wanted a start record continuing the sequence, got %+v", cur)
+	}
+}
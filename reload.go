@@ -0,0 +1,107 @@
+package rolog
+
+import (
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// reloadPoll is how often a running Rolog checks its config file for changes.
+const reloadPoll = time.Second
+
+// WithConfigReload makes a running Rolog reload its settings from the config
+// file at path, in the format of LoadConfig, whenever the file changes or the
+// process receives one of sigs, such as syscall.SIGUSR2. The file is checked
+// every second. If it has a logs section with an entry named after the Rolog,
+// that entry's settings are used, as LoadConfigs would give them.
+//
+// Only the runtime Settings are reloaded: the interval, size limits,
+// retention, bundling, compression, latest link and archive permissions. A
+// reload replaces all of them at once with Reconfigure, so settings missing
+// from the file go back to their defaults. Anything else that has changed,
+// such as the directory, needs a restart. A file that can't be read or gives
+// unusable settings is reported on Err and leaves the settings as they were.
+func WithConfigReload(path string, sigs ...os.Signal) Option {
+	return func(r *Rolog) {
+		r.reloadPath = path
+		r.reloadSignals = append(r.reloadSignals, sigs...)
+	}
+}
+
+// ReloadConfig reloads the settings from the file given to WithConfigReload
+// straight away.
+func (r *Rolog) ReloadConfig() error {
+	if r.reloadPath == "" {
+		return errors.New("no config file to reload")
+	}
+
+	cfg, err := r.loadOwnConfig()
+	if err != nil {
+		return errors.Wrap(err, "could not reload config")
+	}
+	if err := r.Reconfigure(cfg.Settings()); err != nil {
+		return errors.Wrap(err, "could not apply reloaded config")
+	}
+	r.debugf("reloaded settings from %s", r.reloadPath)
+	return nil
+}
+
+// loadOwnConfig reads the Rolog's entry in its config file, falling back on
+// the top level.
+func (r *Rolog) loadOwnConfig() (Config, error) {
+	cfgs, err := LoadConfigs(r.reloadPath)
+	if err != nil {
+		return Config{}, err
+	}
+	if cfg, ok := cfgs[r.name]; ok {
+		return cfg, nil
+	}
+	return LoadConfig(r.reloadPath)
+}
+
+// configStamp identifies a version of the config file.
+type configStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// stampConfig returns the stamp of the config file, or the zero stamp if it
+// is missing.
+func stampConfig(path string) configStamp {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return configStamp{}
+	}
+	return configStamp{size: fi.Size(), modTime: fi.ModTime()}
+}
+
+// checkConfig reloads the config file if it has changed since it was last
+// looked at.
+func (s *scheduler) checkConfig() {
+	stamp := stampConfig(s.r.reloadPath)
+	if stamp == s.config {
+		return
+	}
+	s.config = stamp
+	if stamp == (configStamp{}) {
+		// removed, or being replaced
+		return
+	}
+
+	s.r.debugf("config file %s changed", s.r.reloadPath)
+	s.reloadConfig()
+}
+
+// reloadOnSignal reloads the config file on receipt of a reload signal.
+func (s *scheduler) reloadOnSignal(sig os.Signal) {
+	s.r.debugf("received %s", sig)
+	s.reloadConfig()
+}
+
+// reloadConfig reloads the config file, reporting any failure.
+func (s *scheduler) reloadConfig() {
+	if err := s.r.ReloadConfig(); err != nil {
+		s.r.report(err)
+	}
+}
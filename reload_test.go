@@ -0,0 +1,79 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigReloadOnChange(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	path := filepath.Join(dir, "rolog.json")
+	if err := ioutil.WriteFile(path, []byte(`{"max_backups": 3}`), 0644); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithMaxBackups(3), WithConfigReload(path))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Run(ctx)
+	for clock.numTickers() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	config := `{"interval": "2h", "max_age": "72h", "compression": "gzip", "logs": {"test": {"max_backups": 10}}}`
+	if err := ioutil.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	clock.Advance(reloadPoll)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for r.Settings().MaxBackups != 10 {
+		if time.Now().After(deadline) {
+			t.Errorf("Wanted the config reloaded, got %+v", r.Settings())
+			t.FailNow()
+		}
+		time.Sleep(time.Millisecond)
+	}
+	want := Settings{Interval: 2 * time.Hour, MaxBackups: 10, MaxAge: 72 * time.Hour, Compression: Gzip, CompressWorkers: 1}
+	if got := r.Settings(); got != want {
+		t.Errorf("Wanted settings %+v, got %+v", want, got)
+	}
+
+	// A broken file leaves the settings alone.
+	if err := ioutil.WriteFile(path, []byte(`{"interval": "soon"}`), 0644); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	clock.Advance(reloadPoll)
+	select {
+	case <-r.Err():
+	case <-time.After(5 * time.Second):
+		t.Errorf("Wanted the broken config reported")
+	}
+	if got := r.Settings(); got != want {
+		t.Errorf("Wanted settings %+v kept, got %+v", want, got)
+	}
+}
@@ -0,0 +1,25 @@
+package rolog
+
+import "time"
+
+// renameAttempts bounds how many times renameFile tries a rename, and
+// renameBackoff is the delay before the first retry, doubling each time.
+const (
+	renameAttempts = 5
+	renameBackoff  = 10 * time.Millisecond
+)
+
+// renameFile renames oldpath to newpath on fs, retrying with backoff if the
+// failure is one that goes away by itself, such as a Windows sharing violation
+// caused by a virus scanner or log shipper briefly holding the file open.
+func renameFile(fs FS, oldpath, newpath string) error {
+	delay := renameBackoff
+	for i := 1; ; i++ {
+		err := fs.Rename(oldpath, newpath)
+		if err == nil || i == renameAttempts || !transientRenameError(err) {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+// +build !windows
+
+package rolog
+
+// transientRenameError reports whether a failed rename is worth retrying.
+// Outside Windows, renames don't fail because another process has the file
+// open, so nothing is.
+func transientRenameError(err error) bool {
+	return false
+}
@@ -0,0 +1,26 @@
+package rolog
+
+import (
+	"os"
+	"syscall"
+)
+
+// The Windows errors a rename fails with while another process, such as a
+// virus scanner or a tailer opened without FILE_SHARE_DELETE, holds the file.
+const (
+	errorAccessDenied     = syscall.Errno(5)
+	errorSharingViolation = syscall.Errno(32)
+	errorLockViolation    = syscall.Errno(33)
+)
+
+// transientRenameError reports whether a failed rename is worth retrying.
+func transientRenameError(err error) bool {
+	if lerr, ok := err.(*os.LinkError); ok {
+		err = lerr.Err
+	}
+	switch err {
+	case errorAccessDenied, errorSharingViolation, errorLockViolation:
+		return true
+	}
+	return false
+}
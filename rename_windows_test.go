@@ -0,0 +1,24 @@
+package rolog
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestTransientRenameErrorMatchesSharingViolations(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{&os.LinkError{Op: "rename", Err: errorSharingViolation}, true},
+		{&os.LinkError{Op: "rename", Err: errorAccessDenied}, true},
+		{errorLockViolation, true},
+		{&os.LinkError{Op: "rename", Err: syscall.ERROR_FILE_NOT_FOUND}, false},
+	}
+	for _, c := range cases {
+		if got := transientRenameError(c.err); got != c.want {
+			t.Errorf("Wanted %v for %v, got %v", c.want, c.err, got)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package rolog
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// repair fixes whatever an interrupted rotation left in the directory, so a
+// crash during Rotate never leaves the Rolog wedged:
+//
+//   - temporary files from compression, the manifest or the latest or
+//     current links are removed;
+//   - an archive that still has a partly written compressed or encrypted copy
+//     beside it has the copy removed and is processed again;
+//   - the newest archive is processed if it was never compressed or encrypted
+//     as configured, such as after a crash straight after the rename.
+//
+// It runs once from New, and failures are reported rather than returned.
+// Under WithDryRun the repairs are reported as planned actions instead.
+func (r *Rolog) repair() {
+	var (
+		dir       = filepath.Dir(r.currentPath())
+		prefix, _ = r.archiveLayout()
+		link      = fmt.Sprintf(r.latestFormat(), r.name) + ".tmp"
+		current   = filepath.Base(r.currentPath()) + ".tmp"
+		manifest  = fmt.Sprintf(ManifestFileFormat, r.name) + ".tmp"
+	)
+
+	r.mu.Lock()
+	s := r.archiveSettings()
+	r.mu.Unlock()
+
+	remove := func(name, what string) {
+		path := filepath.Join(dir, name)
+		if s.dryRun != nil {
+			s.dryRun(PlannedAction{Action: ActionRemove, Path: path, Reason: what})
+			return
+		}
+		if err := r.fs.Remove(path); err != nil {
+			r.report(errors.Wrapf(err, "could not remove %s", what))
+		}
+	}
+
+	fis, err := r.fs.ReadDir(dir)
+	if err != nil {
+		r.report(errors.Wrap(err, "could not scan for interrupted rotations"))
+		return
+	}
+
+	names := make(map[string]bool)
+	for _, fi := range fis {
+		name := fi.Name()
+		switch {
+		case fi.IsDir():
+		case name == link || name == current || name == manifest || strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ".tmp"):
+			remove(name, "temporary file")
+		case strings.HasPrefix(name, prefix):
+			names[name] = true
+		}
+	}
+
+	archives, err := r.archives()
+	if err != nil {
+		r.report(err)
+		return
+	}
+
+	for i, a := range archives {
+		name := filepath.Base(a.Path)
+		if !names[name] {
+			continue
+		}
+
+		partial := false
+		for other := range names {
+			if !isDerived(name, other) {
+				continue
+			}
+			remove(other, "partial archive")
+			delete(names, other)
+			partial = true
+		}
+
+		if partial || i == len(archives)-1 && unfinished(a, s) {
+			if s.dryRun != nil {
+				_, plan := planFinalize(s, a.Path)
+				for _, p := range plan {
+					s.dryRun(p)
+				}
+				continue
+			}
+			if _, err := r.finalize(a.Path, s); err != nil {
+				r.report(errors.Wrap(err, "could not finalize interrupted archive"))
+			}
+		}
+	}
+}
+
+// isDerived reports whether other is a processed copy of the archive name,
+// such as name.gz or name.age, as opposed to a separate archive given a
+// numeric suffix by uniquePath.
+func isDerived(name, other string) bool {
+	rest := strings.TrimPrefix(other, name)
+	return len(rest) > 1 && rest != other && rest[0] == '.' && (rest[1] < '0' || rest[1] > '9')
+}
+
+// unfinished reports whether a is missing post-processing that s calls for.
+func unfinished(a ArchiveInfo, s archiveSettings) bool {
+	if s.compression != NoCompression && !a.Compressed {
+		return true
+	}
+	return s.encrypter != nil && !strings.HasSuffix(a.Path, s.encrypter.Extension())
+}
@@ -0,0 +1,148 @@
+package rolog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestNewRecoversInterruptedRotations(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	var (
+		clock  = newFakeClock()
+		older  = fmt.Sprintf(clock.Now().Add(-2*time.Hour).Format(ArchiveFileFormat), "test")
+		newest = fmt.Sprintf(clock.Now().Add(-time.Hour).Format(ArchiveFileFormat), "test")
+		files  = map[string]string{
+			// Crashed after compressing but before removing the plaintext.
+			older:         "older\n",
+			older + ".gz": "not really gzip",
+			// Crashed while compressing.
+			newest:                "newest\n",
+			newest + ".gz.tmp":    "partial",
+			"test-latest.log.tmp": "",
+		}
+	)
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+	}
+
+	r, err := New(dir, "test", WithClock(clock), WithAppend(), WithCompression(Gzip))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	select {
+	case err := <-r.Err():
+		t.Errorf("Wanted a clean recovery, got %q", err)
+	default:
+	}
+
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	var got []string
+	for _, fi := range fis {
+		got = append(got, fi.Name())
+	}
+	want := []string{older + ".gz", newest + ".gz", "test.log"}
+	sort.Strings(want)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Wanted %v, got %v", want, got)
+		t.FailNow()
+	}
+
+	for name, content := range map[string]string{older: "older\n", newest: "newest\n"} {
+		rc, err := OpenArchive(ArchiveInfo{Path: filepath.Join(dir, name+".gz")})
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			continue
+		}
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		if string(b) != content {
+			t.Errorf("Wanted %q in %s.gz, got %q", content, name, b)
+		}
+	}
+}
+
+func TestDryRunLeavesInterruptedRotations(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	var (
+		clock  = newFakeClock()
+		newest = fmt.Sprintf(clock.Now().Add(-time.Hour).Format(ArchiveFileFormat), "test")
+		files  = map[string]string{
+			newest:                "newest\n",
+			"test-latest.log.tmp": "",
+		}
+	)
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+	}
+
+	var plan []PlannedAction
+	r, err := New(dir, "test", WithClock(clock), WithAppend(), WithCompression(Gzip), WithDryRun(func(p PlannedAction) {
+		plan = append(plan, p)
+	}))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	for name, content := range files {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(b) != content {
+			t.Errorf("Wanted %s left as %q, got %q (%v)", name, content, b, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, newest+".gz")); !os.IsNotExist(err) {
+		t.Errorf("Wanted %s left uncompressed, got %v", newest, err)
+	}
+
+	want := []PlanAction{ActionRemove, ActionCompress}
+	if len(plan) != len(want) {
+		t.Errorf("Wanted %v, got %+v", want, plan)
+		t.FailNow()
+	}
+	for i, p := range plan {
+		if p.Action != want[i] {
+			t.Errorf("Wanted action %d to be %s, got %+v", i, want[i], p)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package rolog
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SetMaxTotalSize sets the byte budget shared by the current file and all of
+// its archives. After each rotation the oldest archives are deleted until the
+// combined size is under the budget. A value of zero disables the limit.
+func (r *Rolog) SetMaxTotalSize(n int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.maxTotalSize = n
+	r.publish()
+}
+
+// SetMaxAge sets how long archives are kept. After each rotation archives
+// that were rotated out longer than d ago are deleted. A value of zero disables
+// the limit.
+func (r *Rolog) SetMaxAge(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.maxAge = d
+	r.publish()
+}
+
+// SetDeleteFilter installs a safety callback consulted before any archive is
+// deleted, whether by retention, by bundling or by Purge. Returning false keeps
+// the archive, unbundled. Passing nil allows all deletions.
+func (r *Rolog) SetDeleteFilter(f func(ArchiveInfo) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deleteFilter = f
+}
+
+// Purge deletes archives on demand, keeping the newest keep archives. Pass zero
+// to remove every archive. The current file is never touched.
+func (r *Rolog) Purge(keep int) error {
+	r.mu.Lock()
+	filter := r.deleteFilter
+	r.mu.Unlock()
+
+	r.rotMu.Lock()
+	defer r.rotMu.Unlock()
+
+	archives, err := r.archives()
+	if err != nil {
+		return err
+	}
+
+	if keep < 0 {
+		keep = 0
+	}
+	if keep >= len(archives) {
+		return nil
+	}
+
+	for _, a := range archives[:len(archives)-keep] {
+		if _, err := r.remove(a, filter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// remove deletes an archive unless filter vetoes it, reporting whether the file
+// was removed.
+func (r *Rolog) remove(a ArchiveInfo, filter func(ArchiveInfo) bool) (bool, error) {
+	if filter != nil && !filter(a) {
+		return false, nil
+	}
+
+	err := r.fs.Remove(a.Path)
+	r.audit(AuditPrune, a.Path, err)
+	if err != nil {
+		return false, errors.Wrap(err, "could not remove archive")
+	}
+	if m := r.meter(); m != nil {
+		m.Removed(a)
+	}
+	r.observe(event{kind: eventPrune, archive: a})
+
+	return true, nil
+}
+
+// prune deletes the oldest archives and bundles until none are older than the
+// maximum age, no more than the maximum number of backups remain and the
+// current file and remaining archives fit within the maximum total size.
+func (r *Rolog) prune(s archiveSettings) error {
+	if s.maxTotalSize <= 0 && s.maxBackups <= 0 && s.maxAge <= 0 {
+		return nil
+	}
+
+	archives, err := r.retained()
+	if err != nil {
+		return err
+	}
+
+	for _, v := range expired(s, archives, r.now()) {
+		if _, err := r.remove(v.ArchiveInfo, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// retained returns the archives and bundles that retention applies to, oldest
+// first.
+func (r *Rolog) retained() ([]ArchiveInfo, error) {
+	archives, err := r.archives()
+	if err != nil {
+		return nil, err
+	}
+	bundles, err := r.bundles()
+	if err != nil {
+		return nil, err
+	}
+	return withBundles(archives, bundles), nil
+}
+
+// withBundles merges the bundles into the archives, both sorted oldest first.
+func withBundles(archives, bundles []ArchiveInfo) []ArchiveInfo {
+	if len(bundles) == 0 {
+		return archives
+	}
+	all := append(bundles[:len(bundles):len(bundles)], archives...)
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].End.Before(all[j].End)
+	})
+	return all
+}
+
+// expiredArchive is an archive that retention removes, and the limit it broke.
+type expiredArchive struct {
+	ArchiveInfo
+	limit string
+}
+
+// expired returns the archives, sorted oldest first, that retention under s
+// removes at now, in the order it removes them. Archives vetoed by the delete
+// filter are kept and count against the later limits.
+func expired(s archiveSettings, archives []ArchiveInfo, now time.Time) []expiredArchive {
+	var (
+		victims []expiredArchive
+		kept    = archives
+	)
+	allowed := func(a ArchiveInfo) bool {
+		return s.deleteFilter == nil || s.deleteFilter(a)
+	}
+	// split moves the archives of kept that go to victims.
+	split := func(limit string, goes func(i int, a ArchiveInfo) bool) {
+		var rest []ArchiveInfo
+		for i, a := range kept {
+			if goes(i, a) {
+				victims = append(victims, expiredArchive{a, limit})
+				continue
+			}
+			rest = append(rest, a)
+		}
+		kept = rest
+	}
+
+	if s.maxAge > 0 {
+		cutoff := now.Add(-s.maxAge)
+		split("max age", func(_ int, a ArchiveInfo) bool {
+			return a.End.Before(cutoff) && allowed(a)
+		})
+	}
+
+	if excess := len(kept) - s.maxBackups; s.maxBackups > 0 && excess > 0 {
+		split("max backups", func(i int, a ArchiveInfo) bool {
+			return i < excess && allowed(a)
+		})
+	}
+
+	if s.maxTotalSize > 0 {
+		total := s.current
+		for _, a := range kept {
+			total += a.Size
+		}
+		split("max total size", func(_ int, a ArchiveInfo) bool {
+			if total <= s.maxTotalSize || !allowed(a) {
+				return false
+			}
+			total -= a.Size
+			return true
+		})
+	}
+
+	return victims
+}
@@ -0,0 +1,187 @@
+package rolog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotatePrunesToMaxTotalSize(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithInterval(60*time.Minute))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	var old []string
+	for i := 3; i > 0; i-- {
+		ts := time.Now().Add(-time.Duration(i) * time.Hour)
+		path := filepath.Join(dir, fmt.Sprintf(ts.Format(ArchiveFileFormat), "test"))
+		if err := ioutil.WriteFile(path, []byte("0123456789"), 0644); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		old = append(old, path)
+	}
+
+	r.SetMaxTotalSize(25)
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+
+	if _, err := os.Stat(old[0]); !os.IsNotExist(err) {
+		t.Errorf("Wanted oldest archive to be pruned, got %v", err)
+	}
+	for _, path := range old[1:] {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Wanted %s to be kept, got %q", path, err)
+		}
+	}
+}
+
+func TestPurgeKeepsNewestAndHonorsFilter(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithInterval(60*time.Minute))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	var paths []string
+	for i := 4; i > 0; i-- {
+		ts := time.Now().Add(-time.Duration(i) * time.Hour)
+		path := filepath.Join(dir, fmt.Sprintf(ts.Format(ArchiveFileFormat), "test"))
+		if err := ioutil.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		paths = append(paths, path)
+	}
+
+	r.SetDeleteFilter(func(a ArchiveInfo) bool { return a.Path != paths[0] })
+	if err := r.Purge(1); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	for i, path := range paths {
+		_, err := os.Stat(path)
+		kept := err == nil
+		if want := i == 0 || i == 3; kept != want {
+			t.Errorf("Wanted %s kept=%t, got %t", path, want, kept)
+		}
+	}
+}
+
+func TestRotateKeepsMaxBackups(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithMaxBackups(2))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	for i := 0; i < 4; i++ {
+		if err := r.Rotate(); err != nil {
+			t.Errorf("could not rotate: %q", err)
+			t.FailNow()
+		}
+	}
+
+	archives, err := r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if len(archives) != 2 {
+		t.Errorf("Wanted 2 archives, got %d", len(archives))
+	}
+}
+
+func TestRotatePrunesArchivesOlderThanMaxAge(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithInterval(60*time.Minute), WithMaxAge(36*time.Hour))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	var old []string
+	for _, age := range []time.Duration{72 * time.Hour, 48 * time.Hour, 24 * time.Hour} {
+		ts := time.Now().Add(-age)
+		path := filepath.Join(dir, fmt.Sprintf(ts.Format(ArchiveFileFormat), "test"))
+		if err := ioutil.WriteFile(path, []byte("0123456789"), 0644); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		old = append(old, path)
+	}
+
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+
+	for _, path := range old[:2] {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Wanted %s to be pruned, got %v", path, err)
+		}
+	}
+	if _, err := os.Stat(old[2]); err != nil {
+		t.Errorf("Wanted %s to be kept, got %q", old[2], err)
+	}
+}
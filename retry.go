@@ -0,0 +1,73 @@
+package rolog
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultRetryBackoff is how long a running Rolog waits before retrying a
+// failed scheduled rotation, unless changed with WithRotateRetry.
+const DefaultRetryBackoff = time.Second
+
+// ErrTooManyFailures is reported on the Err channel when the run loop stops
+// after the number of consecutive rotation failures allowed by
+// WithRotateRetry.
+var ErrTooManyFailures = errors.New("too many consecutive rotation failures")
+
+// retryPolicy is how the run loop recovers from failed scheduled rotations.
+type retryPolicy struct {
+	backoff     time.Duration
+	maxFailures int
+}
+
+// WithRotateRetry sets how a running Rolog recovers when a scheduled rotation
+// fails. Every failure is reported on the Err channel and the rotation is
+// retried after backoff, doubling with each consecutive failure up to the
+// rotation interval, as well as on every tick. After maxFailures consecutive
+// failures the loop reports ErrTooManyFailures and stops; zero means it never
+// gives up. A non-positive backoff only retries on the next tick.
+//
+// The default retries after DefaultRetryBackoff and never gives up.
+func WithRotateRetry(backoff time.Duration, maxFailures int) Option {
+	return func(r *Rolog) {
+		r.retry = retryPolicy{backoff: backoff, maxFailures: maxFailures}
+	}
+}
+
+// attemptPolicy is how many times a single rotation tries its strategy.
+type attemptPolicy struct {
+	attempts int
+	backoff  time.Duration
+}
+
+// WithRotateAttempts makes every rotation try the rotation strategy up to
+// attempts times before surfacing its error, waiting backoff before the first
+// retry and twice as long before each one after that. A momentary failure to
+// rename or create a file, such as an NFS hiccup, then costs a short delay
+// rather than a whole rotation cycle. Writes are only paused while a rotation
+// waits under a strategy that isn't a SwappingStrategy, or retries a failed
+// size-triggered swap.
+//
+// The default is a single attempt.
+func WithRotateAttempts(attempts int, backoff time.Duration) Option {
+	return func(r *Rolog) {
+		r.attempts = attemptPolicy{attempts: attempts, backoff: backoff}
+	}
+}
+
+// try calls f until it succeeds or the attempts allowed by p are used up,
+// returning the last error. retrying is told about each failure that is about
+// to be retried.
+func (p attemptPolicy) try(f func() error, retrying func(err error, attempt int)) error {
+	delay := p.backoff
+	for i := 1; ; i++ {
+		err := f()
+		if err == nil || i >= p.attempts {
+			return err
+		}
+		retrying(err, i)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
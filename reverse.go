@@ -0,0 +1,190 @@
+package rolog
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// reverseChunk is how much a ReverseReader reads at a time.
+const reverseChunk = 32 << 10
+
+// ReverseReader reads the lines of the log newest first. It is returned by
+// Reverse.
+type ReverseReader struct {
+	r       *Rolog
+	archive []ArchiveInfo
+	layouts []string
+	f       *backwardFile
+}
+
+// Reverse returns a ReverseReader that starts at the end of the current file
+// and works back through the archives, for finding the last lines before
+// something happened without reading everything before them. Uncompressed
+// files are read backwards a chunk at a time, so the cost is in proportion to
+// how far back the caller reads; a compressed archive has to be decompressed
+// whole, into a temporary file, once the reader gets to it. Like History, it
+// reads what was there when Reverse was called.
+func (r *Rolog) Reverse() (*ReverseReader, error) {
+	h, err := r.snapshot(time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	rr := &ReverseReader{r: r, archive: h.archives, layouts: r.timestampLayouts()}
+	if h.current != nil {
+		if rr.f, err = newBackwardFile(r.currentPath(), h.current); err != nil {
+			return nil, err
+		}
+	}
+	return rr, nil
+}
+
+// ReadLine returns the line before the one it last returned, or io.EOF once
+// the oldest line has been read. The Line's Number is zero, since the file isn't
+// read from the start. An archive that can't be read fails with an error, and
+// the next call carries on with the archive before it.
+func (rr *ReverseReader) ReadLine() (Line, error) {
+	for {
+		if rr.f == nil {
+			n := len(rr.archive)
+			if n == 0 {
+				return Line{}, io.EOF
+			}
+			a := rr.archive[n-1]
+			rr.archive = rr.archive[:n-1]
+
+			rc, err := rr.r.openSource(a)
+			if os.IsNotExist(errors.Cause(err)) {
+				// pruned since Reverse was called
+				continue
+			}
+			if err == nil {
+				rr.f, err = newBackwardFile(a.Path, rc)
+			}
+			if err != nil {
+				return Line{Path: a.Path}, err
+			}
+		}
+
+		b, offset, err := rr.f.prev()
+		if err == io.EOF {
+			rr.f.Close()
+			rr.f = nil
+			continue
+		}
+		if err != nil {
+			return Line{Path: rr.f.path}, err
+		}
+
+		line := Line{Path: rr.f.path, Offset: offset, Text: string(bytes.TrimSuffix(b, []byte("\n")))}
+		line.Time, _ = lineTime(b, rr.layouts)
+		return line, nil
+	}
+}
+
+// Close satisfies io.Closer.
+func (rr *ReverseReader) Close() error {
+	rr.archive = nil
+	if rr.f == nil {
+		return nil
+	}
+	err := rr.f.Close()
+	rr.f = nil
+	return err
+}
+
+// backwardFile reads the lines of a file from its end.
+type backwardFile struct {
+	path  string
+	ra    io.ReaderAt
+	close func() error
+	// buf holds the bytes from pos up to end that haven't been returned yet
+	buf      []byte
+	pos, end int64
+}
+
+// newBackwardFile prepares to read rc backwards. Files that can't be read at
+// arbitrary offsets, such as decompressed archives, are copied to a temporary
+// file first.
+func newBackwardFile(path string, rc io.ReadCloser) (*backwardFile, error) {
+	// openSource limits the current file to its size when it was opened.
+	if l, ok := rc.(limitedFile); ok {
+		if ra, ok := l.Closer.(io.ReaderAt); ok {
+			size := l.Reader.(*io.LimitedReader).N
+			return &backwardFile{path: path, ra: ra, close: l.Close, pos: size, end: size}, nil
+		}
+	}
+	if f, ok := rc.(*os.File); ok {
+		fi, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, errors.Wrap(err, "could not stat "+path)
+		}
+		return &backwardFile{path: path, ra: f, close: f.Close, pos: fi.Size(), end: fi.Size()}, nil
+	}
+
+	defer rc.Close()
+	tmp, err := ioutil.TempFile("", "rolog")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create temporary file")
+	}
+	remove := func() error {
+		tmp.Close()
+		return os.Remove(tmp.Name())
+	}
+	size, err := io.Copy(tmp, rc)
+	if err != nil {
+		remove()
+		return nil, errors.Wrap(err, "could not decompress "+path)
+	}
+	return &backwardFile{path: path, ra: tmp, close: remove, pos: size, end: size}, nil
+}
+
+// prev returns the last line before the ones already returned, with its
+// offset in the file.
+func (b *backwardFile) prev() ([]byte, int64, error) {
+	if b.end == 0 {
+		return nil, 0, io.EOF
+	}
+
+	for {
+		// Look for the newline ending the line before, which isn't the one
+		// ending this line.
+		search := b.buf
+		if n := len(search); n > 0 && search[n-1] == '\n' {
+			search = search[:n-1]
+		}
+		if i := bytes.LastIndexByte(search, '\n'); i >= 0 {
+			line := b.buf[i+1:]
+			b.buf = b.buf[:i+1]
+			b.end = b.pos + int64(i) + 1
+			return line, b.end, nil
+		}
+		if b.pos == 0 {
+			line := b.buf
+			b.buf, b.end = nil, 0
+			return line, 0, nil
+		}
+
+		n := int64(reverseChunk)
+		if b.pos < n {
+			n = b.pos
+		}
+		chunk := make([]byte, n, n+int64(len(b.buf)))
+		if _, err := b.ra.ReadAt(chunk, b.pos-n); err != nil && err != io.EOF {
+			return nil, 0, errors.Wrap(err, "could not read "+b.path)
+		}
+		b.buf = append(chunk, b.buf...)
+		b.pos -= n
+	}
+}
+
+// Close satisfies io.Closer.
+func (b *backwardFile) Close() error {
+	return b.close()
+}
@@ -0,0 +1,99 @@
+package rolog
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestReverseReadsNewestFirst(t *testing.T) {
+	r, _, cleanup := newHistory(t)
+	defer cleanup()
+
+	rr, err := r.Reverse()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer rr.Close()
+
+	// Lines written after Reverse was called aren't seen.
+	r.Write([]byte("later\n"))
+
+	var want []string
+	for _, file := range []string{"third", "second", "first"} {
+		want = append(want, file+" three", file+" two ERROR", file+" one")
+	}
+	for i, w := range want {
+		line, err := rr.ReadLine()
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		if line.Text != w {
+			t.Errorf("Wanted %q, got %q", w, line.Text)
+		}
+		if i < 3 && (line.Path != r.CurrentPath() || line.Offset != []int64{26, 10, 0}[i]) {
+			t.Errorf("Wanted line %d of the current file, got %+v", 3-i, line)
+		}
+	}
+	if _, err := rr.ReadLine(); err != io.EOF {
+		t.Errorf("Wanted EOF, got %v", err)
+	}
+}
+
+func TestReverseAcrossChunks(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r, err := New(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	const n = 10000
+	for i := 0; i < n; i++ {
+		if i == n/2 {
+			r.Rotate()
+		}
+		fmt.Fprintf(r, "line %d\n", i)
+	}
+	r.Write([]byte("partial"))
+
+	rr, err := r.Reverse()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer rr.Close()
+
+	if line, err := rr.ReadLine(); err != nil || line.Text != "partial" {
+		t.Errorf("Wanted the partial last line, got %q, %v", line.Text, err)
+	}
+	for i := n - 1; i >= 0; i-- {
+		line, err := rr.ReadLine()
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		if want := fmt.Sprintf("line %d", i); line.Text != want {
+			t.Errorf("Wanted %q, got %q", want, line.Text)
+			t.FailNow()
+		}
+	}
+	if _, err := rr.ReadLine(); err != io.EOF {
+		t.Errorf("Wanted EOF, got %v", err)
+	}
+}
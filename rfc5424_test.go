@@ -0,0 +1,43 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestRFC5424EncoderFramesEveryLine(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	enc := &RFC5424Encoder{Facility: 16, Severity: 3, Hostname: "web 1", AppName: "api", ProcID: "42"}
+	r, err := New(dir, "test", WithClock(clock), WithEncoder(enc))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("one\ntwo\n"))
+
+	got, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	header := "<131>1 " + clock.Now().Format("2006-01-02T15:04:05.000000Z07:00") + " web_1 api 42 - - "
+	if want := header + "one\n" + header + "two\n"; string(got) != want {
+		t.Errorf("Wanted %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,227 @@
+package rolog
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// RingPolicy decides what a producer does when the ring buffer is full.
+type RingPolicy int
+
+const (
+	// RingBlock makes the producer wait until the writer goroutine frees a
+	// slot, parked rather than spinning. Nothing is lost, but a stalled disk
+	// eventually stalls the caller.
+	RingBlock RingPolicy = iota
+	// RingOverwrite discards the oldest entry that hasn't been persisted yet,
+	// so producers never wait on the disk at the cost of losing data.
+	RingOverwrite
+)
+
+// WithRing makes Write copy each payload into a slot of a fixed-size ring
+// buffer without taking a lock, for very hot paths. A single writer goroutine
+// persists the entries in order. The size is rounded up to a power of two, and
+// policy decides what happens when the ring is full. As with WithAsync,
+// failures are delivered on Err, and Close drains the ring before closing the
+// file. WithRing takes precedence over WithAsync.
+func WithRing(size int, policy RingPolicy) Option {
+	return func(r *Rolog) {
+		r.ringSize = size
+		r.ringPolicy = policy
+	}
+}
+
+// ringSlot holds one entry. seq records which lap of the ring the slot is
+// ready for, in the manner of Vyukov's bounded queue.
+type ringSlot struct {
+	seq uint64
+	p   []byte
+}
+
+// ring is a bounded multi-producer queue. head and tail are claimed with
+// compare-and-swap, so producers and the consumer never block each other.
+type ring struct {
+	head, tail uint64
+	// writers counts producers inside push, so Close can wait for them
+	writers int64
+	// dropped counts entries discarded under RingOverwrite
+	dropped uint64
+	closed  uint32
+	// overwrote is called for every entry discarded under RingOverwrite
+	overwrote func()
+
+	mask   uint64
+	slots  []ringSlot
+	policy RingPolicy
+	wake   chan struct{}
+	// freed wakes producers parked on a full ring under RingBlock
+	freed chan struct{}
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// newRing returns a ring with room for at least size entries.
+func newRing(size int, policy RingPolicy) *ring {
+	n := uint64(1)
+	for n < uint64(size) {
+		n <<= 1
+	}
+
+	q := &ring{
+		mask:   n - 1,
+		slots:  make([]ringSlot, n),
+		policy: policy,
+		wake:   make(chan struct{}, 1),
+		freed:  make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	for i := range q.slots {
+		q.slots[i].seq = uint64(i)
+	}
+	return q
+}
+
+// push adds p to the ring, applying the policy if it is full. It reports false
+// if the ring has been closed.
+func (q *ring) push(p []byte) bool {
+	atomic.AddInt64(&q.writers, 1)
+	defer atomic.AddInt64(&q.writers, -1)
+
+	if atomic.LoadUint32(&q.closed) == 1 {
+		return false
+	}
+
+	parked := false
+	for {
+		pos := atomic.LoadUint64(&q.head)
+		s := &q.slots[pos&q.mask]
+		seq := atomic.LoadUint64(&s.seq)
+
+		switch diff := int64(seq) - int64(pos); {
+		case diff == 0:
+			if atomic.CompareAndSwapUint64(&q.head, pos, pos+1) {
+				s.p = p
+				atomic.StoreUint64(&s.seq, pos+1)
+				select {
+				case q.wake <- struct{}{}:
+				default:
+				}
+				if parked {
+					// pass the wakeup on to any other parked producer
+					q.signalFreed()
+				}
+				return true
+			}
+		case diff < 0:
+			if q.policy == RingBlock {
+				// A slot freed after the check above leaves a wakeup
+				// behind, so this never misses one.
+				<-q.freed
+				parked = true
+				continue
+			}
+			if q.policy == RingOverwrite {
+				if _, ok := q.pop(); ok {
+					atomic.AddUint64(&q.dropped, 1)
+					if q.overwrote != nil {
+						q.overwrote()
+					}
+					continue
+				}
+			}
+			runtime.Gosched()
+		}
+	}
+}
+
+// pop removes the oldest entry from the ring, reporting false if there is none
+// ready.
+func (q *ring) pop() ([]byte, bool) {
+	for {
+		pos := atomic.LoadUint64(&q.tail)
+		s := &q.slots[pos&q.mask]
+		seq := atomic.LoadUint64(&s.seq)
+
+		switch diff := int64(seq) - int64(pos+1); {
+		case diff == 0:
+			if atomic.CompareAndSwapUint64(&q.tail, pos, pos+1) {
+				p := s.p
+				s.p = nil
+				atomic.StoreUint64(&s.seq, pos+q.mask+1)
+				if q.policy == RingBlock {
+					q.signalFreed()
+				}
+				return p, true
+			}
+		case diff < 0:
+			return nil, false
+		}
+	}
+}
+
+// signalFreed wakes a producer parked on a full ring, if there is one.
+func (q *ring) signalFreed() {
+	select {
+	case q.freed <- struct{}{}:
+	default:
+	}
+}
+
+// startRing starts the writer goroutine if ring mode is enabled.
+func (r *Rolog) startRing() {
+	if r.ringSize <= 0 {
+		return
+	}
+
+	q := newRing(r.ringSize, r.ringPolicy)
+	q.overwrote = func() { r.drop(DropRingOverwrite) }
+	r.ring = q
+
+	go func() {
+		defer close(q.done)
+
+		persist := func() {
+			for {
+				p, ok := q.pop()
+				if !ok {
+					return
+				}
+				r.writeBackground(p, "ring entry")
+			}
+		}
+
+		for {
+			select {
+			case <-q.wake:
+				persist()
+			case <-q.stop:
+				persist()
+				return
+			}
+		}
+	}()
+}
+
+// ringWrite hands a copy of p to the ring.
+func (r *Rolog) ringWrite(p []byte) (int, error) {
+	if !r.ring.push(append([]byte(nil), p...)) {
+		return 0, ErrClosed
+	}
+	return len(p), nil
+}
+
+// stopRing refuses further writes and waits until every entry in the ring has
+// been persisted.
+func (r *Rolog) stopRing() {
+	q := r.ring
+	if q == nil || !atomic.CompareAndSwapUint32(&q.closed, 0, 1) {
+		return
+	}
+
+	for atomic.LoadInt64(&q.writers) > 0 {
+		runtime.Gosched()
+	}
+	close(q.stop)
+	<-q.done
+}
@@ -0,0 +1,136 @@
+package rolog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRingPersistsInOrderOnClose(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r, err := New(dir, "test", WithRing(8, RingBlock), WithSyncPolicy(SyncOnRotate))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				fmt.Fprintf(r, "%d %d\n", i, j)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := r.Close(); err != nil {
+		t.Errorf("could not close: %q", err)
+		t.FailNow()
+	}
+	if _, err := r.Write([]byte("late\n")); err == nil {
+		t.Errorf("Wanted an error writing after close")
+	}
+
+	got, err := ioutil.ReadFile(filepath.Join(dir, "test.log"))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	last := map[string]int{}
+	lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+	for _, line := range lines {
+		var producer string
+		var n int
+		fmt.Sscanf(line, "%s %d", &producer, &n)
+		if prev, ok := last[producer]; ok && n != prev+1 {
+			t.Errorf("Wanted entries from producer %s in order, got %d after %d", producer, n, prev)
+		}
+		last[producer] = n
+	}
+	if len(lines) != 800 {
+		t.Errorf("Wanted 800 lines, got %d", len(lines))
+	}
+}
+
+func TestRingOverwriteDropsOldest(t *testing.T) {
+	q := newRing(3, RingOverwrite)
+	for i := 0; i < 6; i++ {
+		q.push([]byte{byte('a' + i)})
+	}
+
+	var got []byte
+	for {
+		p, ok := q.pop()
+		if !ok {
+			break
+		}
+		got = append(got, p...)
+	}
+
+	if string(got) != "cdef" {
+		t.Errorf("Wanted the 4 newest entries, got %q", got)
+	}
+	if q.dropped != 2 {
+		t.Errorf("Wanted 2 dropped entries, got %d", q.dropped)
+	}
+}
+
+func TestRingBlockParksUntilSlotFreed(t *testing.T) {
+	q := newRing(2, RingBlock)
+	q.push([]byte("a"))
+	q.push([]byte("b"))
+
+	pushed := make(chan struct{})
+	go func() {
+		defer close(pushed)
+		q.push([]byte("c"))
+	}()
+
+	select {
+	case <-pushed:
+		t.Errorf("Wanted the producer to wait for room in a full ring")
+		t.FailNow()
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if p, ok := q.pop(); !ok || string(p) != "a" {
+		t.Errorf("Wanted the oldest entry, got %q", p)
+	}
+	select {
+	case <-pushed:
+	case <-time.After(2 * time.Second):
+		t.Errorf("Wanted the producer woken once a slot was freed")
+		t.FailNow()
+	}
+
+	var got []byte
+	for {
+		p, ok := q.pop()
+		if !ok {
+			break
+		}
+		got = append(got, p...)
+	}
+	if string(got) != "bc" {
+		t.Errorf("Wanted the remaining entries in order, got %q", got)
+	}
+}
@@ -1,13 +1,20 @@
 package rolog
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unsafe"
 
+	"github.com/haleyrc/rolog/internal/errq"
 	"github.com/pkg/errors"
 )
 
@@ -16,62 +23,901 @@ const (
 	ArchiveFileFormat = "%s-2006-01-02-150405.log"
 	// CurrentFilename is the name of the file currently being written.
 	CurrentFilename = "%s.log"
+	// DefaultInterval is how often logs are rotated if WithInterval is not
+	// provided.
+	DefaultInterval = 24 * time.Hour
 )
 
 // Rolog is an io.WriteCloser that writes logs to a single master file and
 // periodically pauses to rename the current file for archival, creating a new
 // file to continue writing.
+//
+// The current file is always opened for appending, so other processes may
+// safely append whole lines to it alongside the Rolog; each write lands intact
+// at the end of the file. Such writes aren't counted towards the max size.
 type Rolog struct {
+	// drops counts discarded entries. It is accessed atomically, so it comes
+	// first to keep it 64-bit aligned.
+	drops Drops
+	// state is the State, accessed atomically
+	state int32
 	// f is the current file being written
-	f *os.File
+	f File
+	// fs is the filesystem all file operations go through
+	fs FS
 	// mu is used to synchronize the rotation process and prevent logging during
 	// the rename/create window
 	mu sync.Mutex
 	// interval is how often we should rotate the logs
 	interval time.Duration
-	// path is the full path to the current file
-	path string
+	// maxSize is the size at which the current file is rotated early
+	maxSize int64
+	// size is the number of bytes in the current file
+	size int64
+	// maxBackups is the number of archives to keep
+	maxBackups int
+	// path holds the full path to the current file as a string. It is only
+	// changed with mu held, but is read without it, as by CurrentPath
+	path atomic.Value
+	// reconfig tells the run loop that the interval has changed
+	reconfig chan struct{}
 	// done is used to signal that our Rolog should stop its main run loop
-	done chan int
-	// err is used to store any error during rotate. Not currently used.
+	done chan struct{}
+	// err delivers errors from background rotation to the caller
 	err chan error
+	// events delivers a RotationEvent after each rotation
+	events chan RotationEvent
 	// name is the base name of the log file
 	name string
+	// maxTotalSize is the byte budget for the current file plus all archives
+	maxTotalSize int64
+	// maxAge is how long archives are kept after they are rotated out
+	maxAge time.Duration
+	// compression, if set, is applied to each archive after rotation by
+	// compressWorkers goroutines at compressLevel, in the background if
+	// compressPool is set; compressing counts the archives handed to it
+	// and any catch-up on the backlog of earlier runs
+	compression     Compression
+	compressWorkers int
+	compressLevel   int
+	compressPool    *CompressPool
+	compressing     sync.WaitGroup
+	catchUp         bool
+	// liveCompression, if set, compresses the current file as it is
+	// written, with a flush point every liveFlush
+	liveCompression Compression
+	liveFlush       time.Duration
+	// encrypter, if set, is applied to each archive after rotation
+	encrypter Encrypter
+	// bundleAge is how old an archive must be before it is bundled
+	bundleAge time.Duration
+	// deleteFilter, if set, may veto the deletion of an archive
+	deleteFilter func(ArchiveInfo) bool
+	// latestLink enables the symlink to the newest archive
+	latestLink bool
+	// strategy performs the file mechanics of each rotation
+	strategy RotationStrategy
+	// archivePerm, archiveUID and archiveGID are applied to each archive
+	archivePerm            os.FileMode
+	archiveUID, archiveGID int
+	// clock is the source of time for naming and scheduling
+	clock Clock
+	// ext replaces DefaultExtension in every filename
+	ext string
+	// currentName and archiveName override the naming formats of the
+	// current file and archives, and instance fills in VarInstance
+	currentName, archiveName string
+	instance                 string
+	// encoders transform every write, in order
+	encoders []Encoder
+	// contextFields are the values WriteContext takes from contexts
+	contextFields []contextField
+	// header, if set, returns the header for each new file
+	header func() []byte
+	// footer, if set, returns the trailer for each archived file
+	footer func(current string, at time.Time) []byte
+	// records enables the RotationRecord lines at the boundaries of each file
+	records bool
+	// retry decides how the run loop recovers from failed rotations, and
+	// attempts how often each rotation tries its strategy
+	retry    retryPolicy
+	attempts attemptPolicy
+	// lock takes the lock file in New, and unlock releases it
+	lock   bool
+	unlock io.Closer
+	// fallback receives writes the file refuses, failing records that it is
+	// in use, and onRecover is called once writes reach the file again
+	fallback  io.Writer
+	failing   bool
+	onRecover func()
+	// diskFull decides what happens to writes when the disk is full
+	diskFull DiskFullPolicy
+	// dirSync syncs the directory after files are renamed or created
+	dirSync bool
+	// manifest records the digest of each finalized archive, and manifestMu
+	// serializes rewriting the manifest
+	manifest   bool
+	manifestMu sync.Mutex
+	// currentLink makes the current path a symlink to the file being written
+	currentLink bool
+	// dayLayout names the dated directory the current file is kept in, if
+	// any, beneath dayRoot; dayEnd is when the current file's day is over
+	dayLayout string
+	dayRoot   string
+	dayEnd    time.Time
+	// failover, if set, is the directory to switch to when the current one
+	// keeps failing
+	failover *failover
+	// dirPerm is the mode of created directories, and noMkdir stops them
+	// being created at all
+	dirPerm os.FileMode
+	noMkdir bool
+	// checkEvery is how often a running Rolog checks its current file still
+	// exists
+	checkEvery time.Duration
+	// dropSummary is how often a running Rolog summarizes drops in the log
+	dropSummary time.Duration
+	// triggerFile and rotateSignals request rotations from outside the
+	// process
+	triggerFile   string
+	rotateSignals []os.Signal
+	// handoffWait is how long New waits for another process to hand off
+	// the log, through the request file at handoffPath; handedIn is set if
+	// it did, with the next rotation it had scheduled in resumeAt, and
+	// handedOff once this process has given the log up
+	handoffWait         time.Duration
+	handoffPath         string
+	handedIn, handedOff bool
+	resumeAt            time.Time
+	// reloadPath is the config file reloaded on changes and reloadSignals
+	reloadPath    string
+	reloadSignals []os.Signal
+	// paused suspends scheduled rotation, and missed records a skipped one
+	paused, missed bool
+	// optErr is the first configuration error reported by an Option
+	optErr error
+	// next is when the next scheduled rotation is due
+	next time.Time
+	// tees receive a copy of every write
+	tees []io.Writer
+	// syncPolicy decides when writes are synced
+	syncPolicy SyncPolicy
+	// unsynced and lastSync track progress towards the next sync
+	unsynced int64
+	lastSync time.Time
+	// appendOnStart continues an existing current file instead of archiving it
+	appendOnStart bool
+	// startArchive, if set, decides whether New archives an existing
+	// current file or continues it
+	startArchive *startArchive
+	// perm is the mode for the current file
+	perm os.FileMode
+	// stdLog attaches the Rolog to the standard logger on creation
+	stdLog bool
+	// opts are the options the Rolog was created with, reused by Child
+	opts []Option
+	// buf, if set, collects writes before they reach f; bufSize is its size and
+	// flushEvery how often the run loop drains it
+	buf        *bufio.Writer
+	bufSize    int
+	flushEvery time.Duration
+	// parent and children link Rologs that rotate in lock-step
+	parent   *Rolog
+	children []*Rolog
+	// topics are the logs opened by WriteTopic, guarded by topicMu, and
+	// topicIdle is how long they may go unwritten before being closed
+	topicMu   sync.Mutex
+	topics    map[string]*topicLog
+	topicIdle time.Duration
+	// queue, if set, carries writes to a background writer; queueSize is its
+	// capacity
+	queue     *asyncQueue
+	queueSize int
+	// nonBlocking makes the queue discard entries according to dropPolicy
+	// rather than wait when it is full
+	nonBlocking bool
+	dropPolicy  DropPolicy
+	// dedupWindow enables repeated-line suppression; lastLine, repeats and
+	// runStart track the current run of identical lines
+	dedupWindow time.Duration
+	lastLine    []byte
+	repeats     int
+	runStart    time.Time
+	// dedupBuf and encodeBufs are scratch space reused by every write
+	dedupBuf   []byte
+	encodeBufs [2][]byte
+	// writeTimeout bounds how long Write may block, and stuckWrites counts
+	// the writes that outlasted it and are still in progress, accessed
+	// atomically
+	writeTimeout time.Duration
+	stuckWrites  int32
+	// limiter, if set, throttles writes
+	limiter *limiter
+	// quota, if set, caps the bytes written in each window
+	quota *quota
+	// prealloc is how much space to reserve for each new current file, or -1
+	// for maxSize
+	prealloc int64
+	// ring, if set, carries writes to a background writer without locking;
+	// ringSize and ringPolicy configure it
+	ring       *ring
+	ringSize   int
+	ringPolicy RingPolicy
+	// seq numbers writes awaiting a group commit, and synced is the highest
+	// one known to be on disk; syncing is set while a writer is syncing on
+	// behalf of the others, or a rotation is retiring the old file. The
+	// writes after lostFrom up to lostTo went to an old file that couldn't
+	// be synced, with lostErr. seq is guarded by mu, the rest by commitMu.
+	seq, synced      uint64
+	syncing          bool
+	lostFrom, lostTo uint64
+	lostErr          error
+	commitMu         sync.Mutex
+	commitCond       *sync.Cond
+	// rotations counts file swaps, and processed is the last one whose archive
+	// has been processed. rotations is guarded by mu, the rest by rotMu, which
+	// serializes archive processing and Purge.
+	rotations, processed uint64
+	rotMu                sync.Mutex
+	rotCond              *sync.Cond
+	// swapMu serializes rotations called without mu held, and swapping is set
+	// while one prepares the next file with writes still flowing
+	swapMu   sync.Mutex
+	swapping bool
+	// followers are the Followers of the current file, and watchers the
+	// channels returned by Watch, both guarded by mu
+	followers map[*Follower]struct{}
+	watchers  map[chan []byte]struct{}
+	watch     watchSettings
+	// tracer, if set, records spans for rotations and archive processing
+	tracer Tracer
+	// diagnostics, if set, hears about operational problems as they happen
+	diagnostics diagnostics
+	// debug, if set, receives the trace enabled by WithDebug
+	debug *debugLog
+	// auditPath is where WithAudit keeps the audit trail, and auditLog is
+	// the trail once it is open
+	auditPath string
+	auditLog  *auditLog
+	// systemd, if set, receives the notifications enabled by WithSystemd
+	systemd *systemdNotifier
+	// alerter, if set, counts failed rotations for WithAlert
+	alerter *alerter
+	// passthrough, if set, receives the writes in place of any file
+	passthrough io.Writer
+	// mirrors are the tees that deliver from goroutines of their own
+	mirrors []*mirror
+	// copyDirs are the directories to keep copies of the log in
+	copyDirs []copyDir
+	// dryRun, if set, receives the archive processing instead of it being
+	// done
+	dryRun func(PlannedAction)
+	// written counts the bytes written since New, and writtenAtRotation what
+	// it was at the last rotation; both are guarded by mu
+	written, writtenAtRotation int64
+	// lastRotation is the last processed rotation, and pauses how long
+	// rotations have blocked writes, for Stats
+	lastRotation rotationStats
+	pauses       pauseTracker
+	// live holds the *Settings snapshot published whenever a runtime setting
+	// changes
+	live atomic.Value
+	// metrics holds the metricsHolder for the installed Metrics, if any
+	metrics atomic.Value
+	// observers holds the subscribers for the registered Observers, and
+	// withObservers the Observers given to New
+	observers     atomic.Value
+	withObservers []Observer
 }
 
-// Write satisfies io.Writer. It syncs on every write to prevent the visible log
-// from being stale while we wait for a flush to disk.
+// Write satisfies io.Writer. By default it syncs on every write to prevent the
+// visible log from being stale while we wait for a flush to disk; see
+// WithSyncPolicy. See WithAsync and WithRing for non-blocking variants.
+//
+// A single Write is never split across two files, so a multi-line entry
+// written in one call always ends up whole in one file. If the write would take
+// the current file past its maximum size, the file is rotated first and the
+// entire write goes to the new file, even if it is larger than the maximum size
+// on its own.
 func (r *Rolog) Write(p []byte) (int, error) {
+	if r.limiter != nil && !r.throttle(len(p)) {
+		r.drop(DropRateLimit)
+		return len(p), nil
+	}
+	if r.quota != nil && !r.withinQuota(len(p)) {
+		r.drop(DropQuota)
+		return len(p), nil
+	}
+	if r.writeTimeout > 0 {
+		return r.writeWithin(p, r.writeTimeout)
+	}
+	return r.dispatch(p)
+}
+
+// dispatch hands p to the configured write path.
+func (r *Rolog) dispatch(p []byte) (int, error) {
+	if r.ring != nil {
+		return r.ringWrite(p)
+	}
+	if r.queue != nil {
+		return r.enqueue(p)
+	}
+	return r.write(p)
+}
+
+// write does the work of Write, waiting for the data to be synced if the
+// policy requires it.
+func (r *Rolog) write(p []byte) (int, error) {
+	m := r.meter()
+	if m != nil {
+		start := time.Now()
+		defer func() { m.Wrote(len(p), time.Since(start)) }()
+	}
+
+	var (
+		n    int
+		seq  uint64
+		rot  *rotation
+		err  error
+		prev swapDue
+	)
+	for {
+		n, seq, rot, err = func() (int, uint64, *rotation, error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			if r.closed() {
+				return 0, 0, nil, ErrClosed
+			}
+			return r.writeLocked(p, prev)
+		}()
+		due, ok := err.(swapDue)
+		if !ok {
+			break
+		}
+		prev = due
+
+		// The file is full, so it is swapped for the next one without
+		// holding up other writers, and the write goes there instead. A
+		// failed swap is left to writeLocked to retry and handle.
+		swapped, serr := r.swapRotate(due.reason, func() bool {
+			return r.rotations == due.rotations
+		})
+		if serr == nil && swapped != nil {
+			if err := r.process(swapped); err != nil {
+				r.report(err)
+			}
+		}
+	}
+
+	if rot != nil {
+		if err := r.process(rot); err != nil {
+			r.report(err)
+		}
+	}
+
+	if err == nil && seq > 0 {
+		if err = r.commit(seq); err != nil {
+			return 0, err
+		}
+	}
+	return n, err
+}
+
+// overMaxSize reports whether writing n more bytes would take the current file
+// past the max size. It must be called with mu held.
+func (r *Rolog) overMaxSize(n int64) bool {
+	max := r.settings().MaxSize
+	return max > 0 && r.size > 0 && r.size+n > max
+}
+
+// swapDue is the error writeLocked returns instead of rotating on the write
+// path when the strategy can prepare the next file without mu held. rotations
+// is the count of swaps when it fell due, so that writers racing to it only
+// rotate once.
+type swapDue struct {
+	reason    RotationReason
+	rotations uint64
+}
+
+func (swapDue) Error() string {
+	return "rotation due"
+}
+
+// writeLocked writes p to the current file, returning the sequence number to
+// commit and the rotation to process, if any. Under a SwappingStrategy, a full
+// file is left to the caller to swap with a swapDue error, and the caller then
+// calls again with it as prev, which is otherwise zero: if the file has been
+// swapped since, the write goes ahead, and otherwise the swap failed and the
+// file is rotated here. It must be called with mu held.
+func (r *Rolog) writeLocked(p []byte, prev swapDue) (n int, seq uint64, rot *rotation, err error) {
+	out := p
+	if r.dedupWindow > 0 {
+		r.dedupBuf = r.dedup(r.dedupBuf[:0], out)
+		out = r.dedupBuf
+	}
+	out = r.encode(&r.encodeBufs, out)
+
+	var reason RotationReason
+	switch {
+	case r.swapping || r.passthrough != nil || r.handedOff:
+	case prev.reason != "" && r.rotations != prev.rotations:
+	case r.overMaxSize(int64(len(out))):
+		r.debugf("size trigger: %d bytes plus a %d byte write exceeds the max size of %d", r.size, len(out), r.settings().MaxSize)
+		reason = ReasonSize
+	case r.dayOver(r.now()):
+		r.debugf("day trigger: the day of the current file ended at %s", r.dayEnd)
+		reason = ReasonDay
+	}
+	if _, ok := r.rotationStrategy().(SwappingStrategy); ok && reason == ReasonSize && prev.reason == "" {
+		return 0, 0, nil, swapDue{reason, r.rotations}
+	}
+	if reason != "" {
+		start := r.now()
+		if rot, err = r.rotate(reason); err != nil {
+			r.trace(OpRotate, SpanInfo{Reason: reason}, start, err)
+			r.alertOutcome(err)
+			r.report(err)
+			if !r.failOver(err) {
+				if r.fallBack(out, err) {
+					r.tee(out)
+					return len(p), 0, nil, nil
+				}
+				return 0, 0, nil, errors.Wrap(err, "could not rotate full log")
+			}
+		}
+	}
+
+	n, err = r.output().Write(out)
+	r.size += int64(n)
+	r.written += int64(n)
+	seq = r.syncAfterWrite(n)
+	r.tee(out)
+	if err != nil {
+		if r.buf != nil {
+			// A bufio.Writer refuses every write after its first error, so start
+			// afresh to give the file a chance to recover.
+			r.buf.Reset(r.f)
+		}
+		if r.failOver(err) {
+			m, ferr := r.output().Write(out[n:])
+			r.size += int64(m)
+			r.written += int64(m)
+			if ferr == nil {
+				return len(p), seq, rot, nil
+			}
+			n, err = n+m, ferr
+		}
+		if isDiskFull(err) && r.handleDiskFull(out[n:]) {
+			return len(p), seq, rot, nil
+		}
+		if r.fallBack(out[n:], err) {
+			return len(p), seq, rot, nil
+		}
+		return 0, 0, rot, err
+	}
+	r.succeeded()
+	r.recovered()
+	return len(p), seq, rot, nil
+}
+
+// WriteString satisfies io.StringWriter, so that callers holding a string
+// needn't convert it themselves. The string is written without being copied.
+func (r *Rolog) WriteString(s string) (int, error) {
+	return r.Write(stringBytes(s))
+}
+
+// stringBytes returns the bytes of s without copying them. The result must not
+// be modified, which the io.Writer contract already guarantees.
+func stringBytes(s string) []byte {
+	return *(*[]byte)(unsafe.Pointer(&struct {
+		string
+		cap int
+	}{s, len(s)}))
+}
+
+// Rotate pauses logging switch from the current file to a new one. By default
+// it moves the current file to an archive file by renaming it according to the
+// template and creates a new file handle to continue logging; see
+// RotationStrategy for alternatives. Once the new file is open, writes resume
+// while the archive is post-processed, and old archives are pruned and bundled
+// according to the configured retention.
+//
+// If the strategy is a SwappingStrategy, the next file is prepared while
+// writes carry on, and they are only paused to swap the handles.
+func (r *Rolog) Rotate() error {
+	err := r.rotateFor(ReasonManual)
+	r.audit(AuditRotate, r.currentPath(), err)
+	return err
+}
+
+// rotateFor performs a full rotation triggered by reason.
+func (r *Rolog) rotateFor(reason RotationReason) error {
+	if r.passthrough != nil {
+		return nil
+	}
+	r.debugf("rotating (%s)", reason)
+	start := r.now()
+	rot, err := r.swapRotate(reason, nil)
+	if err != nil {
+		r.trace(OpRotate, SpanInfo{Reason: reason}, start, err)
+		r.alertOutcome(err)
+		r.mu.Lock()
+		r.failOver(err)
+		r.mu.Unlock()
+		return err
+	}
+	return r.process(rot)
+}
+
+// swapRotate swaps the current file for a new one, preparing it without
+// pausing writes if the strategy allows. If due is set and reports, with mu
+// held, that the rotation is no longer due, nothing is done and the rotation
+// is nil. It must be called without mu held, and the returned rotation must
+// then be passed to process.
+func (r *Rolog) swapRotate(reason RotationReason, due func() bool) (*rotation, error) {
+	r.swapMu.Lock()
+	defer r.swapMu.Unlock()
+
 	r.mu.Lock()
-	defer func() {
-		r.f.Sync()
+	if r.closed() {
+		r.mu.Unlock()
+		return nil, ErrClosed
+	}
+	if due != nil && !due() {
+		r.mu.Unlock()
+		return nil, nil
+	}
+	if r.handedOff {
+		r.mu.Unlock()
+		return nil, ErrHandedOff
+	}
+	s, ok := r.rotationStrategy().(SwappingStrategy)
+	if !ok || r.dayOver(r.now()) {
+		defer r.mu.Unlock()
+		return r.rotate(reason)
+	}
+	if err := r.ensureDir(); err != nil {
+		r.mu.Unlock()
+		return nil, err
+	}
+
+	var (
+		start = r.now()
+		rot   = Rotation{
+			FS:      r.fs,
+			File:    r.f,
+			Current: r.currentPath(),
+			Archive: r.uniquePath(filepath.Join(filepath.Dir(r.currentPath()), r.fname())),
+			Perm:    r.perm,
+		}
+	)
+	r.swapping = true
+	attempts := r.attempts
+	r.mu.Unlock()
+
+	var (
+		next     File
+		archived string
+	)
+	err := attempts.try(func() (err error) {
+		next, archived, err = s.Prepare(rot)
+		return err
+	}, r.diagnoseRetry)
+
+	r.holdCommits()
+	r.mu.Lock()
+	paused := time.Now()
+	r.swapping = false
+	if err != nil {
+		r.mu.Unlock()
+		r.releaseCommits()
+		return nil, err
+	}
+	if r.closed() {
 		r.mu.Unlock()
+		r.releaseCommits()
+		next.Close()
+		return nil, ErrClosed
+	}
+
+	r.flushRepeats()
+	r.writeFooter(start)
+	r.writeEndRecord(reason, start, archived)
+	if err := r.drain(); err != nil {
+		r.mu.Unlock()
+		r.releaseCommits()
+		next.Close()
+		return nil, err
+	}
+	written := r.size
+	pending := r.swap(next, archived, start, written, reason)
+	r.recordPause(pending, paused)
+	last := r.seq
+	r.mu.Unlock()
+
+	// Commits of the writes to the old file wait for it to be synced here,
+	// while new writes go on to the next one.
+	if err := r.retire(rot.File, last); err != nil {
+		r.report(err)
+	}
+
+	return pending, nil
+}
+
+// recordPause records that writes were blocked from since until now for rot. It must
+// be called with mu held.
+func (r *Rolog) recordPause(rot *rotation, since time.Time) {
+	rot.paused = time.Since(since)
+	r.pauses.record(rot.paused)
+}
+
+// rotation is a completed file swap whose archive is still to be processed.
+type rotation struct {
+	// ticket orders processing to match the order of the swaps
+	ticket   uint64
+	reason   RotationReason
+	start    time.Time
+	written  int64
+	paused   time.Duration
+	archived string
+	settings archiveSettings
+}
+
+// archiveSettings is a snapshot of the configuration used to process an
+// archive, so that the processing can run without holding mu.
+type archiveSettings struct {
+	compression     Compression
+	compressWorkers int
+	compressLevel   int
+	compressPool    *CompressPool
+	encrypter       Encrypter
+	perm            os.FileMode
+	uid, gid        int
+	latestLink      bool
+	maxBackups      int
+	maxTotalSize    int64
+	maxAge          time.Duration
+	current         int64
+	bundleAge       time.Duration
+	deleteFilter    func(ArchiveInfo) bool
+	children        []*Rolog
+	dirSync         bool
+	manifest        bool
+	dryRun          func(PlannedAction)
+}
+
+// archiveSettings returns the current archive settings. It must be called with
+// mu held.
+func (r *Rolog) archiveSettings() archiveSettings {
+	live := r.settings()
+	return archiveSettings{
+		compression:     live.Compression,
+		compressWorkers: live.CompressWorkers,
+		compressLevel:   live.CompressionLevel,
+		compressPool:    r.compressPool,
+		encrypter:       r.encrypter,
+		perm:            live.ArchivePerm,
+		uid:             r.archiveUID,
+		gid:             r.archiveGID,
+		latestLink:      live.LatestLink,
+		maxBackups:      live.MaxBackups,
+		maxTotalSize:    live.MaxTotalSize,
+		maxAge:          live.MaxAge,
+		current:         r.size,
+		bundleAge:       live.BundleAge,
+		deleteFilter:    r.deleteFilter,
+		children:        append([]*Rolog(nil), r.children...),
+		dirSync:         r.dirSync,
+		manifest:        r.manifest,
+		dryRun:          r.dryRun,
+	}
+}
+
+// rotate swaps the current file for a new one. It must be called with mu held,
+// and the returned rotation must then be passed to process once mu has been
+// released.
+func (r *Rolog) rotate(reason RotationReason) (*rotation, error) {
+	paused := time.Now()
+	r.flushRepeats()
+
+	start := r.now()
+	if r.dayOver(start) {
+		return r.rollDay(reason, start, paused)
+	}
+
+	newPath := r.uniquePath(filepath.Join(filepath.Dir(r.currentPath()), r.fname()))
+	r.writeFooter(start)
+	r.writeEndRecord(reason, start, newPath)
+	written := r.size
+
+	if err := r.drain(); err != nil {
+		return nil, err
+	}
+	// Synced here, rather than only by the strategy, so a failure keeps the
+	// writes it holds from being acknowledged through the next file.
+	if err := r.f.Sync(); err != nil {
+		return nil, errors.Wrap(err, "could not sync log file")
+	}
+	if err := r.ensureDir(); err != nil {
+		return nil, err
+	}
+
+	var (
+		strategy = r.rotationStrategy()
+		rot      = Rotation{
+			FS:      r.fs,
+			File:    r.f,
+			Current: r.currentPath(),
+			Archive: newPath,
+			Perm:    r.perm,
+		}
+		f        File
+		archived string
+	)
+	err := r.attempts.try(func() (err error) {
+		f, archived, err = strategy.Rotate(rot)
+		if err != nil && f != nil {
+			// The strategy left us a handle to carry on with, so that is
+			// the file to rotate on the next attempt. If it is the file
+			// we already had, it is set up as current already.
+			if f != rot.File {
+				r.setFile(f)
+				rot.File = r.f
+			}
+		}
+		return err
+	}, r.diagnoseRetry)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := r.swap(f, archived, start, written, reason)
+	r.recordPause(pending, paused)
+	return pending, nil
+}
+
+// swap makes f the current file after a rotation for reason that started at
+// start and archived the written bytes. It must be called with mu held.
+func (r *Rolog) swap(f File, archived string, start time.Time, written int64, reason RotationReason) *rotation {
+	r.setFile(f)
+
+	r.size, r.unsynced, r.lastSync = 0, 0, start
+	if fi, err := f.Stat(); err == nil {
+		r.size = fi.Size()
+	}
+	if err := r.writeHeader(); err != nil {
+		r.report(err)
+	}
+	if r.dirSync {
+		if err := syncDir(r.fs, filepath.Dir(r.currentPath())); err != nil {
+			r.report(errors.Wrap(err, "could not sync log directory"))
+		}
+	}
+
+	r.rotations++
+	r.writtenAtRotation = r.written
+	for _, m := range r.mirrors {
+		m.rotated()
+	}
+	if err := r.writeStartRecord(reason, start, archived); err != nil {
+		r.report(err)
+	}
+	return &rotation{
+		ticket:   r.rotations,
+		reason:   reason,
+		start:    start,
+		written:  written,
+		archived: archived,
+		settings: r.archiveSettings(),
+	}
+}
+
+// process finalizes the archive produced by a rotation, rotates any children,
+// and applies retention. Rotations are processed one at a time in the order
+// they happened, but without blocking writes. An archive to be compressed on a
+// CompressPool is handed to it, and the processing from compression on is
+// done there. It must be called without mu held. A panic during processing is
+// returned as a *PanicError.
+func (r *Rolog) process(rot *rotation) (err error) {
+	r.rotMu.Lock()
+	defer r.rotMu.Unlock()
+
+	if r.rotCond == nil {
+		r.rotCond = sync.NewCond(&r.rotMu)
+	}
+	for r.processed+1 < rot.ticket {
+		r.rotCond.Wait()
+	}
+	defer func() {
+		r.processed = rot.ticket
+		r.rotCond.Broadcast()
+	}()
+	defer func() {
+		r.trace(OpRotate, SpanInfo{Reason: rot.reason, Path: rot.archived}, rot.start, err)
+		r.alertOutcome(err)
 	}()
+	defer catch("archive processing", &err)
 
-	return fmt.Fprintf(r.f, string(p))
+	s := rot.settings
+	if rot.archived != "" && s.dryRun == nil && s.compressPool != nil && s.compression != NoCompression {
+		// Children still rotate in step; the rest waits for the archive.
+		if err = rotateChildren(s.children); err != nil {
+			return err
+		}
+		r.compressing.Add(1)
+		s.compressPool.submit(func() {
+			defer r.compressing.Done()
+			r.rotMu.Lock()
+			defer r.rotMu.Unlock()
+			if err := r.finish(rot, false); err != nil {
+				r.report(err)
+			}
+		})
+		return nil
+	}
+	return r.finish(rot, true)
 }
 
-// Rotate pauses logging switch from the current file to a new one. It moves the
-// current file to an archive file by renaming it according to the template and
-// creates a new file handle to continue logging.
-func (r *Rolog) Rotate() error {
+// finish finalizes the archive of rot, announces the rotation, rotates the
+// children if asked to, and applies retention. It must be called with rotMu
+// held.
+func (r *Rolog) finish(rot *rotation, children bool) (err error) {
+	defer catch("archive processing", &err)
+
 	var (
-		err     error
-		newPath = filepath.Join(filepath.Dir(r.path), r.fname())
+		s        = rot.settings
+		archived = rot.archived
 	)
 
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	if archived != "" && s.dryRun == nil {
+		if archived, err = r.finalize(archived, s); err != nil {
+			return errors.Wrap(err, "could not finalize archive")
+		}
+		if s.dirSync {
+			if err = syncDir(r.fs, filepath.Dir(archived)); err != nil {
+				return errors.Wrap(err, "could not sync log directory")
+			}
+		}
+	}
 
-	r.f.Sync()
-	r.f.Close()
-	if err = os.Rename(r.path, newPath); err != nil {
-		return errors.Wrap(err, "could not archive old log file")
+	took := r.now().Sub(rot.start)
+	r.recordRotation(rot.start, took)
+	r.notify(RotationEvent{
+		Reason:   rot.reason,
+		OldPath:  r.currentPath(),
+		NewPath:  archived,
+		Bytes:    rot.written,
+		Duration: took,
+		Paused:   rot.paused,
+	})
+
+	if children {
+		if err = rotateChildren(s.children); err != nil {
+			return err
+		}
+	}
+
+	if archived == "" {
+		return nil
+	}
+	if s.dryRun != nil {
+		return r.dryRunProcess(s, archived)
 	}
 
-	r.f, err = os.Create(r.path)
+	if err = r.updateLatest(archived, s); err != nil {
+		return errors.Wrap(err, "could not update latest link")
+	}
+
+	start := r.now()
+	err = r.prune(s)
+	r.trace(OpPrune, SpanInfo{Path: archived}, start, err)
 	if err != nil {
-		return errors.Wrap(err, "could not open new log file")
+		return errors.Wrap(err, "could not prune archives")
+	}
+
+	if err = r.bundle(s); err != nil {
+		return errors.Wrap(err, "could not bundle archives")
 	}
 
 	return nil
@@ -79,91 +925,364 @@ func (r *Rolog) Rotate() error {
 
 // fname returns the canonical name for an archive file.
 func (r *Rolog) fname() string {
-	return fmt.Sprintf(time.Now().Format(ArchiveFileFormat), r.name)
+	return r.fnameAt(r.now())
+}
+
+// fnameAt returns the canonical name for an archive file ending at t.
+func (r *Rolog) fnameAt(t time.Time) string {
+	prefix, layout := r.archiveLayout()
+	return prefix + t.Format(layout) + r.liveCompression.Extension()
+}
+
+// uniquePath returns path, or path with a numeric suffix if a file by that name
+// (or any post-processed variant of it) already exists. This keeps rotations
+// within the same second from clobbering each other.
+func (r *Rolog) uniquePath(path string) string {
+	fis, _ := r.fs.ReadDir(filepath.Dir(path))
+
+	candidate := path
+	for i := 1; ; i++ {
+		taken := false
+		for _, fi := range fis {
+			if strings.HasPrefix(fi.Name(), filepath.Base(candidate)) {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s.%d", path, i)
+	}
 }
 
-// Close satisfies io.Closer. It performs a final sync prior to closing the
-// current file, then signals our run loop to quit.
+// Close satisfies io.Closer. It persists anything queued or buffered, performs
+// a final sync prior to closing the current file, then stops the run loop. A
+// child stops rotating with its parent once closed. Close may be called before
+// Run, and calling it again returns ErrClosed.
 func (r *Rolog) Close() error {
+	if !r.transition(StateNew, StateClosing) && !r.transition(StateRunning, StateClosing) {
+		return ErrClosed
+	}
+	close(r.done)
+	r.sdNotify("STOPPING=1")
+
+	r.detach()
+	if err := r.closeTopics(); err != nil {
+		r.report(err)
+	}
+	r.stopRing()
+	r.stopQueue()
+	r.compressing.Wait()
+
 	r.mu.Lock()
 	defer func() {
+		if r.unlock != nil {
+			r.unlock.Close()
+		}
+		r.setState(StateClosed)
 		r.mu.Unlock()
-		r.done <- 1
 	}()
 
+	defer r.stopObservers()
+	defer r.closeAudit()
+	defer r.stopMirrors()
+
+	r.flushRepeats()
+	if err := r.drain(); err != nil {
+		r.f.Close()
+		return err
+	}
 	r.f.Sync()
 	return r.f.Close()
 }
 
 // New creates a Rolog instance which writes files into the given directory. It
 // uses the provided name as a base for naming the log files, and rotates them
-// on the schedule provided as interval. Note that we automatically set the
-// output of log to the new Rolog.
+// every DefaultInterval unless configured otherwise by opts. The standard
+// logger is left alone unless WithStdLog is given; see also AttachToStdLog.
+//
+// The configuration is validated before any file is touched, and a
+// *ConfigError is returned if it is unusable.
+//
+// If the current file is a symlink, such as one pointing into a data volume, the
+// file it points to is the one written, rotated and recreated, and archives are
+// kept beside it, so the link itself is left in place. With WithCurrentLink the
+// link is the Rolog's own and is managed as described there instead.
 //
 // The returned Rolog is not already running, and its Run method must be invoked
 // manually.
-func New(dir, name string, interval time.Duration) (*Rolog, error) {
-	var (
-		file = filepath.Join(dir, fmt.Sprintf(CurrentFilename, name))
-		r    = &Rolog{}
-		err  error
-	)
+func New(dir, name string, opts ...Option) (_ *Rolog, err error) {
+	r := newRolog(name, opts)
+	if err = r.validate(dir); err != nil {
+		return nil, err
+	}
+	if r.passthrough != nil {
+		r.openPassthrough(dir)
+		r.start()
+		return r, nil
+	}
 
-	r.name = name
+	if r.lock {
+		if r.handoffWait > 0 {
+			err = r.takeOver(dir)
+		} else {
+			err = r.acquireLock(dir)
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err != nil {
+				r.unlock.Close()
+			}
+		}()
+	}
 
-	if _, err = os.Stat(file); err == nil {
-		if err = os.Rename(file, filepath.Join(dir, r.fname())); err != nil {
-			return nil, errors.Wrap(err, "could not archive existing log")
+	if r.dayLayout != "" {
+		r.dayRoot = dir
+		if dir, r.dayEnd, err = r.openDay(dir, r.now()); err != nil {
+			return nil, err
 		}
 	}
 
-	r.f, err = os.Create(file)
+	file := filepath.Join(dir, fmt.Sprintf(r.currentFormat(), name))
+	if !r.currentLink {
+		file = resolveLinks(r.fs, file)
+	}
+
+	var f File
+	if r.currentLink {
+		f, err = r.openLinked(dir, file)
+	} else {
+		flag := currentFlag | os.O_TRUNC
+		if r.continueOnStart(dir, file) {
+			flag = currentFlag
+		} else if _, err = r.fs.Stat(file); err == nil {
+			if err = renameFile(r.fs, file, r.uniquePath(filepath.Join(dir, r.fname()))); err != nil {
+				return nil, errors.Wrap(err, "could not archive existing log")
+			}
+		}
+		f, err = openFile(r.fs, file, flag, r.perm)
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "could not create new log")
 	}
+	r.setFile(f)
 
-	r.path = file
-	r.interval = interval
-	r.done = make(chan int, 1)
-	r.err = make(chan error, 1)
-
-	log.SetOutput(r)
+	if fi, err := r.f.Stat(); err == nil {
+		r.size = fi.Size()
+	}
+	if err := r.writeHeader(); err != nil {
+		r.f.Close()
+		return nil, err
+	}
+	if r.dirSync {
+		if err := syncDir(r.fs, dir); err != nil {
+			r.f.Close()
+			return nil, errors.Wrap(err, "could not sync log directory")
+		}
+	}
+	if err := r.writeStartRecord("", r.now(), ""); err != nil {
+		r.f.Close()
+		return nil, err
+	}
+	if err := r.openAudit(); err != nil {
+		r.f.Close()
+		return nil, err
+	}
+	if err := r.openCopies(); err != nil {
+		r.closeAudit()
+		r.f.Close()
+		return nil, err
+	}
 
+	r.setPath(file)
+	r.start()
 	return r, nil
 }
 
+// start readies an opened Rolog for writing.
+func (r *Rolog) start() {
+	r.done = make(chan struct{})
+	r.reconfig = make(chan struct{}, 1)
+	r.err = make(chan error, errBuffer)
+	r.events = make(chan RotationEvent, eventBuffer)
+	for _, o := range r.withObservers {
+		r.subscribe(o)
+	}
+	if r.passthrough == nil {
+		r.repair()
+	}
+	r.startMirrors()
+	r.startRing()
+	r.startQueue()
+	if r.passthrough == nil {
+		r.startCatchUp()
+	}
+
+	if r.stdLog {
+		r.AttachToStdLog()
+	}
+	r.sdNotify("READY=1")
+}
+
+// newRolog returns an unopened Rolog with the defaults applied, followed by
+// opts.
+func newRolog(name string, opts []Option) *Rolog {
+	r := &Rolog{}
+	r.name = name
+	r.opts = opts
+	r.interval = DefaultInterval
+	r.clock = systemClock{}
+	r.fs = OSFS{}
+	r.strategy = RenameStrategy{}
+	r.archiveUID, r.archiveGID = -1, -1
+	r.retry = retryPolicy{backoff: DefaultRetryBackoff}
+	r.watch = watchSettings{buffer: DefaultWatchBuffer}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.debug != nil {
+		r.fs = debugFS{r.fs, r}
+	}
+	r.publish()
+	return r
+}
+
+// AttachToStdLog sets the output of the standard library's default logger to
+// the Rolog.
+func (r *Rolog) AttachToStdLog() {
+	log.SetOutput(r)
+}
+
 // StartNew calls New, but also starts the Rolog automatically.
-func StartNew(dir, name string, interval time.Duration) (*Rolog, error) {
-	r, err := New(dir, name, interval)
+func StartNew(dir, name string, opts ...Option) (*Rolog, error) {
+	r, err := New(dir, name, opts...)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not start log rotator")
 	}
 
-	r.Run()
+	r.Run(context.Background())
 
 	return r, nil
 }
 
-// Run starts the Rolog loop in a separate goroutine.
-func (r *Rolog) Run() {
-	go r.run()
+// errBuffer is how many undelivered errors are kept for Err.
+const errBuffer = 16
+
+// Err returns a channel on which failures from background work, such as
+// scheduled and size-triggered rotations and the pruning that follows them, are
+// delivered. A failed rotation no longer stops the run loop; the next tick
+// tries again. Panics recovered from background work are delivered as a
+// *PanicError. If the caller doesn't keep up, the oldest undelivered errors are
+// discarded.
+func (r *Rolog) Err() <-chan error {
+	return r.err
+}
+
+// Report delivers err on Err, and to any Metrics and Observers, as though the
+// Rolog had run into it, for sinks and other extensions that work alongside it
+// and have no caller to return their errors to.
+func (r *Rolog) Report(err error) {
+	if err != nil {
+		r.report(err)
+	}
+}
+
+// report delivers err on the error channel without blocking, discarding the
+// oldest pending error if the channel is full.
+func (r *Rolog) report(err error) {
+	if m := r.meter(); m != nil {
+		m.Failed(err)
+	}
+	r.observe(event{kind: eventError, err: err})
+	if r.diagnostics != nil {
+		r.diagnostics.failed(err)
+	}
+
+	errq.Deliver(r.err, err)
+}
+
+// Run starts the Rolog loop in a separate goroutine. The loop stops when ctx is
+// cancelled or the Rolog is closed. Cancelling ctx only stops scheduled
+// rotation; the Rolog remains writable until Close is called.
+//
+// Run does nothing if the loop is already running or the Rolog is closed.
+func (r *Rolog) Run(ctx context.Context) {
+	if !r.transition(StateNew, StateRunning) {
+		return
+	}
+	go func() {
+		defer r.transition(StateRunning, StateNew)
+		defer r.contain("run loop")
+		r.run(ctx)
+	}()
 }
 
-// run simply waits for the provided interval and rotates the logs when it is
-// reached.
-func (r *Rolog) run() {
-	ticker := time.NewTicker(r.interval)
+// run waits for the scheduler's tickers and performs whatever is due, blocking
+// in between. It returns when ctx is cancelled, the Rolog is closed, or too
+// many consecutive rotations have failed.
+func (r *Rolog) run(ctx context.Context) {
+	s := newScheduler(r)
+	defer s.stop()
+
 	for {
+		s.idle()
 		select {
-		case <-ticker.C:
-			if err := r.Rotate(); err != nil {
-				r.err <- err
-				r.done <- 1
+		case t := <-s.tick(s.rotation):
+			r.debugf("scheduled rotation due at %s", t.Format(time.RFC3339))
+			r.setNext(t.Add(s.interval))
+			if s.rotate() {
+				return
+			}
+			if s.resumed {
+				// back on the usual interval after a handoff
+				s.reschedule()
+			}
+		case <-s.tick(s.retry):
+			r.debugf("retrying failed rotation, attempt %d", s.failures+1)
+			if s.rotate() {
+				return
+			}
+		case <-s.tick(s.flush):
+			s.flushBuffer()
+		case <-s.tick(s.liveFlush):
+			r.flushLive()
+		case <-s.tick(s.sync):
+			s.syncBackground()
+		case <-s.tick(s.check):
+			if err := r.checkCurrent(); err != nil {
+				r.report(err)
 			}
+		case <-s.tick(s.summary):
+			s.summarizeDrops()
+		case <-s.tick(s.watchdog):
+			r.sdNotify("WATCHDOG=1")
+		case <-s.tick(s.trigger):
+			s.checkTrigger()
+		case <-s.tick(s.topics):
+			r.closeIdleTopics()
+		case <-s.tick(s.handoff):
+			if r.handOff() {
+				return
+			}
+		case <-s.tick(s.failback):
+			if err := r.failBack(); err != nil {
+				r.report(err)
+			}
+		case sig := <-s.signals:
+			s.rotateOnSignal(sig)
+		case <-s.tick(s.reload):
+			s.checkConfig()
+		case sig := <-s.reloads:
+			s.reloadOnSignal(sig)
+		case <-r.reconfig:
+			s.reschedule()
 		case <-r.done:
 			return
-		default:
-			time.Sleep(100 * time.Millisecond)
+		case <-ctx.Done():
+			return
 		}
 	}
 }
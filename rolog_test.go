@@ -1,24 +1,31 @@
 package rolog
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/pkg/errors"
 )
 
 func TestFnameCreatesNames(t *testing.T) {
-	now := time.Now()
+	clock := newFakeClock()
+	now := clock.Now()
 	year, month, day := now.Date()
 	hour, min, sec := now.Clock()
 
 	want := fmt.Sprintf("test-%d-%0.2d-%0.2d-%0.2d%0.2d%0.2d.log", year, month, day, hour, min, sec)
 	fmt.Printf("Looking for: %s\n", want)
 
-	r := &Rolog{name: "test"}
+	r := &Rolog{name: "test", clock: clock}
 	got := r.fname()
 
 	if want != got {
@@ -39,7 +46,7 @@ func TestNewCreatesARolog(t *testing.T) {
 		t.FailNow()
 	}
 
-	r, err := New(dir, "test", want.interval)
+	r, err := New(dir, "test", WithInterval(want.interval))
 	if err != nil {
 		t.Errorf("unexpected error: %q", err)
 		t.FailNow()
@@ -71,7 +78,7 @@ func TestNewCreatesCorrectCurrentFile(t *testing.T) {
 	}
 	f.Close()
 
-	r, err := New(dir, "test", 60*time.Minute)
+	r, err := New(dir, "test", WithInterval(60*time.Minute))
 	if err != nil {
 		t.Errorf("unexpected error: %q", err)
 		t.FailNow()
@@ -109,7 +116,7 @@ func TestRotateCreatesArchiveAndOpensNew(t *testing.T) {
 		t.FailNow()
 	}
 
-	r, err := New(dir, "test", 5*time.Second)
+	r, err := New(dir, "test", WithInterval(5*time.Second))
 	if err != nil {
 		t.Errorf("unexpected error: %q", err)
 		t.FailNow()
@@ -139,9 +146,6 @@ func TestRotateCreatesArchiveAndOpensNew(t *testing.T) {
 		}
 	}
 
-	// Wait here just to make sure we get a new filename
-	time.Sleep(1 * time.Second)
-
 	{
 		if err := r.Rotate(); err != nil {
 			t.Errorf("could not rotate: %q", err)
@@ -167,24 +171,32 @@ func TestRunCreatesFilesOnTime(t *testing.T) {
 		t.FailNow()
 	}
 
-	r, err := New(dir, "test", 5*time.Second)
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithInterval(5*time.Second), WithStdLog(), WithClock(clock))
 	if err != nil {
 		t.Errorf("unexpected error: %q", err)
 		t.FailNow()
 	}
 
 	defer func() {
+		log.SetOutput(os.Stderr)
 		r.Close()
 		if err := os.RemoveAll(dir); err != nil {
 			t.Errorf("could not cleanup temp files: %q", err)
 		}
 	}()
 
-	r.Run()
+	r.Run(context.Background())
+	for clock.numTickers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
 	log.Println("first")
-	time.Sleep(6 * time.Second)
+	clock.Advance(6 * time.Second)
+	waitForRotation(t, r)
 	log.Println("second")
-	time.Sleep(7 * time.Second)
+	clock.Advance(7 * time.Second)
+	waitForRotation(t, r)
 	log.Println("third")
 	r.Close()
 
@@ -198,3 +210,589 @@ func TestRunCreatesFilesOnTime(t *testing.T) {
 		t.Errorf("Wanted 3 files, got %d", len(fi))
 	}
 }
+
+func TestWriteRotatesAtMaxSize(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithMaxSize(10))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	for i := 0; i < 3; i++ {
+		if _, err := r.Write([]byte("12345678\n")); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+	}
+
+	archives, err := r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if len(archives) != 2 {
+		t.Errorf("Wanted 2 archives, got %d", len(archives))
+	}
+}
+
+func TestNewLeavesStdLogAlone(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+
+	r, err := New(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		log.SetOutput(os.Stderr)
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	if log.Writer() != &buf {
+		t.Errorf("Wanted the standard logger to be untouched")
+	}
+
+	r.AttachToStdLog()
+	if log.Writer() != r {
+		t.Errorf("Wanted the standard logger to write to the Rolog")
+	}
+}
+
+func TestRunStopsWhenContextIsCancelled(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithInterval(250*time.Millisecond))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r.Run(ctx)
+	time.Sleep(600 * time.Millisecond)
+
+	archives, err := r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if len(archives) != 0 {
+		t.Errorf("Wanted no rotations after cancel, got %d", len(archives))
+	}
+}
+
+func TestRunReportsRotationErrorsAndKeepsGoing(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithInterval(200*time.Millisecond))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	// Remove the current file out from under the Rolog so renames fail.
+	os.Remove(filepath.Join(dir, "test.log"))
+
+	r.Run(context.Background())
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-r.Err():
+			if err == nil {
+				t.Errorf("Wanted a rotation error")
+			}
+		case <-time.After(2 * time.Second):
+			t.Errorf("Wanted rotation error %d to be reported", i+1)
+			t.FailNow()
+		}
+	}
+}
+
+func TestWithFileModeAppliesToCurrentFile(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithFileMode(0600))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	for i := 0; i < 2; i++ {
+		fi, err := os.Stat(filepath.Join(dir, "test.log"))
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		if fi.Mode().Perm() != 0600 {
+			t.Errorf("Wanted mode 0600, got %o", fi.Mode().Perm())
+		}
+
+		if err := r.Rotate(); err != nil {
+			t.Errorf("could not rotate: %q", err)
+			t.FailNow()
+		}
+	}
+}
+
+// waitForRotation blocks until r reports a rotation, failing the test if none
+// arrives promptly.
+func waitForRotation(t *testing.T, r *Rolog) RotationEvent {
+	select {
+	case e := <-r.Notify():
+		return e
+	case <-time.After(2 * time.Second):
+		t.Errorf("timed out waiting for rotation")
+		t.FailNow()
+	}
+	return RotationEvent{}
+}
+
+func TestNewWithAppendContinuesExistingFile(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	path := filepath.Join(dir, "test.log")
+	if err := ioutil.WriteFile(path, []byte("first\n"), 0644); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithAppend())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("second\n"))
+
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if string(got) != "first\nsecond\n" {
+		t.Errorf("Wanted %q, got %q", "first\nsecond\n", got)
+	}
+
+	if r.size != int64(len(got)) {
+		t.Errorf("Wanted size %d, got %d", len(got), r.size)
+	}
+}
+
+func TestWriteIsVerbatim(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	want := "100% done, %d items\n"
+	r.Write([]byte(want[:10]))
+	if n, err := r.WriteString(want[10:]); err != nil || n != len(want)-10 {
+		t.Errorf("Wanted %d bytes written, got %d (%v)", len(want)-10, n, err)
+	}
+
+	got, err := ioutil.ReadFile(filepath.Join(dir, "test.log"))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if string(got) != want {
+		t.Errorf("Wanted %q, got %q", want, got)
+	}
+}
+
+func TestWriteStringDoesNotAllocate(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithSyncPolicy(SyncOnRotate))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	line := strings.Repeat("x", 1024) + "\n"
+	if allocs := testing.AllocsPerRun(100, func() { r.WriteString(line) }); allocs != 0 {
+		t.Errorf("Wanted WriteString not to allocate, got %v allocations", allocs)
+	}
+}
+
+func TestWriteAppendsAlongsideExternalWriters(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	other, err := os.OpenFile(r.CurrentPath(), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer other.Close()
+
+	r.Write([]byte("one\n"))
+	other.Write([]byte("external\n"))
+	r.Write([]byte("two\n"))
+
+	got, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if want := "one\nexternal\ntwo\n"; string(got) != want {
+		t.Errorf("Wanted %q, got %q", want, got)
+	}
+}
+
+func TestWriteDoesNotAllocate(t *testing.T) {
+	cases := map[string][]Option{
+		"plain":  nil,
+		"prefix": {WithPrefix("app: ")},
+		"dedup":  {WithDedup(time.Minute)},
+	}
+
+	for name, opts := range cases {
+		dir, err := ioutil.TempDir(".", "tmp")
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+
+		r, err := New(dir, "test", append(opts, WithSyncPolicy(SyncOnRotate))...)
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+
+		line := []byte("something happened\n")
+		r.Write(line)
+		if allocs := testing.AllocsPerRun(100, func() { r.Write(line) }); allocs != 0 {
+			t.Errorf("Wanted %s writes not to allocate, got %v allocations", name, allocs)
+		}
+
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}
+}
+
+func benchmarkWrite(b *testing.B, opts ...Option) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		b.Fatalf("unexpected error: %q", err)
+	}
+	defer os.RemoveAll(dir)
+
+	r, err := New(dir, "bench", append(opts, WithSyncPolicy(SyncOnRotate))...)
+	if err != nil {
+		b.Fatalf("unexpected error: %q", err)
+	}
+	defer r.Close()
+
+	line := []byte("2020-01-02T03:04:05Z INFO request served path=/ status=200\n")
+	b.SetBytes(int64(len(line)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Write(line)
+	}
+}
+
+func BenchmarkWrite(b *testing.B)         { benchmarkWrite(b) }
+func BenchmarkWritePrefix(b *testing.B)   { benchmarkWrite(b, WithPrefix("app: ")) }
+func BenchmarkWriteBuffered(b *testing.B) { benchmarkWrite(b, WithBuffer(64*1024, 0)) }
+func BenchmarkWriteString(b *testing.B) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		b.Fatalf("unexpected error: %q", err)
+	}
+	defer os.RemoveAll(dir)
+
+	r, err := New(dir, "bench", WithSyncPolicy(SyncOnRotate))
+	if err != nil {
+		b.Fatalf("unexpected error: %q", err)
+	}
+	defer r.Close()
+
+	line := "2020-01-02T03:04:05Z INFO request served path=/ status=200\n"
+	b.SetBytes(int64(len(line)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.WriteString(line)
+	}
+}
+
+func TestWriteNeverSplitsAnEntryAcrossFiles(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithMaxSize(256), WithSyncPolicy(SyncOnRotate))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				id := fmt.Sprintf("%d.%d", w, i)
+				entry := id + " begin\n" + id + " " + strings.Repeat("x", 40*(i%8)) + "\n" + id + " end\n"
+				if _, err := r.Write([]byte(entry)); err != nil {
+					t.Errorf("unexpected error: %q", err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	archives, err := r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	paths := []string{r.CurrentPath()}
+	for _, a := range archives {
+		paths = append(paths, a.Path)
+	}
+
+	entries := 0
+	for _, path := range paths {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+
+		lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+		if len(lines)%3 != 0 {
+			t.Errorf("Wanted whole entries in %s, got %d lines", path, len(lines))
+			continue
+		}
+		for i := 0; i < len(lines); i += 3 {
+			id := strings.Fields(lines[i])[0]
+			if lines[i] != id+" begin" || !strings.HasPrefix(lines[i+1], id+" ") || lines[i+2] != id+" end" {
+				t.Errorf("Wanted entry %s to be contiguous in %s, got %q", id, path, lines[i:i+3])
+			}
+			entries++
+		}
+	}
+	if entries != 400 {
+		t.Errorf("Wanted 400 entries, got %d", entries)
+	}
+}
+
+func TestRunRetriesFailedRotationsAndGivesUp(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithInterval(200*time.Millisecond), WithRotateRetry(10*time.Millisecond, 3))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	// Remove the current file out from under the Rolog so renames fail.
+	os.Remove(filepath.Join(dir, "test.log"))
+
+	r.Run(context.Background())
+	for i := 0; i < 4; i++ {
+		select {
+		case err := <-r.Err():
+			if gaveUp := errors.Cause(err) == ErrTooManyFailures; gaveUp != (i == 3) {
+				t.Errorf("Wanted ErrTooManyFailures only after 3 failures, got %q at %d", err, i+1)
+			}
+		case <-time.After(2 * time.Second):
+			t.Errorf("Wanted error %d to be reported", i+1)
+			t.FailNow()
+		}
+	}
+	select {
+	case err := <-r.Err():
+		t.Errorf("Wanted the loop to stop, got %q", err)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+// flakyStrategy fails its first failures rotations before renaming.
+type flakyStrategy struct {
+	failures, calls int
+}
+
+func (s *flakyStrategy) Rotate(rot Rotation) (File, string, error) {
+	s.calls++
+	if s.calls <= s.failures {
+		return nil, "", errors.New("transient failure")
+	}
+	return RenameStrategy{}.Rotate(rot)
+}
+
+func TestRotateRetriesTransientFailures(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	s := &flakyStrategy{failures: 2}
+	r, err := New(dir, "test", WithRotationStrategy(s), WithRotateAttempts(3, time.Millisecond))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	if err := r.Rotate(); err != nil {
+		t.Errorf("Wanted the third attempt to succeed, got %q", err)
+	}
+	if s.calls != 3 {
+		t.Errorf("Wanted 3 attempts, got %d", s.calls)
+	}
+
+	s.calls, s.failures = 0, 3
+	if err := r.Rotate(); err == nil {
+		t.Errorf("Wanted the error once the attempts ran out")
+	}
+	if s.calls != 3 {
+		t.Errorf("Wanted 3 attempts, got %d", s.calls)
+	}
+}
@@ -0,0 +1,367 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: rolog.proto
+
+package rologgrpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Record is a single item on a stream.
+type Record struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*Record_Entry
+	//	*Record_Rotation
+	Kind          isRecord_Kind `protobuf_oneof:"kind"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Record) Reset() {
+	*x = Record{}
+	mi := &file_rolog_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Record) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Record) ProtoMessage() {}
+
+func (x *Record) ProtoReflect() protoreflect.Message {
+	mi := &file_rolog_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Record.ProtoReflect.Descriptor instead.
+func (*Record) Descriptor() ([]byte, []int) {
+	return file_rolog_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Record) GetKind() isRecord_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *Record) GetEntry() *Entry {
+	if x != nil {
+		if x, ok := x.Kind.(*Record_Entry); ok {
+			return x.Entry
+		}
+	}
+	return nil
+}
+
+func (x *Record) GetRotation() *Rotation {
+	if x != nil {
+		if x, ok := x.Kind.(*Record_Rotation); ok {
+			return x.Rotation
+		}
+	}
+	return nil
+}
+
+type isRecord_Kind interface {
+	isRecord_Kind()
+}
+
+type Record_Entry struct {
+	Entry *Entry `protobuf:"bytes,1,opt,name=entry,proto3,oneof"`
+}
+
+type Record_Rotation struct {
+	Rotation *Rotation `protobuf:"bytes,2,opt,name=rotation,proto3,oneof"`
+}
+
+func (*Record_Entry) isRecord_Kind() {}
+
+func (*Record_Rotation) isRecord_Kind() {}
+
+// Entry is a write to the Rolog, as it was written to the file.
+type Entry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Entry) Reset() {
+	*x = Entry{}
+	mi := &file_rolog_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Entry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Entry) ProtoMessage() {}
+
+func (x *Entry) ProtoReflect() protoreflect.Message {
+	mi := &file_rolog_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Entry.ProtoReflect.Descriptor instead.
+func (*Entry) Descriptor() ([]byte, []int) {
+	return file_rolog_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Entry) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Entry) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// Rotation is a completed rotation.
+type Rotation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	OldPath       string                 `protobuf:"bytes,3,opt,name=old_path,json=oldPath,proto3" json:"old_path,omitempty"`
+	NewPath       string                 `protobuf:"bytes,4,opt,name=new_path,json=newPath,proto3" json:"new_path,omitempty"`
+	Bytes         int64                  `protobuf:"varint,5,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Rotation) Reset() {
+	*x = Rotation{}
+	mi := &file_rolog_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Rotation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rotation) ProtoMessage() {}
+
+func (x *Rotation) ProtoReflect() protoreflect.Message {
+	mi := &file_rolog_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rotation.ProtoReflect.Descriptor instead.
+func (*Rotation) Descriptor() ([]byte, []int) {
+	return file_rolog_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Rotation) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Rotation) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Rotation) GetOldPath() string {
+	if x != nil {
+		return x.OldPath
+	}
+	return ""
+}
+
+func (x *Rotation) GetNewPath() string {
+	if x != nil {
+		return x.NewPath
+	}
+	return ""
+}
+
+func (x *Rotation) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+// StreamSummary acknowledges a stream once the client has closed it.
+type StreamSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Received      uint64                 `protobuf:"varint,1,opt,name=received,proto3" json:"received,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamSummary) Reset() {
+	*x = StreamSummary{}
+	mi := &file_rolog_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamSummary) ProtoMessage() {}
+
+func (x *StreamSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_rolog_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamSummary.ProtoReflect.Descriptor instead.
+func (*StreamSummary) Descriptor() ([]byte, []int) {
+	return file_rolog_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StreamSummary) GetReceived() uint64 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+var File_rolog_proto protoreflect.FileDescriptor
+
+const file_rolog_proto_rawDesc = "" +
+	"\n" +
+	"\vrolog.proto\x12\brolog.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"k\n" +
+	"\x06Record\x12'\n" +
+	"\x05entry\x18\x01 \x01(\v2\x0f.rolog.v1.EntryH\x00R\x05entry\x120\n" +
+	"\brotation\x18\x02 \x01(\v2\x12.rolog.v1.RotationH\x00R\brotationB\x06\n" +
+	"\x04kind\"K\n" +
+	"\x05Entry\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\"\x9e\x01\n" +
+	"\bRotation\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x19\n" +
+	"\bold_path\x18\x03 \x01(\tR\aoldPath\x12\x19\n" +
+	"\bnew_path\x18\x04 \x01(\tR\anewPath\x12\x14\n" +
+	"\x05bytes\x18\x05 \x01(\x03R\x05bytes\"+\n" +
+	"\rStreamSummary\x12\x1a\n" +
+	"\breceived\x18\x01 \x01(\x04R\breceived2C\n" +
+	"\n" +
+	"Aggregator\x125\n" +
+	"\x06Stream\x12\x10.rolog.v1.Record\x1a\x17.rolog.v1.StreamSummary(\x01B$Z\"github.com/haleyrc/rolog/rologgrpcb\x06proto3"
+
+var (
+	file_rolog_proto_rawDescOnce sync.Once
+	file_rolog_proto_rawDescData []byte
+)
+
+func file_rolog_proto_rawDescGZIP() []byte {
+	file_rolog_proto_rawDescOnce.Do(func() {
+		file_rolog_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rolog_proto_rawDesc), len(file_rolog_proto_rawDesc)))
+	})
+	return file_rolog_proto_rawDescData
+}
+
+var file_rolog_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_rolog_proto_goTypes = []any{
+	(*Record)(nil),                // 0: rolog.v1.Record
+	(*Entry)(nil),                 // 1: rolog.v1.Entry
+	(*Rotation)(nil),              // 2: rolog.v1.Rotation
+	(*StreamSummary)(nil),         // 3: rolog.v1.StreamSummary
+	(*timestamppb.Timestamp)(nil), // 4: google.protobuf.Timestamp
+}
+var file_rolog_proto_depIdxs = []int32{
+	1, // 0: rolog.v1.Record.entry:type_name -> rolog.v1.Entry
+	2, // 1: rolog.v1.Record.rotation:type_name -> rolog.v1.Rotation
+	4, // 2: rolog.v1.Entry.time:type_name -> google.protobuf.Timestamp
+	4, // 3: rolog.v1.Rotation.time:type_name -> google.protobuf.Timestamp
+	0, // 4: rolog.v1.Aggregator.Stream:input_type -> rolog.v1.Record
+	3, // 5: rolog.v1.Aggregator.Stream:output_type -> rolog.v1.StreamSummary
+	5, // [5:6] is the sub-list for method output_type
+	4, // [4:5] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_rolog_proto_init() }
+func file_rolog_proto_init() {
+	if File_rolog_proto != nil {
+		return
+	}
+	file_rolog_proto_msgTypes[0].OneofWrappers = []any{
+		(*Record_Entry)(nil),
+		(*Record_Rotation)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rolog_proto_rawDesc), len(file_rolog_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_rolog_proto_goTypes,
+		DependencyIndexes: file_rolog_proto_depIdxs,
+		MessageInfos:      file_rolog_proto_msgTypes,
+	}.Build()
+	File_rolog_proto = out.File
+	file_rolog_proto_goTypes = nil
+	file_rolog_proto_depIdxs = nil
+}
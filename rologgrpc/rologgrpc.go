@@ -0,0 +1,246 @@
+// Package rologgrpc streams the entries and rotation events of a Rolog to a
+// remote aggregator over gRPC, so that platforms can consume its output
+// without tailing files. The Aggregator service is defined in rolog.proto;
+// a Sink is its client, and aggregators implement AggregatorServer.
+package rologgrpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative rolog.proto
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/haleyrc/rolog"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SourceKey is the metadata key a Sink names its Rolog under.
+const SourceKey = "rolog-source"
+
+const (
+	// queueSize is how many records a Sink holds while the aggregator is
+	// slow or unreachable before it starts discarding them.
+	queueSize = 1024
+	// maxBackoff caps the wait between attempts to reopen the stream.
+	maxBackoff = 30 * time.Second
+)
+
+// Sink streams the writes and rotations of a Rolog to an aggregator. Records
+// are queued and sent from a goroutine of the Sink's own, so an unreachable
+// aggregator never blocks a write; records are discarded while the queue is
+// full. A broken stream is reported through the Rolog's Err channel and
+// reopened with backoff.
+type Sink struct {
+	r      *rolog.Rolog
+	client AggregatorClient
+	source string
+	stop   func()
+
+	mu     sync.Mutex
+	closed bool
+
+	queue chan *Record
+	quit  chan struct{}
+	done  chan struct{}
+}
+
+// New returns a Sink streaming the output of r, named source, to the
+// aggregator on conn, and attaches it to r. Use ClientCredentials when dialing
+// conn for mutual TLS.
+func New(r *rolog.Rolog, conn grpc.ClientConnInterface, source string) *Sink {
+	s := &Sink{
+		r:      r,
+		client: NewAggregatorClient(conn),
+		source: source,
+		queue:  make(chan *Record, queueSize),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	r.AddTee(s)
+	s.stop = r.Observe(rolog.ObserverFuncs{Rotate: s.rotated})
+	go s.run()
+	return s
+}
+
+// Write satisfies io.Writer, queueing p as an Entry. It is called by the Rolog
+// for every write.
+func (s *Sink) Write(p []byte) (int, error) {
+	s.enqueue(&Record{Kind: &Record_Entry{Entry: &Entry{
+		Time: timestamppb.Now(),
+		Data: append([]byte(nil), p...),
+	}}})
+	return len(p), nil
+}
+
+// rotated queues a Rotation.
+func (s *Sink) rotated(e rolog.RotationEvent) {
+	s.enqueue(&Record{Kind: &Record_Rotation{Rotation: &Rotation{
+		Time:    timestamppb.Now(),
+		Reason:  string(e.Reason),
+		OldPath: e.OldPath,
+		NewPath: e.NewPath,
+		Bytes:   e.Bytes,
+	}}})
+}
+
+// enqueue queues rec without blocking, discarding it if the queue is full or
+// the Sink is closed.
+func (s *Sink) enqueue(rec *Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	select {
+	case s.queue <- rec:
+	default:
+	}
+}
+
+// Close stops the Sink, sending whatever is still queued unless the stream is
+// broken. The Sink stays attached to the Rolog's writes, so it should be closed
+// after the Rolog.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return rolog.ErrClosed
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	s.stop()
+	close(s.quit)
+	<-s.done
+	return nil
+}
+
+// run sends queued records, reopening the stream after failures, until the
+// Sink is closed.
+func (s *Sink) run() {
+	defer close(s.done)
+
+	var (
+		stream  grpc.ClientStreamingClient[Record, StreamSummary]
+		backoff time.Duration
+		err     error
+	)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), SourceKey, s.source)
+	defer func() {
+		if stream != nil {
+			stream.CloseAndRecv()
+		}
+	}()
+
+	for {
+		var rec *Record
+		select {
+		case rec = <-s.queue:
+		case <-s.quit:
+			for stream != nil {
+				select {
+				case rec := <-s.queue:
+					if stream.Send(rec) != nil {
+						return
+					}
+				default:
+					return
+				}
+			}
+			return
+		}
+
+		if stream == nil {
+			stream, err = s.client.Stream(ctx)
+		}
+		if err == nil {
+			err = stream.Send(rec)
+			if err == io.EOF {
+				// the stream was ended by the server, and the reason it
+				// was is only available from the status
+				_, err = stream.CloseAndRecv()
+			}
+		}
+		if err == nil {
+			backoff = 0
+			continue
+		}
+
+		// a stream that has returned an error has already released its
+		// resources
+		s.r.Report(errors.Wrap(err, "could not stream to aggregator"))
+		stream = nil
+		switch {
+		case backoff == 0:
+			backoff = 100 * time.Millisecond
+		case backoff*2 > maxBackoff:
+			backoff = maxBackoff
+		default:
+			backoff *= 2
+		}
+		select {
+		case <-time.After(backoff):
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// ClientCredentials returns credentials for dialing an aggregator with mutual
+// TLS, presenting the certificate in certFile and keyFile and trusting the
+// certificate authorities in caFile.
+func ClientCredentials(certFile, keyFile, caFile string) (credentials.TransportCredentials, error) {
+	cfg, err := mutualTLS(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg.RootCAs = cfg.ClientCAs
+	cfg.ClientCAs = nil
+	return credentials.NewTLS(cfg), nil
+}
+
+// ServerCredentials returns credentials for an aggregator that requires
+// clients to present a certificate signed by one of the authorities in
+// caFile, presenting its own certificate in certFile and keyFile.
+func ServerCredentials(certFile, keyFile, caFile string) (credentials.TransportCredentials, error) {
+	cfg, err := mutualTLS(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	return credentials.NewTLS(cfg), nil
+}
+
+// mutualTLS loads a certificate and a pool of authorities, returning them as
+// a config with the pool as ClientCAs.
+func mutualTLS(certFile, keyFile, caFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load certificate")
+	}
+
+	pem, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read certificate authorities")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("no certificates found in %s", caFile)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
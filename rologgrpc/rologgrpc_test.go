@@ -0,0 +1,116 @@
+package rologgrpc
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/haleyrc/rolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// aggregator collects the records streamed to it.
+type aggregator struct {
+	UnimplementedAggregatorServer
+
+	mu      sync.Mutex
+	source  string
+	records []*Record
+}
+
+func (a *aggregator) Stream(stream grpc.ClientStreamingServer[Record, StreamSummary]) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	if vs := md.Get(SourceKey); len(vs) > 0 {
+		a.mu.Lock()
+		a.source = vs[0]
+		a.mu.Unlock()
+	}
+
+	var n uint64
+	for {
+		rec, err := stream.Recv()
+		if err != nil {
+			return stream.SendAndClose(&StreamSummary{Received: n})
+		}
+		n++
+		a.mu.Lock()
+		a.records = append(a.records, rec)
+		a.mu.Unlock()
+	}
+}
+
+func (a *aggregator) received() (string, []*Record) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.source, append([]*Record(nil), a.records...)
+}
+
+func TestSinkStreams(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	agg := &aggregator{}
+	srv := grpc.NewServer()
+	RegisterAggregatorServer(srv, agg)
+	go srv.Serve(l)
+	defer srv.Stop()
+
+	cc, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer cc.Close()
+
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	r, err := rolog.New(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	s := New(r, cc, "web")
+
+	r.Write([]byte("hello\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, recs := agg.received(); len(recs) >= 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	r.Close()
+	s.Close()
+
+	source, recs := agg.received()
+	if source != "web" {
+		t.Errorf("Wanted source %q, got %q", "web", source)
+	}
+	if len(recs) != 2 {
+		t.Errorf("Wanted 2 records, got %d", len(recs))
+		t.FailNow()
+	}
+	if got := string(recs[0].GetEntry().GetData()); got != "hello\n" {
+		t.Errorf("Wanted entry %q, got %q", "hello\n", got)
+	}
+	rot := recs[1].GetRotation()
+	if rot == nil || rot.GetReason() != string(rolog.ReasonManual) || rot.GetBytes() != 6 {
+		t.Errorf("Wanted a manual rotation of 6 bytes, got %v", recs[1])
+	}
+}
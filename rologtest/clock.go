@@ -0,0 +1,153 @@
+package rologtest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/haleyrc/rolog"
+)
+
+// Epoch is the time a Clock from NewClock starts at.
+var Epoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local)
+
+// Clock is a rolog.Clock whose time only moves when told to. Advancing it
+// fires the tickers that come due in order and waits for the run loops they
+// belong to to finish with each tick, so that once Advance returns any
+// scheduled rotation it called for is done, archive processing and all.
+type Clock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	tickers []*ticker
+}
+
+// ticker is a rolog.Ticker driven by a Clock.
+type ticker struct {
+	clock *Clock
+	c     chan time.Time
+	d     time.Duration
+	next  time.Time
+	// loop is set once a run loop has claimed the ticker, and pending while
+	// it has a tick the loop hasn't finished with
+	loop, pending, stopped bool
+}
+
+// NewClock returns a Clock set to Epoch.
+func NewClock() *Clock {
+	c := &Clock{now: Epoch}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now satisfies rolog.Clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// NewTicker satisfies rolog.Clock.
+func (c *Clock) NewTicker(d time.Duration) rolog.Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &ticker{clock: c, c: make(chan time.Time, 1), d: d, next: c.now.Add(d)}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// Advance moves the clock forward by d. The tickers due along the way fire
+// one instant at a time, with the clock set to when they were due, and each
+// waits for the run loop to act on it, so rotating every hour and advancing by
+// three hours gives three rotations, an hour apart.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	end := c.now.Add(d)
+	for {
+		next, due := end, false
+		for _, t := range c.tickers {
+			if !t.stopped && !t.next.After(next) {
+				next, due = t.next, true
+			}
+		}
+		if !due {
+			break
+		}
+
+		c.now = next
+		for _, t := range c.tickers {
+			if !t.stopped && !t.next.After(c.now) {
+				t.fire()
+			}
+		}
+		for c.busy() {
+			c.cond.Wait()
+		}
+	}
+	c.now = end
+}
+
+// busy reports whether a run loop has yet to finish with a tick.
+func (c *Clock) busy() bool {
+	for _, t := range c.tickers {
+		if t.loop && t.pending && !t.stopped {
+			return true
+		}
+	}
+	return false
+}
+
+// fire delivers a tick, dropping it if the last one is still unread as
+// time.Ticker does.
+func (t *ticker) fire() {
+	select {
+	case t.c <- t.next:
+		t.pending = true
+	default:
+	}
+	t.next = t.next.Add(t.d)
+}
+
+// C satisfies rolog.Ticker.
+func (t *ticker) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop satisfies rolog.Ticker.
+func (t *ticker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.stopped = true
+	t.clock.cond.Broadcast()
+}
+
+// Idle satisfies rolog.IdleTicker.
+func (t *ticker) Idle() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.loop = true
+	if len(t.c) == 0 {
+		t.pending = false
+	}
+	t.clock.cond.Broadcast()
+}
+
+// waitForLoop waits for a run loop to claim one of the tickers.
+func (c *Clock) waitForLoop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for {
+		for _, t := range c.tickers {
+			if t.loop {
+				return
+			}
+		}
+		c.cond.Wait()
+	}
+}
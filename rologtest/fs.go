@@ -0,0 +1,335 @@
+package rologtest
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/haleyrc/rolog"
+)
+
+// FS is an in-memory rolog.FS, so tests need no temporary directories. It
+// also implements rolog.MkdirFS and rolog.ChmodFS. As on Unix, a file that is
+// renamed or removed while open stays usable through the open handle.
+type FS struct {
+	mu    sync.Mutex
+	files map[string]*node
+	dirs  map[string]os.FileMode
+	// now stamps modification times, and defaults to time.Now
+	now func() time.Time
+}
+
+// node is the contents of a file, shared by its open handles.
+type node struct {
+	data    []byte
+	mode    os.FileMode
+	modTime time.Time
+}
+
+// NewFS returns an empty FS holding only the root and working directories.
+func NewFS() *FS {
+	return &FS{
+		files: make(map[string]*node),
+		dirs:  map[string]os.FileMode{".": os.ModeDir | 0755, "/": os.ModeDir | 0755},
+		now:   time.Now,
+	}
+}
+
+// OpenFile satisfies rolog.FS.
+func (fs *FS) OpenFile(name string, flag int, perm os.FileMode) (rolog.File, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	name = filepath.Clean(name)
+	n, ok := fs.files[name]
+	switch {
+	case ok && flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0:
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrExist}
+	case !ok && flag&os.O_CREATE == 0:
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	case !ok:
+		if _, ok := fs.dirs[name]; ok {
+			return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrExist}
+		}
+		if _, ok := fs.dirs[filepath.Dir(name)]; !ok {
+			return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+		}
+		n = &node{mode: perm.Perm(), modTime: fs.now()}
+		fs.files[name] = n
+	}
+	if flag&os.O_TRUNC != 0 {
+		n.data, n.modTime = nil, fs.now()
+	}
+
+	return &file{
+		fs:     fs,
+		n:      n,
+		name:   filepath.Base(name),
+		read:   flag&(os.O_WRONLY|os.O_RDWR) != os.O_WRONLY,
+		write:  flag&(os.O_WRONLY|os.O_RDWR) != 0,
+		append: flag&os.O_APPEND != 0,
+	}, nil
+}
+
+// Rename satisfies rolog.FS. Renaming directories isn't supported.
+func (fs *FS) Rename(oldpath, newpath string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	n, ok := fs.files[oldpath]
+	if !ok {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: os.ErrNotExist}
+	}
+	if _, ok := fs.dirs[filepath.Dir(newpath)]; !ok {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: os.ErrNotExist}
+	}
+	delete(fs.files, oldpath)
+	fs.files[newpath] = n
+	return nil
+}
+
+// Stat satisfies rolog.FS.
+func (fs *FS) Stat(name string) (os.FileInfo, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if n, ok := fs.files[name]; ok {
+		return n.info(filepath.Base(name)), nil
+	}
+	if mode, ok := fs.dirs[name]; ok {
+		return fileInfo{name: filepath.Base(name), mode: mode}, nil
+	}
+	return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+}
+
+// Remove satisfies rolog.FS. Directories must be empty to be removed.
+func (fs *FS) Remove(name string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if _, ok := fs.files[name]; ok {
+		delete(fs.files, name)
+		return nil
+	}
+	if _, ok := fs.dirs[name]; !ok {
+		return &os.PathError{Op: "remove", Path: name, Err: os.ErrNotExist}
+	}
+	if len(fs.list(name)) > 0 {
+		return &os.PathError{Op: "remove", Path: name, Err: os.ErrExist}
+	}
+	delete(fs.dirs, name)
+	return nil
+}
+
+// ReadDir satisfies rolog.FS.
+func (fs *FS) ReadDir(dirname string) ([]os.FileInfo, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	dirname = filepath.Clean(dirname)
+	if _, ok := fs.dirs[dirname]; !ok {
+		return nil, &os.PathError{Op: "readdir", Path: dirname, Err: os.ErrNotExist}
+	}
+	return fs.list(dirname), nil
+}
+
+// list returns the entries of dir sorted by name. It must be called with mu
+// held.
+func (fs *FS) list(dir string) []os.FileInfo {
+	var fis []os.FileInfo
+	for name, n := range fs.files {
+		if filepath.Dir(name) == dir {
+			fis = append(fis, n.info(filepath.Base(name)))
+		}
+	}
+	for name, mode := range fs.dirs {
+		if name != dir && filepath.Dir(name) == dir {
+			fis = append(fis, fileInfo{name: filepath.Base(name), mode: mode})
+		}
+	}
+	sort.Slice(fis, func(i, j int) bool {
+		return fis[i].Name() < fis[j].Name()
+	})
+	return fis
+}
+
+// MkdirAll satisfies rolog.MkdirFS.
+func (fs *FS) MkdirAll(path string, perm os.FileMode) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	var missing []string
+	for dir := filepath.Clean(path); ; dir = filepath.Dir(dir) {
+		if _, ok := fs.files[dir]; ok {
+			return &os.PathError{Op: "mkdir", Path: dir, Err: os.ErrExist}
+		}
+		if _, ok := fs.dirs[dir]; ok {
+			break
+		}
+		missing = append(missing, dir)
+	}
+	for _, dir := range missing {
+		fs.dirs[dir] = os.ModeDir | perm.Perm()
+	}
+	return nil
+}
+
+// Chmod satisfies rolog.ChmodFS.
+func (fs *FS) Chmod(name string, mode os.FileMode) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if n, ok := fs.files[name]; ok {
+		n.mode = mode.Perm()
+		return nil
+	}
+	if _, ok := fs.dirs[name]; ok {
+		fs.dirs[name] = os.ModeDir | mode.Perm()
+		return nil
+	}
+	return &os.PathError{Op: "chmod", Path: name, Err: os.ErrNotExist}
+}
+
+// ReadFile returns the contents of the file called name.
+func (fs *FS) ReadFile(name string) ([]byte, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	n, ok := fs.files[filepath.Clean(name)]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	return append([]byte(nil), n.data...), nil
+}
+
+// WriteFile creates the file called name holding data, replacing any file
+// already there, such as to set up what an earlier run left behind. The
+// directory is created if need be.
+func (fs *FS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	if err := fs.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.files[filepath.Clean(name)] = &node{
+		data:    append([]byte(nil), data...),
+		mode:    perm.Perm(),
+		modTime: fs.now(),
+	}
+	return nil
+}
+
+// Files returns the paths of every file, sorted.
+func (fs *FS) Files() []string {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	paths := make([]string, 0, len(fs.files))
+	for path := range fs.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// info describes n as the file called name. It must be called with the FS's
+// mu held.
+func (n *node) info(name string) os.FileInfo {
+	return fileInfo{name: name, size: int64(len(n.data)), mode: n.mode, modTime: n.modTime}
+}
+
+// file is an open handle on an FS.
+type file struct {
+	fs   *FS
+	n    *node
+	name string
+	// off is where the next read or write happens
+	off                 int64
+	read, write, append bool
+	closed              bool
+}
+
+// Read satisfies rolog.File.
+func (f *file) Read(p []byte) (int, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if f.closed || !f.read {
+		return 0, &os.PathError{Op: "read", Path: f.name, Err: os.ErrInvalid}
+	}
+	if f.off >= int64(len(f.n.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.n.data[f.off:])
+	f.off += int64(n)
+	return n, nil
+}
+
+// Write satisfies rolog.File.
+func (f *file) Write(p []byte) (int, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if f.closed || !f.write {
+		return 0, &os.PathError{Op: "write", Path: f.name, Err: os.ErrInvalid}
+	}
+	if f.append {
+		f.off = int64(len(f.n.data))
+	}
+	if end := f.off + int64(len(p)); end > int64(len(f.n.data)) {
+		f.n.data = append(f.n.data, make([]byte, end-int64(len(f.n.data)))...)
+	}
+	copy(f.n.data[f.off:], p)
+	f.off += int64(len(p))
+	f.n.modTime = f.fs.now()
+	return len(p), nil
+}
+
+// Close satisfies rolog.File.
+func (f *file) Close() error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if f.closed {
+		return &os.PathError{Op: "close", Path: f.name, Err: os.ErrClosed}
+	}
+	f.closed = true
+	return nil
+}
+
+// Stat satisfies rolog.File.
+func (f *file) Stat() (os.FileInfo, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	return f.n.info(f.name), nil
+}
+
+// Sync satisfies rolog.File. The contents are always up to date.
+func (f *file) Sync() error {
+	return nil
+}
+
+// fileInfo describes a file or directory on an FS.
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) Size() int64        { return fi.size }
+func (fi fileInfo) Mode() os.FileMode  { return fi.mode }
+func (fi fileInfo) ModTime() time.Time { return fi.modTime }
+func (fi fileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi fileInfo) Sys() interface{}   { return nil }
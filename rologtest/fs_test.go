@@ -0,0 +1,62 @@
+package rologtest
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestFSKeepsRenamedFilesOpen(t *testing.T) {
+	fs := NewFS()
+	if err := fs.MkdirAll("a/b", 0755); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	f, err := fs.OpenFile("a/b/c.log", os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	f.Write([]byte("one\n"))
+	if err := fs.Rename("a/b/c.log", "a/d.log"); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	f.Write([]byte("two\n"))
+	f.Close()
+
+	r, err := fs.OpenFile("a/d.log", os.O_RDONLY, 0)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if string(got) != "one\ntwo\n" {
+		t.Errorf("Wanted both writes in the renamed file, got %q", got)
+	}
+
+	if _, err := fs.Stat("a/b/c.log"); !os.IsNotExist(err) {
+		t.Errorf("Wanted the old name gone, got %v", err)
+	}
+	if _, err := fs.OpenFile("a/d.log", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644); !os.IsExist(err) {
+		t.Errorf("Wanted O_EXCL to refuse an existing file, got %v", err)
+	}
+	if err := fs.Remove("a"); err == nil {
+		t.Errorf("Wanted removing a non-empty directory to fail")
+	}
+
+	fis, err := fs.ReadDir("a")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(fis) != 2 || fis[0].Name() != "b" || !fis[0].IsDir() || fis[1].Name() != "d.log" || fis[1].Size() != 8 {
+		t.Errorf("Wanted b/ and d.log in a, got %v", fis)
+	}
+}
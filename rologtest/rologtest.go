@@ -0,0 +1,67 @@
+// Package rologtest helps applications test how they use rolog without
+// sleeping or using temporary directories. A Log runs a Rolog against an
+// in-memory FS and a Clock that only moves when told to, and advancing the
+// Clock carries out whatever scheduled rotations come due before it returns:
+//
+//	l, err := rologtest.New("logs", "app", rolog.WithInterval(time.Hour))
+//	...
+//	l.Write([]byte("hello\n"))
+//	l.Advance(time.Hour)
+//	archives, err := l.Archives() // the rotated file is already there
+package rologtest
+
+import (
+	"context"
+	"time"
+
+	"github.com/haleyrc/rolog"
+)
+
+// Log is a running Rolog on a Clock and FS of its own.
+type Log struct {
+	*rolog.Rolog
+	Clock *Clock
+	FS    *FS
+
+	cancel context.CancelFunc
+}
+
+// New creates and runs a Log named name in dir on a new FS, with its Clock
+// at Epoch. The directory is created. opts are applied after the Clock and
+// FS, so they shouldn't include WithClock or WithFS.
+//
+// Writes and rotations are synchronous, as is the run loop through Advance,
+// unless opts ask for background work of their own, such as WithAsync or
+// WithCompressPool.
+func New(dir, name string, opts ...rolog.Option) (*Log, error) {
+	var (
+		clock = NewClock()
+		fs    = NewFS()
+	)
+	fs.now = clock.Now
+
+	r, err := rolog.New(dir, name, append([]rolog.Option{rolog.WithClock(clock), rolog.WithFS(fs)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.Run(ctx)
+	if r.Settings().Interval > 0 {
+		clock.waitForLoop()
+	}
+	return &Log{Rolog: r, Clock: clock, FS: fs, cancel: cancel}, nil
+}
+
+// Advance moves the Log's clock forward by d, returning once the rotations
+// that came due have been done.
+func (l *Log) Advance(d time.Duration) {
+	l.Clock.Advance(d)
+}
+
+// Close closes the Rolog and stops its run loop.
+func (l *Log) Close() error {
+	defer l.cancel()
+
+	return l.Rolog.Close()
+}
@@ -0,0 +1,74 @@
+package rologtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/haleyrc/rolog"
+)
+
+func TestAdvanceRotatesSynchronously(t *testing.T) {
+	l, err := New("logs", "test", rolog.WithInterval(time.Hour))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer l.Close()
+
+	l.Write([]byte("first\n"))
+	l.Advance(90 * time.Minute)
+	l.Write([]byte("second\n"))
+	l.Advance(90 * time.Minute)
+
+	archives, err := l.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(archives) != 3 {
+		t.Errorf("Wanted 3 archives, got %d", len(archives))
+		t.FailNow()
+	}
+	for i, a := range archives {
+		if want := Epoch.Add(time.Duration(i+1) * time.Hour); !a.End.Equal(want) {
+			t.Errorf("Wanted archive %d rotated at %s, got %s", i, want, a.End)
+		}
+	}
+
+	for path, want := range map[string]string{archives[0].Path: "first\n", archives[1].Path: "second\n", archives[2].Path: ""} {
+		got, err := l.FS.ReadFile(path)
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		if string(got) != want {
+			t.Errorf("Wanted %s to contain %q, got %q", path, want, got)
+		}
+	}
+	if got := l.Clock.Now(); !got.Equal(Epoch.Add(3 * time.Hour)) {
+		t.Errorf("Wanted the clock 3h past the epoch, got %s", got)
+	}
+}
+
+func TestAdvanceWaitsForArchiveProcessing(t *testing.T) {
+	l, err := New("logs", "test", rolog.WithInterval(time.Hour), rolog.WithCompression(rolog.Gzip), rolog.WithMaxBackups(1))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer l.Close()
+
+	for i := 0; i < 3; i++ {
+		l.Write([]byte("hello\n"))
+		l.Advance(time.Hour)
+	}
+
+	archives, err := l.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(archives) != 1 || !archives[0].Compressed {
+		t.Errorf("Wanted a single compressed archive, got %+v", archives)
+	}
+}
@@ -0,0 +1,251 @@
+package rolog
+
+import (
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// scheduler is the state of a Rolog's run loop. Each kind of background work
+// has its own Ticker, which is nil while that work is disabled. It is only
+// touched by the loop's goroutine.
+type scheduler struct {
+	r *Rolog
+
+	// interval is the rotation interval the rotation ticker was started
+	// with, and resumed is set while its first tick is instead the one the
+	// process that handed off the log had scheduled
+	interval time.Duration
+	resumed  bool
+	// rotation fires scheduled rotations, and retry fires the next attempt
+	// after one failed
+	rotation, retry Ticker
+	// flush drains the write buffer, liveFlush writes a flush point into a
+	// compressed current file, sync syncs in the background, and check looks
+	// for a deleted or moved current file
+	flush, liveFlush, sync, check Ticker
+	// summary writes a summary of drops into the log, and summarized is the
+	// counts it last covered
+	summary    Ticker
+	summarized Drops
+	// watchdog pings systemd's watchdog
+	watchdog Ticker
+	// trigger looks for the trigger file, and signals receives the rotate
+	// signals
+	trigger Ticker
+	signals chan os.Signal
+	// topics closes idle topic logs, and failback looks for the primary
+	// directory coming back after a failover
+	topics, failback Ticker
+	// handoff looks for another process asking for the log
+	handoff Ticker
+	// reload looks for changes to the config file, config is the version
+	// last seen, and reloads receives the reload signals
+	reload  Ticker
+	config  configStamp
+	reloads chan os.Signal
+
+	// failures counts consecutive failed rotations, and backoff is the delay
+	// before the next retry
+	failures int
+	backoff  time.Duration
+}
+
+// newScheduler starts the tickers for everything r has enabled.
+func newScheduler(r *Rolog) *scheduler {
+	s := &scheduler{r: r}
+	if len(r.rotateSignals) > 0 {
+		s.signals = make(chan os.Signal, 1)
+		signal.Notify(s.signals, r.rotateSignals...)
+	}
+	if len(r.reloadSignals) > 0 {
+		s.reloads = make(chan os.Signal, 1)
+		signal.Notify(s.reloads, r.reloadSignals...)
+	}
+	s.reschedule()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.buf != nil && r.flushEvery > 0 {
+		s.flush = r.clock.NewTicker(r.flushEvery)
+	}
+	if r.liveCompression != NoCompression && r.liveFlush > 0 {
+		s.liveFlush = r.clock.NewTicker(r.liveFlush)
+	}
+	if d := r.syncPolicy.background; d > 0 {
+		s.sync = r.clock.NewTicker(d)
+	}
+	if r.checkEvery > 0 {
+		s.check = r.clock.NewTicker(r.checkEvery)
+	}
+	if r.dropSummary > 0 {
+		s.summary = r.clock.NewTicker(r.dropSummary)
+		s.summarized = r.drops.load()
+	}
+	if r.systemd != nil && r.systemd.watchdog > 0 {
+		s.watchdog = r.clock.NewTicker(r.systemd.watchdog)
+	}
+	if r.triggerFile != "" {
+		s.trigger = r.clock.NewTicker(triggerPoll)
+	}
+	if r.topicIdle > 0 {
+		s.topics = r.clock.NewTicker(r.topicIdle)
+	}
+	if r.failover != nil {
+		s.failback = r.clock.NewTicker(failoverProbe)
+	}
+	if r.handoffPath != "" {
+		s.handoff = r.clock.NewTicker(handoffPoll)
+	}
+	if r.reloadPath != "" {
+		s.reload = r.clock.NewTicker(reloadPoll)
+		s.config = stampConfig(r.reloadPath)
+	}
+	return s
+}
+
+// tick returns the channel of t, or nil if t is disabled so that receiving
+// from it blocks forever.
+func (s *scheduler) tick(t Ticker) <-chan time.Time {
+	if t == nil {
+		return nil
+	}
+	return t.C()
+}
+
+// stopTicker stops *t, if it is running, and disables it.
+func stopTicker(t *Ticker) {
+	if *t != nil {
+		(*t).Stop()
+		*t = nil
+	}
+}
+
+// reschedule restarts the rotation ticker from now with the current interval.
+// A non-positive interval disables scheduled rotation.
+func (s *scheduler) reschedule() {
+	stopTicker(&s.rotation)
+
+	s.interval = s.r.Settings().Interval
+
+	s.r.setNext(time.Time{})
+	s.resumed = false
+	if s.interval <= 0 {
+		s.r.debugf("scheduled rotation disabled")
+		return
+	}
+	var (
+		now   = s.r.now()
+		first = s.interval
+	)
+	if at := s.r.resume(); at.After(now) && at.Sub(now) < first {
+		// pick up the schedule of the process that handed off the log
+		first, s.resumed = at.Sub(now), true
+	}
+	next := now.Add(first)
+	s.r.debugf("rotating every %s, next at %s", s.interval, next.Format(time.RFC3339))
+	s.r.setNext(next)
+	s.rotation = s.r.clock.NewTicker(first)
+}
+
+// rotate performs a scheduled rotation, arranging a retry with backoff if it
+// fails, and reports whether the loop should give up.
+func (s *scheduler) rotate() bool {
+	stopTicker(&s.retry)
+
+	err := s.r.scheduledRotate()
+	switch err {
+	case nil:
+		s.failures = 0
+		return false
+	case ErrClosed:
+		return true
+	}
+	s.r.report(err)
+
+	s.r.mu.Lock()
+	policy := s.r.retry
+	s.r.mu.Unlock()
+
+	s.failures++
+	if policy.maxFailures > 0 && s.failures >= policy.maxFailures {
+		s.r.report(errors.Wrapf(ErrTooManyFailures, "gave up after %d", s.failures))
+		return true
+	}
+
+	if s.failures == 1 {
+		s.backoff = policy.backoff
+	} else {
+		s.backoff *= 2
+	}
+	if s.interval > 0 && s.backoff > s.interval {
+		s.backoff = s.interval
+	}
+	if s.backoff > 0 {
+		s.r.debugf("rotation failed %d times in a row, retrying in %s", s.failures, s.backoff)
+		s.retry = s.r.clock.NewTicker(s.backoff)
+	}
+	return false
+}
+
+// flushBuffer drains the write buffer.
+func (s *scheduler) flushBuffer() {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	if s.r.closed() {
+		return
+	}
+	if err := s.r.drain(); err != nil {
+		s.r.report(err)
+	}
+}
+
+// syncBackground syncs the current file if anything has been written since
+// the last sync.
+func (s *scheduler) syncBackground() {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	if s.r.unsynced > 0 && !s.r.closed() {
+		if err := s.r.sync(); err != nil {
+			s.r.report(errors.Wrap(err, "could not sync log"))
+		}
+	}
+}
+
+// summarizeDrops writes a summary of the drops since the last one into the log.
+func (s *scheduler) summarizeDrops() {
+	s.summarized = s.r.summarizeDrops(s.summarized, s.r.dropSummary)
+}
+
+// tickers returns every ticker of the loop.
+func (s *scheduler) tickers() []*Ticker {
+	return []*Ticker{&s.rotation, &s.retry, &s.flush, &s.liveFlush, &s.sync, &s.check, &s.summary, &s.watchdog, &s.trigger, &s.topics, &s.failback, &s.handoff, &s.reload}
+}
+
+// idle tells the tickers that implement IdleTicker that the loop is waiting.
+func (s *scheduler) idle() {
+	for _, t := range s.tickers() {
+		if i, ok := (*t).(IdleTicker); ok {
+			i.Idle()
+		}
+	}
+}
+
+// stop stops every ticker and clears the next rotation time.
+func (s *scheduler) stop() {
+	for _, t := range s.tickers() {
+		stopTicker(t)
+	}
+	if s.signals != nil {
+		signal.Stop(s.signals)
+	}
+	if s.reloads != nil {
+		signal.Stop(s.reloads)
+	}
+	s.r.setNext(time.Time{})
+}
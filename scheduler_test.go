@@ -0,0 +1,157 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSchedulerRunsEachTickerOnItsOwnInterval(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithInterval(30*time.Minute), WithBuffer(4096, 10*time.Minute), WithSyncPolicy(SyncOnRotate))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Run(context.Background())
+	for clock.numTickers() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	if got := clock.numTickers(); got != 2 {
+		t.Errorf("Wanted tickers only for rotation and flushing, got %d", got)
+	}
+
+	r.Write([]byte("hello\n"))
+	clock.Advance(10 * time.Minute)
+
+	current := filepath.Join(dir, "test.log")
+	deadline := time.Now().Add(time.Second)
+	for {
+		got, _ := ioutil.ReadFile(current)
+		if string(got) == "hello\n" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Errorf("Wanted the buffer flushed after ten minutes, got %q", got)
+			t.FailNow()
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case e := <-r.Notify():
+		t.Errorf("Wanted no rotation before the interval, got %+v", e)
+	default:
+	}
+
+	clock.Advance(20 * time.Minute)
+	waitForRotation(t, r)
+	if want := clock.Now().Add(30 * time.Minute); !r.NextRotation().Equal(want) {
+		t.Errorf("Wanted next rotation at %s, got %s", want, r.NextRotation())
+	}
+}
+
+func TestSchedulerSetIntervalReplacesRotationTicker(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithInterval(time.Hour), WithMaxSize(1<<20))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Run(context.Background())
+	for clock.numTickers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	r.SetInterval(time.Minute)
+	for clock.numTickers() == 1 {
+		time.Sleep(time.Millisecond)
+	}
+	if got := clock.numRunning(); got != 1 {
+		t.Errorf("Wanted the old rotation ticker stopped, got %d running", got)
+	}
+
+	r.SetInterval(0)
+	waitForTickersStopped(t, r, clock)
+
+	r.Write([]byte("hello\n"))
+	clock.Advance(time.Hour)
+	select {
+	case e := <-r.Notify():
+		t.Errorf("Wanted no scheduled rotation, got %+v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSchedulerStopsTickersOnClose(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithInterval(time.Hour), WithBuffer(4096, time.Second), WithFileCheck(time.Minute))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r.Run(context.Background())
+	for clock.numTickers() < 3 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+	}
+
+	waitForTickersStopped(t, r, clock)
+}
+
+func waitForTickersStopped(t *testing.T, r *Rolog, clock *fakeClock) {
+	deadline := time.Now().Add(time.Second)
+	for clock.numRunning() > 0 || !r.NextRotation().IsZero() {
+		if time.Now().After(deadline) {
+			t.Errorf("Wanted every ticker stopped, got %d running and next rotation at %s", clock.numRunning(), r.NextRotation())
+			t.FailNow()
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
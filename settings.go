@@ -0,0 +1,134 @@
+package rolog
+
+import (
+	"os"
+	"time"
+)
+
+// Settings are the parts of a Rolog's configuration that can be changed while
+// it is running. Every change publishes a fresh snapshot atomically, which the
+// write path and rotations read as well as Settings, so reading them never
+// takes a lock of its own and never observes a mix of old and new values.
+type Settings struct {
+	// Interval is how often the logs are rotated. A non-positive interval
+	// disables scheduled rotation and requires a MaxSize.
+	Interval time.Duration
+	// MaxSize is the size in bytes at which the current file is rotated
+	// early. Zero removes the limit.
+	MaxSize int64
+	// MaxBackups is the number of archives to keep. Zero removes the limit.
+	MaxBackups int
+	// MaxTotalSize is the byte budget for the current file and its archives.
+	// Zero removes the limit.
+	MaxTotalSize int64
+	// MaxAge is how long archives are kept. Zero keeps them forever.
+	MaxAge time.Duration
+	// BundleAge is how old an archive must be before it is bundled. Zero
+	// disables bundling.
+	BundleAge time.Duration
+	// Compression is the codec archives are compressed with.
+	Compression Compression
+	// CompressWorkers is how many goroutines compress each archive.
+	CompressWorkers int
+	// CompressionLevel is the level archives are compressed at. Zero means
+	// the codec's default.
+	CompressionLevel int
+	// LatestLink enables the symlink to the newest archive.
+	LatestLink bool
+	// ArchivePerm is the mode applied to archives. Zero leaves the mode they
+	// were created with.
+	ArchivePerm os.FileMode
+}
+
+// validate rejects settings that New would also refuse.
+func (s Settings) validate() error {
+	switch {
+	case s.Interval <= 0 && s.MaxSize <= 0:
+		return &ConfigError{Field: "Interval", Err: ErrInvalidInterval}
+	case s.MaxSize < 0:
+		return &ConfigError{Field: "MaxSize", Err: ErrInvalidSize}
+	case s.MaxTotalSize < 0:
+		return &ConfigError{Field: "MaxTotalSize", Err: ErrInvalidSize}
+	case s.MaxBackups < 0:
+		return &ConfigError{Field: "MaxBackups", Err: ErrInvalidRetention}
+	case s.MaxAge < 0:
+		return &ConfigError{Field: "MaxAge", Err: ErrInvalidAge}
+	case s.BundleAge < 0:
+		return &ConfigError{Field: "BundleAge", Err: ErrInvalidAge}
+	case !s.Compression.valid():
+		return &ConfigError{Field: "Compression", Err: ErrInvalidCompression}
+	}
+	return nil
+}
+
+// Settings returns the runtime settings in effect, without taking any lock.
+func (r *Rolog) Settings() Settings {
+	return *r.settings()
+}
+
+// settings returns the published snapshot of the runtime settings, which the
+// write path and rotations read in place of the fields it is made from.
+func (r *Rolog) settings() *Settings {
+	return r.live.Load().(*Settings)
+}
+
+// Reconfigure replaces every runtime setting in one step. s is validated as a
+// whole first, and a *ConfigError leaves the Rolog untouched. A rotation
+// already under way completes with the old settings and every later one uses
+// the new settings, so no rotation ever sees a half-applied change, such as a
+// new interval with the old retention. Start from Settings to change only some
+// of them.
+func (r *Rolog) Reconfigure(s Settings) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	if s.CompressWorkers < 1 {
+		s.CompressWorkers = 1
+	}
+
+	r.swapMu.Lock()
+	r.mu.Lock()
+	r.interval = s.Interval
+	r.maxSize = s.MaxSize
+	r.maxBackups = s.MaxBackups
+	r.maxTotalSize = s.MaxTotalSize
+	r.maxAge = s.MaxAge
+	r.bundleAge = s.BundleAge
+	r.compression = s.Compression
+	r.compressWorkers = s.CompressWorkers
+	r.compressLevel = s.CompressionLevel
+	r.latestLink = s.LatestLink
+	r.archivePerm = s.ArchivePerm
+	r.publish()
+	r.mu.Unlock()
+	r.swapMu.Unlock()
+
+	r.reschedule()
+	return nil
+}
+
+// publish stores a snapshot of the runtime settings for Settings to read. It
+// must be called with mu held after any of them changes.
+func (r *Rolog) publish() {
+	r.live.Store(&Settings{
+		Interval:         r.interval,
+		MaxSize:          r.maxSize,
+		MaxBackups:       r.maxBackups,
+		MaxTotalSize:     r.maxTotalSize,
+		MaxAge:           r.maxAge,
+		BundleAge:        r.bundleAge,
+		Compression:      r.compression,
+		CompressWorkers:  r.compressWorkers,
+		CompressionLevel: r.compressLevel,
+		LatestLink:       r.latestLink,
+		ArchivePerm:      r.archivePerm,
+	})
+}
+
+// reschedule tells a running loop that the interval may have changed.
+func (r *Rolog) reschedule() {
+	select {
+	case r.reconfig <- struct{}{}:
+	default:
+	}
+}
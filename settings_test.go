@@ -0,0 +1,59 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestReconfigureAppliesAllOrNothing(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithMaxBackups(3))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	s := r.Settings()
+	if s.Interval != DefaultInterval || s.MaxBackups != 3 {
+		t.Errorf("Wanted the configured settings, got %+v", s)
+	}
+
+	bad := s
+	bad.MaxBackups, bad.MaxSize = 1, -1
+	if err := r.Reconfigure(bad); errors.Cause(err) != ErrInvalidSize {
+		t.Errorf("Wanted %v, got %v", ErrInvalidSize, err)
+	}
+	if got := r.Settings(); got != s {
+		t.Errorf("Wanted a rejected change to leave %+v, got %+v", s, got)
+	}
+
+	s.Interval, s.MaxBackups, s.Compression = time.Minute, 1, Gzip
+	if err := r.Reconfigure(s); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if got := r.Settings(); got.Interval != time.Minute || got.MaxBackups != 1 || got.Compression != Gzip {
+		t.Errorf("Wanted %+v, got %+v", s, got)
+	}
+
+	r.SetMaxSize(1 << 20)
+	if got := r.Settings().MaxSize; got != 1<<20 {
+		t.Errorf("Wanted a setter to publish MaxSize %d, got %d", 1<<20, got)
+	}
+}
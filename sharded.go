@@ -0,0 +1,99 @@
+package rolog
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/pkg/errors"
+)
+
+// Sharded splits one log across a fixed number of rotating files, such as
+// app-0.log to app-3.log, each entry going to the file its key hashes to. The
+// same key always lands in the same file, so a tenant's or worker's entries
+// stay together without a file per key. All the shards are rotated together by
+// a single scheduler, with the same options and retention.
+type Sharded struct {
+	shards []*Rolog
+}
+
+// NewSharded creates a Sharded logger of n files, named after name and the
+// shard number, in dir. The opts apply to every shard.
+//
+// As with New, the returned logger is not already running, and its Run method
+// must be invoked manually.
+func NewSharded(dir, name string, n int, opts ...Option) (*Sharded, error) {
+	if n < 1 {
+		return nil, errors.Errorf("a sharded log needs at least one shard, got %d", n)
+	}
+	s := &Sharded{shards: make([]*Rolog, 0, n)}
+
+	root, err := New(dir, shardName(name, 0), opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.shards = append(s.shards, root)
+
+	for i := 1; i < n; i++ {
+		c, err := root.Child(shardName(name, i))
+		if err != nil {
+			s.Close()
+			return nil, errors.Wrapf(err, "could not create shard %d", i)
+		}
+		s.shards = append(s.shards, c)
+	}
+
+	return s, nil
+}
+
+// shardName names the file of shard i of the log named name.
+func shardName(name string, i int) string {
+	return fmt.Sprintf("%s-%d", name, i)
+}
+
+// Len returns the number of shards.
+func (s *Sharded) Len() int {
+	return len(s.shards)
+}
+
+// Shard returns the Rolog for shard i, or nil if there is no such shard.
+func (s *Sharded) Shard(i int) *Rolog {
+	if i < 0 || i >= len(s.shards) {
+		return nil
+	}
+	return s.shards[i]
+}
+
+// ShardFor returns the number of the shard that entries for key are written
+// to.
+func (s *Sharded) ShardFor(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(s.shards)))
+}
+
+// WriteKeyed writes p to the shard for key.
+func (s *Sharded) WriteKeyed(key string, p []byte) (int, error) {
+	return s.shards[s.ShardFor(key)].Write(p)
+}
+
+// Run starts the shared scheduler; see Rolog.Run.
+func (s *Sharded) Run(ctx context.Context) {
+	s.shards[0].Run(ctx)
+}
+
+// Rotate rotates every shard at once.
+func (s *Sharded) Rotate() error {
+	return s.shards[0].Rotate()
+}
+
+// Close closes every shard, returning the first error.
+func (s *Sharded) Close() error {
+	var first error
+	for i := len(s.shards) - 1; i >= 0; i-- {
+		if err := s.shards[i].Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,80 @@
+package rolog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestShardedRoutesByKeyAndRotatesTogether(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	s, err := NewSharded(dir, "app", 4, WithClock(clock))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		s.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	if _, err := NewSharded(dir, "none", 0); err == nil {
+		t.Errorf("Wanted a log of no shards to be rejected")
+	}
+	if s.Len() != 4 || s.Shard(4) != nil {
+		t.Errorf("Wanted 4 shards, got %d", s.Len())
+	}
+
+	want := make(map[int]string)
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("tenant-%d", i)
+		line := key + "\n"
+		for j := 0; j < 2; j++ {
+			if _, err := s.WriteKeyed(key, []byte(line)); err != nil {
+				t.Errorf("unexpected error: %q", err)
+				t.FailNow()
+			}
+			want[s.ShardFor(key)] += line
+		}
+	}
+	if len(want) < 2 {
+		t.Errorf("Wanted the keys spread over several shards, got %d", len(want))
+	}
+
+	for i := 0; i < s.Len(); i++ {
+		path := filepath.Join(dir, fmt.Sprintf("app-%d.log", i))
+		got, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			continue
+		}
+		if string(got) != want[i] {
+			t.Errorf("Wanted %q in %s, got %q", want[i], path, got)
+		}
+	}
+
+	clock.Advance(time.Hour)
+	if err := s.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+
+	for i := 0; i < s.Len(); i++ {
+		archives, err := s.Shard(i).Archives()
+		if err != nil || len(archives) != 1 {
+			t.Errorf("Wanted 1 archive of shard %d, got %d (%v)", i, len(archives), err)
+		}
+	}
+}
@@ -0,0 +1,389 @@
+// Package ship streams the archives of a Rolog to a central log host over TCP,
+// optionally secured with TLS, for environments without object storage.
+//
+// A Shipper sends every archive as soon as its rotation completes, and a
+// Receiver on the log host stores them. Transfers resume where they left off:
+// the Receiver keeps a partial archive until it has every byte, and tells the
+// Shipper how much it already holds when it reconnects.
+//
+// The protocol is line-based. The Shipper opens with
+//
+//	ROLOG-SHIP 1 "<archive name>" <size>
+//
+// and the Receiver answers OFFSET <n> with the number of bytes it already
+// has. The Shipper then sends the rest of the archive, and the Receiver
+// answers OK once it is durable, or ERR <reason>.
+package ship
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/haleyrc/rolog"
+	"github.com/haleyrc/rolog/internal/errq"
+	"github.com/pkg/errors"
+)
+
+// DefaultTimeout bounds dialing and each read and write when Config.Timeout
+// is zero.
+const DefaultTimeout = 30 * time.Second
+
+// errBuffer is how many undelivered errors are kept for Err.
+const errBuffer = 16
+
+// maxBackoff caps the wait between attempts to ship an archive.
+const maxBackoff = time.Minute
+
+// Config says where a Shipper sends archives.
+type Config struct {
+	// Addr is the host:port of the Receiver.
+	Addr string
+	// TLS, if set, makes the connection use TLS.
+	TLS *tls.Config
+	// Timeout bounds dialing and each read and write. It defaults to
+	// DefaultTimeout.
+	Timeout time.Duration
+}
+
+// Shipper sends the archives of a Rolog to a Receiver, one at a time and in
+// the order they were rotated, retrying with backoff until each has been
+// received. Every attempt is recorded in the Rolog's audit trail.
+type Shipper struct {
+	r    *rolog.Rolog
+	c    Config
+	stop func()
+
+	mu      sync.Mutex
+	pending []string
+	wake    chan struct{}
+	done    chan struct{}
+	closed  chan struct{}
+	err     chan error
+}
+
+// New returns a Shipper that sends every archive r produces from now on;
+// archives that already exist can be sent with Ship.
+func New(r *rolog.Rolog, c Config) *Shipper {
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+
+	s := &Shipper{
+		r:      r,
+		c:      c,
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+		closed: make(chan struct{}),
+		err:    make(chan error, errBuffer),
+	}
+	s.stop = r.Observe(rolog.ObserverFuncs{
+		Rotate: func(e rolog.RotationEvent) {
+			if e.NewPath != "" {
+				s.Ship(e.NewPath)
+			}
+		},
+	})
+	go s.run()
+	return s
+}
+
+// Ship queues the archive at path to be sent.
+func (s *Shipper) Ship(path string) {
+	s.mu.Lock()
+	s.pending = append(s.pending, path)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Err returns a channel on which failed attempts to ship an archive are
+// delivered. If the caller doesn't keep up, the oldest undelivered errors are
+// discarded.
+func (s *Shipper) Err() <-chan error {
+	return s.err
+}
+
+// Close stops the Shipper, abandoning any archives not yet sent.
+func (s *Shipper) Close() error {
+	s.stop()
+	close(s.closed)
+	<-s.done
+	return nil
+}
+
+// next returns the oldest pending archive, if there is one.
+func (s *Shipper) next() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) == 0 {
+		return "", false
+	}
+	return s.pending[0], true
+}
+
+// shipped removes the oldest pending archive.
+func (s *Shipper) shipped() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending = s.pending[1:]
+}
+
+// run ships pending archives until the Shipper is closed.
+func (s *Shipper) run() {
+	defer close(s.done)
+
+	var backoff time.Duration
+	for {
+		path, ok := s.next()
+		if !ok {
+			select {
+			case <-s.wake:
+				continue
+			case <-s.closed:
+				return
+			}
+		}
+
+		err := s.send(path)
+		s.r.RecordUpload(path, s.c.Addr, err)
+		if err == nil || os.IsNotExist(errors.Cause(err)) {
+			if err != nil {
+				s.report(errors.Wrapf(err, "could not ship %s", path))
+			}
+			s.shipped()
+			backoff = 0
+			continue
+		}
+		s.report(errors.Wrapf(err, "could not ship %s", path))
+
+		switch {
+		case backoff == 0:
+			backoff = time.Second
+		case backoff*2 > maxBackoff:
+			backoff = maxBackoff
+		default:
+			backoff *= 2
+		}
+		select {
+		case <-time.After(backoff):
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+// report delivers err on the error channel without blocking, discarding the
+// oldest pending error if the channel is full.
+func (s *Shipper) report(err error) {
+	errq.Deliver(s.err, err)
+}
+
+// send transfers a single archive, resuming from wherever the Receiver got
+// to.
+func (s *Shipper) send(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	conn, err := s.dial()
+	if err != nil {
+		return errors.Wrap(err, "could not connect")
+	}
+	defer conn.Close()
+
+	c := &deadlineConn{conn, s.c.Timeout}
+	br := bufio.NewReader(c)
+	if _, err := fmt.Fprintf(c, "ROLOG-SHIP 1 %s %d\n", strconv.Quote(filepath.Base(path)), fi.Size()); err != nil {
+		return err
+	}
+
+	reply, err := readReply(br, "OFFSET")
+	if err != nil {
+		return err
+	}
+	offset, err := strconv.ParseInt(reply, 10, 64)
+	if err != nil || offset < 0 || offset > fi.Size() {
+		return errors.Errorf("bad offset %q", reply)
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(c, f, fi.Size()-offset); err != nil {
+		return err
+	}
+
+	_, err = readReply(br, "OK")
+	return err
+}
+
+// dial connects to the Receiver.
+func (s *Shipper) dial() (net.Conn, error) {
+	d := &net.Dialer{Timeout: s.c.Timeout}
+	if s.c.TLS != nil {
+		return tls.DialWithDialer(d, "tcp", s.c.Addr, s.c.TLS)
+	}
+	return d.Dial("tcp", s.c.Addr)
+}
+
+// readReply reads a reply line, returning what follows want, or the reason
+// given with ERR.
+func readReply(br *bufio.Reader, want string) (string, error) {
+	line, err := br.ReadString('\n')
+	if err != nil {
+		return "", errors.Wrap(err, "could not read reply")
+	}
+	line = strings.TrimSuffix(line, "\n")
+
+	verb, rest := line, ""
+	if i := strings.IndexByte(line, ' '); i >= 0 {
+		verb, rest = line[:i], line[i+1:]
+	}
+	switch verb {
+	case want:
+		return rest, nil
+	case "ERR":
+		return "", errors.Errorf("receiver refused: %s", rest)
+	}
+	return "", errors.Errorf("unexpected reply %q", line)
+}
+
+// Receiver stores archives sent by Shippers in a directory. Complete archives
+// keep their names; an archive still in transit has the suffix ".part".
+type Receiver struct {
+	// Dir is where archives are stored.
+	Dir string
+	// Timeout bounds each read and write. It defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+// Serve accepts connections on l, which may be a TLS listener, and receives
+// an archive on each, until l is closed.
+func (rc *Receiver) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go rc.handle(conn)
+	}
+}
+
+// handle receives a single archive.
+func (rc *Receiver) handle(conn net.Conn) {
+	defer conn.Close()
+
+	timeout := rc.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	c := &deadlineConn{conn, timeout}
+
+	if err := rc.receive(c, bufio.NewReader(c)); err != nil {
+		fmt.Fprintf(c, "ERR %s\n", strings.Replace(err.Error(), "\n", " ", -1))
+	}
+}
+
+// receive reads an archive from br, acknowledging it on w.
+func (rc *Receiver) receive(w io.Writer, br *bufio.Reader) error {
+	line, err := br.ReadString('\n')
+	if err != nil {
+		return err
+	}
+
+	var (
+		quoted string
+		size   int64
+	)
+	if _, err := fmt.Sscanf(line, "ROLOG-SHIP 1 %q %d\n", &quoted, &size); err != nil {
+		return errors.New("bad request")
+	}
+	name := filepath.Base(quoted)
+	if name != quoted || name == "." || name == ".." || size < 0 {
+		return errors.New("bad archive name")
+	}
+
+	final := filepath.Join(rc.Dir, name)
+	if fi, err := os.Stat(final); err == nil && fi.Size() == size {
+		_, err := fmt.Fprintf(w, "OFFSET %d\nOK\n", size)
+		return err
+	}
+
+	part, err := os.OpenFile(final+".part", os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer part.Close()
+
+	offset, err := part.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if offset > size {
+		if err := part.Truncate(0); err != nil {
+			return err
+		}
+		if offset, err = part.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "OFFSET %d\n", offset); err != nil {
+		return err
+	}
+
+	if _, err := io.CopyN(part, br, size-offset); err != nil {
+		return err
+	}
+	if err := part.Sync(); err != nil {
+		return err
+	}
+	if err := part.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(final+".part", final); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "OK\n")
+	return err
+}
+
+// deadlineConn is a net.Conn whose every read and write must finish within
+// timeout.
+type deadlineConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+// Read satisfies io.Reader.
+func (c *deadlineConn) Read(p []byte) (int, error) {
+	c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+	return c.Conn.Read(p)
+}
+
+// Write satisfies io.Writer.
+func (c *deadlineConn) Write(p []byte) (int, error) {
+	c.Conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	return c.Conn.Write(p)
+}
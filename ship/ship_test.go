@@ -0,0 +1,114 @@
+package ship
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haleyrc/rolog"
+)
+
+// waitForFile waits for path to hold want.
+func waitForFile(t *testing.T, s *Shipper, path, want string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, _ := ioutil.ReadFile(path)
+		if string(got) == want {
+			return
+		}
+		select {
+		case err := <-s.Err():
+			t.Errorf("unexpected error: %q", err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Errorf("Wanted %s to hold %q, got %q", path, want, got)
+			t.FailNow()
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestShipperSendsArchives(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	var (
+		logs = filepath.Join(dir, "logs")
+		dest = filepath.Join(dir, "dest")
+	)
+	for _, d := range []string{logs, dest} {
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer l.Close()
+	go (&Receiver{Dir: dest}).Serve(l)
+
+	trail := filepath.Join(dir, "audit.jsonl")
+	r, err := rolog.New(logs, "test", rolog.WithAudit(trail))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	s := New(r, Config{Addr: l.Addr().String()})
+	defer s.Close()
+
+	r.Write([]byte("rotated\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	archives, err := r.Archives()
+	if err != nil || len(archives) != 1 {
+		t.Errorf("Wanted one archive, got %d (%v)", len(archives), err)
+		t.FailNow()
+	}
+	waitForFile(t, s, filepath.Join(dest, filepath.Base(archives[0].Path)), "rotated\n")
+
+	// The upload is audited once the Receiver acknowledges it.
+	want := fmt.Sprintf(`"action":"upload","path":%q,"target":%q,"result":"ok"`, archives[0].Path, l.Addr().String())
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		b, _ := ioutil.ReadFile(trail)
+		if strings.Contains(string(b), want) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Errorf("Wanted the upload audited, got %s", b)
+			t.FailNow()
+		}
+	}
+
+	// A partial transfer is resumed rather than restarted.
+	manual := filepath.Join(logs, "manual.log")
+	ioutil.WriteFile(manual, []byte("0123456789"), 0644)
+	ioutil.WriteFile(filepath.Join(dest, "manual.log.part"), []byte("01234"), 0644)
+	s.Ship(manual)
+	waitForFile(t, s, filepath.Join(dest, "manual.log"), "0123456789")
+
+	if _, err := os.Stat(filepath.Join(dest, "manual.log.part")); !os.IsNotExist(err) {
+		t.Errorf("Wanted the partial archive to be gone, got %v", err)
+	}
+}
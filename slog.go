@@ -0,0 +1,18 @@
+//go:build go1.21
+// +build go1.21
+
+package rolog
+
+import "log/slog"
+
+// SlogHandler returns a slog.Handler that writes each record to the Rolog as a
+// line of JSON. opts is passed through to slog.NewJSONHandler and may be nil.
+func (r *Rolog) SlogHandler(opts *slog.HandlerOptions) slog.Handler {
+	return slog.NewJSONHandler(r, opts)
+}
+
+// SlogTextHandler is SlogHandler, but writes records in slog's key=value text
+// format.
+func (r *Rolog) SlogTextHandler(opts *slog.HandlerOptions) slog.Handler {
+	return slog.NewTextHandler(r, opts)
+}
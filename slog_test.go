@@ -0,0 +1,58 @@
+//go:build go1.21
+// +build go1.21
+
+package rolog
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	logger := slog.New(r.SlogHandler(&slog.HandlerOptions{Level: slog.LevelWarn}))
+	logger.Info("ignored")
+	logger.Warn("disk nearly full", "free", 42)
+
+	got, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+	if len(lines) != 1 {
+		t.Errorf("Wanted 1 record above the level, got %d", len(lines))
+		t.FailNow()
+	}
+
+	var rec map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Errorf("Wanted a JSON record, got %q", lines[0])
+	}
+	if rec["msg"] != "disk nearly full" || rec["free"] != float64(42) || rec["level"] != "WARN" {
+		t.Errorf("Wanted the record's fields, got %v", rec)
+	}
+}
@@ -0,0 +1,177 @@
+package rolog
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// sources returns the archives followed by the current file, oldest first,
+// leaving out any that were written entirely outside of since and until. Zero
+// times leave that end of the range open. The current file is described as
+// though it were an archive rotated now.
+func (r *Rolog) sources(since, until time.Time) ([]ArchiveInfo, error) {
+	archives, err := r.archives()
+	if err != nil {
+		return nil, err
+	}
+
+	current := ArchiveInfo{Path: r.currentPath(), End: r.now()}
+	if n := len(archives); n > 0 {
+		current.Start = archives[n-1].End
+	}
+	if fi, err := r.fs.Stat(r.currentPath()); err == nil {
+		current.Size = fi.Size()
+	}
+
+	var sources []ArchiveInfo
+	for _, a := range append(archives, current) {
+		if !since.IsZero() && a.End.Before(since) {
+			continue
+		}
+		if !until.IsZero() && !a.Start.IsZero() && a.Start.After(until) {
+			continue
+		}
+		sources = append(sources, a)
+	}
+	return sources, nil
+}
+
+// openSource opens one of the files returned by sources for reading,
+// decompressing archives. The current file is only read up to its size when
+// it was opened, so that what is read is consistent however much is written
+// meanwhile. An archive that has been compressed or encrypted since it was
+// listed is opened under its new name.
+func (r *Rolog) openSource(a ArchiveInfo) (io.ReadCloser, error) {
+	if a.Path == r.currentPath() {
+		f, err := r.fs.OpenFile(a.Path, os.O_RDONLY, 0)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not open log")
+		}
+		fi, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, errors.Wrap(err, "could not stat log")
+		}
+		return limitedFile{Reader: io.LimitReader(f, fi.Size()), Closer: f}, nil
+	}
+
+	rc, err := OpenArchive(a)
+	if err == nil || !os.IsNotExist(errors.Cause(err)) {
+		return rc, err
+	}
+
+	r.mu.Lock()
+	s := r.archiveSettings()
+	r.mu.Unlock()
+	for _, ext := range []string{s.compression.Extension(), encrypterExt(s.encrypter)} {
+		if ext == "" {
+			continue
+		}
+		a.Path += ext
+		if rc, err2 := OpenArchive(a); err2 == nil || !os.IsNotExist(errors.Cause(err2)) {
+			return rc, err2
+		}
+	}
+	return nil, err
+}
+
+// encrypterExt returns the extension of e, or "" if it is nil.
+func encrypterExt(e Encrypter) string {
+	if e == nil {
+		return ""
+	}
+	return e.Extension()
+}
+
+// limitedFile reads part of a file and closes the file.
+type limitedFile struct {
+	io.Reader
+	io.Closer
+}
+
+// History returns a single reader of everything the Rolog has kept, from the
+// oldest archive through the current file, decompressing archives as needed.
+// It reads what was there when History was called: the current file is opened
+// straight away and read no further than it had been written, and the
+// archives are opened one at a time as the reader gets to them, so History
+// can be read while writes and rotations carry on. Archives pruned in the
+// meantime are skipped. An encrypted archive can't be read and fails the read
+// with an error.
+func (r *Rolog) History() (io.ReadCloser, error) {
+	return r.snapshot(time.Time{}, time.Time{})
+}
+
+// snapshot returns a reader of the files returned by sources, opening the
+// current file, if it is among them, straight away.
+func (r *Rolog) snapshot(since, until time.Time) (*historyReader, error) {
+	sources, err := r.sources(since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &historyReader{r: r, archives: sources}
+	if n := len(sources) - 1; n >= 0 && sources[n].Path == r.currentPath() {
+		if h.current, err = r.openSource(sources[n]); err != nil {
+			return nil, err
+		}
+		h.archives = sources[:n]
+	}
+	return h, nil
+}
+
+// historyReader reads archives one after another, and then current.
+type historyReader struct {
+	r        *Rolog
+	archives []ArchiveInfo
+	current  io.ReadCloser
+	rc       io.ReadCloser
+}
+
+// Read satisfies io.Reader.
+func (h *historyReader) Read(p []byte) (int, error) {
+	for {
+		switch {
+		case h.rc != nil:
+		case len(h.archives) > 0:
+			rc, err := h.r.openSource(h.archives[0])
+			h.archives = h.archives[1:]
+			if os.IsNotExist(errors.Cause(err)) {
+				continue
+			}
+			if err != nil {
+				return 0, err
+			}
+			h.rc = rc
+		case h.current != nil:
+			h.rc, h.current = h.current, nil
+		default:
+			return 0, io.EOF
+		}
+
+		n, err := h.rc.Read(p)
+		if err == io.EOF {
+			h.rc.Close()
+			h.rc = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+// Close satisfies io.Closer.
+func (h *historyReader) Close() error {
+	h.archives = nil
+	for _, rc := range []io.ReadCloser{h.rc, h.current} {
+		if rc != nil {
+			rc.Close()
+		}
+	}
+	h.rc, h.current = nil, nil
+	return nil
+}
@@ -0,0 +1,107 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+// newHistory returns a gzipping Rolog on a fake clock with two archives and a
+// current file, each written over an hour and holding three lines naming
+// the file and the line. The returned function cleans up.
+func newHistory(t *testing.T) (*Rolog, *fakeClock, func()) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clk := newFakeClock()
+	r, err := New(dir, "test", WithClock(clk), WithCompression(Gzip))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	cleanup := func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}
+
+	for i, file := range []string{"first", "second", "third"} {
+		if i > 0 {
+			clk.Advance(time.Hour)
+			if err := r.Rotate(); err != nil {
+				cleanup()
+				t.Errorf("unexpected error: %q", err)
+				t.FailNow()
+			}
+		}
+		for _, line := range []string{"one", "two ERROR", "three"} {
+			r.Write([]byte(file + " " + line + "\n"))
+		}
+	}
+	clk.Advance(time.Hour)
+	return r, clk, cleanup
+}
+
+func TestSourcesSelectsFilesByTime(t *testing.T) {
+	r, clk, cleanup := newHistory(t)
+	defer cleanup()
+
+	start := clk.Now().Add(-3 * time.Hour)
+	tests := []struct {
+		since, until time.Time
+		want         int
+	}{
+		{want: 3},
+		{since: start.Add(150 * time.Minute), want: 1},
+		{until: start.Add(30 * time.Minute), want: 1},
+		{since: start.Add(30 * time.Minute), until: start.Add(90 * time.Minute), want: 2},
+	}
+	for _, tt := range tests {
+		sources, err := r.sources(tt.since, tt.until)
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			continue
+		}
+		if len(sources) != tt.want {
+			t.Errorf("Wanted %d files between %v and %v, got %+v", tt.want, tt.since, tt.until, sources)
+		}
+		if last := sources[len(sources)-1]; tt.until.IsZero() && last.Path != r.CurrentPath() {
+			t.Errorf("Wanted the current file last, got %s", last.Path)
+		}
+	}
+}
+
+func TestHistoryReadsEverythingInOrder(t *testing.T) {
+	r, _, cleanup := newHistory(t)
+	defer cleanup()
+
+	rc, err := r.History()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer rc.Close()
+
+	// Neither writes nor rotations after History was called show up.
+	r.Write([]byte("later\n"))
+	r.Rotate()
+	r.Write([]byte("later still\n"))
+
+	b, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	var want string
+	for _, file := range []string{"first", "second", "third"} {
+		want += file + " one\n" + file + " two ERROR\n" + file + " three\n"
+	}
+	if string(b) != want {
+		t.Errorf("Wanted %q, got %q", want, b)
+	}
+}
@@ -0,0 +1,61 @@
+package rolog
+
+import (
+	"os"
+	"time"
+)
+
+// startArchive is the test of WithArchiveOnStart.
+type startArchive struct {
+	age  time.Duration
+	size int64
+}
+
+// WithArchiveOnStart makes New archive an existing current file only if it is
+// at least age old or has reached size bytes, and otherwise carry on writing
+// to it as with WithAppend, so that frequent restarts don't leave a trail of
+// nearly empty archives. A file's age is counted from the newest archive
+// beside it, which is when it was started, or from when it was last written
+// if there are none. A zero age or size disables that half of the test, so
+// with both zero the file is always continued.
+func WithArchiveOnStart(age time.Duration, size int64) Option {
+	return func(r *Rolog) {
+		r.startArchive = &startArchive{age: age, size: size}
+	}
+}
+
+// continueOnStart reports whether New should append to the existing current
+// file at path in dir rather than archive it.
+func (r *Rolog) continueOnStart(dir, path string) bool {
+	if r.appendOnStart || r.handedIn {
+		return true
+	}
+
+	c := r.startArchive
+	if c == nil {
+		return false
+	}
+	fi, err := r.fs.Stat(path)
+	if err != nil {
+		return false
+	}
+	if c.size > 0 && fi.Size() >= c.size {
+		r.debugf("archiving the existing %d byte log on start", fi.Size())
+		return false
+	}
+	if age := r.now().Sub(r.started(dir, fi)); c.age > 0 && age >= c.age {
+		r.debugf("archiving the existing log on start, %s old", age)
+		return false
+	}
+	return true
+}
+
+// started returns when the current file described by fi in dir was started:
+// the end of the newest archive, or failing that, its last write.
+func (r *Rolog) started(dir string, fi os.FileInfo) time.Time {
+	archives, err := r.archivesIn(dir)
+	if err != nil || len(archives) == 0 {
+		return fi.ModTime()
+	}
+	return archives[len(archives)-1].End
+}
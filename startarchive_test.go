@@ -0,0 +1,70 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWithArchiveOnStartContinuesSmallYoungFiles(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	clock := newFakeClock()
+	open := func(opts ...Option) *Rolog {
+		r, err := New(dir, "test", append([]Option{WithClock(clock)}, opts...)...)
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		return r
+	}
+	expect := func(r *Rolog, archives int, current string) {
+		got, err := r.Archives()
+		if err != nil || len(got) != archives {
+			t.Errorf("Wanted %d archives, got %d (%v)", archives, len(got), err)
+		}
+		b, err := ioutil.ReadFile(r.CurrentPath())
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+		}
+		if string(b) != current {
+			t.Errorf("Wanted %q in the current file, got %q", current, b)
+		}
+	}
+
+	r := open()
+	r.Write([]byte("a\n"))
+	r.Rotate()
+	r.Write([]byte("b\n"))
+	r.Close()
+
+	// Ten minutes after the last rotation, the file is continued.
+	clock.Advance(10 * time.Minute)
+	r = open(WithArchiveOnStart(time.Hour, 10))
+	expect(r, 1, "b\n")
+	r.Write([]byte("c\n"))
+	r.Close()
+
+	// An hour later, it is archived for its age.
+	clock.Advance(time.Hour)
+	r = open(WithArchiveOnStart(time.Hour, 10))
+	expect(r, 2, "")
+	r.Write([]byte("0123456789\n"))
+	r.Close()
+
+	// Straight away, it is archived for its size.
+	clock.Advance(time.Second)
+	r = open(WithArchiveOnStart(time.Hour, 10))
+	expect(r, 3, "")
+	r.Close()
+}
@@ -0,0 +1,74 @@
+package rolog
+
+import (
+	"sync"
+	"time"
+)
+
+// Stats is a point-in-time summary of a Rolog, for health reporting.
+type Stats struct {
+	// BytesWritten is how many bytes of entries have reached the log files since
+	// New, after any encoding.
+	BytesWritten int64
+	// BytesSinceRotation is how many of those went to the current file.
+	BytesSinceRotation int64
+	// CurrentSize is the size of the current file, including anything it
+	// held before New or that other processes appended.
+	CurrentSize int64
+	// Rotations is how many rotations have happened since New.
+	Rotations uint64
+	// LastRotation is when the last rotation started, and
+	// LastRotationDuration how long it took to process, including
+	// finalizing the archive. Both are zero until the first rotation.
+	LastRotation         time.Time
+	LastRotationDuration time.Duration
+	// Pause summarizes how long rotations have blocked writes.
+	Pause PauseStats
+	// Archives is how many archives are on disk.
+	Archives int
+	// Dropped is the same as Dropped, and Drops breaks it down by reason.
+	Dropped uint64
+	Drops   Drops
+}
+
+// rotationStats records the last processed rotation, under its own lock since
+// it is updated during processing, which must not take mu.
+type rotationStats struct {
+	mu    sync.Mutex
+	start time.Time
+	took  time.Duration
+}
+
+// Stats returns a summary of the Rolog. Counting the archives means listing
+// the directory, and if that fails the error is returned along with everything
+// else.
+func (r *Rolog) Stats() (Stats, error) {
+	r.mu.Lock()
+	s := Stats{
+		BytesWritten:       r.written,
+		BytesSinceRotation: r.written - r.writtenAtRotation,
+		CurrentSize:        r.size,
+		Rotations:          r.rotations,
+		Pause:              r.pauses.stats(),
+	}
+	r.mu.Unlock()
+
+	r.lastRotation.mu.Lock()
+	s.LastRotation, s.LastRotationDuration = r.lastRotation.start, r.lastRotation.took
+	r.lastRotation.mu.Unlock()
+
+	s.Drops = r.DroppedBy()
+	s.Dropped = s.Drops.Total()
+
+	archives, err := r.archives()
+	s.Archives = len(archives)
+	return s, err
+}
+
+// recordRotation notes a processed rotation for Stats.
+func (r *Rolog) recordRotation(start time.Time, took time.Duration) {
+	r.lastRotation.mu.Lock()
+	defer r.lastRotation.mu.Unlock()
+
+	r.lastRotation.start, r.lastRotation.took = start, took
+}
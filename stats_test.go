@@ -0,0 +1,62 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("before\n"))
+	clock.Advance(time.Hour)
+	start := clock.Now()
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	r.Write([]byte("after\n"))
+
+	s, err := r.Stats()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if s.Pause.Count != 1 || s.Pause.Max <= 0 {
+		t.Errorf("Wanted one rotation pause, got %+v", s.Pause)
+	}
+	s.Pause = PauseStats{}
+
+	want := Stats{
+		BytesWritten:       13,
+		BytesSinceRotation: 6,
+		CurrentSize:        6,
+		Rotations:          1,
+		LastRotation:       start,
+		Archives:           1,
+	}
+	if s != want {
+		t.Errorf("Wanted %+v, got %+v", want, s)
+	}
+}
@@ -0,0 +1,291 @@
+package rolog
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Rotation is everything a RotationStrategy needs to perform a rotation.
+type Rotation struct {
+	// FS is the filesystem the files live on.
+	FS FS
+	// File is the open current file.
+	File File
+	// Current is the path the current file lives at.
+	Current string
+	// Archive is the path the archive should be written to.
+	Archive string
+	// Perm is the mode for any newly created current file. Zero means the
+	// default of 0666 before umask.
+	Perm os.FileMode
+}
+
+// RotationStrategy performs the file-level mechanics of a rotation. It returns
+// the handle to continue writing to and the path of the archive it produced,
+// which is empty if the strategy leaves archiving to someone else.
+//
+// Strategies are always invoked with writes paused. If Rotate fails after it
+// has closed rot.File, it should return a handle to the current path along
+// with the error, so that writes can carry on until the next attempt.
+type RotationStrategy interface {
+	Rotate(rot Rotation) (next File, archived string, err error)
+}
+
+// SwappingStrategy is a RotationStrategy that can also prepare the next file
+// while writes continue to the current one, so that writes only pause for the
+// handles to be swapped. Prepare must leave rot.File open; it is synced and
+// closed once the swap is done. Every rotation uses Prepare except those that
+// change day directories, and Rotate is kept for the retry after a failed
+// size-triggered swap. RenameStrategy, except on Windows, and ReopenStrategy
+// are SwappingStrategies; the truncating strategies can't be, since the
+// writes that arrived while the file was prepared would be lost.
+type SwappingStrategy interface {
+	RotationStrategy
+	Prepare(rot Rotation) (next File, archived string, err error)
+}
+
+// SetRotationStrategy changes how Rotate moves the current file out of the
+// way. The default is RenameStrategy. Passing nil restores the default.
+func (r *Rolog) SetRotationStrategy(s RotationStrategy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s == nil {
+		s = RenameStrategy{}
+	}
+	r.strategy = s
+}
+
+// RenameStrategy closes the current file, renames it to the archive path, and
+// creates a fresh file at the current path. Except on Windows, where open files
+// can't be renamed, it is a SwappingStrategy. On Windows, a rename that fails
+// because another process briefly has the file open is retried with backoff.
+type RenameStrategy struct{}
+
+// Rotate satisfies RotationStrategy.
+func (RenameStrategy) Rotate(rot Rotation) (File, string, error) {
+	rot.File.Sync()
+	rot.File.Close()
+	if err := renameFile(rot.FS, rot.Current, rot.Archive); err != nil {
+		err = errors.Wrap(err, "could not archive old log file")
+		next, oerr := openFile(rot.FS, rot.Current, currentFlag, rot.Perm)
+		if oerr != nil {
+			return nil, "", err
+		}
+		return next, "", err
+	}
+
+	next, err := openFile(rot.FS, rot.Current, currentFlag|os.O_TRUNC, rot.Perm)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "could not open new log file")
+	}
+
+	return next, rot.Archive, nil
+}
+
+// ReopenStrategy closes the current file and opens the current path again
+// without renaming anything. It is meant for deployments where an external
+// tool such as logrotate has already moved the file aside, so no archive is
+// produced and post-rotation processing is skipped. It is a SwappingStrategy.
+type ReopenStrategy struct{}
+
+// Prepare satisfies SwappingStrategy. The current path is opened again while
+// writes continue to the file that was moved aside.
+func (ReopenStrategy) Prepare(rot Rotation) (File, string, error) {
+	next, err := openFile(rot.FS, rot.Current, currentFlag, rot.Perm)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "could not reopen log file")
+	}
+
+	return next, "", nil
+}
+
+// Rotate satisfies RotationStrategy.
+func (ReopenStrategy) Rotate(rot Rotation) (File, string, error) {
+	rot.File.Sync()
+	rot.File.Close()
+
+	next, err := openFile(rot.FS, rot.Current, currentFlag, rot.Perm)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "could not reopen log file")
+	}
+
+	return next, "", nil
+}
+
+// CopyTruncateStrategy copies the current file to the archive path and then
+// truncates it in place, matching logrotate's copytruncate. It is meant for
+// environments where other processes hold the file open by path, since the
+// current file keeps its identity. Lines that an external writer appends
+// between the copy and the truncation are lost, and writers that don't use
+// O_APPEND leave a sparse file behind, just as with logrotate.
+type CopyTruncateStrategy struct{}
+
+// Rotate satisfies RotationStrategy.
+func (CopyTruncateStrategy) Rotate(rot Rotation) (File, string, error) {
+	rot.File.Sync()
+	if err := copyFile(rot.FS, rot.Current, rot.Archive, rot.Perm); err != nil {
+		rot.FS.Remove(rot.Archive)
+		return rot.File, "", err
+	}
+
+	next, err := openFile(rot.FS, rot.Current, currentFlag|os.O_TRUNC, rot.Perm)
+	if err != nil {
+		rot.FS.Remove(rot.Archive)
+		return rot.File, "", errors.Wrap(err, "could not truncate log file")
+	}
+	rot.File.Close()
+
+	return next, rot.Archive, nil
+}
+
+// TruncateStrategy truncates the current file in place instead of archiving
+// it, so the log never takes more than a single file; see WithCircular. If
+// Keep is positive, up to that many bytes from the end of the file are kept,
+// from the first line that starts within them, so the newest entries survive
+// the truncation. No archive is produced and post-rotation processing is
+// skipped, as with ReopenStrategy.
+type TruncateStrategy struct {
+	Keep int64
+}
+
+// Rotate satisfies RotationStrategy.
+func (s TruncateStrategy) Rotate(rot Rotation) (File, string, error) {
+	rot.File.Sync()
+	var tail []byte
+	if s.Keep > 0 {
+		var err error
+		if tail, err = readTail(rot.FS, rot.Current, s.Keep); err != nil {
+			return rot.File, "", err
+		}
+	}
+
+	next, err := openFile(rot.FS, rot.Current, currentFlag|os.O_TRUNC, rot.Perm)
+	if err != nil {
+		return rot.File, "", errors.Wrap(err, "could not truncate log file")
+	}
+	rot.File.Close()
+
+	if _, err := next.Write(tail); err != nil {
+		return next, "", errors.Wrap(err, "could not keep the end of log file")
+	}
+	return next, "", nil
+}
+
+// readTail returns the whole lines within the last n bytes of the file at
+// path.
+func readTail(fs FS, path string, n int64) ([]byte, error) {
+	f, err := fs.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open log file")
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not stat log file")
+	}
+	if fi.Size() <= n {
+		return ioutil.ReadAll(f)
+	}
+
+	// Read from the byte before the tail, to tell whether the tail starts
+	// a line.
+	skip := fi.Size() - n - 1
+	if sk, ok := f.(io.Seeker); ok {
+		_, err = sk.Seek(skip, io.SeekStart)
+	} else {
+		_, err = io.CopyN(ioutil.Discard, f, skip)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read log file")
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(f, n+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read log file")
+	}
+	i := bytes.IndexByte(b, '\n')
+	if i < 0 {
+		return nil, nil
+	}
+	return b[i+1:], nil
+}
+
+// moveFile renames src to dst, falling back to copying it and removing the
+// original where a rename can't be done, such as across filesystems.
+func moveFile(fs FS, src, dst string, perm os.FileMode) error {
+	if err := renameFile(fs, src, dst); err == nil {
+		return nil
+	}
+	if err := copyFile(fs, src, dst, perm); err != nil {
+		fs.Remove(dst)
+		return err
+	}
+	return errors.Wrap(fs.Remove(src), "could not remove moved file")
+}
+
+// copyFile copies src to a new file at dst and syncs it.
+func copyFile(fs FS, src, dst string, perm os.FileMode) error {
+	in, err := fs.OpenFile(src, os.O_RDONLY, 0)
+	if err != nil {
+		return errors.Wrap(err, "could not open log file")
+	}
+	defer in.Close()
+
+	out, err := openFile(fs, dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return errors.Wrap(err, "could not create archive")
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return errors.Wrap(err, "could not copy log file")
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return errors.Wrap(err, "could not sync archive")
+	}
+	return errors.Wrap(out.Close(), "could not close archive")
+}
+
+// Reopen closes the current file and opens the current path again without
+// renaming anything, so that an external tool such as logrotate can move the
+// file aside and signal the Rolog to release its handle. Unlike Rotate, no
+// archive processing or retention takes place.
+func (r *Rolog) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed() {
+		return ErrClosed
+	}
+	if r.passthrough != nil {
+		return nil
+	}
+
+	if err := r.drain(); err != nil {
+		return err
+	}
+
+	f, _, err := ReopenStrategy{}.Rotate(Rotation{
+		FS:      r.fs,
+		File:    r.f,
+		Current: r.currentPath(),
+		Perm:    r.perm,
+	})
+	if err != nil {
+		return err
+	}
+	r.setFile(f)
+
+	r.size = 0
+	if fi, err := f.Stat(); err == nil {
+		r.size = fi.Size()
+	}
+
+	return r.writeHeader()
+}
@@ -0,0 +1,45 @@
+//go:build !windows
+// +build !windows
+
+package rolog
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Prepare satisfies SwappingStrategy. The current file is renamed while still
+// open, so writes continue into the archive until the swap.
+func (RenameStrategy) Prepare(rot Rotation) (File, string, error) {
+	if err := rot.FS.Rename(rot.Current, rot.Archive); err != nil {
+		return nil, "", errors.Wrap(err, "could not archive old log file")
+	}
+
+	next, err := openFile(rot.FS, rot.Current, currentFlag|os.O_TRUNC, rot.Perm)
+	if err != nil {
+		rot.FS.Rename(rot.Archive, rot.Current)
+		return nil, "", errors.Wrap(err, "could not open new log file")
+	}
+
+	return next, rot.Archive, nil
+}
+
+// Prepare satisfies SwappingStrategy. The old file is renamed while still open,
+// after the link has been swung, so writes continue into the archive until the
+// swap.
+func (s linkStrategy) Prepare(rot Rotation) (File, string, error) {
+	old, next, err := s.swing(rot)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := rot.FS.Rename(old, rot.Archive); err != nil {
+		replaceLink(rot.FS, rot.Current, old)
+		next.Close()
+		rot.FS.Remove(s.next)
+		return nil, "", errors.Wrap(err, "could not archive old log file")
+	}
+
+	return next, rot.Archive, nil
+}
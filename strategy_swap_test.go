@@ -0,0 +1,147 @@
+//go:build !windows
+// +build !windows
+
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRotateDoesNotStallWrites(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	fs := &slowRenameFS{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithFS(fs), WithClock(clock))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("before\n"))
+	clock.Advance(time.Hour)
+
+	rotated := make(chan error)
+	go func() { rotated <- r.Rotate() }()
+	<-fs.entered
+
+	written := make(chan error)
+	go func() {
+		_, err := r.Write([]byte("during\n"))
+		written <- err
+	}()
+
+	select {
+	case err := <-written:
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Wanted write to complete while the rotation was in progress")
+	}
+
+	close(fs.release)
+	if err := <-rotated; err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+	r.Write([]byte("after\n"))
+
+	archives, err := r.Archives()
+	if err != nil || len(archives) != 1 {
+		t.Errorf("Wanted 1 archive, got %d (%v)", len(archives), err)
+		t.FailNow()
+	}
+
+	for path, want := range map[string]string{
+		archives[0].Path: "before\nduring\n",
+		r.CurrentPath():  "after\n",
+	} {
+		got, _ := ioutil.ReadFile(path)
+		if string(got) != want {
+			t.Errorf("Wanted %q in %s, got %q", want, path, got)
+		}
+	}
+}
+
+func TestSizeRotationDoesNotStallWrites(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	fs := &slowRenameFS{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	r, err := New(dir, "test", WithFS(fs), WithMaxSize(20))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("before\n"))
+
+	full := make(chan error)
+	go func() {
+		_, err := r.Write([]byte("this one fills it\n"))
+		full <- err
+	}()
+	<-fs.entered
+
+	written := make(chan error)
+	go func() {
+		_, err := r.Write([]byte("during\n"))
+		written <- err
+	}()
+
+	select {
+	case err := <-written:
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Wanted write to complete while the rotation was in progress")
+	}
+
+	close(fs.release)
+	if err := <-full; err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	archives, err := r.Archives()
+	if err != nil || len(archives) != 1 {
+		t.Errorf("Wanted 1 archive, got %d (%v)", len(archives), err)
+		t.FailNow()
+	}
+
+	for path, want := range map[string]string{
+		archives[0].Path: "before\nduring\n",
+		r.CurrentPath():  "this one fills it\n",
+	} {
+		got, _ := ioutil.ReadFile(path)
+		if string(got) != want {
+			t.Errorf("Wanted %q in %s, got %q", want, path, got)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReopenStrategyFollowsExternalRename(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test", WithInterval(60*time.Minute))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.SetRotationStrategy(ReopenStrategy{})
+	r.Write([]byte("before\n"))
+
+	current := filepath.Join(dir, "test.log")
+	if err := os.Rename(current, filepath.Join(dir, "test.log.1")); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+	r.Write([]byte("after\n"))
+
+	got, err := ioutil.ReadFile(current)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if string(got) != "after\n" {
+		t.Errorf("Wanted %q, got %q", "after\n", got)
+	}
+
+	archives, err := r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(archives) != 0 {
+		t.Errorf("Wanted no archives, got %d", len(archives))
+	}
+}
+
+func TestReopenReleasesHandle(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("before\n"))
+
+	current := filepath.Join(dir, "test.log")
+	moved := filepath.Join(dir, "test.log.1")
+	if err := os.Rename(current, moved); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	if err := r.Reopen(); err != nil {
+		t.Errorf("could not reopen: %q", err)
+		t.FailNow()
+	}
+	r.Write([]byte("after\n"))
+
+	for path, want := range map[string]string{current: "after\n", moved: "before\n"} {
+		got, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("Wanted %q in %s, got %q", want, path, got)
+		}
+	}
+}
+
+func TestCopyTruncateStrategyKeepsTheCurrentFile(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithRotationStrategy(CopyTruncateStrategy{}))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	current := filepath.Join(dir, "test.log")
+	before, err := os.Stat(current)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r.Write([]byte("before\n"))
+	clock.Advance(time.Hour)
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+	r.Write([]byte("after\n"))
+
+	after, err := os.Stat(current)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if !os.SameFile(before, after) {
+		t.Errorf("Wanted the current file to be truncated in place")
+	}
+	if got, _ := ioutil.ReadFile(current); string(got) != "after\n" {
+		t.Errorf("Wanted %q, got %q", "after\n", got)
+	}
+
+	archives, err := r.Archives()
+	if err != nil || len(archives) != 1 {
+		t.Errorf("Wanted 1 archive, got %d (%v)", len(archives), err)
+		t.FailNow()
+	}
+	if got, _ := ioutil.ReadFile(archives[0].Path); string(got) != "before\n" {
+		t.Errorf("Wanted %q in the archive, got %q", "before\n", got)
+	}
+}
@@ -0,0 +1,211 @@
+package rolog
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SyncPolicy decides when writes are flushed to disk with fsync. Whatever the
+// policy, the current file is always synced before it is rotated or closed.
+type SyncPolicy struct {
+	bytes      int64
+	interval   time.Duration
+	background time.Duration
+	never      bool
+}
+
+var (
+	// SyncEveryWrite syncs after every write. It is the default and the most
+	// durable choice.
+	SyncEveryWrite = SyncPolicy{}
+	// SyncOnRotate never syncs on the write path, leaving it to the operating
+	// system, rotation and Close.
+	SyncOnRotate = SyncPolicy{never: true}
+)
+
+// SyncEveryBytes syncs once at least n bytes have been written since the last
+// sync.
+func SyncEveryBytes(n int64) SyncPolicy {
+	return SyncPolicy{bytes: n}
+}
+
+// SyncEvery syncs on the first write at least d after the last sync.
+func SyncEvery(d time.Duration) SyncPolicy {
+	return SyncPolicy{interval: d}
+}
+
+// SyncInBackground never syncs on the write path. Instead the run loop syncs
+// every d if anything has been written, bounding the data lost in a crash to
+// that window. It only takes effect while the Rolog is running.
+func SyncInBackground(d time.Duration) SyncPolicy {
+	return SyncPolicy{background: d}
+}
+
+// WithSyncPolicy chooses when writes are synced to disk, trading durability for
+// throughput.
+func WithSyncPolicy(p SyncPolicy) Option {
+	return func(r *Rolog) {
+		r.syncPolicy = p
+	}
+}
+
+// WithDirSync syncs the log directory after every rotation and after the
+// current file is created, so a crash straight afterwards can't lose the rename
+// or the new file on filesystems that don't order metadata updates. The
+// directory is also synced once each archive has been finalized.
+func WithDirSync() Option {
+	return func(r *Rolog) {
+		r.dirSync = true
+	}
+}
+
+// Flush syncs everything written so far to disk. It is the way to guarantee
+// durability at transaction boundaries when the SyncPolicy doesn't sync every
+// write. Unlike Close, the Rolog remains usable afterwards.
+func (r *Rolog) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed() {
+		return ErrClosed
+	}
+
+	return errors.Wrap(r.sync(), "could not flush log")
+}
+
+// syncAfterWrite syncs the current file if the policy says it is due after n
+// more bytes were written. Under SyncEveryWrite the sync is left to commit
+// instead, and the sequence number to pass to it is returned. It must be
+// called with mu held.
+func (r *Rolog) syncAfterWrite(n int) uint64 {
+	p := r.syncPolicy
+	r.unsynced += int64(n)
+
+	switch {
+	case p.never, p.background > 0:
+		return 0
+	case p.bytes > 0:
+		if r.unsynced < p.bytes {
+			return 0
+		}
+	case p.interval > 0:
+		if r.now().Sub(r.lastSync) < p.interval {
+			return 0
+		}
+	default:
+		r.seq++
+		return r.seq
+	}
+
+	r.sync()
+	return 0
+}
+
+// commit waits until the write numbered seq has been synced. Writers that
+// arrive while a sync is in flight are covered together by the next one, so
+// concurrent writers share a single fsync instead of queueing for one each.
+// It must be called without mu held.
+func (r *Rolog) commit(seq uint64) error {
+	r.commitMu.Lock()
+	defer r.commitMu.Unlock()
+
+	if r.commitCond == nil {
+		r.commitCond = sync.NewCond(&r.commitMu)
+	}
+
+	for r.synced < seq || seq > r.lostFrom && seq <= r.lostTo {
+		if seq > r.lostFrom && seq <= r.lostTo {
+			return errors.Wrap(r.lostErr, "could not sync log")
+		}
+		if r.syncing {
+			r.commitCond.Wait()
+			continue
+		}
+
+		r.syncing = true
+		r.commitMu.Unlock()
+
+		r.mu.Lock()
+		target := r.seq
+		err := r.sync()
+		r.mu.Unlock()
+
+		r.commitMu.Lock()
+		r.syncing = false
+		if err == nil && target > r.synced {
+			r.synced = target
+		}
+		r.commitCond.Broadcast()
+		if err != nil {
+			return errors.Wrap(err, "could not sync log")
+		}
+	}
+
+	return nil
+}
+
+// holdCommits waits for any sync in flight and then holds off the group
+// commits until retire, so that none is acknowledged by a sync of the next file
+// while the writes before it are still only in the old one. It must be called
+// without mu held.
+func (r *Rolog) holdCommits() {
+	r.commitMu.Lock()
+	defer r.commitMu.Unlock()
+
+	if r.commitCond == nil {
+		r.commitCond = sync.NewCond(&r.commitMu)
+	}
+	for r.syncing {
+		r.commitCond.Wait()
+	}
+	r.syncing = true
+}
+
+// releaseCommits lets the group commits held off by holdCommits go on when
+// there is no file to retire after all.
+func (r *Rolog) releaseCommits() {
+	r.commitMu.Lock()
+	defer r.commitMu.Unlock()
+
+	r.syncing = false
+	r.commitCond.Broadcast()
+}
+
+// retire syncs and closes old, the file swapped out after taking the writes
+// numbered up to last, and then lets the group commits held off by
+// holdCommits go on. If old can't be synced, the commits of its unsynced
+// writes fail. It must be called without mu held.
+func (r *Rolog) retire(old File, last uint64) error {
+	serr := old.Sync()
+	cerr := old.Close()
+
+	r.commitMu.Lock()
+	defer r.commitMu.Unlock()
+
+	r.syncing = false
+	switch {
+	case serr != nil:
+		r.lostFrom, r.lostTo = r.synced, last
+		r.lostErr = errors.Wrap(serr, "could not sync old log file")
+	case last > r.synced:
+		r.synced = last
+	}
+	r.commitCond.Broadcast()
+
+	if serr != nil {
+		return r.lostErr
+	}
+	return errors.Wrap(cerr, "could not close old log file")
+}
+
+// sync flushes the current file to disk. It must be called with mu held.
+func (r *Rolog) sync() error {
+	if err := r.drain(); err != nil {
+		return err
+	}
+	r.unsynced = 0
+	r.lastSync = r.now()
+	return r.f.Sync()
+}
@@ -0,0 +1,317 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestSyncPolicies(t *testing.T) {
+	cases := []struct {
+		policy SyncPolicy
+		want   int
+	}{
+		{SyncEveryWrite, 5},
+		{SyncEveryBytes(10), 1},
+		{SyncOnRotate, 0},
+	}
+
+	for _, c := range cases {
+		dir, err := ioutil.TempDir(".", "tmp")
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+
+		fs := &syncFS{}
+		r, err := New(dir, "test", WithFS(fs), WithSyncPolicy(c.policy))
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+
+		for i := 0; i < 5; i++ {
+			r.Write([]byte("abc\n"))
+		}
+
+		if fs.syncs != c.want {
+			t.Errorf("Wanted %d syncs for %+v, got %d", c.want, c.policy, fs.syncs)
+		}
+
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}
+}
+
+func TestFlushSyncsOnDemand(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	fs := &syncFS{}
+	r, err := New(dir, "test", WithFS(fs), WithSyncPolicy(SyncOnRotate))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("abc\n"))
+	if err := r.Flush(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+	}
+
+	if fs.syncs != 1 {
+		t.Errorf("Wanted 1 sync, got %d", fs.syncs)
+	}
+}
+
+func TestSyncEveryWriteGroupsConcurrentWriters(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	fs := &syncFS{delay: 20 * time.Millisecond}
+	r, err := New(dir, "test", WithFS(fs))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := r.Write([]byte("abc\n")); err != nil {
+				t.Errorf("unexpected error: %q", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	r.mu.Lock()
+	seq, syncs := r.seq, fs.syncs
+	r.mu.Unlock()
+
+	if r.synced != seq {
+		t.Errorf("Wanted every write synced, got %d of %d", r.synced, seq)
+	}
+	if syncs >= writers {
+		t.Errorf("Wanted fewer than %d syncs, got %d", writers, syncs)
+	}
+}
+
+func TestSyncInBackground(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	fs := &syncFS{}
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithFS(fs), WithClock(clock), WithSyncPolicy(SyncInBackground(time.Second)))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Run(ctx)
+	for clock.numTickers() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	for i := 0; i < 5; i++ {
+		r.Write([]byte("abc\n"))
+	}
+
+	syncs := func() int {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return fs.syncs
+	}
+	if n := syncs(); n != 0 {
+		t.Errorf("Wanted no syncs on the write path, got %d", n)
+	}
+
+	clock.Advance(time.Second)
+	deadline := time.Now().Add(time.Second)
+	for syncs() != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := syncs(); n != 1 {
+		t.Errorf("Wanted 1 background sync, got %d", n)
+	}
+}
+
+// dirSyncFS wraps OSFS and counts directory syncs.
+type dirSyncFS struct {
+	OSFS
+	mu    sync.Mutex
+	syncs int
+}
+
+func (f *dirSyncFS) SyncDir(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.syncs++
+	return f.OSFS.SyncDir(name)
+}
+
+func (f *dirSyncFS) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.syncs
+}
+
+func TestWithDirSyncSyncsAfterRenamesAndCreates(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	var (
+		clock = newFakeClock()
+		fs    = &dirSyncFS{}
+	)
+	r, err := New(dir, "test", WithClock(clock), WithFS(fs), WithDirSync())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	if got := fs.count(); got != 1 {
+		t.Errorf("Wanted 1 directory sync after New, got %d", got)
+	}
+
+	clock.Advance(time.Hour)
+	if err := r.Rotate(); err != nil {
+		t.Errorf("could not rotate: %q", err)
+		t.FailNow()
+	}
+
+	if got := fs.count(); got != 3 {
+		t.Errorf("Wanted 2 more directory syncs after rotating, got %d", got-1)
+	}
+}
+
+// failSyncFile is a File that can't be synced.
+type failSyncFile struct {
+	File
+}
+
+func (failSyncFile) Sync() error {
+	return errors.New("sync failed")
+}
+
+func TestCommitsWaitForTheRetiredFile(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	for _, fail := range []bool{false, true} {
+		old, err := OSFS{}.OpenFile(filepath.Join(dir, "old.log"), os.O_WRONLY|os.O_CREATE, 0644)
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		if fail {
+			old = failSyncFile{old}
+		}
+
+		// A write to the old file, awaiting its commit as the file is
+		// swapped out.
+		r.mu.Lock()
+		r.seq++
+		seq := r.seq
+		r.mu.Unlock()
+
+		r.holdCommits()
+		done := make(chan error, 1)
+		go func() { done <- r.commit(seq) }()
+
+		select {
+		case err := <-done:
+			t.Errorf("Wanted the commit to wait for the old file, got %v", err)
+			t.FailNow()
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		rerr := r.retire(old, seq)
+		select {
+		case err := <-done:
+			if (err != nil) != fail || (rerr != nil) != fail {
+				t.Errorf("Wanted failure %t, got %v committing and %v retiring", fail, err, rerr)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("Wanted the commit to finish once the old file was retired")
+			t.FailNow()
+		}
+	}
+
+	// Later writes are synced through the new file as usual.
+	if _, err := r.Write([]byte("after\n")); err != nil {
+		t.Errorf("unexpected error: %q", err)
+	}
+}
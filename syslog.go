@@ -0,0 +1,107 @@
+package rolog
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// localSyslogPaths are where the local syslog daemon listens on common
+// systems.
+var localSyslogPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// WithSyslog mirrors every line written to the current file to a syslog
+// server as an RFC 5424 message from appName, with the user facility and
+// informational severity. network is as for net.Dial, such as "udp", "tcp" or
+// "unixgram", and addr is the address of the server; if addr is empty the
+// local syslog daemon is used. Over TCP messages are framed by octet
+// counting.
+//
+// The mirror runs independently of the file: lines are queued and sent from
+// a goroutine of their own, so a down or slow server never blocks or fails a
+// write. Lines are discarded while the queue is full, and delivery failures are
+// reported on Err. WithSyslog may be given more than once.
+func WithSyslog(network, addr, appName string) Option {
+	return func(r *Rolog) {
+		r.addMirror("syslog", &syslogSink{
+			network: network,
+			addr:    addr,
+			enc:     &RFC5424Encoder{Facility: 1, Severity: 6, AppName: appName},
+		})
+	}
+}
+
+// syslogSink is the mirrorSink for WithSyslog.
+type syslogSink struct {
+	network, addr string
+	enc           *RFC5424Encoder
+
+	conn net.Conn
+	// stream is whether conn is a stream rather than datagrams, in which
+	// case messages must be framed
+	stream bool
+	buf    []byte
+}
+
+// send satisfies mirrorSink.
+func (s *syslogSink) send(line []byte, at time.Time) error {
+	if len(line) == 0 {
+		return nil
+	}
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+
+	msg := s.enc.Encode(s.buf[:0], line, at)
+	msg = msg[:len(msg)-1]
+
+	out := msg
+	switch {
+	case s.stream && strings.HasPrefix(s.network, "tcp"):
+		out = append(strconv.AppendInt(nil, int64(len(msg)), 10), ' ')
+		out = append(out, msg...)
+	case s.stream:
+		out = append(msg, '\n')
+	}
+	s.buf = msg[:0]
+
+	s.conn.SetWriteDeadline(time.Now().Add(mirrorTimeout))
+	_, err := s.conn.Write(out)
+	return err
+}
+
+// connect dials the server, or finds the local daemon.
+func (s *syslogSink) connect() error {
+	if s.addr != "" {
+		conn, err := net.DialTimeout(s.network, s.addr, mirrorTimeout)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+		s.stream = strings.HasPrefix(s.network, "tcp") || s.network == "unix"
+		return nil
+	}
+
+	var err error
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, path := range localSyslogPaths {
+			var conn net.Conn
+			if conn, err = net.DialTimeout(network, path, mirrorTimeout); err == nil {
+				s.conn, s.stream = conn, network == "unix"
+				return nil
+			}
+		}
+	}
+	return err
+}
+
+// reset satisfies mirrorSink.
+func (s *syslogSink) reset() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
@@ -0,0 +1,133 @@
+package rolog
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogMirrorsWrites(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer udp.Close()
+
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer tcp.Close()
+
+	r, err := New(dir, "test",
+		WithSyslog("udp", udp.LocalAddr().String(), "app"),
+		WithSyslog("tcp", tcp.Addr().String(), "app"))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("first\nsecond\n"))
+
+	msg := regexp.MustCompile(`^<14>1 \S+ \S+ app \d+ - - (first|second)$`)
+	udp.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1024)
+	for i := 0; i < 2; i++ {
+		n, _, err := udp.ReadFrom(buf)
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		if !msg.Match(buf[:n]) {
+			t.Errorf("Wanted an RFC 5424 datagram, got %q", buf[:n])
+		}
+	}
+
+	conn, err := tcp.Accept()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	br := bufio.NewReader(conn)
+	for i := 0; i < 2; i++ {
+		length, err := br.ReadString(' ')
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(length))
+		frame := make([]byte, n)
+		if _, err := br.Read(frame); err != nil || !msg.Match(frame) {
+			t.Errorf("Wanted an octet-counted message, got %q (%v)", frame, err)
+		}
+	}
+}
+
+func TestSyslogFailureDoesNotFailWrites(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	r, err := New(dir, "test", WithSyslog("tcp", addr, "app"))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	if _, err := r.Write([]byte("entry\n")); err != nil {
+		t.Errorf("Wanted the write to succeed, got %q", err)
+	}
+	select {
+	case err := <-r.Err():
+		if !strings.Contains(err.Error(), "could not write to syslog") {
+			t.Errorf("Wanted a syslog failure, got %q", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Wanted the failure to be reported")
+	}
+
+	got, _ := ioutil.ReadFile(r.CurrentPath())
+	if string(got) != "entry\n" {
+		t.Errorf("Wanted the file to be written, got %q", got)
+	}
+}
@@ -0,0 +1,85 @@
+package rolog
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WithSystemd integrates the Rolog with a systemd service through the
+// NOTIFY_SOCKET protocol of sd_notify. READY is sent once New succeeds and
+// STOPPING when the Rolog is closed, a STATUS line describes each completed
+// rotation, and if the unit sets WatchdogSec a WATCHDOG ping is sent from the
+// run loop at half that interval. Because the pings come from the same loop
+// that performs scheduled rotations, a rotation that wedges stops them and
+// systemd restarts the service.
+//
+// Outside of systemd, where NOTIFY_SOCKET isn't set, WithSystemd does nothing,
+// so the same configuration can be used everywhere. Failures to notify are
+// reported on Err.
+func WithSystemd() Option {
+	return func(r *Rolog) {
+		addr := os.Getenv("NOTIFY_SOCKET")
+		if addr == "" {
+			return
+		}
+		if addr[0] == '@' {
+			// an abstract socket
+			addr = "\x00" + addr[1:]
+		}
+		r.systemd = &systemdNotifier{addr: addr, watchdog: watchdogInterval()}
+	}
+}
+
+// watchdogInterval returns how often the watchdog should be pinged, which is
+// half of the timeout systemd gave this process, or zero if the watchdog is
+// disabled or meant for another process.
+func watchdogInterval() time.Duration {
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond / 2
+}
+
+// systemdNotifier sends the notifications enabled by WithSystemd.
+type systemdNotifier struct {
+	addr string
+	// watchdog is how often the run loop pings the watchdog, or zero
+	watchdog time.Duration
+}
+
+// sdNotify sends state to the service manager if WithSystemd is enabled,
+// reporting any failure.
+func (r *Rolog) sdNotify(state string) {
+	n := r.systemd
+	if n == nil {
+		return
+	}
+
+	conn, err := net.DialTimeout("unixgram", n.addr, mirrorTimeout)
+	if err == nil {
+		conn.SetWriteDeadline(time.Now().Add(mirrorTimeout))
+		_, err = conn.Write([]byte(state))
+		conn.Close()
+	}
+	if err != nil {
+		r.report(errors.Wrap(err, "could not notify systemd"))
+	}
+}
+
+// sdStatus sends a STATUS line describing a completed rotation.
+func (r *Rolog) sdStatus(e RotationEvent) {
+	if r.systemd == nil {
+		return
+	}
+	r.sdNotify(fmt.Sprintf("STATUS=Last rotated %s at %s (%s, %d bytes)",
+		r.name, r.now().Format(time.RFC3339), e.Reason, e.Bytes))
+}
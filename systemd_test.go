@@ -0,0 +1,136 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSystemdNotifies(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	sock := filepath.Join(dir, "notify.sock")
+	manager, err := net.ListenPacket("unixgram", sock)
+	if err != nil {
+		t.Skipf("unix datagram sockets unavailable: %v", err)
+	}
+	defer manager.Close()
+
+	vars := map[string]string{
+		"NOTIFY_SOCKET": sock,
+		"WATCHDOG_USEC": "20000",
+	}
+	for k, v := range vars {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	r, err := New(dir, "test", WithSystemd())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	buf := make([]byte, 1024)
+	next := func() string {
+		manager.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := manager.ReadFrom(buf)
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		return string(buf[:n])
+	}
+
+	if got := next(); got != "READY=1" {
+		t.Errorf("Wanted READY=1, got %q", got)
+	}
+
+	r.Write([]byte("hello\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if got := next(); !strings.HasPrefix(got, "STATUS=Last rotated test at ") || !strings.HasSuffix(got, "(manual, 6 bytes)") {
+		t.Errorf("Wanted a STATUS line for the rotation, got %q", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Run(ctx)
+	if got := next(); got != "WATCHDOG=1" {
+		t.Errorf("Wanted WATCHDOG=1, got %q", got)
+	}
+}
+
+func TestSystemdDisabledOutsideSystemd(t *testing.T) {
+	os.Unsetenv("NOTIFY_SOCKET")
+
+	r := newRolog("test", []Option{WithSystemd()})
+	if r.systemd != nil {
+		t.Errorf("Wanted no notifier without NOTIFY_SOCKET, got %+v", r.systemd)
+	}
+}
+
+func TestSystemdIgnoresChildren(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	sock := filepath.Join(dir, "notify.sock")
+	manager, err := net.ListenPacket("unixgram", sock)
+	if err != nil {
+		t.Skipf("unix datagram sockets unavailable: %v", err)
+	}
+	defer manager.Close()
+
+	os.Setenv("NOTIFY_SOCKET", sock)
+	defer os.Unsetenv("NOTIFY_SOCKET")
+
+	r, err := New(dir, "test", WithSystemd())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	buf := make([]byte, 1024)
+	manager.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if n, _, err := manager.ReadFrom(buf); err != nil || string(buf[:n]) != "READY=1" {
+		t.Errorf("Wanted READY=1, got %q, %v", buf[:n], err)
+	}
+
+	c, err := r.Child("child")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	c.Close()
+
+	manager.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if n, _, err := manager.ReadFrom(buf); err == nil {
+		t.Errorf("Wanted nothing from the child, got %q", buf[:n])
+	}
+}
@@ -0,0 +1,43 @@
+package rolog
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// WithTee copies every write to w as well as the current file, e.g. os.Stderr
+// under systemd or in a container. The copy sees the same bytes as the file,
+// including any prefix. A failing tee never fails the write; its errors are
+// delivered on Err instead. WithTee may be given more than once.
+func WithTee(w io.Writer) Option {
+	return func(r *Rolog) {
+		if w != nil {
+			r.tees = append(r.tees, w)
+		}
+	}
+}
+
+// AddTee is the runtime equivalent of WithTee, for writers that need the Rolog
+// in order to be built.
+func (r *Rolog) AddTee(w io.Writer) {
+	if w == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tees = append(r.tees[:len(r.tees):len(r.tees)], w)
+}
+
+// tee copies p to every configured tee and watcher. It must be called with mu
+// held.
+func (r *Rolog) tee(p []byte) {
+	for _, w := range r.tees {
+		if _, err := w.Write(p); err != nil {
+			r.report(errors.Wrap(err, "could not write to tee"))
+		}
+	}
+	r.broadcast(p)
+}
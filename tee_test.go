@@ -0,0 +1,68 @@
+package rolog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestWithTeeCopiesWrites(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	var buf bytes.Buffer
+	r, err := New(dir, "test", WithTee(&buf), WithPrefix("> "))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r.Write([]byte("hello\n"))
+	r.Rotate()
+	r.Write([]byte("world\n"))
+
+	if want := "> hello\n> world\n"; buf.String() != want {
+		t.Errorf("Wanted %q, got %q", want, buf.String())
+	}
+}
+
+func TestAddTeeCopiesLaterWrites(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := New(dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	var buf bytes.Buffer
+	r.Write([]byte("before\n"))
+	r.AddTee(&buf)
+	r.Write([]byte("after\n"))
+
+	if want := "after\n"; buf.String() != want {
+		t.Errorf("Wanted %q, got %q", want, buf.String())
+	}
+}
@@ -0,0 +1,82 @@
+package rolog
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// TimeoutError is returned by Write when it takes longer than the timeout set
+// with WithWriteTimeout.
+type TimeoutError struct {
+	// After is the timeout that was exceeded.
+	After time.Duration
+}
+
+// Error satisfies error.
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("write timed out after %s", e.After)
+}
+
+// Timeout reports that the error is a timeout, as net.Error does.
+func (e *TimeoutError) Timeout() bool { return true }
+
+// WithWriteTimeout bounds how long Write may block, whether waiting behind a
+// rotation or on the disk itself, such as a stuck NFS mount. On timeout Write
+// returns a *TimeoutError, but the write carries on in the background and may
+// still land in the file later. Until it has, further writes fail with a
+// *TimeoutError straight away rather than piling up behind it. A non-positive
+// d disables the timeout.
+func WithWriteTimeout(d time.Duration) Option {
+	return func(r *Rolog) {
+		r.writeTimeout = d
+	}
+}
+
+// The states of a write under writeWithin.
+const (
+	writePending int32 = iota
+	writeFinished
+	writeAbandoned
+)
+
+// writeWithin dispatches a copy of p, giving up after d. It fails at once
+// while a write that timed out earlier is still stuck.
+func (r *Rolog) writeWithin(p []byte, d time.Duration) (int, error) {
+	if atomic.LoadInt32(&r.stuckWrites) > 0 {
+		return 0, &TimeoutError{After: d}
+	}
+
+	type result struct {
+		n   int
+		err error
+	}
+
+	var (
+		entry = append([]byte(nil), p...)
+		done  = make(chan result, 1)
+		state = writePending
+	)
+	go func() {
+		n, err := r.dispatch(entry)
+		if !atomic.CompareAndSwapInt32(&state, writePending, writeFinished) {
+			atomic.AddInt32(&r.stuckWrites, -1)
+		}
+		done <- result{n, err}
+	}()
+
+	t := r.clock.NewTicker(d)
+	defer t.Stop()
+
+	select {
+	case res := <-done:
+		return res.n, res.err
+	case <-t.C():
+		atomic.AddInt32(&r.stuckWrites, 1)
+		if !atomic.CompareAndSwapInt32(&state, writePending, writeAbandoned) {
+			// finished just as the timeout fired
+			atomic.AddInt32(&r.stuckWrites, -1)
+		}
+		return 0, &TimeoutError{After: d}
+	}
+}
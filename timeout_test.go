@@ -0,0 +1,87 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWriteTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	fs := newGateFS()
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithFS(fs), WithClock(clock), WithWriteTimeout(time.Second))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	released := false
+	defer func() {
+		if !released {
+			close(fs.release)
+		}
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	result := make(chan error)
+	go func() {
+		_, err := r.Write([]byte("stuck\n"))
+		result <- err
+	}()
+
+	<-fs.entered
+	for clock.numTickers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Second)
+
+	select {
+	case err := <-result:
+		te, ok := err.(*TimeoutError)
+		if !ok || !te.Timeout() || te.After != time.Second {
+			t.Errorf("Wanted a *TimeoutError after 1s, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Wanted Write to give up once the timeout passed")
+	}
+
+	// Later writes fail at once while the first is stuck.
+	quick := make(chan error)
+	go func() {
+		_, err := r.Write([]byte("next\n"))
+		quick <- err
+	}()
+	select {
+	case err := <-quick:
+		if _, ok := err.(*TimeoutError); !ok {
+			t.Errorf("Wanted a *TimeoutError while a write is stuck, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Wanted Write to fail at once while a write is stuck")
+	}
+
+	// Once it lands, writes go through again.
+	close(fs.release)
+	released = true
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := r.Write([]byte("after\n")); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Errorf("Wanted writes to recover once the stuck write finished")
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,130 @@
+package rolog
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// topicLog is the log of a topic and when it was last written.
+type topicLog struct {
+	r    *Rolog
+	last time.Time
+}
+
+// WithTopicIdle closes the log of a topic once nothing has been written to it
+// through WriteTopic for d, freeing its file handle until it's next written.
+// Idle topics are looked for by the run loop, so this has no effect unless Run
+// has been called. The default of zero keeps topic logs open until the Rolog
+// is closed.
+func WithTopicIdle(d time.Duration) Option {
+	return func(r *Rolog) {
+		r.topicIdle = d
+	}
+}
+
+// WriteTopic writes p to the log for topic, creating it as a Child named after
+// r and the topic, such as app-job42.log, on first use. This suits streams
+// whose names are only known at runtime, such as one per job or customer. The
+// topic logs rotate with r and are closed along with it, and one closed while
+// idle carries on with its existing file when next written. Topics must be
+// usable as part of a filename, so can't contain path separators.
+func (r *Rolog) WriteTopic(topic string, p []byte) (int, error) {
+	if topic == "" || topic == "." || topic == ".." || strings.ContainsAny(topic, `/\`) {
+		return 0, errors.Errorf("invalid topic %q", topic)
+	}
+
+	for {
+		c, err := r.topic(topic)
+		if err != nil {
+			return 0, err
+		}
+		n, err := c.Write(p)
+		if err == ErrClosed && !r.closed() {
+			// closed while idle between finding it and writing
+			continue
+		}
+		return n, err
+	}
+}
+
+// Topics returns the topics whose logs are open, sorted.
+func (r *Rolog) Topics() []string {
+	r.topicMu.Lock()
+	defer r.topicMu.Unlock()
+
+	topics := make([]string, 0, len(r.topics))
+	for topic := range r.topics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// topic returns the log for topic, opening it if need be, and marks it as
+// just used.
+func (r *Rolog) topic(topic string) (*Rolog, error) {
+	r.topicMu.Lock()
+	defer r.topicMu.Unlock()
+
+	if r.closed() {
+		return nil, ErrClosed
+	}
+
+	t, ok := r.topics[topic]
+	if !ok {
+		// A topic reopened after going idle carries on with its file.
+		c, err := r.child(r.name+"-"+topic, WithAppend())
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not open log for topic %s", topic)
+		}
+		if r.topics == nil {
+			r.topics = make(map[string]*topicLog)
+		}
+		t = &topicLog{r: c}
+		r.topics[topic] = t
+	}
+	t.last = r.now()
+	return t.r, nil
+}
+
+// closeIdleTopics closes the logs of the topics that haven't been written for
+// the idle period.
+func (r *Rolog) closeIdleTopics() {
+	now := r.now()
+
+	r.topicMu.Lock()
+	var idle []*Rolog
+	for topic, t := range r.topics {
+		if now.Sub(t.last) >= r.topicIdle {
+			idle = append(idle, t.r)
+			delete(r.topics, topic)
+		}
+	}
+	r.topicMu.Unlock()
+
+	for _, c := range idle {
+		r.debugf("closing idle topic log %s", c.name)
+		if err := c.Close(); err != nil {
+			r.report(errors.Wrapf(err, "could not close idle topic log %s", c.name))
+		}
+	}
+}
+
+// closeTopics closes every topic log, returning the first error.
+func (r *Rolog) closeTopics() error {
+	r.topicMu.Lock()
+	topics := r.topics
+	r.topics = nil
+	r.topicMu.Unlock()
+
+	var first error
+	for topic, t := range topics {
+		if err := t.r.Close(); err != nil && first == nil {
+			first = errors.Wrapf(err, "could not close log for topic %s", topic)
+		}
+	}
+	return first
+}
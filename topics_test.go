@@ -0,0 +1,85 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteTopicOpensAndClosesLogs(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	clock := newFakeClock()
+	r, err := New(dir, "app", WithClock(clock), WithInterval(time.Hour), WithTopicIdle(time.Minute))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Run(ctx)
+	for clock.numTickers() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := r.WriteTopic("../escape", []byte("no\n")); err == nil {
+		t.Errorf("Wanted a topic with a path separator to be rejected")
+	}
+	r.WriteTopic("job1", []byte("one\n"))
+	r.WriteTopic("job2", []byte("two\n"))
+	if topics := r.Topics(); len(topics) != 2 || topics[0] != "job1" || topics[1] != "job2" {
+		t.Errorf("Wanted topics [job1 job2], got %v", topics)
+	}
+
+	// Writing job2 again keeps it open while job1 goes idle.
+	clock.Advance(30 * time.Second)
+	r.WriteTopic("job2", []byte("two\n"))
+	clock.Advance(30 * time.Second)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(r.Topics()) != 1 {
+		if time.Now().After(deadline) {
+			t.Errorf("Wanted job1 closed while idle, got %v", r.Topics())
+			t.FailNow()
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if topics := r.Topics(); topics[0] != "job2" {
+		t.Errorf("Wanted job2 kept open, got %v", topics)
+	}
+
+	r.WriteTopic("job1", []byte("again\n"))
+	if err := r.Close(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+	}
+	if len(r.Topics()) != 0 {
+		t.Errorf("Wanted the topics closed with the log")
+	}
+
+	for name, want := range map[string]string{
+		"app-job1.log": "one\nagain\n",
+		"app-job2.log": "two\ntwo\n",
+	} {
+		got, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("Wanted %q in %s, got %q", want, name, got)
+		}
+	}
+}
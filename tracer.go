@@ -0,0 +1,57 @@
+package rolog
+
+import (
+	"path/filepath"
+	"time"
+)
+
+// Operations passed to a Tracer.
+const (
+	// OpRotate covers a whole rotation, from the swap through processing
+	// the archive.
+	OpRotate = "rotate"
+	// OpCompress covers compressing an archive.
+	OpCompress = "compress"
+	// OpEncrypt covers encrypting an archive.
+	OpEncrypt = "encrypt"
+	// OpPrune covers applying retention after a rotation.
+	OpPrune = "prune"
+)
+
+// SpanInfo describes the subject of an operation given to a Tracer.
+type SpanInfo struct {
+	// Name is the base name of the log files, and Dir their directory.
+	Name, Dir string
+	// Reason is what triggered the rotation, for OpRotate.
+	Reason RotationReason
+	// Path is the archive being worked on, if any.
+	Path string
+}
+
+// Tracer is told about the Rolog's units of work once they finish, so that
+// they can be recorded as spans in a tracing system; see the otelrolog
+// package. It is called from whichever goroutine did the work and must be safe
+// for concurrent use.
+type Tracer interface {
+	// Span records op, described by info, which ran from start to end and
+	// failed with err, if it isn't nil.
+	Span(op string, info SpanInfo, start, end time.Time, err error)
+}
+
+// WithTracer reports the Rolog's rotations, compression, encryption and pruning
+// to t.
+func WithTracer(t Tracer) Option {
+	return func(r *Rolog) {
+		r.tracer = t
+	}
+}
+
+// trace records op on the Tracer, if there is one, as having run from start
+// until now.
+func (r *Rolog) trace(op string, info SpanInfo, start time.Time, err error) {
+	if r.tracer == nil {
+		return
+	}
+	info.Name, info.Dir = r.name, filepath.Dir(r.currentPath())
+	r.tracer.Span(op, info, start, r.now(), err)
+}
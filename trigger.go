@@ -0,0 +1,61 @@
+package rolog
+
+import (
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// triggerPoll is how often a running Rolog looks for its trigger file.
+const triggerPoll = time.Second
+
+// WithTriggerFile makes a running Rolog rotate whenever a file appears at path,
+// removing it first, so that scripts and the rolog command can request a
+// rotation by creating it. The path is checked every second.
+func WithTriggerFile(path string) Option {
+	return func(r *Rolog) {
+		r.triggerFile = path
+	}
+}
+
+// WithRotateSignal makes a running Rolog rotate whenever the process receives
+// one of sigs, such as the SIGHUP that logrotate's postrotate scripts
+// traditionally send. The signals are only caught while the Rolog is running.
+func WithRotateSignal(sigs ...os.Signal) Option {
+	return func(r *Rolog) {
+		r.rotateSignals = append(r.rotateSignals, sigs...)
+	}
+}
+
+// checkTrigger rotates if the trigger file exists, removing it first so that
+// each one requests a single rotation.
+func (s *scheduler) checkTrigger() {
+	path := s.r.triggerFile
+	if _, err := s.r.fs.Stat(path); err != nil {
+		if !os.IsNotExist(err) {
+			s.r.report(errors.Wrap(err, "could not check trigger file"))
+		}
+		return
+	}
+	if err := s.r.fs.Remove(path); err != nil {
+		s.r.report(errors.Wrap(err, "could not remove trigger file"))
+		return
+	}
+
+	s.r.debugf("trigger file %s found", path)
+	s.rotateOnRequest()
+}
+
+// rotateOnSignal rotates on receipt of a rotate signal.
+func (s *scheduler) rotateOnSignal(sig os.Signal) {
+	s.r.debugf("received %s", sig)
+	s.rotateOnRequest()
+}
+
+// rotateOnRequest performs a rotation requested from outside the process.
+func (s *scheduler) rotateOnRequest() {
+	if err := s.r.Rotate(); err != nil && err != ErrClosed {
+		s.r.report(err)
+	}
+}
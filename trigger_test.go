@@ -0,0 +1,66 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTriggerFileRotates(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clk := newFakeClock()
+	trigger := filepath.Join(dir, "rotate")
+	r, err := New(dir, "test", WithClock(clk), WithTriggerFile(trigger))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Run(ctx)
+	for clk.numTickers() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	clk.Advance(triggerPoll)
+	select {
+	case e := <-r.Notify():
+		t.Errorf("Wanted no rotation without the trigger file, got %+v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := ioutil.WriteFile(trigger, nil, 0644); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	clk.Advance(triggerPoll)
+
+	select {
+	case e := <-r.Notify():
+		if e.Reason != ReasonManual {
+			t.Errorf("Wanted a manual rotation, got %s", e.Reason)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Wanted a rotation after the trigger file appeared")
+		t.FailNow()
+	}
+	if _, err := os.Stat(trigger); !os.IsNotExist(err) {
+		t.Errorf("Wanted the trigger file removed, got %v", err)
+	}
+}
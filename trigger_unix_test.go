@@ -0,0 +1,57 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRotateSignalRotates(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	clk := newFakeClock()
+	r, err := New(dir, "test", WithClock(clk), WithRotateSignal(syscall.SIGUSR1))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Run(ctx)
+	// the signal is caught before the rotation ticker is started
+	for clk.numTickers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	select {
+	case e := <-r.Notify():
+		if e.Reason != ReasonManual {
+			t.Errorf("Wanted a manual rotation, got %s", e.Reason)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Wanted a rotation after the signal")
+	}
+}
@@ -0,0 +1,129 @@
+package rolog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Sentinel causes of a ConfigError, for comparison with errors.Cause.
+var (
+	ErrEmptyDir           = errors.New("dir must not be empty")
+	ErrEmptyName          = errors.New("name must not be empty")
+	ErrInvalidInterval    = errors.New("interval must be positive unless a max size is set")
+	ErrInvalidSize        = errors.New("size must not be negative")
+	ErrInvalidRetention   = errors.New("retention must keep at least one archive")
+	ErrInvalidAge         = errors.New("age must not be negative")
+	ErrInvalidTemplate    = errors.New("filename template must contain exactly one %s")
+	ErrInvalidArchiveName = errors.New("archive filename template must contain one %s followed by a timestamp after any variables")
+	ErrInvalidKeep        = errors.New("circular keep must not be negative and must be less than the max size")
+	ErrDirNotWritable     = errors.New("dir is not a writable directory")
+	ErrInvalidCompression = errors.New("compression must be gzip, zstd or empty")
+)
+
+// ConfigError reports a configuration that New or Config.Validate refused.
+type ConfigError struct {
+	// Field names the offending setting.
+	Field string
+	// Err is one of the sentinel errors above.
+	Err error
+	// Detail is the underlying error, if any, such as the filesystem error
+	// behind ErrDirNotWritable.
+	Detail error
+}
+
+// Error satisfies error.
+func (e *ConfigError) Error() string {
+	msg := "invalid " + e.Field + ": " + e.Err.Error()
+	if e.Detail != nil {
+		msg += ": " + e.Detail.Error()
+	}
+	return msg
+}
+
+// Cause returns the sentinel error for use with errors.Cause.
+func (e *ConfigError) Cause() error {
+	return e.Err
+}
+
+// Unwrap returns the sentinel error for use with the standard errors package.
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// invalid records a configuration error found while applying an Option. Only
+// the first one is kept.
+func (r *Rolog) invalid(field string, err error) {
+	if r.optErr == nil {
+		r.optErr = &ConfigError{Field: field, Err: err}
+	}
+}
+
+// validate rejects nonsensical settings before any file is touched.
+func (r *Rolog) validate(dir string) error {
+	switch {
+	case dir == "":
+		return &ConfigError{Field: "Dir", Err: ErrEmptyDir}
+	case r.name == "":
+		return &ConfigError{Field: "Name", Err: ErrEmptyName}
+	case r.optErr != nil:
+		return r.optErr
+	case r.interval <= 0 && r.maxSize <= 0:
+		return &ConfigError{Field: "Interval", Err: ErrInvalidInterval}
+	case r.maxSize < 0:
+		return &ConfigError{Field: "MaxSize", Err: ErrInvalidSize}
+	case r.maxTotalSize < 0:
+		return &ConfigError{Field: "MaxTotalSize", Err: ErrInvalidSize}
+	case !r.validKeep():
+		return &ConfigError{Field: "CircularKeep", Err: ErrInvalidKeep}
+	case r.maxBackups < 0:
+		return &ConfigError{Field: "MaxBackups", Err: ErrInvalidRetention}
+	case r.maxAge < 0:
+		return &ConfigError{Field: "MaxAge", Err: ErrInvalidAge}
+	case r.bundleAge < 0:
+		return &ConfigError{Field: "BundleAge", Err: ErrInvalidAge}
+	case r.currentName != "" && strings.Count(r.currentName, "%s") != 1:
+		return &ConfigError{Field: "CurrentFilename", Err: ErrInvalidTemplate}
+	case r.liveCompression != NoCompression && r.compression != NoCompression:
+		return &ConfigError{Field: "Compression", Err: ErrLiveCompression}
+	case r.liveCompression != NoCompression && truncates(r.strategy):
+		return &ConfigError{Field: "RotationStrategy", Err: ErrLiveTruncate}
+	case r.archiveName != "" && !r.validArchiveLayout():
+		return &ConfigError{Field: "ArchiveFilename", Err: ErrInvalidArchiveName}
+	case r.currentLink && !supportsLinks(r.fs):
+		return &ConfigError{Field: "CurrentLink", Err: ErrNoSymlinks}
+	}
+	if r.passthrough != nil {
+		return nil
+	}
+
+	return r.checkDir(dir)
+}
+
+// checkDir confirms that dir is a directory we can create files in, creating it
+// first if need be.
+func (r *Rolog) checkDir(dir string) error {
+	if err := r.mkdir(dir); err != nil {
+		return &ConfigError{Field: "Dir", Err: ErrDirNotWritable, Detail: err}
+	}
+
+	fi, err := r.fs.Stat(dir)
+	if err != nil {
+		return &ConfigError{Field: "Dir", Err: ErrDirNotWritable, Detail: err}
+	}
+	if !fi.IsDir() {
+		return &ConfigError{Field: "Dir", Err: ErrDirNotWritable}
+	}
+
+	probe := filepath.Join(dir, "."+r.name+".probe")
+	f, err := r.fs.OpenFile(probe, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return &ConfigError{Field: "Dir", Err: ErrDirNotWritable, Detail: err}
+	}
+	f.Close()
+	r.fs.Remove(probe)
+
+	return nil
+}
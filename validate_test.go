@@ -0,0 +1,56 @@
+package rolog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		dir  string
+		name string
+		opts []Option
+		want error
+	}{
+		{"", "test", nil, ErrEmptyDir},
+		{dir, "", nil, ErrEmptyName},
+		{dir, "test", []Option{WithInterval(0)}, ErrInvalidInterval},
+		{dir, "test", []Option{WithMaxSize(-1)}, ErrInvalidSize},
+		{dir, "test", []Option{WithMaxBackups(0)}, ErrInvalidRetention},
+		{dir, "test", []Option{WithMaxAge(-time.Hour)}, ErrInvalidAge},
+		{dir, "test", []Option{WithCurrentFilename("current.log")}, ErrInvalidTemplate},
+		{filepath.Join(dir, "missing"), "test", []Option{WithoutMkdir()}, ErrDirNotWritable},
+	}
+
+	for _, c := range cases {
+		_, err := New(c.dir, c.name, c.opts...)
+		if _, ok := err.(*ConfigError); !ok {
+			t.Errorf("Wanted a *ConfigError for %v, got %T", c.want, err)
+			continue
+		}
+		if errors.Cause(err) != c.want {
+			t.Errorf("Wanted %v, got %v", c.want, err)
+		}
+	}
+
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(fis) != 0 {
+		t.Errorf("Wanted no files to be created, found %d", len(fis))
+	}
+}
@@ -0,0 +1,304 @@
+package rolog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// VerifyProblem is a kind of problem Verify finds with the archives of a log.
+type VerifyProblem string
+
+// The problems Verify reports.
+const (
+	// ProblemCorrupt is an archive that can't be read back, such as a
+	// compressed one that fails its checksum or was cut short.
+	ProblemCorrupt VerifyProblem = "corrupt"
+	// ProblemMissing is an archive that the rotation records name but that
+	// isn't there, leaving a gap in the sequence.
+	ProblemMissing VerifyProblem = "missing"
+	// ProblemName is an archive whose name has extensions the Rolog doesn't
+	// add, such as one left behind by an interrupted copy.
+	ProblemName VerifyProblem = "name"
+)
+
+// ArchiveProblem is a problem found with one archive.
+type ArchiveProblem struct {
+	Problem VerifyProblem
+	// Path is the archive, or where a missing one would be.
+	Path string
+	// Detail explains the problem, such as the error reading the archive.
+	Detail string
+}
+
+// VerifyReport is the outcome of a Verify.
+type VerifyReport struct {
+	// Verified are the archives that were read back without any problem,
+	// oldest first.
+	Verified []string
+	// Skipped are the encrypted archives that can't be checked, not being
+	// in the manifest.
+	Skipped []string
+	// Problems are what was found wrong, in the order the archives were
+	// checked.
+	Problems []ArchiveProblem
+}
+
+// OK reports whether Verify found no problems.
+func (v VerifyReport) OK() bool {
+	return len(v.Problems) == 0
+}
+
+// Verify checks the archives of the log as they are now. Each one is read back
+// to the end, which checks the checksums of compressed archives, and one that
+// WithManifest recorded must still have the digest in the manifest. Its name
+// must parse as one of the log's archives with only the extensions that
+// compression, encryption and same-second rotations add. Under
+// WithRotationRecords, the start record of each archive and of the current
+// file must name an archive that is still there, so gaps in the sequence
+// show up; the predecessor of the oldest archive is taken to have gone to
+// retention. Encrypted archives can't be read back, so those missing from
+// the manifest are skipped.
+//
+// Problems are returned in the report rather than as an error, which is kept
+// for failing to list the archives, and ctx's error if ctx is cancelled
+// first. Archives processed away while Verify runs are passed over.
+func (r *Rolog) Verify(ctx context.Context) (VerifyReport, error) {
+	if r.passthrough != nil {
+		return VerifyReport{}, nil
+	}
+	r.mu.Lock()
+	s := r.archiveSettings()
+	current := r.currentPath()
+	r.mu.Unlock()
+
+	return r.verify(ctx, s, current)
+}
+
+// VerifyArchives verifies the archives of the log named name in dir, as Verify
+// would for a Rolog created with the same arguments, but without opening the
+// log, for tools that check logs written by another process. As with
+// ListArchives, only the naming options affect the result.
+func VerifyArchives(ctx context.Context, dir, name string, opts ...Option) (VerifyReport, error) {
+	r := newRolog(name, opts)
+	r.setPath(filepath.Join(dir, fmt.Sprintf(r.currentFormat(), name)))
+	return r.verify(ctx, r.archiveSettings(), r.currentPath())
+}
+
+// verify verifies the archives of the log under s, whose current file is at
+// current.
+func (r *Rolog) verify(ctx context.Context, s archiveSettings, current string) (VerifyReport, error) {
+	archives, err := r.archives()
+	if err != nil {
+		return VerifyReport{}, err
+	}
+
+	var (
+		report VerifyReport
+		names  = make(map[string]bool, len(archives))
+	)
+	for _, a := range archives {
+		names[rotatedName(a.Path, s)] = true
+	}
+	// gap reports a missing archive if prev, the name in a start record,
+	// isn't one of them.
+	gap := func(prev, in string) {
+		if prev != "" && !names[prev] {
+			report.Problems = append(report.Problems, ArchiveProblem{
+				Problem: ProblemMissing,
+				Path:    filepath.Join(filepath.Dir(current), prev),
+				Detail:  fmt.Sprintf("named by the start record of %s", in),
+			})
+		}
+	}
+
+	// digests are the manifests of the archive directories, read as needed.
+	digests := make(map[string]map[string]string)
+	digest := func(a ArchiveInfo) (string, error) {
+		dir := filepath.Dir(a.Path)
+		if digests[dir] == nil {
+			m, err := r.readManifest(dir)
+			if err != nil {
+				return "", err
+			}
+			digests[dir] = m
+		}
+		return digests[dir][filepath.Base(a.Path)], nil
+	}
+
+	for i, a := range archives {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		want, err := digest(a)
+		if err != nil {
+			return report, err
+		}
+
+		ok := true
+		if !archiveExt(a, s) {
+			ok = false
+			report.Problems = append(report.Problems, ArchiveProblem{
+				Problem: ProblemName,
+				Path:    a.Path,
+				Detail:  fmt.Sprintf("unexpected extension %q", a.ext),
+			})
+		}
+		if want != "" {
+			got, err := fileDigest(r.fs, a.Path)
+			switch {
+			case os.IsNotExist(errors.Cause(err)):
+				// processed since it was listed
+				continue
+			case err == nil && got != want:
+				err = errors.Errorf("digest %s doesn't match %s in the manifest", got, want)
+				fallthrough
+			case err != nil:
+				ok = false
+				report.Problems = append(report.Problems, ArchiveProblem{
+					Problem: ProblemCorrupt,
+					Path:    a.Path,
+					Detail:  err.Error(),
+				})
+			}
+		}
+		if encrypted(a.Path, s) {
+			switch {
+			case want == "":
+				report.Skipped = append(report.Skipped, a.Path)
+			case ok:
+				report.Verified = append(report.Verified, a.Path)
+			}
+			continue
+		}
+
+		prev, err := r.readBack(ctx, a)
+		switch {
+		case err == context.Canceled || err == context.DeadlineExceeded:
+			return report, err
+		case os.IsNotExist(errors.Cause(err)):
+			// processed since it was listed
+			continue
+		case err != nil:
+			ok = false
+			report.Problems = append(report.Problems, ArchiveProblem{
+				Problem: ProblemCorrupt,
+				Path:    a.Path,
+				Detail:  err.Error(),
+			})
+		}
+		if i > 0 {
+			gap(prev, a.Path)
+		}
+		if ok {
+			report.Verified = append(report.Verified, a.Path)
+		}
+	}
+
+	if len(archives) > 0 {
+		if f, err := r.fs.OpenFile(current, os.O_RDONLY, 0); err == nil {
+			rec, _ := startRecord(bufio.NewReader(f))
+			f.Close()
+			gap(rec.Archive, current)
+		}
+	}
+	return report, nil
+}
+
+// readBack reads the archive a to the end, returning the previous archive
+// named by its start record, if it has one.
+func (r *Rolog) readBack(ctx context.Context, a ArchiveInfo) (string, error) {
+	rc, err := OpenArchive(a)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	br := bufio.NewReader(ctxReader{ctx: ctx, r: rc})
+	rec, err := startRecord(br)
+	if err == nil {
+		_, err = io.Copy(ioutil.Discard, br)
+	}
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return "", err
+	}
+	return rec.Archive, errors.Wrap(err, "could not read archive")
+}
+
+// startRecord reads the first line from br, returning it as a RotationRecord if
+// it is a start record.
+func startRecord(br *bufio.Reader) (RotationRecord, error) {
+	var rec RotationRecord
+	line, err := br.ReadSlice('\n')
+	if err == bufio.ErrBufferFull || err == io.EOF {
+		// too long for a record, or the whole file
+		err = nil
+	}
+	if err != nil {
+		return rec, err
+	}
+	if json.Unmarshal(line, &rec) != nil || rec.Event != "start" {
+		return RotationRecord{}, nil
+	}
+	return rec, nil
+}
+
+// postRotationExts are the extensions that processing adds to an archive once
+// it has been rotated.
+var postRotationExts = []string{".gz", ".bz2", ".zst", ".age", ".gpg"}
+
+// rotatedName returns the name the archive at path was rotated out under,
+// before any extensions added by processing under s, as rotation records name
+// it.
+func rotatedName(path string, s archiveSettings) string {
+	name := filepath.Base(path)
+	for {
+		if e := encrypterExt(s.encrypter); e != "" && strings.HasSuffix(name, e) {
+			name = strings.TrimSuffix(name, e)
+			continue
+		}
+		ext := filepath.Ext(name)
+		known := false
+		for _, e := range postRotationExts {
+			known = known || ext == e
+		}
+		if !known {
+			return name
+		}
+		name = strings.TrimSuffix(name, ext)
+	}
+}
+
+// archiveExt reports whether the extensions of a are all ones that rotation
+// and processing under s add: the numeric suffix of a same-second rotation,
+// then those of compression and encryption.
+func archiveExt(a ArchiveInfo, s archiveSettings) bool {
+	processed := len(filepath.Base(a.Path)) - len(rotatedName(a.Path, s))
+	if processed > len(a.ext) {
+		return false
+	}
+	for _, part := range strings.Split(a.ext[:len(a.ext)-processed], ".")[1:] {
+		if part == "" || strings.Trim(part, "0123456789") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// encrypted reports whether the archive at path has been encrypted.
+func encrypted(path string, s archiveSettings) bool {
+	switch filepath.Ext(path) {
+	case ".age", ".gpg":
+		return true
+	}
+	e := encrypterExt(s.encrypter)
+	return e != "" && strings.HasSuffix(path, e)
+}
@@ -0,0 +1,210 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestVerifyFindsCorruptAndMissingArchives(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithRotationRecords())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	for i := 0; i < 3; i++ {
+		r.Write([]byte("hello\n"))
+		clock.Advance(time.Minute)
+		if err := r.Rotate(); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+	}
+
+	archives, err := r.Archives()
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(archives) != 3 {
+		t.Errorf("Wanted 3 archives, got %+v", archives)
+		t.FailNow()
+	}
+	report, err := r.Verify(context.Background())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if !report.OK() || len(report.Verified) != 3 {
+		t.Errorf("Wanted every archive verified, got %+v", report)
+	}
+
+	// The first is replaced by a broken gzip file, and the second is lost.
+	corrupt := archives[0].Path + ".gz"
+	if err := ioutil.WriteFile(corrupt, []byte("not gzip"), 0644); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	os.Remove(archives[0].Path)
+	os.Remove(archives[1].Path)
+
+	report, err = r.Verify(context.Background())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(report.Verified) != 1 || report.Verified[0] != archives[2].Path {
+		t.Errorf("Wanted only %s verified, got %+v", archives[2].Path, report.Verified)
+	}
+	want := []struct {
+		problem VerifyProblem
+		path    string
+	}{
+		{ProblemCorrupt, corrupt},
+		{ProblemMissing, archives[1].Path},
+	}
+	if len(report.Problems) != len(want) {
+		t.Errorf("Wanted %d problems, got %+v", len(want), report.Problems)
+		t.FailNow()
+	}
+	for i, w := range want {
+		if p := report.Problems[i]; p.Problem != w.problem || p.Path != w.path {
+			t.Errorf("Wanted problem %d to be %s %s, got %+v", i, w.problem, w.path, p)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.Verify(ctx); err != context.Canceled {
+		t.Errorf("Wanted context.Canceled, got %v", err)
+	}
+}
+
+func TestVerifyArchivesFlagsUnexpectedNames(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r, err := New(dir, "test", WithCompression(Gzip))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	r.Write([]byte("hello\n"))
+	if err := r.Rotate(); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	r.Close()
+
+	archives, err := ListArchives(dir, "test")
+	if err != nil || len(archives) != 1 {
+		t.Errorf("Wanted one archive, got %+v, %v", archives, err)
+		t.FailNow()
+	}
+	partial := filepath.Join(dir, filepath.Base(archives[0].Path)+".partial")
+	if err := ioutil.WriteFile(partial, []byte("hello\n"), 0644); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	report, err := VerifyArchives(context.Background(), dir, "test")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(report.Verified) != 1 || report.Verified[0] != archives[0].Path {
+		t.Errorf("Wanted %s verified, got %+v", archives[0].Path, report.Verified)
+	}
+	if len(report.Problems) != 1 || report.Problems[0].Problem != ProblemName || report.Problems[0].Path != partial {
+		t.Errorf("Wanted %s flagged for its name, got %+v", partial, report.Problems)
+	}
+}
+
+func TestVerifyChecksTheManifest(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	clock := newFakeClock()
+	r, err := New(dir, "test", WithClock(clock), WithManifest(), WithMaxBackups(2))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	for i := 0; i < 3; i++ {
+		r.Write([]byte("hello\n"))
+		clock.Advance(time.Minute)
+		if err := r.Rotate(); err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+	}
+
+	archives, err := r.Archives()
+	if err != nil || len(archives) != 2 {
+		t.Errorf("Wanted 2 archives, got %+v (%v)", archives, err)
+		t.FailNow()
+	}
+	digests, err := r.readManifest(dir)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	for _, a := range archives {
+		if digests[filepath.Base(a.Path)] == "" {
+			t.Errorf("Wanted %s in the manifest, got %v", a.Path, digests)
+		}
+	}
+
+	// A plaintext archive has nothing else to catch a flipped byte.
+	if err := ioutil.WriteFile(archives[0].Path, []byte("hellO\n"), 0644); err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	report, err := r.Verify(context.Background())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	if len(report.Problems) != 1 || report.Problems[0].Problem != ProblemCorrupt || report.Problems[0].Path != archives[0].Path {
+		t.Errorf("Wanted the changed archive found corrupt, got %+v", report.Problems)
+	}
+	if len(report.Verified) != 1 || report.Verified[0] != archives[1].Path {
+		t.Errorf("Wanted the other archive verified, got %+v", report.Verified)
+	}
+}
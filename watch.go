@@ -0,0 +1,112 @@
+package rolog
+
+import "context"
+
+// DefaultWatchBuffer is how many entries are held for each Watch channel,
+// unless changed with WithWatchBuffer.
+const DefaultWatchBuffer = 256
+
+// WatchPolicy says what happens to entries for a Watch channel that is full.
+type WatchPolicy int
+
+const (
+	// WatchDropNewest discards the entries that don't fit. It is the
+	// default.
+	WatchDropNewest WatchPolicy = iota
+	// WatchDropOldest discards the oldest entry held to make room.
+	WatchDropOldest
+	// WatchDisconnect closes the channel, so the watcher knows it fell
+	// behind and can start over.
+	WatchDisconnect
+)
+
+// watchSettings are the buffering of Watch channels.
+type watchSettings struct {
+	buffer int
+	policy WatchPolicy
+}
+
+// WithWatchBuffer sets how many entries are held for each Watch channel and
+// what happens to further entries while it is full. A non-positive size means
+// DefaultWatchBuffer.
+func WithWatchBuffer(size int, policy WatchPolicy) Option {
+	return func(r *Rolog) {
+		if size <= 0 {
+			size = DefaultWatchBuffer
+		}
+		r.watch = watchSettings{buffer: size, policy: policy}
+	}
+}
+
+// Watch returns a channel on which every entry written from now on is
+// delivered, for live dashboards and in-app log viewers that would otherwise
+// have to read the file back. An entry is the bytes of a single write as they
+// went to the file, and is shared between watchers, so it must not be
+// modified. Watchers never slow writes down: one that falls behind loses
+// entries as WithWatchBuffer says. The channel is closed once ctx is done or
+// the Rolog is closed.
+func (r *Rolog) Watch(ctx context.Context) (<-chan []byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed() {
+		return nil, ErrClosed
+	}
+
+	ch := make(chan []byte, r.watch.buffer)
+	if r.watchers == nil {
+		r.watchers = make(map[chan []byte]struct{})
+	}
+	r.watchers[ch] = struct{}{}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-r.done:
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.unwatch(ch)
+	}()
+	return ch, nil
+}
+
+// unwatch closes ch if it is still being watched. It must be called with mu
+// held.
+func (r *Rolog) unwatch(ch chan []byte) {
+	if _, ok := r.watchers[ch]; ok {
+		delete(r.watchers, ch)
+		close(ch)
+	}
+}
+
+// broadcast delivers a copy of p to every watcher. It must be called with mu
+// held.
+func (r *Rolog) broadcast(p []byte) {
+	if len(r.watchers) == 0 || len(p) == 0 {
+		return
+	}
+
+	entry := append([]byte(nil), p...)
+	for ch := range r.watchers {
+		select {
+		case ch <- entry:
+			continue
+		default:
+		}
+
+		switch r.watch.policy {
+		case WatchDropOldest:
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- entry:
+			default:
+			}
+		case WatchDisconnect:
+			r.unwatch(ch)
+		}
+	}
+}
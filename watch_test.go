@@ -0,0 +1,117 @@
+package rolog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWatchDeliversEntriesToEveryWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	r, err := New(dir, "test", WithPrefix("> "))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	defer r.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a, err := r.Watch(ctx)
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+	b, err := r.Watch(context.Background())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r.Write([]byte("hello\n"))
+	for _, ch := range []<-chan []byte{a, b} {
+		if got := string(<-ch); got != "> hello\n" {
+			t.Errorf("Wanted the entry as written, got %q", got)
+		}
+	}
+
+	cancel()
+	expectClosed(t, a)
+
+	r.Close()
+	expectClosed(t, b)
+	if _, err := r.Watch(context.Background()); err != ErrClosed {
+		t.Errorf("Wanted %v, got %v", ErrClosed, err)
+	}
+}
+
+func TestWatchSlowConsumerPolicies(t *testing.T) {
+	tests := []struct {
+		policy WatchPolicy
+		want   []string
+		closed bool
+	}{
+		{policy: WatchDropNewest, want: []string{"1\n", "2\n"}},
+		{policy: WatchDropOldest, want: []string{"3\n", "4\n"}},
+		{policy: WatchDisconnect, want: []string{"1\n", "2\n"}, closed: true},
+	}
+	for _, tt := range tests {
+		dir, err := ioutil.TempDir(".", "tmp")
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+
+		r, err := New(dir, "test", WithWatchBuffer(2, tt.policy))
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+		ch, err := r.Watch(context.Background())
+		if err != nil {
+			t.Errorf("unexpected error: %q", err)
+			t.FailNow()
+		}
+
+		for _, line := range []string{"1\n", "2\n", "3\n", "4\n"} {
+			r.Write([]byte(line))
+		}
+		for _, want := range tt.want {
+			if got := string(<-ch); got != want {
+				t.Errorf("Wanted %q under policy %d, got %q", want, tt.policy, got)
+			}
+		}
+		if tt.closed {
+			expectClosed(t, ch)
+		}
+
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}
+}
+
+// expectClosed fails t unless ch is closed, with nothing left to receive,
+// within a few seconds.
+func expectClosed(t *testing.T, ch <-chan []byte) {
+	select {
+	case p, ok := <-ch:
+		if ok {
+			t.Errorf("Wanted the channel closed, got %q", p)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Wanted the channel closed")
+	}
+}
@@ -0,0 +1,235 @@
+// Package webhook posts a Rolog's rotations, prunes and persistent errors to an
+// HTTP endpoint, such as a Slack or PagerDuty integration, without a separate
+// watcher process.
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/haleyrc/rolog"
+	"github.com/pkg/errors"
+)
+
+// Defaults for the zero values of Config.
+const (
+	DefaultRetries        = 3
+	DefaultBackoff        = time.Second
+	DefaultErrorThreshold = 3
+	DefaultTimeout        = 10 * time.Second
+)
+
+// Kind is the kind of event a notification is for.
+type Kind string
+
+// The kinds of event that are posted.
+const (
+	// KindRotate is a completed rotation.
+	KindRotate Kind = "rotate"
+	// KindPrune is an archive deleted by retention or Purge.
+	KindPrune Kind = "prune"
+	// KindError is an error the Rolog keeps running into.
+	KindError Kind = "error"
+)
+
+// Event is the body of a notification, posted as JSON. Text summarizes the
+// event, which is enough for chat integrations such as Slack's incoming
+// webhooks.
+type Event struct {
+	Kind     Kind                 `json:"kind"`
+	Time     time.Time            `json:"time"`
+	Text     string               `json:"text"`
+	Rotation *rolog.RotationEvent `json:"rotation,omitempty"`
+	Archive  *rolog.ArchiveInfo   `json:"archive,omitempty"`
+	Error    string               `json:"error,omitempty"`
+}
+
+// Config says where and when notifications are posted.
+type Config struct {
+	// URL is the endpoint notifications are posted to.
+	URL string
+	// Header is added to every request, for example for authorization.
+	Header http.Header
+	// Kinds are the kinds of event to post. All of them are posted if it is
+	// empty.
+	Kinds []Kind
+	// Retries is how many more times a failed post is attempted, waiting
+	// Backoff before the first retry and twice as long before each one after.
+	// They default to DefaultRetries and DefaultBackoff; a negative Retries
+	// disables retrying.
+	Retries int
+	Backoff time.Duration
+	// ErrorThreshold is how many errors in a row, without a rotation
+	// succeeding in between, make an error persistent enough to post. Only
+	// the error that reaches the threshold is posted. It defaults to
+	// DefaultErrorThreshold.
+	ErrorThreshold int
+	// Client sends the requests. It defaults to a client with a timeout of
+	// DefaultTimeout.
+	Client *http.Client
+}
+
+// DeliveryError is reported on the Rolog's Err channel when a notification
+// couldn't be posted. It doesn't count towards the ErrorThreshold.
+type DeliveryError struct {
+	Kind Kind
+	Err  error
+}
+
+// Error satisfies error.
+func (e *DeliveryError) Error() string {
+	return fmt.Sprintf("could not post %s webhook: %v", e.Kind, e.Err)
+}
+
+// Webhook posts the events of a Rolog as they happen. Posting happens on the
+// Webhook's own goroutine, so a slow endpoint never holds up the Rolog, but
+// events that arrive while it is far behind are discarded. Each post about an
+// archive is recorded in the Rolog's audit trail as an upload.
+type Webhook struct {
+	r     *rolog.Rolog
+	c     Config
+	kinds map[Kind]bool
+	stop  func()
+
+	// errors counts the errors since the last successful rotation, and is
+	// only touched by the observer goroutine
+	errors int
+}
+
+// New returns a Webhook that posts the events of r as configured by c.
+func New(r *rolog.Rolog, c Config) *Webhook {
+	if c.Retries == 0 {
+		c.Retries = DefaultRetries
+	}
+	if c.Backoff <= 0 {
+		c.Backoff = DefaultBackoff
+	}
+	if c.ErrorThreshold <= 0 {
+		c.ErrorThreshold = DefaultErrorThreshold
+	}
+	if c.Client == nil {
+		c.Client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	w := &Webhook{r: r, c: c, kinds: map[Kind]bool{}}
+	for _, k := range c.Kinds {
+		w.kinds[k] = true
+	}
+	w.stop = r.Observe(rolog.ObserverFuncs{
+		Rotate: w.rotated,
+		Prune:  w.pruned,
+		Error:  w.failed,
+	})
+	return w
+}
+
+// Close stops the Webhook from posting any more events.
+func (w *Webhook) Close() error {
+	w.stop()
+	return nil
+}
+
+// rotated posts a completed rotation.
+func (w *Webhook) rotated(e rolog.RotationEvent) {
+	w.errors = 0
+	w.post(Event{
+		Kind:     KindRotate,
+		Text:     fmt.Sprintf("Rotated %s to %s (%d bytes, %s)", e.OldPath, e.NewPath, e.Bytes, e.Reason),
+		Rotation: &e,
+	})
+}
+
+// pruned posts a deleted archive.
+func (w *Webhook) pruned(a rolog.ArchiveInfo) {
+	w.post(Event{
+		Kind:    KindPrune,
+		Text:    fmt.Sprintf("Pruned %s (%d bytes)", a.Path, a.Size),
+		Archive: &a,
+	})
+}
+
+// failed counts an error, posting it if it reaches the threshold.
+func (w *Webhook) failed(err error) {
+	if _, ok := err.(*DeliveryError); ok {
+		return
+	}
+
+	w.errors++
+	if w.errors != w.c.ErrorThreshold {
+		return
+	}
+	w.post(Event{
+		Kind:  KindError,
+		Text:  fmt.Sprintf("The log has run into %d errors in a row: %v", w.errors, err),
+		Error: err.Error(),
+	})
+}
+
+// post sends e if its kind is enabled, retrying with backoff, and reports
+// the failure if every attempt fails.
+func (w *Webhook) post(e Event) {
+	if len(w.kinds) > 0 && !w.kinds[e.Kind] {
+		return
+	}
+	e.Time = time.Now()
+
+	body, err := json.Marshal(e)
+	if err != nil {
+		w.r.Report(&DeliveryError{Kind: e.Kind, Err: err})
+		return
+	}
+
+	backoff := w.c.Backoff
+	for attempt := 0; ; attempt++ {
+		if err = w.send(body); err == nil || attempt >= w.c.Retries {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	if path := e.archive(); path != "" {
+		w.r.RecordUpload(path, w.c.URL, err)
+	}
+	if err != nil {
+		w.r.Report(&DeliveryError{Kind: e.Kind, Err: err})
+	}
+}
+
+// archive returns the path of the archive e is about, if any.
+func (e Event) archive() string {
+	switch {
+	case e.Rotation != nil:
+		return e.Rotation.NewPath
+	case e.Archive != nil:
+		return e.Archive.Path
+	}
+	return ""
+}
+
+// send makes a single attempt to post body.
+func (w *Webhook) send(body []byte) error {
+	req, err := http.NewRequest("POST", w.c.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, vs := range w.c.Header {
+		req.Header[k] = append([]string(nil), vs...)
+	}
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := w.c.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
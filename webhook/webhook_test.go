@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/haleyrc/rolog"
+	"github.com/pkg/errors"
+)
+
+func TestWebhookPostsEvents(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	var (
+		mu       sync.Mutex
+		events   []Event
+		attempts int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if req.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Wanted the configured header, got %q", req.Header.Get("Authorization"))
+		}
+		var e Event
+		json.NewDecoder(req.Body).Decode(&e)
+		events = append(events, e)
+	}))
+	defer srv.Close()
+
+	r, err := rolog.New(dir, "test", rolog.WithMaxBackups(1))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	w := New(r, Config{
+		URL:     srv.URL,
+		Header:  http.Header{"Authorization": {"Bearer token"}},
+		Backoff: time.Millisecond,
+	})
+	defer w.Close()
+
+	for i := 0; i < 2; i++ {
+		r.Write([]byte("entry\n"))
+		r.Rotate()
+	}
+	for i := 0; i < DefaultErrorThreshold+1; i++ {
+		r.Report(errors.New("disk on fire"))
+	}
+
+	want := []Kind{KindRotate, KindRotate, KindPrune, KindError}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := len(events)
+		mu.Unlock()
+		if n >= len(want) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != len(want) {
+		t.Errorf("Wanted %d events, got %+v", len(want), events)
+		t.FailNow()
+	}
+	for i, k := range want {
+		if events[i].Kind != k || events[i].Text == "" {
+			t.Errorf("Wanted a %s event, got %+v", k, events[i])
+		}
+	}
+	if events[0].Rotation == nil || events[2].Archive == nil || events[3].Error != "disk on fire" {
+		t.Errorf("Wanted the event details, got %+v", events)
+	}
+}
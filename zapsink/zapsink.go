@@ -0,0 +1,50 @@
+// Package zapsink adapts a Rolog for use with zap without losing its Sync
+// semantics.
+package zapsink
+
+import (
+	"github.com/haleyrc/rolog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Sink is a zapcore.WriteSyncer and zap.Sink backed by a Rolog. Sync maps to
+// Rolog.Flush, so zap's Sync calls make logs durable whatever the SyncPolicy.
+type Sink struct {
+	r *rolog.Rolog
+}
+
+var _ zap.Sink = (*Sink)(nil)
+
+// New returns a Sink writing to r.
+func New(r *rolog.Rolog) *Sink {
+	return &Sink{r: r}
+}
+
+// Write satisfies zapcore.WriteSyncer.
+func (s *Sink) Write(p []byte) (int, error) {
+	return s.r.Write(p)
+}
+
+// Sync satisfies zapcore.WriteSyncer.
+func (s *Sink) Sync() error {
+	return s.r.Flush()
+}
+
+// Close satisfies zap.Sink by closing the Rolog.
+func (s *Sink) Close() error {
+	return s.r.Close()
+}
+
+// NewCore returns a zapcore.Core that encodes entries enabled by level with enc
+// and writes them to r.
+func NewCore(r *rolog.Rolog, enc zapcore.Encoder, level zapcore.LevelEnabler) zapcore.Core {
+	return zapcore.NewCore(enc, New(r), level)
+}
+
+// NewLogger returns a production-style zap.Logger writing JSON entries at
+// level and above to r.
+func NewLogger(r *rolog.Rolog, level zapcore.Level, opts ...zap.Option) *zap.Logger {
+	enc := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	return zap.New(NewCore(r, enc, level), opts...)
+}
@@ -0,0 +1,61 @@
+package zapsink
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/haleyrc/rolog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggerSyncFlushes(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "tmp")
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	r, err := rolog.New(dir, "test", rolog.WithBuffer(4096, 0), rolog.WithSyncPolicy(rolog.SyncOnRotate))
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		r.Close()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("could not cleanup temp files: %q", err)
+		}
+	}()
+
+	logger := NewLogger(r, zapcore.InfoLevel)
+	logger.Debug("ignored")
+	logger.Info("served", zap.Int("status", 200))
+
+	if got, _ := ioutil.ReadFile(r.CurrentPath()); len(got) != 0 {
+		t.Errorf("Wanted the entry to be buffered before Sync, got %q", got)
+	}
+
+	if err := logger.Sync(); err != nil {
+		t.Errorf("could not sync: %q", err)
+	}
+
+	got, err := ioutil.ReadFile(r.CurrentPath())
+	if err != nil {
+		t.Errorf("unexpected error: %q", err)
+		t.FailNow()
+	}
+
+	var rec map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(got))), &rec); err != nil {
+		t.Errorf("Wanted a single JSON entry, got %q", got)
+		t.FailNow()
+	}
+	if rec["msg"] != "served" || rec["status"] != float64(200) {
+		t.Errorf("Wanted the entry's fields, got %v", rec)
+	}
+}